@@ -4,31 +4,103 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/router"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type ctxKey struct{}
+type ctxKey int
 
-var userIDKey ctxKey
+const (
+	userIDKey ctxKey = iota
+	scopesKey
+	profileKey
+)
+
+const bearerScheme = "Bearer "
+
+// Profile holds the learning preferences of the user carried by its tokens
+type Profile struct {
+	NativeLang  string
+	TargetLangs []string
+	DailyGoal   int
+	Timezone    string
+}
+
+// TokenInfo describes an opaque token resolved by an Introspector
+type TokenInfo struct {
+	Active  bool
+	UserID  string
+	Scopes  []string
+	Profile Profile
+}
+
+// Introspector resolves opaque tokens, such as personal access tokens, to their owner
+type Introspector interface {
+	Introspect(ctx context.Context, token string) (TokenInfo, error)
+}
+
+type authConfig struct {
+	key          any
+	tokenPrefix  string
+	introspector Introspector
+	denylist     Denylist
+}
+
+// AuthOption configures the Auth middleware
+type AuthOption func(*authConfig)
+
+// WithIntrospection makes Auth resolve tokens starting with prefix through the introspector
+// instead of verifying them as JWTs
+func WithIntrospection(prefix string, i Introspector) AuthOption {
+	return func(c *authConfig) {
+		c.tokenPrefix = prefix
+		c.introspector = i
+	}
+}
+
+func Auth(key any, opts ...AuthOption) router.Middleware {
+	cfg := &authConfig{key: key}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
-func Auth(key any) router.Middleware {
 	return func(next http.Handler) http.Handler {
-		return authMiddleware(next, key)
+		return authMiddleware(next, cfg)
 	}
 }
 
-func authMiddleware(next http.Handler, key any) http.Handler {
+func authMiddleware(next http.Handler, cfg *authConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rawToken := r.Header.Get("Authorization")
+		rawToken := BearerToken(r)
 		if rawToken == "" {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
+		if cfg.introspector != nil && strings.HasPrefix(rawToken, cfg.tokenPrefix) {
+			info, err := cfg.introspector.Introspect(r.Context(), rawToken)
+			if err != nil {
+				authError("failed to introspect token", w, r, err)
+				return
+			}
+			if !info.Active || info.UserID == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userIDKey, info.UserID)
+			ctx = context.WithValue(ctx, scopesKey, info.Scopes)
+			ctx = context.WithValue(ctx, profileKey, info.Profile)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		token, err := jwt.Parse(rawToken, func(t *jwt.Token) (any, error) {
-			return key, nil
+			return cfg.key, nil
 		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 		if err != nil {
@@ -52,11 +124,84 @@ func authMiddleware(next http.Handler, key any) http.Handler {
 			return
 		}
 
+		if cfg.denylist != nil {
+			// tokens without an issue time are treated as issued before any revocation
+			var issuedAt time.Time
+			if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+				issuedAt = iat.Time
+			}
+
+			denied, err := cfg.denylist.Denied(r.Context(), uid, issuedAt)
+			if err != nil {
+				authError("failed to check token denylist", w, r, err)
+				return
+			}
+			if denied {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
 		ctx := context.WithValue(r.Context(), userIDKey, uid)
+		ctx = context.WithValue(ctx, profileKey, profileFromClaims(claims))
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// profileFromClaims reads the profile claims, ignoring the ones with unexpected types
+func profileFromClaims(claims jwt.MapClaims) Profile {
+	var p Profile
+	p.NativeLang, _ = claims["native_lang"].(string)
+	p.Timezone, _ = claims["zoneinfo"].(string)
+	if goal, ok := claims["daily_goal"].(float64); ok {
+		p.DailyGoal = int(goal)
+	}
+	if langs, ok := claims["target_langs"].([]any); ok {
+		for _, l := range langs {
+			if s, ok := l.(string); ok {
+				p.TargetLangs = append(p.TargetLangs, s)
+			}
+		}
+	}
+	return p
+}
+
+// BearerToken returns the token from the Authorization header, with or without the Bearer scheme
+func BearerToken(r *http.Request) string {
+	h := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(h) > len(bearerScheme) && strings.EqualFold(h[:len(bearerScheme)], bearerScheme) {
+		return strings.TrimSpace(h[len(bearerScheme):])
+	}
+	return h
+}
+
+// RequireScope restricts scoped tokens to the given scopes: safe methods need the read scope
+// and all other methods need the write scope. Requests authenticated with unscoped tokens pass through.
+func RequireScope(read, write string) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := ScopesFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			need := write
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				need = read
+			}
+
+			if !slices.Contains(scopes, need) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func authError(msg string, w http.ResponseWriter, r *http.Request, err error) {
 	slog.Error(msg,
 		"error", err,
@@ -71,3 +216,17 @@ func UserIDFromContext(ctx context.Context) string {
 	uid, _ := ctx.Value(userIDKey).(string)
 	return uid
 }
+
+// ScopesFromContext returns the scopes of the request token. The second value is false
+// when the token is not restricted to any scopes.
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	return scopes, ok
+}
+
+// ProfileFromContext returns the profile carried by the request token.
+// Tokens without profile claims yield the zero profile.
+func ProfileFromContext(ctx context.Context) Profile {
+	p, _ := ctx.Value(profileKey).(Profile)
+	return p
+}
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/router"
 	"github.com/golang-jwt/jwt/v5"
@@ -70,6 +73,37 @@ func TestAuth_ValidToken(t *testing.T) {
 	assert.Equal(t, "user-123\n", rec.Body.String())
 }
 
+func TestAuth_ProfileClaims(t *testing.T) {
+	key := []byte("test-api-key")
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":          "user-123",
+		"native_lang":  "en",
+		"target_langs": []string{"de", "fr"},
+		"daily_goal":   20,
+		"zoneinfo":     "Europe/Berlin",
+	})
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	var profile Profile
+	r := router.New()
+	r.Use(Auth(key))
+	r.HandleFunc("/protected", func(w http.ResponseWriter, r *http.Request) {
+		profile = ProfileFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", signed)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, Profile{
+		NativeLang:  "en",
+		TargetLangs: []string{"de", "fr"},
+		DailyGoal:   20,
+		Timezone:    "Europe/Berlin",
+	}, profile)
+}
+
 func TestAuth_ValidToken_NoUser(t *testing.T) {
 	key := []byte("test-api-key")
 	token := jwt.New(jwt.SigningMethodHS256)
@@ -91,3 +125,175 @@ func TestAuth_ValidToken_NoUser(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, rec.Code)
 }
+
+type mockIntrospector struct {
+	introspectFunc func(ctx context.Context, token string) (TokenInfo, error)
+}
+
+func (m *mockIntrospector) Introspect(ctx context.Context, token string) (TokenInfo, error) {
+	return m.introspectFunc(ctx, token)
+}
+
+func TestAuth_BearerToken(t *testing.T) {
+	key := []byte("test-api-key")
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "user-123"})
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	r := router.New()
+	r.Use(Auth(key))
+
+	r.HandleFunc("/protected", func(w http.ResponseWriter, r *http.Request) {
+		_, scoped := ScopesFromContext(r.Context())
+		assert.False(t, scoped)
+		fmt.Fprintln(w, UserIDFromContext(r.Context()))
+	})
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-123\n", rec.Body.String())
+}
+
+func TestAuth_Introspection(t *testing.T) {
+	introspector := &mockIntrospector{
+		introspectFunc: func(ctx context.Context, token string) (TokenInfo, error) {
+			switch token {
+			case "lxp_active":
+				return TokenInfo{Active: true, UserID: "user-123", Scopes: []string{"words:read"}}, nil
+			case "lxp_error":
+				return TokenInfo{}, errors.New("auth service down")
+			default:
+				return TokenInfo{}, nil
+			}
+		},
+	}
+
+	r := router.New()
+	r.Use(Auth([]byte("test-api-key"), WithIntrospection("lxp_", introspector)))
+
+	r.HandleFunc("/protected", func(w http.ResponseWriter, r *http.Request) {
+		scopes, scoped := ScopesFromContext(r.Context())
+		assert.True(t, scoped)
+		fmt.Fprintln(w, UserIDFromContext(r.Context()), scopes)
+	})
+
+	tests := []struct {
+		token  string
+		status int
+		body   string
+	}{
+		{"Bearer lxp_active", http.StatusOK, "user-123 [words:read]\n"},
+		{"lxp_revoked", http.StatusUnauthorized, ""},
+		{"lxp_error", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", tt.token)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.status, rec.Code, tt.token)
+		if tt.body != "" {
+			assert.Equal(t, tt.body, rec.Body.String())
+		}
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	handler := RequireScope("words:read", "words:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		ctx    context.Context
+		status int
+	}{
+		{"unscoped read", "GET", context.Background(), http.StatusOK},
+		{"unscoped write", "PUT", context.Background(), http.StatusOK},
+		{"read scope read", "GET", context.WithValue(context.Background(), scopesKey, []string{"words:read"}), http.StatusOK},
+		{"read scope write", "PUT", context.WithValue(context.Background(), scopesKey, []string{"words:read"}), http.StatusForbidden},
+		{"write scope read", "GET", context.WithValue(context.Background(), scopesKey, []string{"words:write"}), http.StatusForbidden},
+		{"write scope write", "DELETE", context.WithValue(context.Background(), scopesKey, []string{"words:write"}), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+type mockDenylist struct {
+	deniedFunc func(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+func (m *mockDenylist) Denied(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	return m.deniedFunc(ctx, userID, issuedAt)
+}
+
+func TestAuth_Denylist(t *testing.T) {
+	key := []byte("test-api-key")
+	issuedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:  "user-123",
+		IssuedAt: jwt.NewNumericDate(issuedAt),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		denied bool
+		status int
+	}{
+		{name: "allowed", status: http.StatusOK},
+		{name: "denied", denied: true, status: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := router.New()
+			r.Use(Auth(key, WithDenylist(&mockDenylist{
+				deniedFunc: func(ctx context.Context, userID string, iat time.Time) (bool, error) {
+					assert.Equal(t, "user-123", userID)
+					assert.True(t, issuedAt.Equal(iat))
+					return tc.denied, nil
+				},
+			})))
+			r.HandleFunc("/protected", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	for header, want := range map[string]string{
+		"Bearer abc":   "abc",
+		"bearer  abc ": "abc",
+		"abc":          "abc",
+		"":             "",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", header)
+		assert.Equal(t, want, BearerToken(r), header)
+	}
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Denylist tells whether the tokens of a user issued at the given time have been revoked
+type Denylist interface {
+	Denied(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+// WithDenylist makes Auth reject JWTs denied by the denylist
+func WithDenylist(d Denylist) AuthOption {
+	return func(c *authConfig) {
+		c.denylist = d
+	}
+}
+
+// Revoked tells whether a token issued at issuedAt was revoked by revoking the sessions of its user at revokedAt.
+// JWTs carry their issue time in whole seconds, so both times are compared in seconds, and a token issued
+// in the same second as the revocation counts as revoked: no token issued before a revocation is let through,
+// at the cost of rejecting the tokens issued within the rest of that second.
+func Revoked(issuedAt, revokedAt time.Time) bool {
+	return !revokedAt.IsZero() && !issuedAt.Truncate(time.Second).After(revokedAt.Truncate(time.Second))
+}
+
+// tokenSource issues service tokens for calling other services
+type tokenSource interface {
+	Token(audience string) (string, error)
+}
+
+// denylistRefreshTimeout bounds a refresh of a RemoteDenylist, which runs apart from the requests checked against it
+const denylistRefreshTimeout = 5 * time.Second
+
+// RemoteDenylist polls the revoked sessions of the auth service. The list is refreshed
+// at most once per TTL, so revocations take effect after at most one TTL.
+// Refreshes run in the background while the last list keeps being used, only the first load
+// is waited for. When a refresh fails, the last list is kept and the next check tries again.
+type RemoteDenylist struct {
+	url      string
+	ttl      time.Duration
+	client   *http.Client
+	tokens   tokenSource
+	audience string
+	now      func() time.Time
+
+	mu        sync.Mutex
+	revoked   map[string]time.Time
+	loaded    bool
+	refreshAt time.Time
+	// refreshing is closed when the running refresh ends, it is nil while no refresh runs
+	refreshing chan struct{}
+	// err is the error of the last refresh
+	err error
+}
+
+// RemoteDenylistOption configures a RemoteDenylist
+type RemoteDenylistOption func(*RemoteDenylist)
+
+// WithDenylistToken authenticates requests with service tokens issued for the audience service
+func WithDenylistToken(src tokenSource, audience string) RemoteDenylistOption {
+	return func(d *RemoteDenylist) {
+		d.tokens = src
+		d.audience = audience
+	}
+}
+
+// NewRemoteDenylist creates a denylist loading the revoked sessions from the given endpoint every ttl
+func NewRemoteDenylist(endpoint string, ttl time.Duration, opts ...RemoteDenylistOption) *RemoteDenylist {
+	d := &RemoteDenylist{
+		url:    endpoint,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 5 * time.Second},
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+type revokedSessionsResponse struct {
+	Sessions []struct {
+		Sub       string    `json:"sub"`
+		RevokedAt time.Time `json:"revoked_at"`
+	} `json:"sessions"`
+}
+
+// Denied reports whether the sessions of the user have been revoked after the token was issued
+func (d *RemoteDenylist) Denied(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	d.mu.Lock()
+	now := d.now()
+	if d.refreshing == nil && !now.Before(d.refreshAt) {
+		d.refreshing = make(chan struct{})
+		go d.refresh(d.refreshing, now)
+	}
+	done, loaded := d.refreshing, d.loaded
+	d.mu.Unlock()
+
+	// there is nothing to check the token against until the first list is loaded
+	if !loaded && done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.loaded {
+		return false, d.err
+	}
+
+	return Revoked(issuedAt, d.revoked[userID]), nil
+}
+
+// refresh loads the list and swaps it in, then closes done. The lock is not held while loading.
+func (d *RemoteDenylist) refresh(done chan struct{}, started time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), denylistRefreshTimeout)
+	defer cancel()
+
+	revoked, err := d.load(ctx)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer close(done)
+
+	d.refreshing = nil
+	d.err = err
+	if err != nil {
+		if d.loaded {
+			slog.Warn("failed to refresh token denylist, using the previous one", "error", err)
+		}
+		return
+	}
+
+	d.revoked = revoked
+	d.loaded = true
+	d.refreshAt = started.Add(d.ttl)
+}
+
+func (d *RemoteDenylist) load(ctx context.Context) (map[string]time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create denylist request: %w", err)
+	}
+
+	if d.tokens != nil {
+		tk, err := d.tokens.Token(d.audience)
+		if err != nil {
+			return nil, fmt.Errorf("issue service token: %w", err)
+		}
+		req.Header.Set("Authorization", bearerScheme+tk)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("load denylist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("load denylist: unexpected status %d", resp.StatusCode)
+	}
+
+	var rs revokedSessionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rs); err != nil {
+		return nil, fmt.Errorf("decode denylist: %w", err)
+	}
+
+	revoked := make(map[string]time.Time, len(rs.Sessions))
+	for _, s := range rs.Sessions {
+		if s.RevokedAt.After(revoked[s.Sub]) {
+			revoked[s.Sub] = s.RevokedAt
+		}
+	}
+
+	return revoked, nil
+}
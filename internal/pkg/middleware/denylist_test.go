@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(audience string) (string, error) {
+	return string(s) + ":" + audience, nil
+}
+
+func TestRevoked(t *testing.T) {
+	revokedAt := time.Date(2025, 1, 2, 3, 4, 5, 500_000_000, time.UTC)
+
+	assert.True(t, Revoked(revokedAt.Add(-time.Second), revokedAt))
+	// JWTs issued in the second of the revocation carry the same issue time as those issued before it
+	assert.True(t, Revoked(revokedAt.Truncate(time.Second), revokedAt))
+	assert.True(t, Revoked(revokedAt.Add(400*time.Millisecond), revokedAt))
+	assert.False(t, Revoked(revokedAt.Add(500*time.Millisecond), revokedAt))
+	assert.False(t, Revoked(revokedAt, time.Time{}))
+}
+
+func TestRemoteDenylist(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "Bearer service-token:auth", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"sessions":[{"sub":"user-123","revoked_at":"2025-01-02T03:04:05Z"}]}`))
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	d := NewRemoteDenylist(srv.URL, time.Minute, WithDenylistToken(staticTokenSource("service-token"), "auth"))
+	d.now = func() time.Time { return now }
+
+	revokedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	denied, err := d.Denied(context.Background(), "user-123", revokedAt.Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, denied)
+
+	denied, err = d.Denied(context.Background(), "user-123", revokedAt.Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, denied)
+
+	denied, err = d.Denied(context.Background(), "user-456", time.Time{})
+	require.NoError(t, err)
+	assert.False(t, denied)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the list is refreshed in the background
+	now = now.Add(2 * time.Minute)
+	_, err = d.Denied(context.Background(), "user-123", revokedAt)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestRemoteDenylist_Error(t *testing.T) {
+	var (
+		fail  atomic.Bool
+		calls atomic.Int32
+	)
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sessions":[{"sub":"user-123","revoked_at":"2025-01-02T03:04:05Z"}]}`))
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	d := NewRemoteDenylist(srv.URL, time.Minute)
+	d.now = func() time.Time { return now }
+
+	_, err := d.Denied(context.Background(), "user-123", time.Time{})
+	require.Error(t, err)
+
+	// a failed load is tried again right away
+	fail.Store(false)
+	denied, err := d.Denied(context.Background(), "user-123", time.Time{})
+	require.NoError(t, err)
+	assert.True(t, denied)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// a failed refresh keeps the previous list and is tried again by the next check
+	fail.Store(true)
+	now = now.Add(2 * time.Minute)
+	denied, err = d.Denied(context.Background(), "user-123", time.Time{})
+	require.NoError(t, err)
+	assert.True(t, denied)
+	assert.Eventually(t, func() bool {
+		denied, err := d.Denied(context.Background(), "user-123", time.Time{})
+		return err == nil && denied && calls.Load() >= 4
+	}, time.Second, time.Millisecond)
+}
+
+func TestRemoteDenylist_SlowRefresh(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"sessions":[{"sub":"user-123","revoked_at":"2025-01-02T03:04:05Z"}]}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	now := time.Now()
+	d := NewRemoteDenylist(srv.URL, time.Minute)
+	d.now = func() time.Time { return now }
+
+	_, err := d.Denied(context.Background(), "user-123", time.Time{})
+	require.NoError(t, err)
+
+	// checks use the previous list while the refresh hangs, and start no other refresh
+	now = now.Add(2 * time.Minute)
+	for range 3 {
+		denied, err := d.Denied(context.Background(), "user-123", time.Time{})
+		require.NoError(t, err)
+		assert.True(t, denied)
+	}
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestRemoteDenylist_Canceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	d := NewRemoteDenylist(srv.URL, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := d.Denied(ctx, "user-123", time.Time{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
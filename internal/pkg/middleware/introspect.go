@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxCachedTokens = 10000
+
+// RemoteIntrospector resolves tokens through the RFC 7662 introspection endpoint of the auth service.
+// Results are cached for a short time so that revocation takes effect after at most one TTL.
+type RemoteIntrospector struct {
+	url      string
+	ttl      time.Duration
+	client   *http.Client
+	tokens   tokenSource
+	audience string
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedToken
+}
+
+type cachedToken struct {
+	info      TokenInfo
+	expiresAt time.Time
+}
+
+// RemoteIntrospectorOption configures a RemoteIntrospector
+type RemoteIntrospectorOption func(*RemoteIntrospector)
+
+// WithIntrospectionToken authenticates requests with service tokens issued for the audience service,
+// the auth service only introspects tokens for other services
+func WithIntrospectionToken(src tokenSource, audience string) RemoteIntrospectorOption {
+	return func(ri *RemoteIntrospector) {
+		ri.tokens = src
+		ri.audience = audience
+	}
+}
+
+// NewRemoteIntrospector creates an introspector calling the given endpoint and caching results for ttl
+func NewRemoteIntrospector(endpoint string, ttl time.Duration, opts ...RemoteIntrospectorOption) *RemoteIntrospector {
+	ri := &RemoteIntrospector{
+		url:    endpoint,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 5 * time.Second},
+		now:    time.Now,
+		cache:  make(map[[sha256.Size]byte]cachedToken),
+	}
+	for _, opt := range opts {
+		opt(ri)
+	}
+
+	return ri
+}
+
+type introspectResponse struct {
+	Active bool   `json:"active"`
+	Sub    string `json:"sub"`
+	Scope  string `json:"scope"`
+	Exp    int64  `json:"exp"`
+
+	NativeLang  string   `json:"native_lang"`
+	TargetLangs []string `json:"target_langs"`
+	DailyGoal   int      `json:"daily_goal"`
+	Timezone    string   `json:"zoneinfo"`
+}
+
+// Introspect returns the state of the token, consulting the cache first
+func (ri *RemoteIntrospector) Introspect(ctx context.Context, token string) (TokenInfo, error) {
+	// only digests are kept in memory
+	key := sha256.Sum256([]byte(token))
+	now := ri.now()
+	if info, ok := ri.cached(key, now); ok {
+		return info, nil
+	}
+
+	body := url.Values{"token": {token}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ri.url, strings.NewReader(body))
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if ri.tokens != nil {
+		tk, err := ri.tokens.Token(ri.audience)
+		if err != nil {
+			return TokenInfo{}, fmt.Errorf("issue service token: %w", err)
+		}
+		req.Header.Set("Authorization", bearerScheme+tk)
+	}
+
+	resp, err := ri.client.Do(req)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return TokenInfo{}, fmt.Errorf("introspect token: unexpected status %d", resp.StatusCode)
+	}
+
+	var ir introspectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ir); err != nil {
+		return TokenInfo{}, fmt.Errorf("decode introspection response: %w", err)
+	}
+
+	info := TokenInfo{Active: ir.Active}
+	expiresAt := now.Add(ri.ttl)
+	if ir.Active {
+		info.UserID = ir.Sub
+		info.Scopes = strings.Fields(ir.Scope)
+		info.Profile = Profile{
+			NativeLang:  ir.NativeLang,
+			TargetLangs: ir.TargetLangs,
+			DailyGoal:   ir.DailyGoal,
+			Timezone:    ir.Timezone,
+		}
+		if ir.Exp > 0 {
+			if exp := time.Unix(ir.Exp, 0); exp.Before(expiresAt) {
+				expiresAt = exp
+			}
+		}
+	}
+
+	ri.store(key, cachedToken{info: info, expiresAt: expiresAt}, now)
+	return info, nil
+}
+
+func (ri *RemoteIntrospector) cached(key [sha256.Size]byte, now time.Time) (TokenInfo, bool) {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	ct, ok := ri.cache[key]
+	if !ok || !now.Before(ct.expiresAt) {
+		return TokenInfo{}, false
+	}
+
+	return ct.info, true
+}
+
+func (ri *RemoteIntrospector) store(key [sha256.Size]byte, ct cachedToken, now time.Time) {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+
+	if len(ri.cache) >= maxCachedTokens {
+		for k, v := range ri.cache {
+			if !now.Before(v.expiresAt) {
+				delete(ri.cache, k)
+			}
+		}
+	}
+
+	// every entry is still fresh, start over rather than grow without bound
+	if len(ri.cache) >= maxCachedTokens {
+		clear(ri.cache)
+	}
+
+	ri.cache[key] = ct
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteIntrospector(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		assert.Equal(t, "Bearer service-token:auth", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+
+		if r.PostForm.Get("token") != "lxp_active" {
+			_ = json.NewEncoder(w).Encode(introspectResponse{Active: false})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(introspectResponse{
+			Active: true,
+			Sub:    "user-123",
+			Scope:  "words:read words:write",
+
+			TargetLangs: []string{"de"},
+			Timezone:    "Europe/Berlin",
+		})
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	ri := NewRemoteIntrospector(srv.URL, time.Minute, WithIntrospectionToken(staticTokenSource("service-token"), "auth"))
+	ri.now = func() time.Time { return now }
+
+	info, err := ri.Introspect(context.Background(), "lxp_active")
+	require.NoError(t, err)
+	assert.Equal(t, TokenInfo{
+		Active:  true,
+		UserID:  "user-123",
+		Scopes:  []string{"words:read", "words:write"},
+		Profile: Profile{TargetLangs: []string{"de"}, Timezone: "Europe/Berlin"},
+	}, info)
+
+	_, err = ri.Introspect(context.Background(), "lxp_active")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(2 * time.Minute)
+	_, err = ri.Introspect(context.Background(), "lxp_active")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	info, err = ri.Introspect(context.Background(), "lxp_unknown")
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+}
+
+func TestRemoteIntrospector_TokenExpiresBeforeTTL(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(introspectResponse{
+			Active: true,
+			Sub:    "user-123",
+			Exp:    now.Add(10 * time.Second).Unix(),
+		})
+	}))
+	defer srv.Close()
+
+	ri := NewRemoteIntrospector(srv.URL, time.Minute)
+	ri.now = func() time.Time { return now }
+
+	_, err := ri.Introspect(context.Background(), "lxp_active")
+	require.NoError(t, err)
+
+	now = now.Add(20 * time.Second)
+	_, err = ri.Introspect(context.Background(), "lxp_active")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRemoteIntrospector_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	ri := NewRemoteIntrospector(srv.URL, time.Minute)
+	_, err := ri.Introspect(context.Background(), "lxp_active")
+	require.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/router"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
+)
+
+type serviceKey struct{}
+
+// ServiceAuth only lets through requests carrying a valid service token
+func ServiceAuth(v *svctoken.Verifier) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawToken := BearerToken(r)
+			if rawToken == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := v.Verify(rawToken)
+			if err != nil {
+				authError("failed to verify service token", w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), serviceKey{}, claims.Service())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ServiceFromContext returns the name of the calling service
+func ServiceFromContext(ctx context.Context) string {
+	svc, _ := ctx.Value(serviceKey{}).(string)
+	return svc
+}
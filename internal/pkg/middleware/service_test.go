@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/router"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAuth(t *testing.T) {
+	key := []byte("service-secret")
+	tk, err := svctoken.NewSigner("words", key, time.Minute).Token("image")
+	require.NoError(t, err)
+
+	r := router.New()
+	r.Use(ServiceAuth(svctoken.NewVerifier("image", key)))
+
+	r.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, ServiceFromContext(r.Context()))
+	})
+
+	req := httptest.NewRequest("POST", "/internal", nil)
+	req.Header.Set("Authorization", "Bearer "+tk)
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "words\n", rec.Body.String())
+}
+
+func TestServiceAuth_Unauthorized(t *testing.T) {
+	key := []byte("service-secret")
+	otherAudience, err := svctoken.NewSigner("words", key, time.Minute).Token("auth")
+	require.NoError(t, err)
+
+	r := router.New()
+	r.Use(ServiceAuth(svctoken.NewVerifier("image", key)))
+
+	r.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tk := range []string{"", "garbage", otherAudience} {
+		req := httptest.NewRequest("POST", "/internal", nil)
+		req.Header.Set("Authorization", tk)
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+}
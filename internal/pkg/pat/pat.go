@@ -0,0 +1,11 @@
+// Package pat holds what the services share about the personal access tokens issued by the auth service
+package pat
+
+// Prefix marks personal access tokens so that they can be told apart from JWTs
+const Prefix = "lxp_"
+
+// Scopes which can be granted to personal access tokens
+const (
+	ScopeWordsRead  = "words:read"
+	ScopeWordsWrite = "words:write"
+)
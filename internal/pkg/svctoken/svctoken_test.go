@@ -0,0 +1,65 @@
+package svctoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerVerifier(t *testing.T) {
+	key := []byte("service-secret")
+	signer := NewSigner("words", key, time.Minute)
+	verifier := NewVerifier("image", key)
+
+	tk, err := signer.Token("image")
+	require.NoError(t, err)
+
+	claims, err := verifier.Verify(tk)
+	require.NoError(t, err)
+	assert.Equal(t, "words", claims.Service())
+}
+
+func TestVerifier_WrongAudience(t *testing.T) {
+	key := []byte("service-secret")
+	tk, err := NewSigner("words", key, time.Minute).Token("auth")
+	require.NoError(t, err)
+
+	_, err = NewVerifier("image", key).Verify(tk)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_WrongKey(t *testing.T) {
+	tk, err := NewSigner("words", []byte("service-secret"), time.Minute).Token("image")
+	require.NoError(t, err)
+
+	_, err = NewVerifier("image", []byte("other-secret")).Verify(tk)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_Expired(t *testing.T) {
+	key := []byte("service-secret")
+	signer := NewSigner("words", key, time.Minute)
+	signer.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	tk, err := signer.Token("image")
+	require.NoError(t, err)
+
+	_, err = NewVerifier("image", key).Verify(tk)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifier_UserToken(t *testing.T) {
+	key := []byte("service-secret")
+	tk, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "user-123",
+		Audience:  jwt.ClaimStrings{"image"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	_, err = NewVerifier("image", key).Verify(tk)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -64,7 +64,7 @@ func StartPostgres(ctx context.Context, cfg PostgresStartRequest) (PostgresStart
 	}, closer
 }
 
-func RunMigrations(t *testing.T, db *sql.DB, folder string) {
+func RunMigrations(t testing.TB, db *sql.DB, folder string) {
 	t.Helper()
 
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
@@ -89,11 +89,11 @@ func RunMigrations(t *testing.T, db *sql.DB, folder string) {
 }
 
 type dbQuery struct {
-	t   *testing.T
+	t   testing.TB
 	row *sql.Row
 }
 
-func Query(t *testing.T, db *sql.DB, query string, args ...interface{}) *dbQuery {
+func Query(t testing.TB, db *sql.DB, query string, args ...interface{}) *dbQuery {
 	t.Helper()
 
 	row := db.QueryRow(query, args...)
@@ -5,19 +5,29 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/config"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/provider"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/remote"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/rest"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
 )
 
+const (
+	serviceName      = "auth"
+	wordsServiceName = "words"
+)
+
 func run(ctx context.Context) error {
 	slog.Info("starting auth service")
 
@@ -41,6 +51,9 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("failed to register oauth providers: %w", err)
 	}
 
+	signer := svctoken.NewSigner(serviceName, []byte(cfg.Service.Secret), cfg.Service.TokenTTL)
+	words := remote.NewUserDataClient(cfg.Words.InternalURL, remote.WithServiceToken(signer, wordsServiceName))
+
 	srv := service.NewAuth(
 		service.WithAuthenticator(auth),
 		service.WithStore(pgs),
@@ -52,6 +65,13 @@ func run(ctx context.Context) error {
 			Secret: token.NewSecretString(cfg.JWT.RefreshSecret),
 			TTL:    cfg.JWT.RefreshTTL,
 		})),
+		service.WithDeviceFlow(service.DeviceConfig{
+			VerificationURL: cfg.Device.VerificationURL,
+			CodeTTL:         cfg.Device.CodeTTL,
+			PollInterval:    cfg.Device.PollInterval,
+		}),
+		service.WithUserData(wordsServiceName, words),
+		service.WithSessionDenylist(cfg.JWT.AccessTTL),
 	)
 
 	mux := http.NewServeMux()
@@ -62,8 +82,23 @@ func run(ctx context.Context) error {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	api := rest.NewAPI(srv)
-	mux.Handle("/api/v1/", api)
+	proxies, err := parseProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	clients, err := parseIntrospectionClients(cfg.Introspection.Clients)
+	if err != nil {
+		return err
+	}
+
+	api := rest.NewAPI(srv, rest.WithTrustedProxies(proxies...), rest.WithIntrospectionClients(clients...))
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", api))
+
+	// endpoints called by other services, such as the session denylist
+	verifier := svctoken.NewVerifier(serviceName, []byte(cfg.Service.Secret))
+	internal := middleware.ServiceAuth(verifier)(rest.NewInternalAPI(srv))
+	mux.Handle("/internal/v1/", http.StripPrefix("/internal/v1", internal))
 
 	httpSrv := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.HTTP.ListenAddr, cfg.HTTP.ListenPort),
@@ -92,6 +127,45 @@ func run(ctx context.Context) error {
 	}
 }
 
+// parseProxies parses a comma separated list of networks
+func parseProxies(s string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy network %q: %w", p, err)
+		}
+		proxies = append(proxies, prefix)
+	}
+
+	return proxies, nil
+}
+
+// parseIntrospectionClients parses a comma separated list of client_id:client_secret pairs
+func parseIntrospectionClients(s string) ([]rest.IntrospectionClient, error) {
+	var clients []rest.IntrospectionClient
+	for i, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(c, ":")
+		if !ok || id == "" || secret == "" {
+			// the entry is not quoted, it holds a secret
+			return nil, fmt.Errorf("invalid introspection client %d: expected client_id:client_secret", i+1)
+		}
+		clients = append(clients, rest.IntrospectionClient{ID: id, Secret: secret})
+	}
+
+	return clients, nil
+}
+
 func registerProviders(ctx context.Context, auth *oauth.Authenticator, cfg config.Config) error {
 	prvGoogle, err := provider.NewGoogle(ctx, provider.GoogleConfig{
 		ClientID:     cfg.OAuth.Google.ClientID,
@@ -55,6 +55,7 @@ func TestRun(t *testing.T) {
 	t.Setenv("JWT_REFRESH_SECRET", "secret")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_ID", "client_id")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_SECRET", "client_secret")
+	t.Setenv("SERVICE_SECRET", "service_secret")
 	t.Setenv("DB_HOST", dbHost)
 	t.Setenv("DB_PORT", dbPort)
 	t.Setenv("DB_NAME", dbName)
@@ -117,6 +118,7 @@ func TestRun_Cancel(t *testing.T) {
 	t.Setenv("JWT_REFRESH_SECRET", "secret")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_ID", "client_id")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_SECRET", "client_secret")
+	t.Setenv("SERVICE_SECRET", "service_secret")
 	t.Setenv("DB_HOST", dbHost)
 	t.Setenv("DB_PORT", dbPort)
 	t.Setenv("DB_NAME", dbName)
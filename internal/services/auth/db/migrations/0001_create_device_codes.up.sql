@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS device_codes (
+    id SERIAL PRIMARY KEY,
+    device_code_hash TEXT NOT NULL UNIQUE,
+    user_code VARCHAR(16) NOT NULL UNIQUE,
+    client_id TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    user_id INT,
+    provider VARCHAR(64),
+    poll_interval INT NOT NULL,
+    last_polled_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
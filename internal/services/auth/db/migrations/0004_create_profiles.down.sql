@@ -0,0 +1 @@
+DROP TABLE IF EXISTS profiles;
\ No newline at end of file
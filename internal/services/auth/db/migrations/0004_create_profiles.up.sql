@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS profiles (
+    user_id INT PRIMARY KEY,
+    display_name TEXT NOT NULL DEFAULT '',
+    native_lang VARCHAR(16) NOT NULL DEFAULT '',
+    target_langs TEXT[] NOT NULL DEFAULT '{}',
+    daily_goal INT NOT NULL DEFAULT 0,
+    timezone TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+ALTER TABLE identities
+    DROP CONSTRAINT IF EXISTS identities_user_id_fkey,
+    ADD CONSTRAINT identities_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
\ No newline at end of file
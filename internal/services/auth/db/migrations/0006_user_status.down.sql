@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS identities_email_idx;
+DROP INDEX IF EXISTS identities_user_id_idx;
+DROP INDEX IF EXISTS users_sessions_revoked_at_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS sessions_revoked_at,
+    DROP COLUMN IF EXISTS disabled_at,
+    DROP COLUMN IF EXISTS role;
\ No newline at end of file
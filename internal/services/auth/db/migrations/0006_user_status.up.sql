@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS users_sessions_revoked_at_idx ON users (sessions_revoked_at);
+CREATE INDEX IF NOT EXISTS identities_user_id_idx ON identities (user_id);
+CREATE INDEX IF NOT EXISTS identities_email_idx ON identities (LOWER(email) text_pattern_ops);
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS user_id INT;
+
+UPDATE audit_events AS e
+SET user_id = u.id
+FROM users AS u
+WHERE u.uid = e.user_uid;
+
+DELETE FROM audit_events WHERE user_uid IS NOT NULL AND user_id IS NULL;
+
+DROP INDEX IF EXISTS audit_events_user_uid_idx;
+ALTER TABLE audit_events
+    DROP COLUMN IF EXISTS user_uid,
+    ADD CONSTRAINT audit_events_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS audit_events_user_id_idx ON audit_events (user_id, created_at);
\ No newline at end of file
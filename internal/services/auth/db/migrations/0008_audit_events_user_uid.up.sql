@@ -0,0 +1,11 @@
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS user_uid UUID;
+
+UPDATE audit_events AS e
+SET user_uid = u.uid
+FROM users AS u
+WHERE u.id = e.user_id;
+
+DROP INDEX IF EXISTS audit_events_user_id_idx;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS user_id;
+
+CREATE INDEX IF NOT EXISTS audit_events_user_uid_idx ON audit_events (user_uid, created_at);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE device_codes
+    DROP COLUMN IF EXISTS confirm_hash,
+    DROP COLUMN IF EXISTS pending_provider,
+    DROP COLUMN IF EXISTS pending_user_id;
\ No newline at end of file
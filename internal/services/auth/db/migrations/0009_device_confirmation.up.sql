@@ -0,0 +1,4 @@
+ALTER TABLE device_codes
+    ADD COLUMN IF NOT EXISTS pending_user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS pending_provider VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS confirm_hash TEXT;
\ No newline at end of file
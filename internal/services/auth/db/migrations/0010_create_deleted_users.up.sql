@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS deleted_users (
+    uid uuid PRIMARY KEY,
+    deleted_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS deleted_users_deleted_at_idx ON deleted_users (deleted_at);
\ No newline at end of file
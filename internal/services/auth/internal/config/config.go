@@ -8,10 +8,14 @@ import (
 
 // Config holds the entire configuration for the auth service
 type Config struct {
-	HTTP  httpConfig
-	JWT   jwtConfig
-	DB    dbConfig
-	OAuth oauthConfig
+	HTTP          httpConfig
+	JWT           jwtConfig
+	DB            dbConfig
+	OAuth         oauthConfig
+	Device        deviceConfig
+	Service       serviceConfig
+	Words         wordsConfig
+	Introspection introspectionConfig
 }
 
 type httpConfig struct {
@@ -21,6 +25,8 @@ type httpConfig struct {
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
+	// TrustedProxies is a comma separated list of the networks of the proxies in front of the service
+	TrustedProxies string
 }
 
 type jwtConfig struct {
@@ -51,6 +57,26 @@ type oauthConfig struct {
 	Google googleConfig
 }
 
+type deviceConfig struct {
+	VerificationURL string
+	CodeTTL         time.Duration
+	PollInterval    time.Duration
+}
+
+type serviceConfig struct {
+	Secret   string
+	TokenTTL time.Duration
+}
+
+type wordsConfig struct {
+	InternalURL string
+}
+
+type introspectionConfig struct {
+	// Clients is a comma separated list of client_id:client_secret pairs allowed to introspect tokens
+	Clients string
+}
+
 // FromEnv loads the configuration from environment variables
 func FromEnv() Config {
 	return Config{
@@ -61,6 +87,7 @@ func FromEnv() Config {
 			WriteTimeout:    env.Duration("HTTP_WRITE_TIMEOUT", 30*time.Second),
 			IdleTimeout:     env.Duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
 			ShutdownTimeout: env.Duration("HTTP_SHUTDOWN_TIMEOUT", 10*time.Second),
+			TrustedProxies:  env.String("HTTP_TRUSTED_PROXIES", ""),
 		},
 		JWT: jwtConfig{
 			AccessSecret:     env.RequireString("JWT_ACCESS_SECRET"),
@@ -85,5 +112,20 @@ func FromEnv() Config {
 				RedirectURL:  env.String("OAUTH_GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/google/callback"),
 			},
 		},
+		Device: deviceConfig{
+			VerificationURL: env.String("DEVICE_VERIFICATION_URL", "http://localhost:8080/api/v1/device"),
+			CodeTTL:         env.Duration("DEVICE_CODE_TTL", 10*time.Minute),
+			PollInterval:    env.Duration("DEVICE_POLL_INTERVAL", 5*time.Second),
+		},
+		Service: serviceConfig{
+			Secret:   env.RequireString("SERVICE_SECRET"),
+			TokenTTL: env.Duration("SERVICE_TOKEN_TTL", time.Minute),
+		},
+		Words: wordsConfig{
+			InternalURL: env.String("WORDS_INTERNAL_URL", "http://localhost:8081/internal/v1"),
+		},
+		Introspection: introspectionConfig{
+			Clients: env.String("INTROSPECTION_CLIENTS", ""),
+		},
 	}
 }
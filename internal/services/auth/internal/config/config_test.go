@@ -15,6 +15,7 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("HTTP_WRITE_TIMEOUT", "45s")
 	t.Setenv("HTTP_IDLE_TIMEOUT", "90s")
 	t.Setenv("HTTP_SHUTDOWN_TIMEOUT", "15s")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "10.0.0.0/8")
 	t.Setenv("JWT_ACCESS_SECRET", "access_secret")
 	t.Setenv("JWT_REFRESH_SECRET", "refresh_secret")
 	t.Setenv("JWT_ISSUER", "test-issuer")
@@ -30,6 +31,13 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("OAUTH_GOOGLE_CLIENT_ID", "google_client_id")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_SECRET", "google_client_secret")
 	t.Setenv("OAUTH_GOOGLE_REDIRECT_URL", "http://localhost:9090/auth/google/callback")
+	t.Setenv("DEVICE_VERIFICATION_URL", "http://localhost:9090/api/v1/device")
+	t.Setenv("DEVICE_CODE_TTL", "5m")
+	t.Setenv("DEVICE_POLL_INTERVAL", "3s")
+	t.Setenv("SERVICE_SECRET", "service_secret")
+	t.Setenv("SERVICE_TOKEN_TTL", "2m")
+	t.Setenv("WORDS_INTERNAL_URL", "http://words:8080/internal/v1")
+	t.Setenv("INTROSPECTION_CLIENTS", "tool:secret")
 
 	cfg := config.FromEnv()
 
@@ -39,6 +47,7 @@ func TestFromEnv(t *testing.T) {
 	assert.Equal(t, 45*time.Second, cfg.HTTP.WriteTimeout)
 	assert.Equal(t, 90*time.Second, cfg.HTTP.IdleTimeout)
 	assert.Equal(t, 15*time.Second, cfg.HTTP.ShutdownTimeout)
+	assert.Equal(t, "10.0.0.0/8", cfg.HTTP.TrustedProxies)
 	assert.Equal(t, "access_secret", cfg.JWT.AccessSecret)
 	assert.Equal(t, "refresh_secret", cfg.JWT.RefreshSecret)
 	assert.Equal(t, 20*time.Minute, cfg.JWT.AccessTTL)
@@ -54,6 +63,13 @@ func TestFromEnv(t *testing.T) {
 	assert.Equal(t, "google_client_id", cfg.OAuth.Google.ClientID)
 	assert.Equal(t, "google_client_secret", cfg.OAuth.Google.ClientSecret)
 	assert.Equal(t, "http://localhost:9090/auth/google/callback", cfg.OAuth.Google.RedirectURL)
+	assert.Equal(t, "http://localhost:9090/api/v1/device", cfg.Device.VerificationURL)
+	assert.Equal(t, 5*time.Minute, cfg.Device.CodeTTL)
+	assert.Equal(t, 3*time.Second, cfg.Device.PollInterval)
+	assert.Equal(t, "service_secret", cfg.Service.Secret)
+	assert.Equal(t, 2*time.Minute, cfg.Service.TokenTTL)
+	assert.Equal(t, "http://words:8080/internal/v1", cfg.Words.InternalURL)
+	assert.Equal(t, "tool:secret", cfg.Introspection.Clients)
 }
 
 func TestFromEnv_Defaults(t *testing.T) {
@@ -61,6 +77,7 @@ func TestFromEnv_Defaults(t *testing.T) {
 	t.Setenv("JWT_REFRESH_SECRET", "default_refresh")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_ID", "client_id")
 	t.Setenv("OAUTH_GOOGLE_CLIENT_SECRET", "secret")
+	t.Setenv("SERVICE_SECRET", "service_secret")
 	cfg := config.FromEnv()
 
 	assert.Equal(t, "", cfg.HTTP.ListenAddr)
@@ -69,6 +86,7 @@ func TestFromEnv_Defaults(t *testing.T) {
 	assert.Equal(t, 30*time.Second, cfg.HTTP.WriteTimeout)
 	assert.Equal(t, 60*time.Second, cfg.HTTP.IdleTimeout)
 	assert.Equal(t, 10*time.Second, cfg.HTTP.ShutdownTimeout)
+	assert.Empty(t, cfg.HTTP.TrustedProxies)
 	assert.Equal(t, "default_access", cfg.JWT.AccessSecret)
 	assert.Equal(t, "default_refresh", cfg.JWT.RefreshSecret)
 	assert.Equal(t, "lexigo-auth-service", cfg.JWT.Issuer)
@@ -84,4 +102,10 @@ func TestFromEnv_Defaults(t *testing.T) {
 	assert.Equal(t, "client_id", cfg.OAuth.Google.ClientID)
 	assert.Equal(t, "secret", cfg.OAuth.Google.ClientSecret)
 	assert.Equal(t, "http://localhost:8080/auth/google/callback", cfg.OAuth.Google.RedirectURL)
+	assert.Equal(t, "http://localhost:8080/api/v1/device", cfg.Device.VerificationURL)
+	assert.Equal(t, 10*time.Minute, cfg.Device.CodeTTL)
+	assert.Equal(t, 5*time.Second, cfg.Device.PollInterval)
+	assert.Equal(t, time.Minute, cfg.Service.TokenTTL)
+	assert.Equal(t, "http://localhost:8081/internal/v1", cfg.Words.InternalURL)
+	assert.Empty(t, cfg.Introspection.Clients)
 }
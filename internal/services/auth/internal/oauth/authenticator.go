@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"sync"
 
 	"golang.org/x/oauth2"
@@ -16,6 +17,8 @@ var (
 	ErrProviderConflict = errors.New("provider already exists")
 	ErrProviderNotFound = errors.New("provider not found")
 	ErrAuthFailed       = errors.New("auth failed")
+	// ErrStateMismatch is an ErrAuthFailed raised when the state does not match the one of the login
+	ErrStateMismatch = fmt.Errorf("state mismatch: %w", ErrAuthFailed)
 )
 
 // User represents an authenticated user from an identity provider
@@ -73,6 +76,20 @@ func (a *Authenticator) Use(name string, p identityProvider) error {
 	return nil
 }
 
+// Providers returns the names of all registered identity providers in sorted order
+func (a *Authenticator) Providers() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.providers))
+	for name := range a.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
 // LoginURL generates a login URL for the specified provider and saves the state in the provided environment
 func (a *Authenticator) LoginURL(env Env, provider string) (string, error) {
 	p, err := a.getProvider(provider)
@@ -106,7 +123,7 @@ func (a *Authenticator) Exchange(ctx context.Context, env Env, provider, code, s
 	}
 
 	if saved != state {
-		return User{}, ErrAuthFailed
+		return User{}, ErrStateMismatch
 	}
 
 	usr, err := p.Exchange(ctx, code)
@@ -200,6 +200,7 @@ func TestAuthenticator_Exchange_StateMismatch(t *testing.T) {
 	_, err = a.Exchange(context.Background(), env, "test", "code", "wrong_state")
 	require.Error(t, err)
 	require.True(t, errors.Is(err, ErrAuthFailed))
+	require.True(t, errors.Is(err, ErrStateMismatch))
 }
 
 func TestAuthenticator_Exchange_ProviderExchangeError(t *testing.T) {
@@ -220,3 +221,13 @@ func TestAuthenticator_Exchange_ProviderExchangeError(t *testing.T) {
 	_, err = a.Exchange(context.Background(), env, "test", "code", "valid_state")
 	require.Error(t, err)
 }
+
+func TestAuthenticator_Providers(t *testing.T) {
+	a := NewAuthenticator()
+	require.Empty(t, a.Providers())
+
+	require.NoError(t, a.Use("google", &mockIdentityProvider{}))
+	require.NoError(t, a.Use("github", &mockIdentityProvider{}))
+
+	require.Equal(t, []string{"github", "google"}, a.Providers())
+}
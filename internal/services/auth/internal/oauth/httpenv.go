@@ -2,7 +2,8 @@ package oauth
 
 import "net/http"
 
-// HTTPEnv implements the Env interface using HTTP cookies
+// HTTPEnv implements the Env interface using HTTP cookies. The cookies are sent to every path, since
+// the device flow saves values on the callback of a provider and loads them on the device endpoints.
 type HTTPEnv struct {
 	w http.ResponseWriter
 	r *http.Request
@@ -17,7 +18,9 @@ func (e *HTTPEnv) Save(key, val string) error {
 	http.SetCookie(e.w, &http.Cookie{
 		Name:     key,
 		Value:    val,
+		Path:     "/",
 		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	})
 	return nil
 }
@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxExportSize limits the size of the data a single service may contribute to an export
+const maxExportSize = 64 << 20
+
+// tokenSource issues service tokens for calling other services
+type tokenSource interface {
+	Token(audience string) (string, error)
+}
+
+// UserDataClient exports and deletes the data another service keeps about a user through
+// its internal API: GET {base}/users/{uid}/export and DELETE {base}/users/{uid}
+type UserDataClient struct {
+	baseURL  string
+	client   *http.Client
+	tokens   tokenSource
+	audience string
+}
+
+type UserDataClientOption func(*UserDataClient) *UserDataClient
+
+// WithServiceToken authenticates calls with service tokens issued for the audience service
+func WithServiceToken(src tokenSource, audience string) UserDataClientOption {
+	return func(c *UserDataClient) *UserDataClient {
+		c.tokens = src
+		c.audience = audience
+		return c
+	}
+}
+
+func NewUserDataClient(baseURL string, opts ...UserDataClientOption) *UserDataClient {
+	c := &UserDataClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		c = opt(c)
+	}
+
+	return c
+}
+
+// ExportUserData returns the JSON document the service exports for the user
+func (c *UserDataClient) ExportUserData(ctx context.Context, userUID string) (json.RawMessage, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.userURL(userUID)+"/export")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("export user data: unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxExportSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read export: %w", err)
+	}
+	if len(data) > maxExportSize {
+		return nil, fmt.Errorf("export exceeds %d bytes", maxExportSize)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("export is not valid json")
+	}
+
+	return json.RawMessage(data), nil
+}
+
+// DeleteUserData deletes everything the service keeps about the user
+func (c *UserDataClient) DeleteUserData(ctx context.Context, userUID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.userURL(userUID))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete user data: unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *UserDataClient) userURL(userUID string) string {
+	return c.baseURL + "/users/" + url.PathEscape(userUID)
+}
+
+func (c *UserDataClient) do(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if c.tokens != nil {
+		tk, err := c.tokens.Token(c.audience)
+		if err != nil {
+			return nil, fmt.Errorf("issue service token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tk)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, target, err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,72 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDataClient_Export(t *testing.T) {
+	key := []byte("service-secret")
+	verifier := svctoken.NewVerifier("words", key)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/internal/v1/users/uid-1/export", r.URL.Path)
+
+		tk, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+		claims, err := verifier.Verify(tk)
+		require.NoError(t, err)
+		assert.Equal(t, "auth", claims.Service())
+
+		_, _ = w.Write([]byte(`{"user_id":"uid-1","picks":[]}`))
+	}))
+	defer srv.Close()
+
+	c := NewUserDataClient(srv.URL+"/internal/v1/",
+		WithServiceToken(svctoken.NewSigner("auth", key, time.Minute), "words"))
+
+	data, err := c.ExportUserData(t.Context(), "uid-1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id":"uid-1","picks":[]}`, string(data))
+}
+
+func TestUserDataClient_Export_InvalidJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`not json`))
+	}))
+	defer srv.Close()
+
+	_, err := NewUserDataClient(srv.URL).ExportUserData(t.Context(), "uid-1")
+	require.Error(t, err)
+}
+
+func TestUserDataClient_Delete(t *testing.T) {
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		deleted = append(deleted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, NewUserDataClient(srv.URL).DeleteUserData(t.Context(), "uid-1"))
+	assert.Equal(t, []string{"/users/uid-1"}, deleted)
+}
+
+func TestUserDataClient_Delete_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := NewUserDataClient(srv.URL).DeleteUserData(t.Context(), "uid-1")
+	require.Error(t, err)
+}
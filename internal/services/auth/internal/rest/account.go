@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+type accountIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Picture   string    `json:"picture,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAccountIdentity(id service.AccountIdentity) accountIdentity {
+	return accountIdentity{
+		Provider:  id.Provider,
+		Email:     id.Email,
+		Name:      id.Name,
+		Picture:   id.Picture,
+		CreatedAt: id.CreatedAt,
+	}
+}
+
+type accountExport struct {
+	UserUID      string                     `json:"user_uid"`
+	CreatedAt    time.Time                  `json:"created_at"`
+	ExportedAt   time.Time                  `json:"exported_at"`
+	Identities   []accountIdentity          `json:"identities"`
+	Profile      profileResponse            `json:"profile"`
+	AccessTokens []personalToken            `json:"access_tokens"`
+	Services     map[string]json.RawMessage `json:"services"`
+}
+
+func (a *API) handleExportAccount(w http.ResponseWriter, r *http.Request, uid string) {
+	export, err := a.srv.ExportAccount(r.Context(), uid)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := accountExport{
+		UserUID:      export.UserUID,
+		CreatedAt:    export.CreatedAt,
+		ExportedAt:   export.ExportedAt,
+		Identities:   make([]accountIdentity, 0, len(export.Identities)),
+		Profile:      newProfileResponse(export.Profile),
+		AccessTokens: make([]personalToken, 0, len(export.AccessTokens)),
+		Services:     export.Services,
+	}
+	for _, id := range export.Identities {
+		resp.Identities = append(resp.Identities, newAccountIdentity(id))
+	}
+	for _, pt := range export.AccessTokens {
+		resp.AccessTokens = append(resp.AccessTokens, newPersonalToken(pt))
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="lexigo-export-%s.json"`, export.UserUID))
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+func (a *API) handleDeleteAccount(w http.ResponseWriter, r *http.Request, uid string) {
+	if err := a.srv.DeleteAccount(r.Context(), uid); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_HandleExportAccount(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		exportAccountFunc: func(ctx context.Context, userUID string) (service.AccountExport, error) {
+			assert.Equal(t, "uid-1", userUID)
+			return service.AccountExport{
+				UserUID:    userUID,
+				CreatedAt:  created,
+				ExportedAt: created,
+				Identities: []service.AccountIdentity{{Provider: "google", Email: "user@example.com", CreatedAt: created}},
+				Profile:    service.Profile{UID: userUID},
+				Services:   map[string]json.RawMessage{"words": json.RawMessage(`{"picks":[]}`)},
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/me/export", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="lexigo-export-uid-1.json"`, rec.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `{
+		"user_uid": "uid-1",
+		"created_at": "2025-01-01T00:00:00Z",
+		"exported_at": "2025-01-01T00:00:00Z",
+		"identities": [{"provider":"google","email":"user@example.com","created_at":"2025-01-01T00:00:00Z"}],
+		"profile": {"uid":"uid-1","display_name":"","native_lang":"","target_langs":[],"daily_goal":0,"timezone":""},
+		"access_tokens": [],
+		"services": {"words": {"picks":[]}}
+	}`, rec.Body.String())
+}
+
+func TestAPI_HandleDeleteAccount(t *testing.T) {
+	deleted := false
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		deleteAccountFunc: func(ctx context.Context, userUID string) error {
+			assert.Equal(t, "uid-1", userUID)
+			deleted = true
+			return nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("DELETE", "/me", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.True(t, deleted)
+}
+
+func TestAPI_HandleDeleteAccount_ServiceUnavailable(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		deleteAccountFunc: func(ctx context.Context, userUID string) error {
+			return serr.NewServiceError(errors.New("connection refused"), http.StatusBadGateway, "delete user data from words")
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("DELETE", "/me", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+// admin resolves the user like authenticated and makes sure it is an administrator
+func (a *API) admin(next userHandlerFunc) http.HandlerFunc {
+	return a.authenticated(func(w http.ResponseWriter, r *http.Request, uid string) {
+		if err := a.srv.AuthorizeAdmin(r.Context(), uid); err != nil {
+			httpx.HandleErr(w, r, err)
+			return
+		}
+
+		next(w, r, uid)
+	})
+}
+
+type adminUserResponse struct {
+	UID               string            `json:"uid"`
+	Role              string            `json:"role"`
+	Disabled          bool              `json:"disabled"`
+	DisabledAt        *time.Time        `json:"disabled_at,omitempty"`
+	SessionsRevokedAt *time.Time        `json:"sessions_revoked_at,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	Identities        []accountIdentity `json:"identities"`
+}
+
+func newAdminUserResponse(u service.AdminUser) adminUserResponse {
+	resp := adminUserResponse{
+		UID:               u.UID,
+		Role:              u.Role,
+		Disabled:          !u.DisabledAt.IsZero(),
+		DisabledAt:        optionalTime(u.DisabledAt),
+		SessionsRevokedAt: optionalTime(u.SessionsRevokedAt),
+		CreatedAt:         u.CreatedAt,
+		Identities:        make([]accountIdentity, 0, len(u.Identities)),
+	}
+	for _, id := range u.Identities {
+		resp.Identities = append(resp.Identities, newAccountIdentity(id))
+	}
+
+	return resp
+}
+
+type listUsersResponse struct {
+	Users      []adminUserResponse `json:"users"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+func (a *API) handleListUsers(w http.ResponseWriter, r *http.Request, _ string) {
+	q := r.URL.Query()
+
+	var limit int
+	if l := q.Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadRequest, "invalid limit")
+			sErr.Env["limit"] = l
+			httpx.HandleErr(w, r, sErr)
+			return
+		}
+	}
+
+	users, err := a.srv.ListUsers(r.Context(), service.ListUsersRequest{
+		Email:    q.Get("email"),
+		Provider: q.Get("provider"),
+		Cursor:   q.Get("cursor"),
+		Limit:    limit,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := listUsersResponse{
+		Users:      make([]adminUserResponse, 0, len(users.Users)),
+		NextCursor: users.NextCursor,
+	}
+	for _, u := range users.Users {
+		resp.Users = append(resp.Users, newAdminUserResponse(u))
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+func (a *API) handleGetUser(w http.ResponseWriter, r *http.Request, _ string) {
+	u, err := a.srv.GetUser(r.Context(), r.PathValue("user_uid"))
+	a.writeAdminUser(w, r, u, err)
+}
+
+func (a *API) handleDisableUser(w http.ResponseWriter, r *http.Request, uid string) {
+	u, err := a.srv.DisableUser(r.Context(), uid, r.PathValue("user_uid"))
+	a.writeAdminUser(w, r, u, err)
+}
+
+func (a *API) handleEnableUser(w http.ResponseWriter, r *http.Request, uid string) {
+	u, err := a.srv.EnableUser(r.Context(), uid, r.PathValue("user_uid"))
+	a.writeAdminUser(w, r, u, err)
+}
+
+func (a *API) handleRevokeSessions(w http.ResponseWriter, r *http.Request, uid string) {
+	if err := a.srv.RevokeSessions(r.Context(), uid, r.PathValue("user_uid")); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) writeAdminUser(w http.ResponseWriter, r *http.Request, u service.AdminUser, err error) {
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newAdminUserResponse(u)); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
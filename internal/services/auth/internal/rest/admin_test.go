@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func allowAdmin(ctx context.Context, userUID string) error {
+	return nil
+}
+
+func TestAPI_HandleListUsers(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := &mockAuthService{
+		authenticateFunc:   authenticateAs("admin-uid"),
+		authorizeAdminFunc: allowAdmin,
+		listUsersFunc: func(ctx context.Context, r service.ListUsersRequest) (service.ListUsersResponse, error) {
+			assert.Equal(t, service.ListUsersRequest{Email: "ann", Provider: "google", Cursor: "10", Limit: 5}, r)
+			return service.ListUsersResponse{
+				Users: []service.AdminUser{{
+					UID:        "uid-1",
+					Role:       "user",
+					CreatedAt:  created,
+					DisabledAt: created,
+					Identities: []service.AccountIdentity{{Provider: "google", Email: "ann@example.com", CreatedAt: created}},
+				}},
+				NextCursor: "11",
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/admin/users?email=ann&provider=google&cursor=10&limit=5", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"users": [{
+			"uid": "uid-1",
+			"role": "user",
+			"disabled": true,
+			"disabled_at": "2025-01-01T00:00:00Z",
+			"created_at": "2025-01-01T00:00:00Z",
+			"identities": [{"provider":"google","email":"ann@example.com","created_at":"2025-01-01T00:00:00Z"}]
+		}],
+		"next_cursor": "11"
+	}`, rec.Body.String())
+}
+
+func TestAPI_HandleListUsers_Forbidden(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		authorizeAdminFunc: func(ctx context.Context, userUID string) error {
+			return serr.NewServiceError(nil, http.StatusForbidden, "admin role required")
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/admin/users", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAPI_HandleDisableUser(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc:   authenticateAs("admin-uid"),
+		authorizeAdminFunc: allowAdmin,
+		disableUserFunc: func(ctx context.Context, adminUID, userUID string) (service.AdminUser, error) {
+			assert.Equal(t, "admin-uid", adminUID)
+			assert.Equal(t, "uid-1", userUID)
+			return service.AdminUser{UID: userUID, DisabledAt: time.Now()}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("POST", "/admin/users/uid-1/disable", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"disabled":true`)
+}
+
+func TestAPI_HandleRevokeSessions(t *testing.T) {
+	revoked := ""
+	srv := &mockAuthService{
+		authenticateFunc:   authenticateAs("admin-uid"),
+		authorizeAdminFunc: allowAdmin,
+		revokeSessionsFunc: func(ctx context.Context, adminUID, userUID string) error {
+			assert.Equal(t, "admin-uid", adminUID)
+			revoked = userUID
+			return nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("DELETE", "/admin/users/uid-1/sessions", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "uid-1", revoked)
+}
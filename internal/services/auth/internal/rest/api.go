@@ -2,8 +2,10 @@ package rest
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"net/netip"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
@@ -14,35 +16,119 @@ type authService interface {
 	LoginURL(provider string, env oauth.Env) (string, error)
 	AuthCallback(ctx context.Context, env oauth.Env, req service.AuthCallbackRequest) (service.AuthCallbackResponse, error)
 	Refresh(ctx context.Context, refreshToken string) (string, error)
+	Providers() []string
+	DeviceCode(ctx context.Context, r service.DeviceCodeRequest) (service.DeviceCodeResponse, error)
+	DeviceLoginURL(ctx context.Context, provider, userCode string, env oauth.Env) (string, error)
+	PollDeviceToken(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error)
+	ApproveDevice(ctx context.Context, env oauth.Env, r service.ApproveDeviceRequest) error
+	Authenticate(ctx context.Context, accessToken string) (string, error)
+	CreateAccessToken(ctx context.Context, r service.CreateAccessTokenRequest) (service.CreateAccessTokenResponse, error)
+	ListAccessTokens(ctx context.Context, userUID string) ([]service.PersonalToken, error)
+	RevokeAccessToken(ctx context.Context, userUID string, id int64) error
+	Introspect(ctx context.Context, raw string) (service.Introspection, error)
+	UserInfo(ctx context.Context, accessToken string) (service.UserInfo, error)
+	GetProfile(ctx context.Context, userUID string) (service.Profile, error)
+	UpdateProfile(ctx context.Context, r service.UpdateProfileRequest) (service.Profile, error)
+	ExportAccount(ctx context.Context, userUID string) (service.AccountExport, error)
+	DeleteAccount(ctx context.Context, userUID string) error
+	AuthorizeAdmin(ctx context.Context, userUID string) error
+	ListUsers(ctx context.Context, r service.ListUsersRequest) (service.ListUsersResponse, error)
+	GetUser(ctx context.Context, userUID string) (service.AdminUser, error)
+	DisableUser(ctx context.Context, adminUID, userUID string) (service.AdminUser, error)
+	EnableUser(ctx context.Context, adminUID, userUID string) (service.AdminUser, error)
+	RevokeSessions(ctx context.Context, adminUID, userUID string) error
+	ListAuditEvents(ctx context.Context, r service.ListAuditEventsRequest) (service.ListAuditEventsResponse, error)
 }
 
 type API struct {
 	srv authService
 	mux *http.ServeMux
+	// proxies are the networks of the proxies trusted to forward the address of the client
+	proxies []netip.Prefix
+	// clients maps the IDs of the clients allowed to introspect tokens to the hashes of their secrets
+	clients map[string][sha256.Size]byte
 }
 
-func NewAPI(srv authService) *API {
+type APIOption func(*API) *API
+
+// WithTrustedProxies trusts the proxies in the networks to report the address of the client in X-Forwarded-For
+func WithTrustedProxies(proxies ...netip.Prefix) APIOption {
+	return func(a *API) *API {
+		a.proxies = proxies
+		return a
+	}
+}
+
+// IntrospectionClient is a client allowed to call the introspection endpoint
+type IntrospectionClient struct {
+	ID     string
+	Secret string
+}
+
+// WithIntrospectionClients allows the clients to introspect tokens. Without clients the
+// introspection endpoint rejects every request.
+func WithIntrospectionClients(clients ...IntrospectionClient) APIOption {
+	return func(a *API) *API {
+		a.clients = make(map[string][sha256.Size]byte, len(clients))
+		for _, c := range clients {
+			a.clients[c.ID] = sha256.Sum256([]byte(c.Secret))
+		}
+		return a
+	}
+}
+
+func NewAPI(srv authService, opts ...APIOption) *API {
 	api := &API{
 		srv: srv,
 		mux: http.NewServeMux(),
 	}
+	for _, opt := range opts {
+		api = opt(api)
+	}
 	api.mount()
 	return api
 }
 
 func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	a.mux.ServeHTTP(w, r)
+	a.mux.ServeHTTP(w, r.WithContext(service.WithClient(r.Context(), requestClient(r, a.proxies))))
 }
 
 func (a *API) mount() {
-	a.mux.HandleFunc("/{provider}/login", a.handleLogin)
-	a.mux.HandleFunc("/{provider}/callback", a.handleCallback)
+	a.mux.HandleFunc("GET /{provider}/login", a.handleLogin)
+	a.mux.HandleFunc("GET /{provider}/callback", a.handleCallback)
 	a.mux.HandleFunc("POST /refresh", a.handleRefresh)
+	a.mux.HandleFunc("POST /device/code", a.handleDeviceCode)
+	a.mux.HandleFunc("POST /device/token", a.handleDeviceToken)
+	a.mux.HandleFunc("GET /device", a.handleVerification)
+	a.mux.HandleFunc("POST /device/approve", a.handleApproveDevice)
+	a.mux.HandleFunc("POST /tokens", a.authenticated(a.handleCreateToken))
+	a.mux.HandleFunc("GET /tokens", a.authenticated(a.handleListTokens))
+	a.mux.HandleFunc("DELETE /tokens/{token_id}", a.authenticated(a.handleRevokeToken))
+	a.mux.HandleFunc("POST /introspect", a.handleIntrospect)
+	a.mux.HandleFunc("GET /userinfo", a.handleUserInfo)
+	a.mux.HandleFunc("GET /me", a.authenticated(a.handleGetProfile))
+	a.mux.HandleFunc("PATCH /me", a.authenticated(a.handleUpdateProfile))
+	a.mux.HandleFunc("DELETE /me", a.authenticated(a.handleDeleteAccount))
+	a.mux.HandleFunc("GET /me/export", a.authenticated(a.handleExportAccount))
+	a.mux.HandleFunc("GET /admin/users", a.admin(a.handleListUsers))
+	a.mux.HandleFunc("GET /admin/users/{user_uid}", a.admin(a.handleGetUser))
+	a.mux.HandleFunc("POST /admin/users/{user_uid}/disable", a.admin(a.handleDisableUser))
+	a.mux.HandleFunc("POST /admin/users/{user_uid}/enable", a.admin(a.handleEnableUser))
+	a.mux.HandleFunc("DELETE /admin/users/{user_uid}/sessions", a.admin(a.handleRevokeSessions))
+	a.mux.HandleFunc("GET /admin/audit-events", a.admin(a.handleListAuditEvents))
 }
 
 func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	p := r.PathValue("provider")
-	url, err := a.srv.LoginURL(p, oauth.NewHTTPEnv(w, r))
+	env := oauth.NewHTTPEnv(w, r)
+
+	var url string
+	var err error
+	if userCode := r.URL.Query().Get("user_code"); userCode != "" {
+		url, err = a.srv.DeviceLoginURL(r.Context(), p, userCode, env)
+	} else {
+		url, err = a.srv.LoginURL(p, env)
+	}
 	if err != nil {
 		httpx.HandleErr(w, r, err)
 		return
@@ -71,6 +157,11 @@ func (a *API) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if resp.Device != nil {
+		a.renderDeviceConfirmation(w, r, *resp.Device)
+		return
+	}
+
 	err = httpx.WriteJSON(w, http.StatusOK, callbackResponse{
 		AccessToken:  resp.AccessToken,
 		RefreshToken: resp.RefreshToken,
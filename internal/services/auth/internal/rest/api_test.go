@@ -15,9 +15,31 @@ import (
 )
 
 type mockAuthService struct {
-	loginURLFunc     func(provider string, env oauth.Env) (string, error)
-	authCallbackFunc func(ctx context.Context, env oauth.Env, req service.AuthCallbackRequest) (service.AuthCallbackResponse, error)
-	refreshFunc      func(ctx context.Context, refreshToken string) (string, error)
+	loginURLFunc       func(provider string, env oauth.Env) (string, error)
+	authCallbackFunc   func(ctx context.Context, env oauth.Env, req service.AuthCallbackRequest) (service.AuthCallbackResponse, error)
+	refreshFunc        func(ctx context.Context, refreshToken string) (string, error)
+	providersFunc      func() []string
+	deviceCodeFunc     func(ctx context.Context, r service.DeviceCodeRequest) (service.DeviceCodeResponse, error)
+	deviceLoginFunc    func(ctx context.Context, provider, userCode string, env oauth.Env) (string, error)
+	pollDeviceFunc     func(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error)
+	approveDeviceFunc  func(ctx context.Context, env oauth.Env, r service.ApproveDeviceRequest) error
+	authenticateFunc   func(ctx context.Context, accessToken string) (string, error)
+	createTokenFunc    func(ctx context.Context, r service.CreateAccessTokenRequest) (service.CreateAccessTokenResponse, error)
+	listTokensFunc     func(ctx context.Context, userUID string) ([]service.PersonalToken, error)
+	revokeTokenFunc    func(ctx context.Context, userUID string, id int64) error
+	introspectFunc     func(ctx context.Context, raw string) (service.Introspection, error)
+	userInfoFunc       func(ctx context.Context, accessToken string) (service.UserInfo, error)
+	getProfileFunc     func(ctx context.Context, userUID string) (service.Profile, error)
+	updateProfileFunc  func(ctx context.Context, r service.UpdateProfileRequest) (service.Profile, error)
+	exportAccountFunc  func(ctx context.Context, userUID string) (service.AccountExport, error)
+	deleteAccountFunc  func(ctx context.Context, userUID string) error
+	authorizeAdminFunc func(ctx context.Context, userUID string) error
+	listUsersFunc      func(ctx context.Context, r service.ListUsersRequest) (service.ListUsersResponse, error)
+	getUserFunc        func(ctx context.Context, userUID string) (service.AdminUser, error)
+	disableUserFunc    func(ctx context.Context, adminUID, userUID string) (service.AdminUser, error)
+	enableUserFunc     func(ctx context.Context, adminUID, userUID string) (service.AdminUser, error)
+	revokeSessionsFunc func(ctx context.Context, adminUID, userUID string) error
+	listAuditFunc      func(ctx context.Context, r service.ListAuditEventsRequest) (service.ListAuditEventsResponse, error)
 }
 
 func (m *mockAuthService) LoginURL(provider string, env oauth.Env) (string, error) {
@@ -28,10 +50,98 @@ func (m *mockAuthService) AuthCallback(ctx context.Context, env oauth.Env, req s
 	return m.authCallbackFunc(ctx, env, req)
 }
 
+func (m *mockAuthService) ExportAccount(ctx context.Context, userUID string) (service.AccountExport, error) {
+	return m.exportAccountFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) DeleteAccount(ctx context.Context, userUID string) error {
+	return m.deleteAccountFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) AuthorizeAdmin(ctx context.Context, userUID string) error {
+	return m.authorizeAdminFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) ListUsers(ctx context.Context, r service.ListUsersRequest) (service.ListUsersResponse, error) {
+	return m.listUsersFunc(ctx, r)
+}
+
+func (m *mockAuthService) GetUser(ctx context.Context, userUID string) (service.AdminUser, error) {
+	return m.getUserFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) DisableUser(ctx context.Context, adminUID, userUID string) (service.AdminUser, error) {
+	return m.disableUserFunc(ctx, adminUID, userUID)
+}
+
+func (m *mockAuthService) EnableUser(ctx context.Context, adminUID, userUID string) (service.AdminUser, error) {
+	return m.enableUserFunc(ctx, adminUID, userUID)
+}
+
+func (m *mockAuthService) RevokeSessions(ctx context.Context, adminUID, userUID string) error {
+	return m.revokeSessionsFunc(ctx, adminUID, userUID)
+}
+
+func (m *mockAuthService) ListAuditEvents(ctx context.Context, r service.ListAuditEventsRequest) (service.ListAuditEventsResponse, error) {
+	return m.listAuditFunc(ctx, r)
+}
+
 func (m *mockAuthService) Refresh(ctx context.Context, refreshToken string) (string, error) {
 	return m.refreshFunc(ctx, refreshToken)
 }
 
+func (m *mockAuthService) Providers() []string {
+	return m.providersFunc()
+}
+
+func (m *mockAuthService) DeviceCode(ctx context.Context, r service.DeviceCodeRequest) (service.DeviceCodeResponse, error) {
+	return m.deviceCodeFunc(ctx, r)
+}
+
+func (m *mockAuthService) DeviceLoginURL(ctx context.Context, provider, userCode string, env oauth.Env) (string, error) {
+	return m.deviceLoginFunc(ctx, provider, userCode, env)
+}
+
+func (m *mockAuthService) PollDeviceToken(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error) {
+	return m.pollDeviceFunc(ctx, deviceCode)
+}
+
+func (m *mockAuthService) ApproveDevice(ctx context.Context, env oauth.Env, r service.ApproveDeviceRequest) error {
+	return m.approveDeviceFunc(ctx, env, r)
+}
+
+func (m *mockAuthService) Authenticate(ctx context.Context, accessToken string) (string, error) {
+	return m.authenticateFunc(ctx, accessToken)
+}
+
+func (m *mockAuthService) CreateAccessToken(ctx context.Context, r service.CreateAccessTokenRequest) (service.CreateAccessTokenResponse, error) {
+	return m.createTokenFunc(ctx, r)
+}
+
+func (m *mockAuthService) ListAccessTokens(ctx context.Context, userUID string) ([]service.PersonalToken, error) {
+	return m.listTokensFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) RevokeAccessToken(ctx context.Context, userUID string, id int64) error {
+	return m.revokeTokenFunc(ctx, userUID, id)
+}
+
+func (m *mockAuthService) Introspect(ctx context.Context, raw string) (service.Introspection, error) {
+	return m.introspectFunc(ctx, raw)
+}
+
+func (m *mockAuthService) UserInfo(ctx context.Context, accessToken string) (service.UserInfo, error) {
+	return m.userInfoFunc(ctx, accessToken)
+}
+
+func (m *mockAuthService) GetProfile(ctx context.Context, userUID string) (service.Profile, error) {
+	return m.getProfileFunc(ctx, userUID)
+}
+
+func (m *mockAuthService) UpdateProfile(ctx context.Context, r service.UpdateProfileRequest) (service.Profile, error) {
+	return m.updateProfileFunc(ctx, r)
+}
+
 func TestAPI_HandleLogin(t *testing.T) {
 	srv := &mockAuthService{
 		loginURLFunc: func(provider string, env oauth.Env) (string, error) {
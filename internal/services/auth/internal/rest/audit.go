@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+// requestClient describes the client of the request. Proxies in the trusted networks append the address
+// they received the request from to X-Forwarded-For, so the client is the right-most address that is not
+// one of them. The entries left of it are written by the client and are not to be trusted.
+func requestClient(r *http.Request, proxies []netip.Prefix) service.Client {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0 && trustedProxy(ip, proxies); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			break
+		}
+		ip = hop
+	}
+
+	return service.Client{IP: ip, UserAgent: r.UserAgent()}
+}
+
+func trustedProxy(ip string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	return slices.ContainsFunc(proxies, func(p netip.Prefix) bool { return p.Contains(addr) })
+}
+
+type auditEvent struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	UserUID   string    `json:"user_uid,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type listAuditEventsResponse struct {
+	Events     []auditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func (a *API) handleListAuditEvents(w http.ResponseWriter, r *http.Request, _ string) {
+	q := r.URL.Query()
+	req := service.ListAuditEventsRequest{
+		UserUID: q.Get("user_uid"),
+		Type:    q.Get("type"),
+		Cursor:  q.Get("cursor"),
+	}
+
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadRequest, "invalid limit")
+			sErr.Env["limit"] = l
+			httpx.HandleErr(w, r, sErr)
+			return
+		}
+		req.Limit = limit
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{name: "from", dst: &req.From},
+		{name: "to", dst: &req.To},
+	} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadRequest, "%s must be an RFC 3339 timestamp", p.name)
+			sErr.Env[p.name] = v
+			httpx.HandleErr(w, r, sErr)
+			return
+		}
+		*p.dst = t
+	}
+
+	events, err := a.srv.ListAuditEvents(r.Context(), req)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := listAuditEventsResponse{
+		Events:     make([]auditEvent, 0, len(events.Events)),
+		NextCursor: events.NextCursor,
+	}
+	for _, e := range events.Events {
+		resp.Events = append(resp.Events, auditEvent{
+			ID:        e.ID,
+			Type:      e.Type,
+			UserUID:   e.UserUID,
+			Provider:  e.Provider,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Detail:    e.Detail,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
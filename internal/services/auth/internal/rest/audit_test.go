@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestClient(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	req := httptest.NewRequest("GET", "/google/callback", nil)
+	req.RemoteAddr = "10.0.0.5:51234"
+	req.Header.Set("User-Agent", "lexigo-cli/1.0")
+	assert.Equal(t, service.Client{IP: "10.0.0.5", UserAgent: "lexigo-cli/1.0"}, requestClient(req, proxies))
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	assert.Equal(t, "203.0.113.7", requestClient(req, proxies).IP)
+
+	// the client prepends an address of its choice
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	assert.Equal(t, "203.0.113.7", requestClient(req, proxies).IP)
+
+	// without trusted proxies the header is ignored
+	assert.Equal(t, "10.0.0.5", requestClient(req, nil).IP)
+
+	// a client reaching the service directly cannot forward an address
+	req.RemoteAddr = "192.0.2.9:51234"
+	assert.Equal(t, "192.0.2.9", requestClient(req, proxies).IP)
+}
+
+func TestAPI_HandleListAuditEvents(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := &mockAuthService{
+		authenticateFunc:   authenticateAs("admin-uid"),
+		authorizeAdminFunc: allowAdmin,
+		listAuditFunc: func(ctx context.Context, r service.ListAuditEventsRequest) (service.ListAuditEventsResponse, error) {
+			assert.Equal(t, service.ListAuditEventsRequest{
+				UserUID: "uid-1",
+				Type:    service.EventLoginFailed,
+				From:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC),
+				Cursor:  "42",
+				Limit:   10,
+			}, r)
+			return service.ListAuditEventsResponse{
+				Events: []service.AuditEvent{{
+					ID:        41,
+					Type:      service.EventLoginFailed,
+					UserUID:   "uid-1",
+					Provider:  "google",
+					IP:        "203.0.113.7",
+					UserAgent: "curl/8.0",
+					Detail:    "account disabled",
+					CreatedAt: created,
+				}},
+				NextCursor: "41",
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/admin/audit-events?user_uid=uid-1&type=login_failed&from=2025-01-01T00:00:00Z&to=2025-01-03T00:00:00Z&cursor=42&limit=10", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"events": [{
+			"id": 41,
+			"type": "login_failed",
+			"user_uid": "uid-1",
+			"provider": "google",
+			"ip": "203.0.113.7",
+			"user_agent": "curl/8.0",
+			"detail": "account disabled",
+			"created_at": "2025-01-02T03:04:05Z"
+		}],
+		"next_cursor": "41"
+	}`, rec.Body.String())
+}
+
+func TestAPI_HandleListAuditEvents_InvalidTime(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc:   authenticateAs("admin-uid"),
+		authorizeAdminFunc: allowAdmin,
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/admin/audit-events?from=yesterday", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
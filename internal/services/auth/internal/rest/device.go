@@ -0,0 +1,191 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+var verificationPage = template.Must(template.New("verification").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Connect a device</title></head>
+<body>
+	<h1>Connect a device</h1>
+	<form method="get">
+		<label for="user_code">Enter the code shown on your device</label>
+		<input id="user_code" name="user_code" value="{{.UserCode}}" autocomplete="off" required>
+		{{range .Providers}}
+		<button type="submit" formaction="{{.}}/login">Continue with {{.}}</button>
+		{{end}}
+	</form>
+</body>
+</html>
+`))
+
+// deviceConfirmationPage asks the user who signed in to confirm the device, the form is posted
+// from the callback of the provider to the approve endpoint next to the verification page
+var deviceConfirmationPage = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Connect a device</title></head>
+<body>
+	<h1>Connect a device</h1>
+	<p>{{.ClientID}} asks to access your account{{if .Scope}} with the scope {{.Scope}}{{end}}.</p>
+	<p>Connect it only if your device shows the code <strong>{{.UserCode}}</strong>.</p>
+	<form method="post" action="../device/approve">
+		<input type="hidden" name="user_code" value="{{.UserCode}}">
+		<input type="hidden" name="confirm_token" value="{{.ConfirmToken}}">
+		<button type="submit">Connect the device</button>
+	</form>
+	<p>If you did not start connecting a device, close this window.</p>
+</body>
+</html>
+`))
+
+var deviceConnectedPage = template.Must(template.New("connected").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Device connected</title></head>
+<body>
+	<h1>Device connected</h1>
+	<p>You can close this window and return to your device.</p>
+</body>
+</html>
+`))
+
+type deviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+func (a *API) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
+	resp, err := a.srv.DeviceCode(r.Context(), service.DeviceCodeRequest{
+		ClientID: r.PostFormValue("client_id"),
+		Scope:    r.PostFormValue("scope"),
+	})
+	if err != nil {
+		writeDeviceErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, deviceCodeResponse{
+		DeviceCode:              resp.DeviceCode,
+		UserCode:                resp.UserCode,
+		VerificationURI:         resp.VerificationURI,
+		VerificationURIComplete: resp.VerificationURIComplete,
+		ExpiresIn:               int(resp.ExpiresIn.Seconds()),
+		Interval:                int(resp.Interval.Seconds()),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+func (a *API) handleVerification(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := verificationPage.Execute(w, struct {
+		UserCode  string
+		Providers []string
+	}{
+		UserCode:  r.URL.Query().Get("user_code"),
+		Providers: a.srv.Providers(),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("render verification page: %w", err))
+		return
+	}
+}
+
+func (a *API) renderDeviceConfirmation(w http.ResponseWriter, r *http.Request, c service.DeviceConfirmation) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// the page must not be framed, so that the user cannot be tricked into confirming
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	if err := deviceConfirmationPage.Execute(w, c); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("render device confirmation page: %w", err))
+		return
+	}
+}
+
+// handleApproveDevice approves the device the user confirmed on the confirmation page
+func (a *API) handleApproveDevice(w http.ResponseWriter, r *http.Request) {
+	err := a.srv.ApproveDevice(r.Context(), oauth.NewHTTPEnv(w, r), service.ApproveDeviceRequest{
+		UserCode:     r.PostFormValue("user_code"),
+		ConfirmToken: r.PostFormValue("confirm_token"),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	a.renderDeviceConnected(w, r)
+}
+
+func (a *API) renderDeviceConnected(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := deviceConnectedPage.Execute(w, nil); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("render device connected page: %w", err))
+		return
+	}
+}
+
+type deviceTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
+func (a *API) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("grant_type") != deviceCodeGrantType {
+		writeDeviceErr(w, r, serr.NewServiceError(nil, http.StatusBadRequest, "unsupported_grant_type"))
+		return
+	}
+
+	resp, err := a.srv.PollDeviceToken(r.Context(), r.PostFormValue("device_code"))
+	if err != nil {
+		writeDeviceErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	err = httpx.WriteJSON(w, http.StatusOK, deviceTokenResponse{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		TokenType:    "Bearer",
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+type deviceErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeDeviceErr reports service errors in the OAuth error response format expected by device clients
+func writeDeviceErr(w http.ResponseWriter, r *http.Request, err error) {
+	var se *serr.ServiceError
+	if !errors.As(err, &se) {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := httpx.WriteJSON(w, se.StatusCode, deviceErrorResponse{Error: se.Msg}); err != nil {
+		slog.Error("write device error", "error", err)
+	}
+}
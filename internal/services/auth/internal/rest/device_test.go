@@ -0,0 +1,233 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormRequest(target string, form url.Values) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestAPI_HandleDeviceCode(t *testing.T) {
+	srv := &mockAuthService{
+		deviceCodeFunc: func(ctx context.Context, r service.DeviceCodeRequest) (service.DeviceCodeResponse, error) {
+			assert.Equal(t, "tv-app", r.ClientID)
+			assert.Equal(t, "words", r.Scope)
+			return service.DeviceCodeResponse{
+				DeviceCode:              "device_code_value",
+				UserCode:                "BCDF-GHJK",
+				VerificationURI:         "https://example.com/device",
+				VerificationURIComplete: "https://example.com/device?user_code=BCDF-GHJK",
+				ExpiresIn:               10 * time.Minute,
+				Interval:                5 * time.Second,
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := newFormRequest("/device/code", url.Values{"client_id": {"tv-app"}, "scope": {"words"}})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t,
+		`{
+			"device_code":"device_code_value",
+			"user_code":"BCDF-GHJK",
+			"verification_uri":"https://example.com/device",
+			"verification_uri_complete":"https://example.com/device?user_code=BCDF-GHJK",
+			"expires_in":600,
+			"interval":5
+		}`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleVerification(t *testing.T) {
+	srv := &mockAuthService{
+		providersFunc: func() []string {
+			return []string{"github", "google"}
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/device?user_code=BCDF-GHJK", nil)
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `value="BCDF-GHJK"`)
+	assert.Contains(t, rec.Body.String(), `formaction="github/login"`)
+	assert.Contains(t, rec.Body.String(), `formaction="google/login"`)
+}
+
+func TestAPI_HandleLogin_DeviceUserCode(t *testing.T) {
+	srv := &mockAuthService{
+		deviceLoginFunc: func(ctx context.Context, provider, userCode string, env oauth.Env) (string, error) {
+			assert.Equal(t, "google", provider)
+			assert.Equal(t, "BCDF-GHJK", userCode)
+			return "http://example.com/login", nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/google/login?user_code=BCDF-GHJK", nil)
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "http://example.com/login", rec.Header().Get("Location"))
+}
+
+func TestAPI_Callback_DeviceConfirmation(t *testing.T) {
+	srv := &mockAuthService{
+		authCallbackFunc: func(ctx context.Context, env oauth.Env, req service.AuthCallbackRequest) (service.AuthCallbackResponse, error) {
+			return service.AuthCallbackResponse{Device: &service.DeviceConfirmation{
+				UserCode:     "BCDF-GHJK",
+				ClientID:     "tv-app",
+				ConfirmToken: "confirm_token",
+			}}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/google/callback?code=test_code&state=test_state", nil)
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "BCDF-GHJK")
+	assert.Contains(t, body, "tv-app")
+	assert.Contains(t, body, `method="post" action="../device/approve"`)
+	assert.Contains(t, body, `name="confirm_token" value="confirm_token"`)
+	assert.NotContains(t, body, "Device connected")
+}
+
+func TestAPI_HandleApproveDevice(t *testing.T) {
+	srv := &mockAuthService{
+		approveDeviceFunc: func(ctx context.Context, env oauth.Env, r service.ApproveDeviceRequest) error {
+			if r.ConfirmToken != "confirm_token" {
+				return serr.NewServiceError(nil, http.StatusForbidden, "invalid device confirmation")
+			}
+			assert.Equal(t, "BCDF-GHJK", r.UserCode)
+			return nil
+		},
+	}
+	api := NewAPI(srv)
+
+	form := url.Values{"user_code": {"BCDF-GHJK"}, "confirm_token": {"confirm_token"}}
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, newFormRequest("/device/approve", form))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Device connected")
+
+	form.Set("confirm_token", "forged_token")
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, newFormRequest("/device/approve", form))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("GET", "/device/approve", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestAPI_HandleDeviceToken(t *testing.T) {
+	srv := &mockAuthService{
+		pollDeviceFunc: func(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error) {
+			assert.Equal(t, "device_code_value", deviceCode)
+			return service.DeviceTokenResponse{
+				AccessToken:  "access_token_value",
+				RefreshToken: "refresh_token_value",
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := newFormRequest("/device/token", url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {"device_code_value"},
+	})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t,
+		`{
+			"access_token":"access_token_value",
+			"refresh_token":"refresh_token_value",
+			"token_type":"Bearer"
+		}`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleDeviceToken_Pending(t *testing.T) {
+	srv := &mockAuthService{
+		pollDeviceFunc: func(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error) {
+			return service.DeviceTokenResponse{}, serr.NewServiceError(service.ErrAuthorizationPending, http.StatusBadRequest, "authorization_pending")
+		},
+	}
+	api := NewAPI(srv)
+
+	req := newFormRequest("/device/token", url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {"device_code_value"},
+	})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"authorization_pending"}`, rec.Body.String())
+}
+
+func TestAPI_HandleDeviceToken_UnsupportedGrant(t *testing.T) {
+	api := NewAPI(&mockAuthService{})
+
+	req := newFormRequest("/device/token", url.Values{
+		"grant_type":  {"password"},
+		"device_code": {"device_code_value"},
+	})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"unsupported_grant_type"}`, rec.Body.String())
+}
+
+func TestAPI_HandleDeviceToken_InternalError(t *testing.T) {
+	srv := &mockAuthService{
+		pollDeviceFunc: func(ctx context.Context, deviceCode string) (service.DeviceTokenResponse, error) {
+			return service.DeviceTokenResponse{}, errors.New("db down")
+		},
+	}
+	api := NewAPI(srv)
+
+	req := newFormRequest("/device/token", url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {"device_code_value"},
+	})
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+type internalService interface {
+	RevokedSessions(ctx context.Context) ([]service.RevokedSession, error)
+	Introspect(ctx context.Context, raw string) (service.Introspection, error)
+	Revoke(ctx context.Context, raw string) error
+}
+
+// InternalAPI serves the endpoints other services call, such as the session denylist
+// services verifying access tokens on their own check tokens against. The server mounts it
+// behind service authentication, so that only services can look up and revoke tokens.
+type InternalAPI struct {
+	srv internalService
+	mux *http.ServeMux
+}
+
+func NewInternalAPI(srv internalService) *InternalAPI {
+	api := &InternalAPI{
+		srv: srv,
+		mux: http.NewServeMux(),
+	}
+	api.mount()
+	return api
+}
+
+func (a *InternalAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+func (a *InternalAPI) mount() {
+	a.mux.HandleFunc("GET /sessions/revoked", a.handleRevokedSessions)
+	a.mux.HandleFunc("POST /introspect", a.handleIntrospect)
+	a.mux.HandleFunc("POST /revoke", a.handleRevoke)
+}
+
+type revokedSession struct {
+	Sub       string    `json:"sub"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type revokedSessionsResponse struct {
+	Sessions []revokedSession `json:"sessions"`
+}
+
+func (a *InternalAPI) handleRevokedSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := a.srv.RevokedSessions(r.Context())
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := revokedSessionsResponse{Sessions: make([]revokedSession, 0, len(sessions))}
+	for _, rs := range sessions {
+		resp.Sessions = append(resp.Sessions, revokedSession{Sub: rs.UserUID, RevokedAt: rs.RevokedAt})
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
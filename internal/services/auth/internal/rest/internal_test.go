@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInternalService struct {
+	revokedSessionsFunc func(ctx context.Context) ([]service.RevokedSession, error)
+	introspectFunc      func(ctx context.Context, raw string) (service.Introspection, error)
+	revokeFunc          func(ctx context.Context, raw string) error
+}
+
+func (m *mockInternalService) RevokedSessions(ctx context.Context) ([]service.RevokedSession, error) {
+	return m.revokedSessionsFunc(ctx)
+}
+
+func (m *mockInternalService) Introspect(ctx context.Context, raw string) (service.Introspection, error) {
+	return m.introspectFunc(ctx, raw)
+}
+
+func (m *mockInternalService) Revoke(ctx context.Context, raw string) error {
+	return m.revokeFunc(ctx, raw)
+}
+
+func TestInternalAPI_HandleRevokedSessions(t *testing.T) {
+	api := NewInternalAPI(&mockInternalService{
+		revokedSessionsFunc: func(ctx context.Context) ([]service.RevokedSession, error) {
+			return []service.RevokedSession{{UserUID: "uid-1", RevokedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}}, nil
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/sessions/revoked", nil)
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sessions":[{"sub":"uid-1","revoked_at":"2025-01-02T03:04:05Z"}]}`, rec.Body.String())
+}
+
+func TestInternalAPI_ServiceAuth(t *testing.T) {
+	secret := []byte("service-secret")
+	var introspected, revoked []string
+	api := middleware.ServiceAuth(svctoken.NewVerifier("auth", secret))(NewInternalAPI(&mockInternalService{
+		introspectFunc: func(ctx context.Context, raw string) (service.Introspection, error) {
+			introspected = append(introspected, raw)
+			return service.Introspection{}, nil
+		},
+		revokeFunc: func(ctx context.Context, raw string) error {
+			revoked = append(revoked, raw)
+			return nil
+		},
+	}))
+
+	for _, path := range []string{"/introspect", "/revoke"} {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, formRequest("POST", path, url.Values{"token": {"access_token"}}))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+
+		req := formRequest("POST", path, url.Values{"token": {"access_token"}})
+		req.Header.Set("Authorization", "Bearer access_token")
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+	assert.Empty(t, introspected)
+	assert.Empty(t, revoked)
+
+	tk, err := svctoken.NewSigner("words", secret, time.Minute).Token("auth")
+	require.NoError(t, err)
+	for _, path := range []string{"/introspect", "/revoke"} {
+		req := formRequest("POST", path, url.Values{"token": {"access_token"}})
+		req.Header.Set("Authorization", "Bearer "+tk)
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+	assert.Equal(t, []string{"access_token"}, introspected)
+	assert.Equal(t, []string{"access_token"}, revoked)
+}
@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+// introspectResponse follows RFC 7662. Inactive tokens only report the active flag.
+type introspectResponse struct {
+	Active    bool   `json:"active"`
+	Sub       string `json:"sub,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Typ       string `json:"typ,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Picture   string `json:"picture,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+
+	NativeLang  string   `json:"native_lang,omitempty"`
+	TargetLangs []string `json:"target_langs,omitempty"`
+	DailyGoal   int      `json:"daily_goal,omitempty"`
+	Timezone    string   `json:"zoneinfo,omitempty"`
+}
+
+type introspector interface {
+	Introspect(ctx context.Context, raw string) (service.Introspection, error)
+}
+
+func (a *InternalAPI) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	writeIntrospection(w, r, a.srv)
+}
+
+// handleIntrospect serves introspection to clients outside the cluster. RFC 7662 requires the
+// endpoint to authenticate its callers, so clients send their credentials with HTTP Basic authentication.
+func (a *API) handleIntrospect(w http.ResponseWriter, r *http.Request) {
+	if !a.authenticateClient(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+		httpx.HandleErr(w, r, serr.NewServiceError(nil, http.StatusUnauthorized, "invalid client credentials"))
+		return
+	}
+
+	writeIntrospection(w, r, a.srv)
+}
+
+// authenticateClient reports whether the request carries the credentials of an introspection client
+func (a *API) authenticateClient(r *http.Request) bool {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	want, ok := a.clients[id]
+	got := sha256.Sum256([]byte(secret))
+	return ok && subtle.ConstantTimeCompare(want[:], got[:]) == 1
+}
+
+func writeIntrospection(w http.ResponseWriter, r *http.Request, srv introspector) {
+	raw, err := formToken(r)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	info, err := srv.Introspect(r.Context(), raw)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := introspectResponse{Active: info.Active}
+	if info.Active {
+		c := info.Claims
+		resp.Sub = c.ID
+		resp.Scope = c.Scope
+		resp.Exp = unixTime(c.ExpiresAt)
+		resp.Iat = unixTime(c.IssuedAt)
+		resp.Jti = c.TokenID
+		resp.TokenType = info.TokenType
+		resp.Typ = string(c.Type)
+		resp.Email = c.Email
+		resp.Name = c.Name
+		resp.Picture = c.Picture
+		resp.Provider = c.Provider
+		resp.NativeLang = c.NativeLang
+		resp.TargetLangs = c.TargetLangs
+		resp.DailyGoal = c.DailyGoal
+		resp.Timezone = c.Timezone
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+// handleRevoke follows RFC 7009: unknown and already revoked tokens are not an error
+func (a *InternalAPI) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	raw, err := formToken(r)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := a.srv.Revoke(r.Context(), raw); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type userInfoResponse struct {
+	Sub      string `json:"sub"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Picture  string `json:"picture,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+func (a *API) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	raw := middleware.BearerToken(r)
+	if raw == "" {
+		httpx.HandleErr(w, r, serr.NewServiceError(nil, http.StatusUnauthorized, "missing access token"))
+		return
+	}
+
+	info, err := a.srv.UserInfo(r.Context(), raw)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	err = httpx.WriteJSON(w, http.StatusOK, userInfoResponse{
+		Sub:      info.UID,
+		Email:    info.Email,
+		Name:     info.Name,
+		Picture:  info.Picture,
+		Provider: info.Provider,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+// formToken reads the token parameter of a form encoded request
+func formToken(r *http.Request) (string, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", serr.NewServiceError(err, http.StatusBadRequest, "invalid request body")
+	}
+
+	raw := r.PostForm.Get("token")
+	if raw == "" {
+		return "", serr.NewServiceError(nil, http.StatusBadRequest, "token is required")
+	}
+
+	return raw, nil
+}
+
+// unixTime converts t to seconds since the epoch, keeping the zero time as 0
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func formRequest(method, target string, values url.Values) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestInternalAPI_HandleIntrospect(t *testing.T) {
+	srv := &mockInternalService{
+		introspectFunc: func(ctx context.Context, raw string) (service.Introspection, error) {
+			if raw != "access_token" {
+				return service.Introspection{}, nil
+			}
+			return service.Introspection{
+				Active:    true,
+				TokenType: service.TokenTypeAccess,
+				Claims: token.UserClaims{
+					Type:      token.TypeAccess,
+					ID:        "uid-1",
+					Email:     "user@example.com",
+					Provider:  "google",
+					Name:      "User",
+					Picture:   "https://example.com/u.png",
+					Scope:     "words:read words:write",
+					TokenID:   "jti-1",
+					IssuedAt:  time.Unix(1700000000, 0),
+					ExpiresAt: time.Unix(1700000900, 0),
+				},
+			}, nil
+		},
+	}
+	api := NewInternalAPI(srv)
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, formRequest("POST", "/introspect", url.Values{"token": {"access_token"}}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t,
+		`{
+			"active":true,
+			"sub":"uid-1",
+			"scope":"words:read words:write",
+			"exp":1700000900,
+			"iat":1700000000,
+			"jti":"jti-1",
+			"token_type":"access_token",
+			"typ":"access",
+			"email":"user@example.com",
+			"name":"User",
+			"picture":"https://example.com/u.png",
+			"provider":"google"
+		}`,
+		rec.Body.String(),
+	)
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, formRequest("POST", "/introspect", url.Values{"token": {"revoked"}}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"active":false}`, rec.Body.String())
+}
+
+func TestInternalAPI_HandleIntrospect_MissingToken(t *testing.T) {
+	api := NewInternalAPI(&mockInternalService{})
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, formRequest("POST", "/introspect", url.Values{}))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPI_HandleIntrospect(t *testing.T) {
+	srv := &mockAuthService{
+		introspectFunc: func(ctx context.Context, raw string) (service.Introspection, error) {
+			assert.Equal(t, "access_token", raw)
+			return service.Introspection{
+				Active:    true,
+				TokenType: service.TokenTypeAccess,
+				Claims:    token.UserClaims{Type: token.TypeAccess, ID: "uid-1", Scope: "words:read"},
+			}, nil
+		},
+	}
+	api := NewAPI(srv, WithIntrospectionClients(IntrospectionClient{ID: "tool", Secret: "tool_secret"}))
+
+	req := formRequest("POST", "/introspect", url.Values{"token": {"access_token"}})
+	req.SetBasicAuth("tool", "tool_secret")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"active":true,"sub":"uid-1","scope":"words:read","token_type":"access_token","typ":"access"}`, rec.Body.String())
+}
+
+func TestAPI_HandleIntrospect_Unauthorized(t *testing.T) {
+	srv := &mockAuthService{
+		introspectFunc: func(ctx context.Context, raw string) (service.Introspection, error) {
+			t.Fatal("tokens must not be introspected for unauthenticated clients")
+			return service.Introspection{}, nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		api    *API
+		client string
+		secret string
+	}{
+		{name: "no credentials", api: NewAPI(srv, WithIntrospectionClients(IntrospectionClient{ID: "tool", Secret: "tool_secret"}))},
+		{name: "wrong secret", api: NewAPI(srv, WithIntrospectionClients(IntrospectionClient{ID: "tool", Secret: "tool_secret"})), client: "tool", secret: "other_secret"},
+		{name: "unknown client", api: NewAPI(srv, WithIntrospectionClients(IntrospectionClient{ID: "tool", Secret: "tool_secret"})), client: "other", secret: "tool_secret"},
+		{name: "no clients", api: NewAPI(srv), client: "tool", secret: "tool_secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := formRequest("POST", "/introspect", url.Values{"token": {"access_token"}})
+			if tt.client != "" {
+				req.SetBasicAuth(tt.client, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			tt.api.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, `Basic realm="introspect"`, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestInternalAPI_HandleRevoke(t *testing.T) {
+	var revoked []string
+	srv := &mockInternalService{
+		revokeFunc: func(ctx context.Context, raw string) error {
+			revoked = append(revoked, raw)
+			return nil
+		},
+	}
+	api := NewInternalAPI(srv)
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, formRequest("POST", "/revoke", url.Values{"token": {"refresh_token"}}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"refresh_token"}, revoked)
+}
+
+func TestAPI_HandleUserInfo(t *testing.T) {
+	srv := &mockAuthService{
+		userInfoFunc: func(ctx context.Context, accessToken string) (service.UserInfo, error) {
+			assert.Equal(t, "access_token", accessToken)
+			return service.UserInfo{
+				UID:      "uid-1",
+				Email:    "user@example.com",
+				Name:     "User",
+				Provider: "google",
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"sub":"uid-1","email":"user@example.com","name":"User","provider":"google"}`, rec.Body.String())
+}
+
+func TestAPI_HandleUserInfo_MissingToken(t *testing.T) {
+	api := NewAPI(&mockAuthService{})
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("GET", "/userinfo", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
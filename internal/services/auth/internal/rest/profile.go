@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+type profileResponse struct {
+	UID         string     `json:"uid"`
+	DisplayName string     `json:"display_name"`
+	NativeLang  string     `json:"native_lang"`
+	TargetLangs []string   `json:"target_langs"`
+	DailyGoal   int        `json:"daily_goal"`
+	Timezone    string     `json:"timezone"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+func newProfileResponse(p service.Profile) profileResponse {
+	langs := p.TargetLangs
+	if langs == nil {
+		langs = []string{}
+	}
+
+	return profileResponse{
+		UID:         p.UID,
+		DisplayName: p.DisplayName,
+		NativeLang:  p.NativeLang,
+		TargetLangs: langs,
+		DailyGoal:   p.DailyGoal,
+		Timezone:    p.Timezone,
+		UpdatedAt:   optionalTime(p.UpdatedAt),
+	}
+}
+
+func (a *API) handleGetProfile(w http.ResponseWriter, r *http.Request, uid string) {
+	p, err := a.srv.GetProfile(r.Context(), uid)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newProfileResponse(p)); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+// updateProfileRequest holds the fields to change, omitted fields are left as they are
+type updateProfileRequest struct {
+	DisplayName *string   `json:"display_name"`
+	NativeLang  *string   `json:"native_lang"`
+	TargetLangs *[]string `json:"target_langs"`
+	DailyGoal   *int      `json:"daily_goal"`
+	Timezone    *string   `json:"timezone"`
+}
+
+func (a *API) handleUpdateProfile(w http.ResponseWriter, r *http.Request, uid string) {
+	var req updateProfileRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	p, err := a.srv.UpdateProfile(r.Context(), service.UpdateProfileRequest{
+		UserUID:     uid,
+		DisplayName: req.DisplayName,
+		NativeLang:  req.NativeLang,
+		TargetLangs: req.TargetLangs,
+		DailyGoal:   req.DailyGoal,
+		Timezone:    req.Timezone,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newProfileResponse(p)); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
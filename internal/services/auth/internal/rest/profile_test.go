@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_HandleGetProfile(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		getProfileFunc: func(ctx context.Context, userUID string) (service.Profile, error) {
+			assert.Equal(t, "uid-1", userUID)
+			return service.Profile{UID: userUID}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t,
+		`{"uid":"uid-1","display_name":"","native_lang":"","target_langs":[],"daily_goal":0,"timezone":""}`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleUpdateProfile(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		updateProfileFunc: func(ctx context.Context, r service.UpdateProfileRequest) (service.Profile, error) {
+			assert.Equal(t, "uid-1", r.UserUID)
+			assert.Nil(t, r.DisplayName)
+			assert.Nil(t, r.NativeLang)
+			assert.Equal(t, &[]string{"de"}, r.TargetLangs)
+			assert.Equal(t, 20, *r.DailyGoal)
+			return service.Profile{
+				UID:         "uid-1",
+				NativeLang:  "en",
+				TargetLangs: []string{"de"},
+				DailyGoal:   20,
+				Timezone:    "UTC",
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("PATCH", "/me", strings.NewReader(`{"target_langs":["de"],"daily_goal":20}`))
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t,
+		`{"uid":"uid-1","display_name":"","native_lang":"en","target_langs":["de"],"daily_goal":20,"timezone":"UTC"}`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleUpdateProfile_Unauthorized(t *testing.T) {
+	api := NewAPI(&mockAuthService{authenticateFunc: authenticateAs("uid-1")})
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("PATCH", "/me", strings.NewReader(`{"daily_goal":20}`)))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
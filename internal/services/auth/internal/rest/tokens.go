@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+)
+
+// userHandlerFunc is an http handler which receives the UID of the authenticated user
+type userHandlerFunc func(w http.ResponseWriter, r *http.Request, uid string)
+
+// authenticated resolves the user from the access token in the Authorization header
+func (a *API) authenticated(next userHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := middleware.BearerToken(r)
+		if raw == "" {
+			httpx.HandleErr(w, r, serr.NewServiceError(nil, http.StatusUnauthorized, "missing access token"))
+			return
+		}
+
+		uid, err := a.srv.Authenticate(r.Context(), raw)
+		if err != nil {
+			httpx.HandleErr(w, r, err)
+			return
+		}
+
+		next(w, r, uid)
+	}
+}
+
+type personalToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newPersonalToken(pt service.PersonalToken) personalToken {
+	return personalToken{
+		ID:         pt.ID,
+		Name:       pt.Name,
+		Prefix:     pt.Prefix,
+		Scopes:     pt.Scopes,
+		ExpiresAt:  optionalTime(pt.ExpiresAt),
+		LastUsedAt: optionalTime(pt.LastUsedAt),
+		RevokedAt:  optionalTime(pt.RevokedAt),
+		CreatedAt:  pt.CreatedAt,
+	}
+}
+
+// optionalTime maps the zero time to nil so that it is omitted from responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type createTokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int64    `json:"expires_in"`
+}
+
+type createTokenResponse struct {
+	personalToken
+	Token string `json:"token"`
+}
+
+func (a *API) handleCreateToken(w http.ResponseWriter, r *http.Request, uid string) {
+	var req createTokenRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	resp, err := a.srv.CreateAccessToken(r.Context(), service.CreateAccessTokenRequest{
+		UserUID: uid,
+		Name:    req.Name,
+		Scopes:  req.Scopes,
+		TTL:     time.Duration(req.ExpiresIn) * time.Second,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	err = httpx.WriteJSON(w, http.StatusCreated, createTokenResponse{
+		personalToken: newPersonalToken(resp.PersonalToken),
+		Token:         resp.Token,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+func (a *API) handleListTokens(w http.ResponseWriter, r *http.Request, uid string) {
+	tokens, err := a.srv.ListAccessTokens(r.Context(), uid)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	resp := make([]personalToken, 0, len(tokens))
+	for _, pt := range tokens {
+		resp = append(resp, newPersonalToken(pt))
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, resp); err != nil {
+		httpx.HandleErr(w, r, fmt.Errorf("write response json: %w", err))
+		return
+	}
+}
+
+func (a *API) handleRevokeToken(w http.ResponseWriter, r *http.Request, uid string) {
+	id, err := strconv.ParseInt(r.PathValue("token_id"), 10, 64)
+	if err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid token id"))
+		return
+	}
+
+	if err := a.srv.RevokeAccessToken(r.Context(), uid, id); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
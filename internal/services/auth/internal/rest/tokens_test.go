@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func authenticateAs(uid string) func(ctx context.Context, accessToken string) (string, error) {
+	return func(ctx context.Context, accessToken string) (string, error) {
+		if accessToken != "valid_access_token" {
+			return "", serr.NewServiceError(errors.New("invalid token"), http.StatusUnauthorized, "invalid access token")
+		}
+		return uid, nil
+	}
+}
+
+func TestAPI_HandleCreateToken(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		createTokenFunc: func(ctx context.Context, r service.CreateAccessTokenRequest) (service.CreateAccessTokenResponse, error) {
+			assert.Equal(t, service.CreateAccessTokenRequest{
+				UserUID: "uid-1",
+				Name:    "importer",
+				Scopes:  []string{"words:write"},
+				TTL:     time.Hour,
+			}, r)
+			return service.CreateAccessTokenResponse{
+				Token: "lxp_secret",
+				PersonalToken: service.PersonalToken{
+					ID:        5,
+					Name:      "importer",
+					Prefix:    "lxp_se",
+					Scopes:    []string{"words:write"},
+					ExpiresAt: createdAt.Add(time.Hour),
+					CreatedAt: createdAt,
+				},
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("POST", "/tokens", strings.NewReader(`{"name":"importer","scopes":["words:write"],"expires_in":3600}`))
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t,
+		`{
+			"id":5,
+			"name":"importer",
+			"prefix":"lxp_se",
+			"scopes":["words:write"],
+			"expires_at":"2025-01-02T04:04:05Z",
+			"created_at":"2025-01-02T03:04:05Z",
+			"token":"lxp_secret"
+		}`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleCreateToken_Unauthorized(t *testing.T) {
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("POST", "/tokens", strings.NewReader(`{"name":"importer","scopes":["words:write"]}`))
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest("POST", "/tokens", strings.NewReader(`{"name":"importer","scopes":["words:write"]}`))
+	req.Header.Set("Authorization", "lxp_personal_token")
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAPI_HandleListTokens(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		listTokensFunc: func(ctx context.Context, userUID string) ([]service.PersonalToken, error) {
+			assert.Equal(t, "uid-1", userUID)
+			return []service.PersonalToken{
+				{
+					ID:        2,
+					Name:      "script",
+					Prefix:    "lxp_ab",
+					Scopes:    []string{"words:read"},
+					RevokedAt: createdAt.Add(time.Minute),
+					CreatedAt: createdAt,
+				},
+			}, nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("GET", "/tokens", nil)
+	req.Header.Set("Authorization", "valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t,
+		`[{
+			"id":2,
+			"name":"script",
+			"prefix":"lxp_ab",
+			"scopes":["words:read"],
+			"revoked_at":"2025-01-02T03:05:05Z",
+			"created_at":"2025-01-02T03:04:05Z"
+		}]`,
+		rec.Body.String(),
+	)
+}
+
+func TestAPI_HandleRevokeToken(t *testing.T) {
+	var revoked []int64
+	srv := &mockAuthService{
+		authenticateFunc: authenticateAs("uid-1"),
+		revokeTokenFunc: func(ctx context.Context, userUID string, id int64) error {
+			assert.Equal(t, "uid-1", userUID)
+			revoked = append(revoked, id)
+			return nil
+		},
+	}
+	api := NewAPI(srv)
+
+	req := httptest.NewRequest("DELETE", "/tokens/7", nil)
+	req.Header.Set("Authorization", "Bearer valid_access_token")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []int64{7}, revoked)
+}
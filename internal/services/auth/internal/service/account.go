@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+)
+
+// userDataService is another service keeping data about users, which is exported
+// and deleted together with their account
+type userDataService interface {
+	ExportUserData(ctx context.Context, userUID string) (json.RawMessage, error)
+	DeleteUserData(ctx context.Context, userUID string) error
+}
+
+type linkedService struct {
+	name string
+	svc  userDataService
+}
+
+// WithUserData registers a service whose user data is part of account exports and deletions
+func WithUserData(name string, svc userDataService) AuthOption {
+	return func(s *Auth) *Auth {
+		s.linked = append(s.linked, linkedService{name: name, svc: svc})
+		return s
+	}
+}
+
+// AccountIdentity describes an identity linked to an account
+type AccountIdentity struct {
+	Provider  string
+	Email     string
+	Name      string
+	Picture   string
+	CreatedAt time.Time
+}
+
+func newAccountIdentity(id store.Identity) AccountIdentity {
+	return AccountIdentity{
+		Provider:  id.Provider,
+		Email:     id.Email,
+		Name:      id.Name,
+		Picture:   id.Picture,
+		CreatedAt: id.CreatedAt,
+	}
+}
+
+// AccountExport holds everything stored about a user across services
+type AccountExport struct {
+	UserUID      string
+	CreatedAt    time.Time
+	ExportedAt   time.Time
+	Identities   []AccountIdentity
+	Profile      Profile
+	AccessTokens []PersonalToken
+	// Services holds the data exported by each linked service, keyed by service name
+	Services map[string]json.RawMessage
+}
+
+// ExportAccount collects the data of the user from the auth store and all linked services
+func (s *Auth) ExportAccount(ctx context.Context, userUID string) (AccountExport, error) {
+	usr, err := s.getUser(ctx, userUID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	ids, err := s.store.ListUserIdentities(ctx, store.ListUserIdentitiesRequest{UserUID: userUID})
+	if err != nil {
+		return AccountExport{}, fmt.Errorf("list user identities: %w", err)
+	}
+
+	profile, err := s.GetProfile(ctx, userUID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	tokens, err := s.ListAccessTokens(ctx, userUID)
+	if err != nil {
+		return AccountExport{}, err
+	}
+
+	export := AccountExport{
+		UserUID:      usr.UID,
+		CreatedAt:    usr.CreatedAt,
+		ExportedAt:   s.now(),
+		Identities:   make([]AccountIdentity, 0, len(ids)),
+		Profile:      profile,
+		AccessTokens: tokens,
+		Services:     make(map[string]json.RawMessage, len(s.linked)),
+	}
+	for _, id := range ids {
+		export.Identities = append(export.Identities, newAccountIdentity(id))
+	}
+
+	for _, l := range s.linked {
+		data, err := l.svc.ExportUserData(ctx, userUID)
+		if err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadGateway, "export user data from %s", l.name)
+			sErr.Env["service"] = l.name
+			return AccountExport{}, sErr
+		}
+
+		export.Services[l.name] = data
+	}
+
+	return export, nil
+}
+
+// DeleteAccount revokes the sessions of the user and deletes the user from all linked services
+// and then from the auth store. Sessions are revoked first, so that no token issued so far works
+// while the data is being deleted. Linked services are cleaned up before the account itself, so that
+// a failed deletion can be retried by the user, who is still able to sign in until the account is gone.
+func (s *Auth) DeleteAccount(ctx context.Context, userUID string) error {
+	if _, err := s.getUser(ctx, userUID); err != nil {
+		return err
+	}
+
+	err := s.store.RevokeUserSessions(ctx, store.RevokeUserSessionsRequest{UID: userUID, RevokedAt: s.now()})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return fmt.Errorf("revoke user sessions: %w", err)
+	}
+
+	for _, l := range s.linked {
+		if err := l.svc.DeleteUserData(ctx, userUID); err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadGateway, "delete user data from %s", l.name)
+			sErr.Env["service"] = l.name
+			return sErr
+		}
+	}
+
+	err = s.store.DeleteUser(ctx, store.DeleteUserRequest{UID: userUID, DeletedAt: s.now()})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Auth) getUser(ctx context.Context, userUID string) (store.User, error) {
+	usr, err := s.store.GetUser(ctx, store.GetUserRequest{UID: userUID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return store.User{}, serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return store.User{}, fmt.Errorf("get user: %w", err)
+	}
+
+	return usr, nil
+}
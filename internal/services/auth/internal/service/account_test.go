@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserData struct {
+	exportFunc func(ctx context.Context, userUID string) (json.RawMessage, error)
+	deleteFunc func(ctx context.Context, userUID string) error
+}
+
+func (m *mockUserData) ExportUserData(ctx context.Context, userUID string) (json.RawMessage, error) {
+	return m.exportFunc(ctx, userUID)
+}
+
+func (m *mockUserData) DeleteUserData(ctx context.Context, userUID string) error {
+	return m.deleteFunc(ctx, userUID)
+}
+
+func newAccountAuth(st *mockStore, words *mockUserData) *Auth {
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(st),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{}),
+		WithUserData("words", words),
+	)
+	srv.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return srv
+}
+
+func existingUser(ctx context.Context, r store.GetUserRequest) (store.User, error) {
+	return store.User{ID: 1, UID: r.UID}, nil
+}
+
+func TestAuth_ExportAccount(t *testing.T) {
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: existingUser,
+		listUserIdentitiesFunc: func(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error) {
+			return []store.Identity{{ID: "google-1", Provider: "google", Email: "user@example.com"}}, nil
+		},
+		listAccessTokensFunc: func(ctx context.Context, r store.ListAccessTokensRequest) ([]store.AccessToken, error) {
+			return []store.AccessToken{{ID: 3, Name: "script"}}, nil
+		},
+	}, &mockUserData{
+		exportFunc: func(ctx context.Context, userUID string) (json.RawMessage, error) {
+			assert.Equal(t, "uid-1", userUID)
+			return json.RawMessage(`{"picks":[]}`), nil
+		},
+	})
+
+	export, err := srv.ExportAccount(context.Background(), "uid-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "uid-1", export.UserUID)
+	assert.Equal(t, []AccountIdentity{{Provider: "google", Email: "user@example.com"}}, export.Identities)
+	assert.Equal(t, "uid-1", export.Profile.UID)
+	require.Len(t, export.AccessTokens, 1)
+	assert.Equal(t, "script", export.AccessTokens[0].Name)
+	assert.JSONEq(t, `{"picks":[]}`, string(export.Services["words"]))
+}
+
+func TestAuth_ExportAccount_ServiceError(t *testing.T) {
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: existingUser,
+		listUserIdentitiesFunc: func(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error) {
+			return nil, nil
+		},
+		listAccessTokensFunc: func(ctx context.Context, r store.ListAccessTokensRequest) ([]store.AccessToken, error) {
+			return nil, nil
+		},
+	}, &mockUserData{
+		exportFunc: func(ctx context.Context, userUID string) (json.RawMessage, error) {
+			return nil, errors.New("connection refused")
+		},
+	})
+
+	_, err := srv.ExportAccount(context.Background(), "uid-1")
+	requireStatus(t, err, http.StatusBadGateway)
+}
+
+func TestAuth_DeleteAccount(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var calls []string
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: existingUser,
+		revokeUserSessionsFunc: func(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+			assert.Equal(t, now, r.RevokedAt)
+			calls = append(calls, "revoke:"+r.UID)
+			return nil
+		},
+		deleteUserFunc: func(ctx context.Context, r store.DeleteUserRequest) error {
+			assert.Equal(t, now, r.DeletedAt)
+			calls = append(calls, "auth:"+r.UID)
+			return nil
+		},
+	}, &mockUserData{
+		deleteFunc: func(ctx context.Context, userUID string) error {
+			calls = append(calls, "words:"+userUID)
+			return nil
+		},
+	})
+
+	require.NoError(t, srv.DeleteAccount(context.Background(), "uid-1"))
+	assert.Equal(t, []string{"revoke:uid-1", "words:uid-1", "auth:uid-1"}, calls)
+}
+
+func TestAuth_DeleteAccount_RevokesTokens(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	// the store keeps a record of the deleted user, the users row itself is gone
+	var deleted []store.RevokedSession
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: existingUser,
+		revokeUserSessionsFunc: func(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+			return nil
+		},
+		deleteUserFunc: func(ctx context.Context, r store.DeleteUserRequest) error {
+			deleted = append(deleted, store.RevokedSession{UserUID: r.UID, RevokedAt: r.DeletedAt})
+			return nil
+		},
+		listRevokedSessionsFunc: func(ctx context.Context, r store.ListRevokedSessionsRequest) ([]store.RevokedSession, error) {
+			return deleted, nil
+		},
+	}, &mockUserData{
+		deleteFunc: func(ctx context.Context, userUID string) error {
+			return nil
+		},
+	})
+
+	require.NoError(t, srv.DeleteAccount(context.Background(), "uid-1"))
+
+	sessions, err := srv.RevokedSessions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "uid-1", sessions[0].UserUID)
+	assert.True(t, middleware.Revoked(issuedAt, sessions[0].RevokedAt), "tokens issued before the deletion must be rejected")
+}
+
+func TestAuth_DeleteAccount_ServiceError(t *testing.T) {
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: existingUser,
+		revokeUserSessionsFunc: func(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+			return nil
+		},
+		deleteUserFunc: func(ctx context.Context, r store.DeleteUserRequest) error {
+			t.Fatal("user must not be deleted when a linked service fails")
+			return nil
+		},
+	}, &mockUserData{
+		deleteFunc: func(ctx context.Context, userUID string) error {
+			return errors.New("connection refused")
+		},
+	})
+
+	err := srv.DeleteAccount(context.Background(), "uid-1")
+	requireStatus(t, err, http.StatusBadGateway)
+}
+
+func TestAuth_DeleteAccount_NotFound(t *testing.T) {
+	srv := newAccountAuth(&mockStore{
+		getUserFunc: func(ctx context.Context, r store.GetUserRequest) (store.User, error) {
+			return store.User{}, store.ErrNotFound
+		},
+	}, &mockUserData{})
+
+	err := srv.DeleteAccount(context.Background(), "uid-1")
+	requireStatus(t, err, http.StatusNotFound)
+}
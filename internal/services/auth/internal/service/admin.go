@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+)
+
+const (
+	defaultUsersPageSize = 20
+	maxUsersPageSize     = 100
+)
+
+// defaultDenylistWindow matches the default access token TTL
+const defaultDenylistWindow = 15 * time.Minute
+
+// WithSessionDenylist sets how long revoked sessions are reported by RevokedSessions.
+// It must be at least the access token TTL, since older access tokens have expired anyway.
+func WithSessionDenylist(window time.Duration) AuthOption {
+	return func(s *Auth) *Auth {
+		s.denylistWindow = window
+		return s
+	}
+}
+
+// AdminUser describes a user as seen by administrators
+type AdminUser struct {
+	UID               string
+	Role              string
+	CreatedAt         time.Time
+	DisabledAt        time.Time
+	SessionsRevokedAt time.Time
+	Identities        []AccountIdentity
+}
+
+// AuthorizeAdmin makes sure the user is an active administrator
+func (s *Auth) AuthorizeAdmin(ctx context.Context, userUID string) error {
+	usr, err := s.store.GetUser(ctx, store.GetUserRequest{UID: userUID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return serr.NewServiceError(err, http.StatusUnauthorized, "user not found")
+		}
+
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	if usr.Role != store.RoleAdmin || usr.Disabled() {
+		return serr.NewServiceError(nil, http.StatusForbidden, "admin role required")
+	}
+
+	return nil
+}
+
+type ListUsersRequest struct {
+	Email    string
+	Provider string
+	Cursor   string
+	Limit    int
+}
+
+type ListUsersResponse struct {
+	Users []AdminUser
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// ListUsers lists users, optionally filtered by the email prefix and provider of their identities
+func (s *Auth) ListUsers(ctx context.Context, r ListUsersRequest) (ListUsersResponse, error) {
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultUsersPageSize
+	}
+	if limit < 0 || limit > maxUsersPageSize {
+		sErr := serr.NewServiceError(nil, http.StatusBadRequest, "limit must be between 1 and %d", maxUsersPageSize)
+		sErr.Env["limit"] = strconv.Itoa(r.Limit)
+		return ListUsersResponse{}, sErr
+	}
+
+	var after int64
+	if r.Cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(r.Cursor, 10, 64)
+		if err != nil || after < 0 {
+			return ListUsersResponse{}, serr.NewServiceError(err, http.StatusBadRequest, "invalid cursor")
+		}
+	}
+
+	// one more user than requested tells whether there is a next page
+	users, err := s.store.ListUsers(ctx, store.ListUsersRequest{
+		Email:    strings.TrimSpace(r.Email),
+		Provider: strings.TrimSpace(r.Provider),
+		AfterID:  after,
+		Limit:    limit + 1,
+	})
+	if err != nil {
+		return ListUsersResponse{}, fmt.Errorf("list users: %w", err)
+	}
+
+	resp := ListUsersResponse{Users: make([]AdminUser, 0, min(len(users), limit))}
+	if len(users) > limit {
+		users = users[:limit]
+		resp.NextCursor = strconv.FormatInt(users[limit-1].ID, 10)
+	}
+
+	for _, usr := range users {
+		au, err := s.adminUser(ctx, usr)
+		if err != nil {
+			return ListUsersResponse{}, err
+		}
+
+		resp.Users = append(resp.Users, au)
+	}
+
+	return resp, nil
+}
+
+// GetUser returns the user together with its identities
+func (s *Auth) GetUser(ctx context.Context, userUID string) (AdminUser, error) {
+	usr, err := s.getUser(ctx, userUID)
+	if err != nil {
+		return AdminUser{}, err
+	}
+
+	return s.adminUser(ctx, usr)
+}
+
+// DisableUser disables the account and revokes all of its sessions.
+// Disabled users cannot sign in, refresh tokens or use personal access tokens.
+func (s *Auth) DisableUser(ctx context.Context, adminUID, userUID string) (AdminUser, error) {
+	if adminUID == userUID {
+		return AdminUser{}, serr.NewServiceError(nil, http.StatusBadRequest, "administrators cannot disable themselves")
+	}
+
+	now := s.now()
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		err := tx.UpdateUserStatus(ctx, store.UpdateUserStatusRequest{UID: userUID, DisabledAt: now})
+		if err != nil {
+			return err
+		}
+
+		return tx.RevokeUserSessions(ctx, store.RevokeUserSessionsRequest{UID: userUID, RevokedAt: now})
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return AdminUser{}, serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return AdminUser{}, fmt.Errorf("disable user: %w", err)
+	}
+
+	s.audit(ctx, EventUserDisabled, userUID, "", "by "+adminUID)
+	return s.GetUser(ctx, userUID)
+}
+
+// EnableUser enables a disabled account. Sessions revoked while disabling it stay revoked.
+func (s *Auth) EnableUser(ctx context.Context, adminUID, userUID string) (AdminUser, error) {
+	err := s.store.UpdateUserStatus(ctx, store.UpdateUserStatusRequest{UID: userUID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return AdminUser{}, serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return AdminUser{}, fmt.Errorf("enable user: %w", err)
+	}
+
+	s.audit(ctx, EventUserEnabled, userUID, "", "by "+adminUID)
+	return s.GetUser(ctx, userUID)
+}
+
+// RevokeSessions invalidates all refresh and access tokens of the user issued so far
+// and revokes all of its personal access tokens
+func (s *Auth) RevokeSessions(ctx context.Context, adminUID, userUID string) error {
+	err := s.store.RevokeUserSessions(ctx, store.RevokeUserSessionsRequest{UID: userUID, RevokedAt: s.now()})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return fmt.Errorf("revoke user sessions: %w", err)
+	}
+
+	s.audit(ctx, EventSessionsRevoked, userUID, "", "by "+adminUID)
+	return nil
+}
+
+// RevokedSession tells that all tokens of the user issued before RevokedAt are invalid
+type RevokedSession struct {
+	UserUID   string
+	RevokedAt time.Time
+}
+
+// RevokedSessions lists the sessions revoked recently enough for their access tokens to be still valid.
+// Services verifying access tokens on their own use it as a denylist.
+func (s *Auth) RevokedSessions(ctx context.Context) ([]RevokedSession, error) {
+	revoked, err := s.store.ListRevokedSessions(ctx, store.ListRevokedSessionsRequest{
+		Since: s.now().Add(-s.denylistWindow),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list revoked sessions: %w", err)
+	}
+
+	sessions := make([]RevokedSession, 0, len(revoked))
+	for _, rs := range revoked {
+		sessions = append(sessions, RevokedSession{UserUID: rs.UserUID, RevokedAt: rs.RevokedAt})
+	}
+
+	return sessions, nil
+}
+
+// checkUser makes sure the account is enabled and, unless issuedAt is zero,
+// that the token issued at that time has not been revoked with the sessions of the user
+func checkUser(usr store.User, issuedAt time.Time) error {
+	if usr.Disabled() {
+		return serr.NewServiceError(nil, http.StatusForbidden, "account disabled")
+	}
+
+	if !issuedAt.IsZero() && middleware.Revoked(issuedAt, usr.SessionsRevokedAt) {
+		return serr.NewServiceError(nil, http.StatusUnauthorized, "session revoked")
+	}
+
+	return nil
+}
+
+func (s *Auth) adminUser(ctx context.Context, usr store.User) (AdminUser, error) {
+	ids, err := s.store.ListUserIdentities(ctx, store.ListUserIdentitiesRequest{UserUID: usr.UID})
+	if err != nil {
+		return AdminUser{}, fmt.Errorf("list user identities: %w", err)
+	}
+
+	au := AdminUser{
+		UID:               usr.UID,
+		Role:              usr.Role,
+		CreatedAt:         usr.CreatedAt,
+		DisabledAt:        usr.DisabledAt,
+		SessionsRevokedAt: usr.SessionsRevokedAt,
+		Identities:        make([]AccountIdentity, 0, len(ids)),
+	}
+	for _, id := range ids {
+		au.Identities = append(au.Identities, newAccountIdentity(id))
+	}
+
+	return au, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_AuthorizeAdmin(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name   string
+		user   store.User
+		status int
+	}{
+		{name: "admin", user: store.User{Role: store.RoleAdmin}},
+		{name: "user", user: store.User{Role: store.RoleUser}, status: http.StatusForbidden},
+		{name: "disabled admin", user: store.User{Role: store.RoleAdmin, DisabledAt: now}, status: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newPATAuth(&mockStore{
+				getUserFunc: func(ctx context.Context, r store.GetUserRequest) (store.User, error) {
+					return tc.user, nil
+				},
+			}, &mockTokenIssuer{}, now)
+
+			err := srv.AuthorizeAdmin(context.Background(), "uid-1")
+			if tc.status == 0 {
+				require.NoError(t, err)
+				return
+			}
+			requireStatus(t, err, tc.status)
+		})
+	}
+}
+
+func TestAuth_ListUsers(t *testing.T) {
+	var listed store.ListUsersRequest
+	srv := newPATAuth(&mockStore{
+		listUsersFunc: func(ctx context.Context, r store.ListUsersRequest) ([]store.User, error) {
+			listed = r
+			return []store.User{{ID: 11, UID: "uid-1"}, {ID: 12, UID: "uid-2"}, {ID: 13, UID: "uid-3"}}, nil
+		},
+		listUserIdentitiesFunc: func(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error) {
+			return []store.Identity{{Provider: "google", Email: r.UserUID + "@example.com"}}, nil
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	resp, err := srv.ListUsers(context.Background(), ListUsersRequest{Email: " ann ", Cursor: "10", Limit: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, store.ListUsersRequest{Email: "ann", AfterID: 10, Limit: 3}, listed)
+	require.Len(t, resp.Users, 2)
+	assert.Equal(t, "uid-2", resp.Users[1].UID)
+	assert.Equal(t, "uid-2@example.com", resp.Users[1].Identities[0].Email)
+	assert.Equal(t, "12", resp.NextCursor)
+}
+
+func TestAuth_ListUsers_InvalidRequest(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{}, time.Now())
+
+	for _, r := range []ListUsersRequest{
+		{Limit: -1},
+		{Limit: maxUsersPageSize + 1},
+		{Cursor: "abc"},
+	} {
+		_, err := srv.ListUsers(context.Background(), r)
+		requireStatus(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestAuth_DisableUser(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var (
+		status  store.UpdateUserStatusRequest
+		revoked store.RevokeUserSessionsRequest
+	)
+	srv := newPATAuth(&mockStore{
+		updateUserStatusFunc: func(ctx context.Context, r store.UpdateUserStatusRequest) error {
+			status = r
+			return nil
+		},
+		revokeUserSessionsFunc: func(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+			revoked = r
+			return nil
+		},
+		listUserIdentitiesFunc: func(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error) {
+			return nil, nil
+		},
+	}, &mockTokenIssuer{}, now)
+
+	_, err := srv.DisableUser(context.Background(), "admin-uid", "uid-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, store.UpdateUserStatusRequest{UID: "uid-1", DisabledAt: now}, status)
+	assert.Equal(t, store.RevokeUserSessionsRequest{UID: "uid-1", RevokedAt: now}, revoked)
+}
+
+func TestAuth_DisableUser_Self(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{}, time.Now())
+
+	_, err := srv.DisableUser(context.Background(), "uid-1", "uid-1")
+	requireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestAuth_RevokeSessions_NotFound(t *testing.T) {
+	srv := newPATAuth(&mockStore{
+		revokeUserSessionsFunc: func(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+			return store.ErrNotFound
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	err := srv.RevokeSessions(context.Background(), "admin-uid", "uid-1")
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestAuth_RevokedSessions(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := newPATAuth(&mockStore{
+		listRevokedSessionsFunc: func(ctx context.Context, r store.ListRevokedSessionsRequest) ([]store.RevokedSession, error) {
+			assert.Equal(t, now.Add(-10*time.Minute), r.Since)
+			return []store.RevokedSession{{UserUID: "uid-1", RevokedAt: now}}, nil
+		},
+	}, &mockTokenIssuer{}, now)
+	srv.denylistWindow = 10 * time.Minute
+
+	sessions, err := srv.RevokedSessions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []RevokedSession{{UserUID: "uid-1", RevokedAt: now}}, sessions)
+}
+
+func TestAuth_Refresh_DisabledUser(t *testing.T) {
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(&mockStore{
+			getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+				return store.Identity{Provider: r.Provider, User: store.User{UID: r.UID, DisabledAt: time.Now()}}, nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{
+			validateFunc: func(tk string) (token.UserClaims, error) {
+				return token.UserClaims{ID: "uid-1", Type: token.TypeRefresh}, nil
+			},
+		}),
+	)
+
+	_, err := srv.Refresh(context.Background(), "refresh_token")
+	requireStatus(t, err, http.StatusForbidden)
+}
+
+func TestAuth_Refresh_RevokedSession(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	// the token carries its issue time in whole seconds, it may have been issued up to a second later
+	revokedAt := issuedAt.Add(300 * time.Millisecond)
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(&mockStore{
+			getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+				return store.Identity{Provider: r.Provider, User: store.User{UID: r.UID, SessionsRevokedAt: revokedAt}}, nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{
+			validateFunc: func(tk string) (token.UserClaims, error) {
+				return token.UserClaims{ID: "uid-1", Type: token.TypeRefresh, IssuedAt: issuedAt}, nil
+			},
+		}),
+	)
+
+	_, err := srv.Refresh(context.Background(), "refresh_token")
+	requireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestAuth_Introspect_DisabledUser(t *testing.T) {
+	srv := newIntrospectAuth(&mockStore{
+		isTokenRevokedFunc: func(ctx context.Context, r store.IsTokenRevokedRequest) (bool, error) {
+			return false, nil
+		},
+		getUserFunc: func(ctx context.Context, r store.GetUserRequest) (store.User, error) {
+			return store.User{UID: r.UID, DisabledAt: time.Now()}, nil
+		},
+	})
+
+	info, err := srv.Introspect(context.Background(), "access")
+	require.NoError(t, err)
+	assert.False(t, info.Active)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/google/uuid"
+)
+
+// Audit event types
+const (
+	EventLoginSucceeded  = "login_succeeded"
+	EventLoginFailed     = "login_failed"
+	EventStateMismatch   = "state_mismatch"
+	EventUserCreated     = "user_created"
+	EventTokenRefreshed  = "token_refreshed"
+	EventRefreshFailed   = "refresh_failed"
+	EventUserDisabled    = "user_disabled"
+	EventUserEnabled     = "user_enabled"
+	EventSessionsRevoked = "sessions_revoked"
+	EventDeviceApproved  = "device_approved"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// Client describes the client a request was made from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient returns a context carrying the client of the request, which is recorded in audit events
+func WithClient(ctx context.Context, c Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+func clientFromContext(ctx context.Context) Client {
+	c, _ := ctx.Value(clientKey{}).(Client)
+	return c
+}
+
+// AuditEvent records a security relevant event, such as a sign in attempt
+type AuditEvent struct {
+	ID        int64
+	Type      string
+	UserUID   string
+	Provider  string
+	IP        string
+	UserAgent string
+	Detail    string
+	CreatedAt time.Time
+}
+
+// audit records an event together with the client of the request. Failing to record an event
+// is logged but does not fail the operation being audited.
+func (s *Auth) audit(ctx context.Context, typ, userUID, provider, detail string) {
+	c := clientFromContext(ctx)
+	err := s.store.CreateAuditEvent(ctx, store.CreateAuditEventRequest{
+		Type:      typ,
+		UserUID:   userUID,
+		Provider:  provider,
+		IP:        c.IP,
+		UserAgent: c.UserAgent,
+		Detail:    detail,
+		CreatedAt: s.now(),
+	})
+	if err != nil {
+		slog.Error("failed to record audit event",
+			"error", err,
+			"type", typ,
+			"user", userUID,
+			"provider", provider)
+	}
+}
+
+type ListAuditEventsRequest struct {
+	UserUID string
+	Type    string
+	From    time.Time
+	To      time.Time
+	Cursor  string
+	Limit   int
+}
+
+type ListAuditEventsResponse struct {
+	Events []AuditEvent
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// ListAuditEvents lists audit events newest first, optionally filtered by user, type and time range
+func (s *Auth) ListAuditEvents(ctx context.Context, r ListAuditEventsRequest) (ListAuditEventsResponse, error) {
+	limit := r.Limit
+	if limit == 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit < 0 || limit > maxAuditPageSize {
+		sErr := serr.NewServiceError(nil, http.StatusBadRequest, "limit must be between 1 and %d", maxAuditPageSize)
+		sErr.Env["limit"] = strconv.Itoa(r.Limit)
+		return ListAuditEventsResponse{}, sErr
+	}
+
+	if r.UserUID != "" {
+		if _, err := uuid.Parse(r.UserUID); err != nil {
+			sErr := serr.NewServiceError(err, http.StatusBadRequest, "invalid user uid")
+			sErr.Env["user_uid"] = r.UserUID
+			return ListAuditEventsResponse{}, sErr
+		}
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return ListAuditEventsResponse{}, serr.NewServiceError(nil, http.StatusBadRequest, "from must be before to")
+	}
+
+	var before int64
+	if r.Cursor != "" {
+		var err error
+		before, err = strconv.ParseInt(r.Cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return ListAuditEventsResponse{}, serr.NewServiceError(err, http.StatusBadRequest, "invalid cursor")
+		}
+	}
+
+	events, err := s.store.ListAuditEvents(ctx, store.ListAuditEventsRequest{
+		UserUID:  r.UserUID,
+		Type:     r.Type,
+		From:     r.From,
+		To:       r.To,
+		BeforeID: before,
+		Limit:    limit + 1,
+	})
+	if err != nil {
+		return ListAuditEventsResponse{}, fmt.Errorf("list audit events: %w", err)
+	}
+
+	resp := ListAuditEventsResponse{Events: make([]AuditEvent, 0, min(len(events), limit))}
+	if len(events) > limit {
+		events = events[:limit]
+		resp.NextCursor = strconv.FormatInt(events[limit-1].ID, 10)
+	}
+
+	for _, e := range events {
+		resp.Events = append(resp.Events, AuditEvent{
+			ID:        e.ID,
+			Type:      e.Type,
+			UserUID:   e.UserUID,
+			Provider:  e.Provider,
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Detail:    e.Detail,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
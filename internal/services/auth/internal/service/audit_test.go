@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_AuthCallback_AuditsStateMismatch(t *testing.T) {
+	var events []store.CreateAuditEventRequest
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{
+			exchangeFunc: func(ctx context.Context, env oauth.Env, providerName, code, state string) (oauth.User, error) {
+				return oauth.User{}, oauth.ErrStateMismatch
+			},
+		}),
+		WithStore(&mockStore{
+			createAuditEventFunc: func(ctx context.Context, r store.CreateAuditEventRequest) error {
+				events = append(events, r)
+				return nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{}),
+	)
+
+	ctx := WithClient(context.Background(), Client{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	_, err := srv.AuthCallback(ctx, newMockEnv(), AuthCallbackRequest{Provider: "google", Code: "code", State: "forged"})
+	requireStatus(t, err, http.StatusUnauthorized)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventStateMismatch, events[0].Type)
+	assert.Equal(t, "google", events[0].Provider)
+	assert.Equal(t, "203.0.113.7", events[0].IP)
+	assert.Equal(t, "curl/8.0", events[0].UserAgent)
+}
+
+func TestAuth_AuthCallback_AuditsLogin(t *testing.T) {
+	var types []string
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{
+			exchangeFunc: func(ctx context.Context, env oauth.Env, providerName, code, state string) (oauth.User, error) {
+				return oauth.User{ID: "user123", Email: "test@example.com"}, nil
+			},
+		}),
+		WithStore(&mockStore{
+			getIdentityFunc: func(ctx context.Context, r store.GetIdentityRequest) (store.Identity, error) {
+				return store.Identity{ID: r.ID, Provider: r.Provider, User: store.User{ID: 1, UID: "uid-123"}}, nil
+			},
+			createAuditEventFunc: func(ctx context.Context, r store.CreateAuditEventRequest) error {
+				assert.Equal(t, "uid-123", r.UserUID)
+				types = append(types, r.Type)
+				return nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{
+			issueFunc: func(claims token.UserClaims) (string, error) { return "access_token", nil },
+		}),
+		WithRefreshToken(&mockTokenIssuer{
+			issueFunc: func(claims token.UserClaims) (string, error) { return "refresh_token", nil },
+		}),
+	)
+
+	_, err := srv.AuthCallback(context.Background(), newMockEnv(), AuthCallbackRequest{Provider: "google", Code: "code", State: "state"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{EventLoginSucceeded}, types)
+}
+
+func TestAuth_Refresh_AuditsFailure(t *testing.T) {
+	var events []store.CreateAuditEventRequest
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(&mockStore{
+			getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+				return store.Identity{Provider: r.Provider, User: store.User{UID: r.UID, DisabledAt: time.Now()}}, nil
+			},
+			createAuditEventFunc: func(ctx context.Context, r store.CreateAuditEventRequest) error {
+				events = append(events, r)
+				return nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{
+			validateFunc: func(tk string) (token.UserClaims, error) {
+				return token.UserClaims{ID: "uid-1", Provider: "google", Type: token.TypeRefresh}, nil
+			},
+		}),
+	)
+
+	_, err := srv.Refresh(context.Background(), "refresh_token")
+	requireStatus(t, err, http.StatusForbidden)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventRefreshFailed, events[0].Type)
+	assert.Equal(t, "uid-1", events[0].UserUID)
+	assert.Equal(t, "account disabled", events[0].Detail)
+}
+
+func TestAuth_ListAuditEvents(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	var listed store.ListAuditEventsRequest
+	srv := newPATAuth(&mockStore{
+		listAuditEventsFunc: func(ctx context.Context, r store.ListAuditEventsRequest) ([]store.AuditEvent, error) {
+			listed = r
+			return []store.AuditEvent{{ID: 9, Type: EventLoginFailed}, {ID: 8}, {ID: 7}}, nil
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	resp, err := srv.ListAuditEvents(context.Background(), ListAuditEventsRequest{
+		UserUID: "5f0c3b4e-0c1d-4a8e-9b55-0a2b3c4d5e6f",
+		From:    from,
+		To:      to,
+		Cursor:  "10",
+		Limit:   2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, store.ListAuditEventsRequest{
+		UserUID:  "5f0c3b4e-0c1d-4a8e-9b55-0a2b3c4d5e6f",
+		From:     from,
+		To:       to,
+		BeforeID: 10,
+		Limit:    3,
+	}, listed)
+	require.Len(t, resp.Events, 2)
+	assert.Equal(t, EventLoginFailed, resp.Events[0].Type)
+	assert.Equal(t, "8", resp.NextCursor)
+}
+
+func TestAuth_ListAuditEvents_InvalidRequest(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{}, time.Now())
+	now := time.Now()
+
+	for _, r := range []ListAuditEventsRequest{
+		{Limit: -1},
+		{Limit: maxAuditPageSize + 1},
+		{UserUID: "not-a-uuid"},
+		{From: now, To: now.Add(-time.Hour)},
+		{Cursor: "0"},
+	} {
+		_, err := srv.ListAuditEvents(context.Background(), r)
+		requireStatus(t, err, http.StatusBadRequest)
+	}
+}
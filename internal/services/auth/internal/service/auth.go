@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
 	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
@@ -22,6 +23,7 @@ type tokenIssuer interface {
 type authenticator interface {
 	LoginURL(env oauth.Env, providerName string) (string, error)
 	Exchange(ctx context.Context, env oauth.Env, providerName, code, state string) (oauth.User, error)
+	Providers() []string
 }
 
 // Auth handles OAuth authentication and token management
@@ -30,6 +32,11 @@ type Auth struct {
 	store        store.Store
 	accessToken  tokenIssuer
 	refreshToken tokenIssuer
+	device       DeviceConfig
+	linked       []linkedService
+	// denylistWindow is how long revoked sessions are reported to other services
+	denylistWindow time.Duration
+	now            func() time.Time
 }
 
 // AuthOption defines a functional option for configuring the Auth service
@@ -63,9 +70,20 @@ func WithRefreshToken(iss tokenIssuer) AuthOption {
 	}
 }
 
+func WithDeviceFlow(cfg DeviceConfig) AuthOption {
+	return func(s *Auth) *Auth {
+		s.device = cfg
+		return s
+	}
+}
+
 // NewAuth creates a new Auth service with the provided options
 func NewAuth(opts ...AuthOption) *Auth {
-	s := &Auth{}
+	s := &Auth{
+		device:         defaultDeviceConfig,
+		denylistWindow: defaultDenylistWindow,
+		now:            time.Now,
+	}
 	for _, opt := range opts {
 		s = opt(s)
 	}
@@ -89,8 +107,23 @@ func NewAuth(opts ...AuthOption) *Auth {
 	return s
 }
 
+// Providers returns the names of the configured identity providers
+func (s *Auth) Providers() []string {
+	return s.auth.Providers()
+}
+
 // LoginURL generates a login URL for the specified provider
 func (s *Auth) LoginURL(providerName string, env oauth.Env) (string, error) {
+	// a plain login must never complete a device verification left over from an earlier attempt
+	if err := env.Save(deviceEnvKey, ""); err != nil {
+		return "", fmt.Errorf("reset device user code: %w", err)
+	}
+
+	return s.loginURL(providerName, env)
+}
+
+// loginURL generates a login URL for the specified provider without touching the device flow state
+func (s *Auth) loginURL(providerName string, env oauth.Env) (string, error) {
 	url, err := s.auth.LoginURL(env, providerName)
 	if err != nil {
 		if errors.Is(err, oauth.ErrProviderNotFound) {
@@ -111,9 +144,12 @@ type AuthCallbackRequest struct {
 	State    string
 }
 
+// AuthCallbackResponse holds the tokens of the user, or when the user signed in to approve a device,
+// the confirmation of the approval the user is shown instead
 type AuthCallbackResponse struct {
 	AccessToken  string
 	RefreshToken string
+	Device       *DeviceConfirmation
 }
 
 // AuthCallback handles the OAuth callback, exchanges the code for user info, and issues tokens
@@ -128,12 +164,19 @@ func (s *Auth) AuthCallback(ctx context.Context, env oauth.Env, r AuthCallbackRe
 		}
 
 		if errors.Is(err, oauth.ErrAuthFailed) {
+			event := EventLoginFailed
+			if errors.Is(err, oauth.ErrStateMismatch) {
+				event = EventStateMismatch
+			}
+			s.audit(ctx, event, "", r.Provider, err.Error())
+
 			sErr := serr.NewServiceError(err, http.StatusUnauthorized, "authentication failed")
 			sErr.Env["provider"] = r.Provider
 			err = sErr
 			return
 		}
 
+		s.audit(ctx, EventLoginFailed, "", r.Provider, err.Error())
 		err = fmt.Errorf("exchange: %w", err)
 		return
 	}
@@ -144,27 +187,28 @@ func (s *Auth) AuthCallback(ctx context.Context, env oauth.Env, r AuthCallbackRe
 		return
 	}
 
-	at, atErr := s.accessToken.Issue(token.UserClaims{
-		ID:       id.User.UID,
-		Email:    id.Email,
-		Provider: id.Provider,
-		Name:     id.Name,
-		Picture:  id.Picture,
-	})
-	if atErr != nil {
-		err = fmt.Errorf("issue access token: %w", atErr)
+	if err = checkUser(id.User, time.Time{}); err != nil {
+		s.audit(ctx, EventLoginFailed, id.User.UID, r.Provider, err.Error())
 		return
 	}
 
-	rt, rtErr := s.refreshToken.Issue(token.UserClaims{
-		ID:   id.User.UID,
-		Type: token.TypeRefresh,
-	})
-	if rtErr != nil {
-		err = fmt.Errorf("issue refresh token: %w", rtErr)
+	if userCode, _ := env.Load(deviceEnvKey); userCode != "" {
+		var confirm DeviceConfirmation
+		if confirm, err = s.confirmDevice(ctx, env, userCode, id); err != nil {
+			return
+		}
+
+		s.audit(ctx, EventLoginSucceeded, id.User.UID, r.Provider, "device approval requested")
+		resp = AuthCallbackResponse{Device: &confirm}
+		return
+	}
+
+	at, rt, err := s.issueTokens(ctx, id)
+	if err != nil {
 		return
 	}
 
+	s.audit(ctx, EventLoginSucceeded, id.User.UID, r.Provider, "")
 	resp = AuthCallbackResponse{
 		AccessToken:  at,
 		RefreshToken: rt,
@@ -176,31 +220,71 @@ func (s *Auth) AuthCallback(ctx context.Context, env oauth.Env, r AuthCallbackRe
 func (a *Auth) Refresh(ctx context.Context, refreshToken string) (string, error) {
 	claims, err := a.refreshToken.Validate(refreshToken)
 	if err != nil {
+		a.audit(ctx, EventRefreshFailed, "", "", "invalid refresh token")
 		return "", serr.NewServiceError(err, http.StatusUnauthorized, "invalid refresh token")
 	}
 
+	revoked, err := a.isRevoked(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+	if revoked {
+		a.audit(ctx, EventRefreshFailed, claims.ID, claims.Provider, "refresh token revoked")
+		return "", serr.NewServiceError(nil, http.StatusUnauthorized, "refresh token revoked")
+	}
+
 	id, err := a.store.GetUserIdentity(ctx, store.GetUserIdentityRequest{
 		UID:      claims.ID,
 		Provider: claims.Provider,
 	})
 	if err != nil {
+		a.audit(ctx, EventRefreshFailed, "", claims.Provider, "invalid user identity")
 		return "", serr.NewServiceError(err, http.StatusUnauthorized, "invalid user identity")
 	}
 
-	at, atErr := a.accessToken.Issue(token.UserClaims{
-		ID:       id.User.UID,
-		Email:    id.Email,
-		Provider: id.Provider,
-		Name:     id.Name,
-		Picture:  id.Picture,
-	})
+	if err := checkUser(id.User, claims.IssuedAt); err != nil {
+		a.audit(ctx, EventRefreshFailed, id.User.UID, claims.Provider, err.Error())
+		return "", err
+	}
+
+	claims, err = a.accessClaims(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	at, atErr := a.accessToken.Issue(claims)
 	if atErr != nil {
 		return "", fmt.Errorf("issue access token: %w", atErr)
 	}
 
+	a.audit(ctx, EventTokenRefreshed, id.User.UID, id.Provider, "")
 	return at, nil
 }
 
+// issueTokens issues a new access and refresh token pair for the given identity
+func (s *Auth) issueTokens(ctx context.Context, id store.Identity) (string, string, error) {
+	claims, err := s.accessClaims(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	at, err := s.accessToken.Issue(claims)
+	if err != nil {
+		return "", "", fmt.Errorf("issue access token: %w", err)
+	}
+
+	rt, err := s.refreshToken.Issue(token.UserClaims{
+		ID:       id.User.UID,
+		Type:     token.TypeRefresh,
+		Provider: id.Provider,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("issue refresh token: %w", err)
+	}
+
+	return at, rt, nil
+}
+
 // getOrCreateUser retrieves an existing user identity or creates a new user and identity
 func (s *Auth) getOrCreateUser(ctx context.Context, provider string, usr oauth.User) (store.Identity, error) {
 	id, err := s.store.GetIdentity(ctx, store.GetIdentityRequest{
@@ -245,6 +329,7 @@ func (s *Auth) getOrCreateUser(ctx context.Context, provider string, usr oauth.U
 			return store.Identity{}, fmt.Errorf("with tx: %w", err)
 		}
 
+		s.audit(ctx, EventUserCreated, id.User.UID, provider, "")
 	}
 
 	return id, nil
@@ -17,6 +17,7 @@ import (
 type mockAuthenticator struct {
 	loginFunc    func(env oauth.Env, providerName string) (string, error)
 	exchangeFunc func(ctx context.Context, env oauth.Env, providerName, code, state string) (oauth.User, error)
+	providers    []string
 }
 
 func (m *mockAuthenticator) LoginURL(env oauth.Env, providerName string) (string, error) {
@@ -27,11 +28,47 @@ func (m *mockAuthenticator) Exchange(ctx context.Context, env oauth.Env, provide
 	return m.exchangeFunc(ctx, env, providerName, code, state)
 }
 
+func (m *mockAuthenticator) Providers() []string {
+	return m.providers
+}
+
 type mockStore struct {
 	getIdentityFunc        func(ctx context.Context, r store.GetIdentityRequest) (store.Identity, error)
 	getUserIdentityFunc    func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error)
 	createUserFunc         func(ctx context.Context) (int64, error)
 	createUserIdentityFunc func(ctx context.Context, r store.CreateUserIdentityRequest) (string, error)
+
+	createDeviceAuthFunc        func(ctx context.Context, r store.CreateDeviceAuthRequest) (int64, error)
+	getDeviceAuthFunc           func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error)
+	getDeviceAuthByUserCodeFunc func(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error)
+	setDeviceAuthPendingFunc    func(ctx context.Context, r store.SetDeviceAuthPendingRequest) error
+	approveDeviceAuthFunc       func(ctx context.Context, r store.ApproveDeviceAuthRequest) error
+	updateDeviceAuthPollFunc    func(ctx context.Context, r store.UpdateDeviceAuthPollRequest) error
+	deleteDeviceAuthFunc        func(ctx context.Context, r store.DeleteDeviceAuthRequest) error
+
+	createAccessTokenFunc      func(ctx context.Context, r store.CreateAccessTokenRequest) (int64, error)
+	getAccessTokenFunc         func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error)
+	listAccessTokensFunc       func(ctx context.Context, r store.ListAccessTokensRequest) ([]store.AccessToken, error)
+	revokeAccessTokenFunc      func(ctx context.Context, r store.RevokeAccessTokenRequest) error
+	updateAccessTokenUsageFunc func(ctx context.Context, r store.UpdateAccessTokenUsageRequest) error
+
+	revokeTokenFunc    func(ctx context.Context, r store.RevokeTokenRequest) error
+	isTokenRevokedFunc func(ctx context.Context, r store.IsTokenRevokedRequest) (bool, error)
+
+	getProfileFunc    func(ctx context.Context, r store.GetProfileRequest) (store.Profile, error)
+	updateProfileFunc func(ctx context.Context, r store.UpdateProfileRequest) error
+
+	getUserFunc            func(ctx context.Context, r store.GetUserRequest) (store.User, error)
+	listUserIdentitiesFunc func(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error)
+	deleteUserFunc         func(ctx context.Context, r store.DeleteUserRequest) error
+
+	listUsersFunc           func(ctx context.Context, r store.ListUsersRequest) ([]store.User, error)
+	updateUserStatusFunc    func(ctx context.Context, r store.UpdateUserStatusRequest) error
+	revokeUserSessionsFunc  func(ctx context.Context, r store.RevokeUserSessionsRequest) error
+	listRevokedSessionsFunc func(ctx context.Context, r store.ListRevokedSessionsRequest) ([]store.RevokedSession, error)
+
+	createAuditEventFunc func(ctx context.Context, r store.CreateAuditEventRequest) error
+	listAuditEventsFunc  func(ctx context.Context, r store.ListAuditEventsRequest) ([]store.AuditEvent, error)
 }
 
 func (m *mockStore) GetIdentity(ctx context.Context, r store.GetIdentityRequest) (store.Identity, error) {
@@ -50,6 +87,118 @@ func (m *mockStore) CreateUserIdentity(ctx context.Context, r store.CreateUserId
 	return m.createUserIdentityFunc(ctx, r)
 }
 
+func (m *mockStore) CreateDeviceAuth(ctx context.Context, r store.CreateDeviceAuthRequest) (int64, error) {
+	return m.createDeviceAuthFunc(ctx, r)
+}
+
+func (m *mockStore) GetDeviceAuth(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+	return m.getDeviceAuthFunc(ctx, r)
+}
+
+func (m *mockStore) GetDeviceAuthByUserCode(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error) {
+	return m.getDeviceAuthByUserCodeFunc(ctx, r)
+}
+
+func (m *mockStore) SetDeviceAuthPending(ctx context.Context, r store.SetDeviceAuthPendingRequest) error {
+	return m.setDeviceAuthPendingFunc(ctx, r)
+}
+
+func (m *mockStore) ApproveDeviceAuth(ctx context.Context, r store.ApproveDeviceAuthRequest) error {
+	return m.approveDeviceAuthFunc(ctx, r)
+}
+
+func (m *mockStore) UpdateDeviceAuthPoll(ctx context.Context, r store.UpdateDeviceAuthPollRequest) error {
+	return m.updateDeviceAuthPollFunc(ctx, r)
+}
+
+func (m *mockStore) DeleteDeviceAuth(ctx context.Context, r store.DeleteDeviceAuthRequest) error {
+	return m.deleteDeviceAuthFunc(ctx, r)
+}
+
+func (m *mockStore) CreateAccessToken(ctx context.Context, r store.CreateAccessTokenRequest) (int64, error) {
+	return m.createAccessTokenFunc(ctx, r)
+}
+
+func (m *mockStore) GetAccessToken(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+	return m.getAccessTokenFunc(ctx, r)
+}
+
+func (m *mockStore) ListAccessTokens(ctx context.Context, r store.ListAccessTokensRequest) ([]store.AccessToken, error) {
+	return m.listAccessTokensFunc(ctx, r)
+}
+
+func (m *mockStore) RevokeAccessToken(ctx context.Context, r store.RevokeAccessTokenRequest) error {
+	return m.revokeAccessTokenFunc(ctx, r)
+}
+
+func (m *mockStore) UpdateAccessTokenUsage(ctx context.Context, r store.UpdateAccessTokenUsageRequest) error {
+	return m.updateAccessTokenUsageFunc(ctx, r)
+}
+
+func (m *mockStore) RevokeToken(ctx context.Context, r store.RevokeTokenRequest) error {
+	return m.revokeTokenFunc(ctx, r)
+}
+
+func (m *mockStore) IsTokenRevoked(ctx context.Context, r store.IsTokenRevokedRequest) (bool, error) {
+	return m.isTokenRevokedFunc(ctx, r)
+}
+
+// GetProfile returns an empty profile unless the test overrides it, since most flows only embed it into tokens
+func (m *mockStore) GetProfile(ctx context.Context, r store.GetProfileRequest) (store.Profile, error) {
+	if m.getProfileFunc == nil {
+		return store.Profile{UserUID: r.UserUID}, nil
+	}
+	return m.getProfileFunc(ctx, r)
+}
+
+func (m *mockStore) UpdateProfile(ctx context.Context, r store.UpdateProfileRequest) error {
+	return m.updateProfileFunc(ctx, r)
+}
+
+// GetUser returns an active user unless the test overrides it, since most flows only check the account status
+func (m *mockStore) GetUser(ctx context.Context, r store.GetUserRequest) (store.User, error) {
+	if m.getUserFunc == nil {
+		return store.User{UID: r.UID, Role: store.RoleUser}, nil
+	}
+	return m.getUserFunc(ctx, r)
+}
+
+func (m *mockStore) ListUserIdentities(ctx context.Context, r store.ListUserIdentitiesRequest) ([]store.Identity, error) {
+	return m.listUserIdentitiesFunc(ctx, r)
+}
+
+func (m *mockStore) DeleteUser(ctx context.Context, r store.DeleteUserRequest) error {
+	return m.deleteUserFunc(ctx, r)
+}
+
+func (m *mockStore) ListUsers(ctx context.Context, r store.ListUsersRequest) ([]store.User, error) {
+	return m.listUsersFunc(ctx, r)
+}
+
+func (m *mockStore) UpdateUserStatus(ctx context.Context, r store.UpdateUserStatusRequest) error {
+	return m.updateUserStatusFunc(ctx, r)
+}
+
+func (m *mockStore) RevokeUserSessions(ctx context.Context, r store.RevokeUserSessionsRequest) error {
+	return m.revokeUserSessionsFunc(ctx, r)
+}
+
+func (m *mockStore) ListRevokedSessions(ctx context.Context, r store.ListRevokedSessionsRequest) ([]store.RevokedSession, error) {
+	return m.listRevokedSessionsFunc(ctx, r)
+}
+
+// CreateAuditEvent discards the event unless the test overrides it, since most flows record events on the side
+func (m *mockStore) CreateAuditEvent(ctx context.Context, r store.CreateAuditEventRequest) error {
+	if m.createAuditEventFunc == nil {
+		return nil
+	}
+	return m.createAuditEventFunc(ctx, r)
+}
+
+func (m *mockStore) ListAuditEvents(ctx context.Context, r store.ListAuditEventsRequest) ([]store.AuditEvent, error) {
+	return m.listAuditEventsFunc(ctx, r)
+}
+
 func (m *mockStore) WithTx(ctx context.Context, fn func(store.Store) error) error {
 	return fn(m)
 }
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+)
+
+const (
+	// deviceEnvKey is the env key holding the user code while the user signs in with a provider
+	deviceEnvKey = "device_user_code"
+	// deviceConfirmEnvKey is the env key holding the token the user confirms the approval of a device with
+	deviceConfirmEnvKey = "device_confirm_token"
+
+	// userCodeAlphabet omits vowels and look-alike characters to keep user codes easy to type
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+
+	deviceCodeSize     = 32
+	deviceCodeAttempts = 3
+
+	// slowDownStep is the amount the polling interval grows by on every slow_down response
+	slowDownStep = 5 * time.Second
+)
+
+// Device flow token errors as defined in RFC 8628, section 3.5
+var (
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	ErrSlowDown             = errors.New("slow_down")
+	ErrExpiredToken         = errors.New("expired_token")
+	ErrInvalidGrant         = errors.New("invalid_grant")
+)
+
+// DeviceConfig holds the settings of the OAuth device authorization grant
+type DeviceConfig struct {
+	VerificationURL string
+	CodeTTL         time.Duration
+	PollInterval    time.Duration
+}
+
+var defaultDeviceConfig = DeviceConfig{
+	VerificationURL: "http://localhost:8080/api/v1/device",
+	CodeTTL:         10 * time.Minute,
+	PollInterval:    5 * time.Second,
+}
+
+type DeviceCodeRequest struct {
+	ClientID string
+	Scope    string
+}
+
+type DeviceCodeResponse struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresIn               time.Duration
+	Interval                time.Duration
+}
+
+// DeviceCode starts a device authorization request and returns the codes the device shows to the user
+func (s *Auth) DeviceCode(ctx context.Context, r DeviceCodeRequest) (DeviceCodeResponse, error) {
+	if r.ClientID == "" {
+		return DeviceCodeResponse{}, serr.NewServiceError(nil, http.StatusBadRequest, "invalid_request")
+	}
+
+	deviceCode := randDeviceCode()
+	for range deviceCodeAttempts {
+		userCode := randUserCode()
+		_, err := s.store.CreateDeviceAuth(ctx, store.CreateDeviceAuthRequest{
+			DeviceCodeHash: hashToken(deviceCode),
+			UserCode:       userCode,
+			ClientID:       r.ClientID,
+			Scope:          r.Scope,
+			Interval:       s.device.PollInterval,
+			ExpiresAt:      s.now().Add(s.device.CodeTTL),
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrExists) {
+				continue
+			}
+
+			return DeviceCodeResponse{}, fmt.Errorf("create device auth: %w", err)
+		}
+
+		display := formatUserCode(userCode)
+		complete, err := url.Parse(s.device.VerificationURL)
+		if err != nil {
+			return DeviceCodeResponse{}, fmt.Errorf("parse verification url: %w", err)
+		}
+		q := complete.Query()
+		q.Set("user_code", display)
+		complete.RawQuery = q.Encode()
+
+		return DeviceCodeResponse{
+			DeviceCode:              deviceCode,
+			UserCode:                display,
+			VerificationURI:         s.device.VerificationURL,
+			VerificationURIComplete: complete.String(),
+			ExpiresIn:               s.device.CodeTTL,
+			Interval:                s.device.PollInterval,
+		}, nil
+	}
+
+	return DeviceCodeResponse{}, errors.New("create device auth: user code collisions")
+}
+
+// DeviceLoginURL validates the user code entered on the verification page and returns
+// the provider login URL which completes the device verification on callback
+func (s *Auth) DeviceLoginURL(ctx context.Context, providerName, userCode string, env oauth.Env) (string, error) {
+	code := normalizeUserCode(userCode)
+	da, err := s.store.GetDeviceAuthByUserCode(ctx, store.GetDeviceAuthByUserCodeRequest{UserCode: code})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return "", invalidUserCodeError(err, userCode)
+		}
+
+		return "", fmt.Errorf("get device auth: %w", err)
+	}
+
+	if da.Approved() || !s.now().Before(da.ExpiresAt) {
+		return "", invalidUserCodeError(ErrExpiredToken, userCode)
+	}
+
+	if err := env.Save(deviceEnvKey, code); err != nil {
+		return "", fmt.Errorf("save device user code: %w", err)
+	}
+
+	return s.loginURL(providerName, env)
+}
+
+type DeviceTokenResponse struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// PollDeviceToken exchanges a device code for tokens once the user has approved the request.
+// Until then it returns ServiceErrors carrying the RFC 8628 error codes.
+func (s *Auth) PollDeviceToken(ctx context.Context, deviceCode string) (resp DeviceTokenResponse, err error) {
+	var outcome error
+	err = s.store.WithTx(ctx, func(tx store.Store) error {
+		// the lock serializes concurrent polls, so an approved code is exchanged for tokens only once
+		da, err := tx.GetDeviceAuth(ctx, store.GetDeviceAuthRequest{
+			DeviceCodeHash: hashToken(deviceCode),
+			ForUpdate:      true,
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				outcome = deviceTokenError(ErrInvalidGrant)
+				return nil
+			}
+
+			return fmt.Errorf("get device auth: %w", err)
+		}
+
+		now := s.now()
+		if !now.Before(da.ExpiresAt) {
+			outcome = deviceTokenError(ErrExpiredToken)
+			return nil
+		}
+
+		if da.Approved() {
+			id, err := tx.GetUserIdentity(ctx, store.GetUserIdentityRequest{
+				UID:      da.UserUID,
+				Provider: da.Provider,
+			})
+			if err != nil {
+				return fmt.Errorf("get user identity: %w", err)
+			}
+
+			if err := checkUser(id.User, time.Time{}); err != nil {
+				return err
+			}
+
+			if err := tx.DeleteDeviceAuth(ctx, store.DeleteDeviceAuthRequest{ID: da.ID}); err != nil {
+				if errors.Is(err, store.ErrNotFound) {
+					outcome = deviceTokenError(ErrInvalidGrant)
+					return nil
+				}
+
+				return fmt.Errorf("delete device auth: %w", err)
+			}
+
+			resp.AccessToken, resp.RefreshToken, err = s.issueTokens(ctx, id)
+			return err
+		}
+
+		interval := da.Interval
+		outcome = deviceTokenError(ErrAuthorizationPending)
+		if !da.LastPolledAt.IsZero() && now.Sub(da.LastPolledAt) < da.Interval {
+			interval += slowDownStep
+			outcome = deviceTokenError(ErrSlowDown)
+		}
+
+		err = tx.UpdateDeviceAuthPoll(ctx, store.UpdateDeviceAuthPollRequest{
+			ID:       da.ID,
+			PolledAt: now,
+			Interval: interval,
+		})
+		if err != nil {
+			return fmt.Errorf("update device auth poll: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("poll device token: %w", err)
+		return
+	}
+
+	if outcome != nil {
+		resp = DeviceTokenResponse{}
+		err = outcome
+	}
+	return
+}
+
+// DeviceConfirmation is what the user is shown to confirm the approval of a device after signing in.
+// The approval has to be posted back with ConfirmToken.
+type DeviceConfirmation struct {
+	UserCode     string
+	ClientID     string
+	Scope        string
+	ConfirmToken string
+}
+
+// confirmDevice records the signed in user on the device authorization request and returns what the user
+// confirms the approval with. The device is not approved before the user confirms it, so that a link with
+// the user code of someone else's device does not connect that device to the account.
+func (s *Auth) confirmDevice(ctx context.Context, env oauth.Env, userCode string, id store.Identity) (DeviceConfirmation, error) {
+	if err := env.Save(deviceEnvKey, ""); err != nil {
+		return DeviceConfirmation{}, fmt.Errorf("reset device user code: %w", err)
+	}
+
+	da, err := s.store.GetDeviceAuthByUserCode(ctx, store.GetDeviceAuthByUserCodeRequest{UserCode: userCode})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return DeviceConfirmation{}, invalidUserCodeError(err, userCode)
+		}
+
+		return DeviceConfirmation{}, fmt.Errorf("get device auth: %w", err)
+	}
+
+	confirmToken := randDeviceCode()
+	err = s.store.SetDeviceAuthPending(ctx, store.SetDeviceAuthPendingRequest{
+		UserCode:    userCode,
+		UserID:      id.User.ID,
+		Provider:    id.Provider,
+		ConfirmHash: hashToken(confirmToken),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return DeviceConfirmation{}, invalidUserCodeError(err, userCode)
+		}
+
+		return DeviceConfirmation{}, fmt.Errorf("set device auth pending: %w", err)
+	}
+
+	if err := env.Save(deviceConfirmEnvKey, confirmToken); err != nil {
+		return DeviceConfirmation{}, fmt.Errorf("save device confirm token: %w", err)
+	}
+
+	return DeviceConfirmation{
+		UserCode:     formatUserCode(userCode),
+		ClientID:     da.ClientID,
+		Scope:        da.Scope,
+		ConfirmToken: confirmToken,
+	}, nil
+}
+
+type ApproveDeviceRequest struct {
+	UserCode     string
+	ConfirmToken string
+}
+
+// ApproveDevice approves the device authorization request the user confirmed after signing in.
+// The token must be both the one posted and the one saved in the env of the sign in, otherwise
+// it returns a ServiceError with status code 403. If the request is gone, expired or approved already,
+// it returns a ServiceError with status code 404.
+func (s *Auth) ApproveDevice(ctx context.Context, env oauth.Env, r ApproveDeviceRequest) error {
+	saved, _ := env.Load(deviceConfirmEnvKey)
+	if r.ConfirmToken == "" || subtle.ConstantTimeCompare([]byte(saved), []byte(r.ConfirmToken)) != 1 {
+		return serr.NewServiceError(nil, http.StatusForbidden, "invalid device confirmation")
+	}
+
+	if err := env.Save(deviceConfirmEnvKey, ""); err != nil {
+		return fmt.Errorf("reset device confirm token: %w", err)
+	}
+
+	userCode := normalizeUserCode(r.UserCode)
+	err := s.store.ApproveDeviceAuth(ctx, store.ApproveDeviceAuthRequest{
+		UserCode:    userCode,
+		ConfirmHash: hashToken(r.ConfirmToken),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return invalidUserCodeError(err, r.UserCode)
+		}
+
+		return fmt.Errorf("approve device auth: %w", err)
+	}
+
+	da, err := s.store.GetDeviceAuthByUserCode(ctx, store.GetDeviceAuthByUserCodeRequest{UserCode: userCode})
+	if err == nil {
+		s.audit(ctx, EventDeviceApproved, da.UserUID, da.Provider, da.ClientID)
+	}
+
+	return nil
+}
+
+// deviceTokenError wraps a device flow error so that its RFC 8628 code becomes the error message
+func deviceTokenError(err error) error {
+	return serr.NewServiceError(err, http.StatusBadRequest, "%s", err.Error())
+}
+
+func invalidUserCodeError(err error, userCode string) error {
+	se := serr.NewServiceError(err, http.StatusNotFound, "invalid or expired user code")
+	se.Env["user_code"] = userCode
+	return se
+}
+
+// randDeviceCode generates a high-entropy device code
+func randDeviceCode() string {
+	b := make([]byte, deviceCodeSize)
+	// rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// randUserCode generates a short user code from userCodeAlphabet
+func randUserCode() string {
+	// bytes above the largest multiple of the alphabet size are rejected to avoid modulo bias
+	limit := byte(256 - 256%len(userCodeAlphabet))
+	code := make([]byte, 0, userCodeLength)
+	b := make([]byte, 1)
+	for len(code) < userCodeLength {
+		_, _ = rand.Read(b)
+		if b[0] >= limit {
+			continue
+		}
+		code = append(code, userCodeAlphabet[int(b[0])%len(userCodeAlphabet)])
+	}
+	return string(code)
+}
+
+// hashToken hashes device codes and access tokens so that only digests are persisted
+func hashToken(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatUserCode splits the user code into two dash separated halves, e.g. BCDF-GHJK
+func formatUserCode(code string) string {
+	half := len(code) / 2
+	return code[:half] + "-" + code[half:]
+}
+
+// normalizeUserCode strips separators and case from user input
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/oauth"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMapEnv(vals map[string]string) *mockEnv {
+	return &mockEnv{
+		saveFunc: func(key, val string) error {
+			vals[key] = val
+			return nil
+		},
+		loadFunc: func(key string) (string, error) {
+			return vals[key], nil
+		},
+	}
+}
+
+func newDeviceAuth(st *mockStore, now time.Time) *Auth {
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{
+			loginFunc: func(env oauth.Env, providerName string) (string, error) {
+				return "http://example.com/login", nil
+			},
+		}),
+		WithStore(st),
+		WithAccessToken(&mockTokenIssuer{
+			issueFunc: func(claims token.UserClaims) (string, error) {
+				return "access_token", nil
+			},
+		}),
+		WithRefreshToken(&mockTokenIssuer{
+			issueFunc: func(claims token.UserClaims) (string, error) {
+				return "refresh_token", nil
+			},
+		}),
+		WithDeviceFlow(DeviceConfig{
+			VerificationURL: "https://example.com/device",
+			CodeTTL:         10 * time.Minute,
+			PollInterval:    5 * time.Second,
+		}),
+	)
+	srv.now = func() time.Time { return now }
+	return srv
+}
+
+func requireDeviceError(t *testing.T, err error, expected error) {
+	t.Helper()
+
+	var sErr *serr.ServiceError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, http.StatusBadRequest, sErr.StatusCode)
+	assert.Equal(t, expected.Error(), sErr.Msg)
+	assert.ErrorIs(t, err, expected)
+}
+
+func TestAuth_DeviceCode(t *testing.T) {
+	now := time.Now()
+	var created []store.CreateDeviceAuthRequest
+	srv := newDeviceAuth(&mockStore{
+		createDeviceAuthFunc: func(ctx context.Context, r store.CreateDeviceAuthRequest) (int64, error) {
+			created = append(created, r)
+			return 1, nil
+		},
+	}, now)
+
+	resp, err := srv.DeviceCode(context.Background(), DeviceCodeRequest{
+		ClientID: "tv-app",
+		Scope:    "words",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, created, 1)
+	assert.Equal(t, "tv-app", created[0].ClientID)
+	assert.Equal(t, "words", created[0].Scope)
+	assert.Equal(t, 5*time.Second, created[0].Interval)
+	assert.Equal(t, now.Add(10*time.Minute), created[0].ExpiresAt)
+	assert.Equal(t, hashToken(resp.DeviceCode), created[0].DeviceCodeHash)
+	assert.NotEqual(t, resp.DeviceCode, created[0].DeviceCodeHash)
+
+	assert.Regexp(t, regexp.MustCompile(`^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$`), resp.UserCode)
+	assert.Equal(t, normalizeUserCode(resp.UserCode), created[0].UserCode)
+	assert.Equal(t, "https://example.com/device", resp.VerificationURI)
+	assert.Equal(t, "https://example.com/device?user_code="+url.QueryEscape(resp.UserCode), resp.VerificationURIComplete)
+	assert.Equal(t, 10*time.Minute, resp.ExpiresIn)
+	assert.Equal(t, 5*time.Second, resp.Interval)
+}
+
+func TestAuth_DeviceCode_UserCodeCollision(t *testing.T) {
+	attempts := 0
+	srv := newDeviceAuth(&mockStore{
+		createDeviceAuthFunc: func(ctx context.Context, r store.CreateDeviceAuthRequest) (int64, error) {
+			attempts++
+			if attempts == 1 {
+				return 0, store.ErrExists
+			}
+			return 1, nil
+		},
+	}, time.Now())
+
+	_, err := srv.DeviceCode(context.Background(), DeviceCodeRequest{ClientID: "tv-app"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestAuth_DeviceCode_MissingClientID(t *testing.T) {
+	srv := newDeviceAuth(&mockStore{}, time.Now())
+
+	_, err := srv.DeviceCode(context.Background(), DeviceCodeRequest{})
+	require.Error(t, err)
+
+	var sErr *serr.ServiceError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, http.StatusBadRequest, sErr.StatusCode)
+}
+
+func TestAuth_DeviceLoginURL(t *testing.T) {
+	now := time.Now()
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthByUserCodeFunc: func(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error) {
+			if r.UserCode != "BCDFGHJK" {
+				return store.DeviceAuth{}, store.ErrNotFound
+			}
+			return store.DeviceAuth{ID: 1, UserCode: r.UserCode, ExpiresAt: now.Add(time.Minute)}, nil
+		},
+	}, now)
+
+	vals := map[string]string{}
+	url, err := srv.DeviceLoginURL(context.Background(), "google", "bcdf-ghjk", newMapEnv(vals))
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://example.com/login", url)
+	assert.Equal(t, "BCDFGHJK", vals[deviceEnvKey])
+}
+
+func TestAuth_DeviceLoginURL_Expired(t *testing.T) {
+	now := time.Now()
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthByUserCodeFunc: func(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{ID: 1, UserCode: r.UserCode, ExpiresAt: now.Add(-time.Second)}, nil
+		},
+	}, now)
+
+	_, err := srv.DeviceLoginURL(context.Background(), "google", "BCDF-GHJK", newMockEnv())
+	require.Error(t, err)
+
+	var sErr *serr.ServiceError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, http.StatusNotFound, sErr.StatusCode)
+}
+
+func TestAuth_LoginURL_ResetsDeviceUserCode(t *testing.T) {
+	srv := newDeviceAuth(&mockStore{}, time.Now())
+
+	vals := map[string]string{deviceEnvKey: "BCDFGHJK"}
+	_, err := srv.LoginURL("google", newMapEnv(vals))
+	require.NoError(t, err)
+
+	assert.Empty(t, vals[deviceEnvKey])
+}
+
+func TestAuth_AuthCallback_ConfirmsDevice(t *testing.T) {
+	var pending []store.SetDeviceAuthPendingRequest
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{
+			exchangeFunc: func(ctx context.Context, env oauth.Env, providerName, code, state string) (oauth.User, error) {
+				return oauth.User{ID: "user123"}, nil
+			},
+		}),
+		WithStore(&mockStore{
+			getIdentityFunc: func(ctx context.Context, r store.GetIdentityRequest) (store.Identity, error) {
+				return store.Identity{
+					ID:       r.ID,
+					Provider: r.Provider,
+					User:     store.User{ID: 7, UID: "uid-7"},
+				}, nil
+			},
+			getDeviceAuthByUserCodeFunc: func(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error) {
+				return store.DeviceAuth{ID: 1, UserCode: r.UserCode, ClientID: "tv-app", Scope: "words"}, nil
+			},
+			setDeviceAuthPendingFunc: func(ctx context.Context, r store.SetDeviceAuthPendingRequest) error {
+				pending = append(pending, r)
+				return nil
+			},
+			approveDeviceAuthFunc: func(ctx context.Context, r store.ApproveDeviceAuthRequest) error {
+				t.Fatal("the device must not be approved before the user confirms it")
+				return nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{}),
+		WithRefreshToken(&mockTokenIssuer{}),
+	)
+
+	vals := map[string]string{deviceEnvKey: "BCDFGHJK"}
+	resp, err := srv.AuthCallback(context.Background(), newMapEnv(vals), AuthCallbackRequest{
+		Provider: "google",
+		Code:     "code",
+		State:    "state",
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.AccessToken)
+	assert.Empty(t, resp.RefreshToken)
+	require.NotNil(t, resp.Device)
+	assert.Equal(t, "BCDF-GHJK", resp.Device.UserCode)
+	assert.Equal(t, "tv-app", resp.Device.ClientID)
+	assert.Equal(t, "words", resp.Device.Scope)
+	assert.NotEmpty(t, resp.Device.ConfirmToken)
+	assert.Empty(t, vals[deviceEnvKey])
+	assert.Equal(t, resp.Device.ConfirmToken, vals[deviceConfirmEnvKey])
+	require.Equal(t, []store.SetDeviceAuthPendingRequest{{
+		UserCode:    "BCDFGHJK",
+		UserID:      7,
+		Provider:    "google",
+		ConfirmHash: hashToken(resp.Device.ConfirmToken),
+	}}, pending)
+}
+
+func TestAuth_ApproveDevice(t *testing.T) {
+	var approved []store.ApproveDeviceAuthRequest
+	srv := newDeviceAuth(&mockStore{
+		approveDeviceAuthFunc: func(ctx context.Context, r store.ApproveDeviceAuthRequest) error {
+			approved = append(approved, r)
+			return nil
+		},
+		getDeviceAuthByUserCodeFunc: func(ctx context.Context, r store.GetDeviceAuthByUserCodeRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{ID: 1, UserCode: r.UserCode, UserUID: "uid-7", Provider: "google"}, nil
+		},
+	}, time.Now())
+
+	vals := map[string]string{deviceConfirmEnvKey: "confirm_token"}
+	err := srv.ApproveDevice(context.Background(), newMapEnv(vals), ApproveDeviceRequest{
+		UserCode:     "bcdf-ghjk",
+		ConfirmToken: "confirm_token",
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, vals[deviceConfirmEnvKey])
+	assert.Equal(t, []store.ApproveDeviceAuthRequest{{UserCode: "BCDFGHJK", ConfirmHash: hashToken("confirm_token")}}, approved)
+}
+
+func TestAuth_ApproveDevice_Forged(t *testing.T) {
+	srv := newDeviceAuth(&mockStore{
+		approveDeviceAuthFunc: func(ctx context.Context, r store.ApproveDeviceAuthRequest) error {
+			t.Fatal("a forged confirmation must not approve the device")
+			return nil
+		},
+	}, time.Now())
+
+	for name, tc := range map[string]struct {
+		saved  string
+		posted string
+	}{
+		"no sign in":      {saved: "", posted: "confirm_token"},
+		"other sign in":   {saved: "confirm_token", posted: "forged_token"},
+		"no confirmation": {saved: "", posted: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vals := map[string]string{deviceConfirmEnvKey: tc.saved}
+			err := srv.ApproveDevice(context.Background(), newMapEnv(vals), ApproveDeviceRequest{
+				UserCode:     "BCDF-GHJK",
+				ConfirmToken: tc.posted,
+			})
+
+			var sErr *serr.ServiceError
+			require.ErrorAs(t, err, &sErr)
+			assert.Equal(t, http.StatusForbidden, sErr.StatusCode)
+		})
+	}
+}
+
+func TestAuth_ApproveDevice_Expired(t *testing.T) {
+	srv := newDeviceAuth(&mockStore{
+		approveDeviceAuthFunc: func(ctx context.Context, r store.ApproveDeviceAuthRequest) error {
+			return store.ErrNotFound
+		},
+	}, time.Now())
+
+	vals := map[string]string{deviceConfirmEnvKey: "confirm_token"}
+	err := srv.ApproveDevice(context.Background(), newMapEnv(vals), ApproveDeviceRequest{
+		UserCode:     "BCDF-GHJK",
+		ConfirmToken: "confirm_token",
+	})
+
+	var sErr *serr.ServiceError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, http.StatusNotFound, sErr.StatusCode)
+}
+
+func TestAuth_PollDeviceToken_Pending(t *testing.T) {
+	now := time.Now()
+	var polls []store.UpdateDeviceAuthPollRequest
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{
+				ID:        1,
+				Interval:  5 * time.Second,
+				ExpiresAt: now.Add(time.Minute),
+			}, nil
+		},
+		updateDeviceAuthPollFunc: func(ctx context.Context, r store.UpdateDeviceAuthPollRequest) error {
+			polls = append(polls, r)
+			return nil
+		},
+	}, now)
+
+	_, err := srv.PollDeviceToken(context.Background(), "device_code")
+	requireDeviceError(t, err, ErrAuthorizationPending)
+
+	require.Equal(t, []store.UpdateDeviceAuthPollRequest{{
+		ID:       1,
+		PolledAt: now,
+		Interval: 5 * time.Second,
+	}}, polls)
+}
+
+func TestAuth_PollDeviceToken_SlowDown(t *testing.T) {
+	now := time.Now()
+	var polls []store.UpdateDeviceAuthPollRequest
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{
+				ID:           1,
+				Interval:     5 * time.Second,
+				LastPolledAt: now.Add(-2 * time.Second),
+				ExpiresAt:    now.Add(time.Minute),
+			}, nil
+		},
+		updateDeviceAuthPollFunc: func(ctx context.Context, r store.UpdateDeviceAuthPollRequest) error {
+			polls = append(polls, r)
+			return nil
+		},
+	}, now)
+
+	_, err := srv.PollDeviceToken(context.Background(), "device_code")
+	requireDeviceError(t, err, ErrSlowDown)
+
+	require.Len(t, polls, 1)
+	assert.Equal(t, 10*time.Second, polls[0].Interval)
+}
+
+func TestAuth_PollDeviceToken_Expired(t *testing.T) {
+	now := time.Now()
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{ID: 1, ExpiresAt: now.Add(-time.Second)}, nil
+		},
+	}, now)
+
+	_, err := srv.PollDeviceToken(context.Background(), "device_code")
+	requireDeviceError(t, err, ErrExpiredToken)
+}
+
+func TestAuth_PollDeviceToken_UnknownCode(t *testing.T) {
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{}, store.ErrNotFound
+		},
+	}, time.Now())
+
+	_, err := srv.PollDeviceToken(context.Background(), "device_code")
+	requireDeviceError(t, err, ErrInvalidGrant)
+}
+
+func TestAuth_PollDeviceToken_Approved(t *testing.T) {
+	now := time.Now()
+	var deleted []store.DeleteDeviceAuthRequest
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			if r.DeviceCodeHash != hashToken("device_code") || !r.ForUpdate {
+				return store.DeviceAuth{}, store.ErrNotFound
+			}
+			return store.DeviceAuth{
+				ID:        1,
+				UserUID:   "uid-7",
+				Provider:  "google",
+				ExpiresAt: now.Add(time.Minute),
+			}, nil
+		},
+		getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+			if r.UID != "uid-7" || r.Provider != "google" {
+				return store.Identity{}, errors.New("unexpected identity")
+			}
+			return store.Identity{ID: "identity-7", Provider: r.Provider, User: store.User{ID: 7, UID: r.UID}}, nil
+		},
+		deleteDeviceAuthFunc: func(ctx context.Context, r store.DeleteDeviceAuthRequest) error {
+			deleted = append(deleted, r)
+			return nil
+		},
+	}, now)
+
+	resp, err := srv.PollDeviceToken(context.Background(), "device_code")
+	require.NoError(t, err)
+
+	assert.Equal(t, "access_token", resp.AccessToken)
+	assert.Equal(t, "refresh_token", resp.RefreshToken)
+	assert.Equal(t, []store.DeleteDeviceAuthRequest{{ID: 1}}, deleted)
+}
+
+func TestAuth_PollDeviceToken_AlreadyExchanged(t *testing.T) {
+	now := time.Now()
+	srv := newDeviceAuth(&mockStore{
+		getDeviceAuthFunc: func(ctx context.Context, r store.GetDeviceAuthRequest) (store.DeviceAuth, error) {
+			return store.DeviceAuth{
+				ID:        1,
+				UserUID:   "uid-7",
+				Provider:  "google",
+				ExpiresAt: now.Add(time.Minute),
+			}, nil
+		},
+		getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+			return store.Identity{ID: "identity-7", Provider: r.Provider, User: store.User{ID: 7, UID: r.UID}}, nil
+		},
+		deleteDeviceAuthFunc: func(ctx context.Context, r store.DeleteDeviceAuthRequest) error {
+			return store.ErrNotFound
+		},
+	}, now)
+
+	resp, err := srv.PollDeviceToken(context.Background(), "device_code")
+	requireDeviceError(t, err, ErrInvalidGrant)
+	assert.Empty(t, resp.AccessToken)
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", normalizeUserCode("bcdf-ghjk"))
+	assert.Equal(t, "BCDFGHJK", normalizeUserCode(" BCDF GHJK "))
+	assert.Equal(t, "BCDF-GHJK", formatUserCode("BCDFGHJK"))
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/pat"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+)
+
+// Token types reported by introspection
+const (
+	TokenTypeAccess   = "access_token"
+	TokenTypeRefresh  = "refresh_token"
+	TokenTypePersonal = "personal_access_token"
+)
+
+// defaultScope is granted to access tokens issued through interactive sign in
+var defaultScope = strings.Join(patScopes, " ")
+
+// Introspection describes a token as defined by RFC 7662
+type Introspection struct {
+	Active    bool
+	TokenType string
+	Claims    token.UserClaims
+	Scopes    []string
+}
+
+// Introspect reports whether the token is active and returns its claims.
+// Invalid, expired and revoked tokens are reported as inactive rather than as errors.
+func (s *Auth) Introspect(ctx context.Context, raw string) (Introspection, error) {
+	if strings.HasPrefix(raw, pat.Prefix) {
+		info, err := s.IntrospectAccessToken(ctx, raw)
+		if err != nil {
+			return Introspection{}, err
+		}
+		if !info.Active {
+			return Introspection{}, nil
+		}
+
+		// personal access tokens carry no claims, so the profile is looked up on every introspection
+		p, err := s.store.GetProfile(ctx, store.GetProfileRequest{UserUID: info.UserUID})
+		if err != nil {
+			return Introspection{}, fmt.Errorf("get profile: %w", err)
+		}
+
+		return Introspection{
+			Active:    true,
+			TokenType: TokenTypePersonal,
+			Claims: token.UserClaims{
+				ID:          info.UserUID,
+				Name:        p.DisplayName,
+				Scope:       strings.Join(info.Scopes, " "),
+				NativeLang:  p.NativeLang,
+				TargetLangs: p.TargetLangs,
+				DailyGoal:   p.DailyGoal,
+				Timezone:    p.Timezone,
+				ExpiresAt:   info.ExpiresAt,
+			},
+			Scopes: info.Scopes,
+		}, nil
+	}
+
+	tokenType := TokenTypeAccess
+	claims, err := s.accessToken.Validate(raw)
+	if err != nil || claims.Type == token.TypeRefresh {
+		tokenType = TokenTypeRefresh
+		claims, err = s.refreshToken.Validate(raw)
+		if err != nil || claims.Type != token.TypeRefresh {
+			return Introspection{}, nil
+		}
+	}
+
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return Introspection{}, err
+	}
+	if revoked {
+		return Introspection{}, nil
+	}
+
+	usr, err := s.store.GetUser(ctx, store.GetUserRequest{UID: claims.ID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return Introspection{}, nil
+		}
+
+		return Introspection{}, fmt.Errorf("get user: %w", err)
+	}
+	if checkUser(usr, claims.IssuedAt) != nil {
+		return Introspection{}, nil
+	}
+
+	return Introspection{
+		Active:    true,
+		TokenType: tokenType,
+		Claims:    claims,
+		Scopes:    strings.Fields(claims.Scope),
+	}, nil
+}
+
+// UserInfo describes the signed in user, similar to the OpenID Connect userinfo response
+type UserInfo struct {
+	UID      string
+	Email    string
+	Name     string
+	Picture  string
+	Provider string
+}
+
+// UserInfo returns the profile of the user the access token was issued to
+func (s *Auth) UserInfo(ctx context.Context, accessToken string) (UserInfo, error) {
+	claims, err := s.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	id, err := s.store.GetUserIdentity(ctx, store.GetUserIdentityRequest{
+		UID:      claims.ID,
+		Provider: claims.Provider,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return UserInfo{}, serr.NewServiceError(err, http.StatusUnauthorized, "invalid user identity")
+		}
+
+		return UserInfo{}, fmt.Errorf("get user identity: %w", err)
+	}
+
+	return UserInfo{
+		UID:      id.User.UID,
+		Email:    id.Email,
+		Name:     id.Name,
+		Picture:  id.Picture,
+		Provider: id.Provider,
+	}, nil
+}
+
+// Revoke revokes an access, refresh or personal access token as defined by RFC 7009.
+// Revoking an invalid or already revoked token succeeds without doing anything.
+func (s *Auth) Revoke(ctx context.Context, raw string) error {
+	if strings.HasPrefix(raw, pat.Prefix) {
+		tk, err := s.store.GetAccessToken(ctx, store.GetAccessTokenRequest{TokenHash: hashToken(raw)})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("get access token: %w", err)
+		}
+
+		err = s.store.RevokeAccessToken(ctx, store.RevokeAccessTokenRequest{ID: tk.ID, UserUID: tk.UserUID})
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("revoke access token: %w", err)
+		}
+
+		return nil
+	}
+
+	claims, err := s.accessToken.Validate(raw)
+	if err != nil {
+		claims, err = s.refreshToken.Validate(raw)
+		if err != nil {
+			return nil
+		}
+	}
+
+	if claims.TokenID == "" {
+		return nil
+	}
+
+	err = s.store.RevokeToken(ctx, store.RevokeTokenRequest{
+		TokenID:   claims.TokenID,
+		ExpiresAt: claims.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// validateAccessToken validates an access token and makes sure it has not been revoked
+func (s *Auth) validateAccessToken(ctx context.Context, raw string) (token.UserClaims, error) {
+	claims, err := s.accessToken.Validate(raw)
+	if err != nil {
+		return token.UserClaims{}, serr.NewServiceError(err, http.StatusUnauthorized, "invalid access token")
+	}
+
+	if claims.Type == token.TypeRefresh || claims.ID == "" {
+		return token.UserClaims{}, serr.NewServiceError(nil, http.StatusUnauthorized, "invalid access token")
+	}
+
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return token.UserClaims{}, err
+	}
+	if revoked {
+		return token.UserClaims{}, serr.NewServiceError(nil, http.StatusUnauthorized, "access token revoked")
+	}
+
+	usr, err := s.store.GetUser(ctx, store.GetUserRequest{UID: claims.ID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return token.UserClaims{}, serr.NewServiceError(err, http.StatusUnauthorized, "invalid access token")
+		}
+
+		return token.UserClaims{}, fmt.Errorf("get user: %w", err)
+	}
+
+	if err := checkUser(usr, claims.IssuedAt); err != nil {
+		return token.UserClaims{}, err
+	}
+
+	return claims, nil
+}
+
+// isRevoked reports whether the token has been revoked. Tokens without an id cannot be revoked.
+func (s *Auth) isRevoked(ctx context.Context, claims token.UserClaims) (bool, error) {
+	if claims.TokenID == "" {
+		return false, nil
+	}
+
+	revoked, err := s.store.IsTokenRevoked(ctx, store.IsTokenRevokedRequest{TokenID: claims.TokenID})
+	if err != nil {
+		return false, fmt.Errorf("check token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/pat"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIntrospectAuth creates a service whose access and refresh issuers share a secret,
+// so that either issuer accepts both kinds of tokens like the real JWT issuers do
+func newIntrospectAuth(st *mockStore) *Auth {
+	validate := func(tk string) (token.UserClaims, error) {
+		switch tk {
+		case "access":
+			return token.UserClaims{Type: token.TypeAccess, ID: "uid-1", Scope: "words:read", TokenID: "jti-access"}, nil
+		case "refresh":
+			return token.UserClaims{Type: token.TypeRefresh, ID: "uid-1", TokenID: "jti-refresh"}, nil
+		case "revoked":
+			return token.UserClaims{Type: token.TypeAccess, ID: "uid-1", TokenID: "jti-revoked"}, nil
+		case "legacy":
+			return token.UserClaims{Type: token.TypeAccess, ID: "uid-1"}, nil
+		default:
+			return token.UserClaims{}, errors.New("invalid token")
+		}
+	}
+
+	srv := newPATAuth(st, &mockTokenIssuer{validateFunc: validate}, time.Now())
+	srv.refreshToken = srv.accessToken
+	return srv
+}
+
+func revokedStore(revoked ...string) *mockStore {
+	return &mockStore{
+		isTokenRevokedFunc: func(ctx context.Context, r store.IsTokenRevokedRequest) (bool, error) {
+			for _, id := range revoked {
+				if id == r.TokenID {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+	}
+}
+
+func TestAuth_Introspect(t *testing.T) {
+	srv := newIntrospectAuth(revokedStore("jti-revoked"))
+
+	res, err := srv.Introspect(context.Background(), "access")
+	require.NoError(t, err)
+	assert.True(t, res.Active)
+	assert.Equal(t, TokenTypeAccess, res.TokenType)
+	assert.Equal(t, "uid-1", res.Claims.ID)
+	assert.Equal(t, []string{"words:read"}, res.Scopes)
+
+	res, err = srv.Introspect(context.Background(), "refresh")
+	require.NoError(t, err)
+	assert.True(t, res.Active)
+	assert.Equal(t, TokenTypeRefresh, res.TokenType)
+
+	res, err = srv.Introspect(context.Background(), "legacy")
+	require.NoError(t, err)
+	assert.True(t, res.Active)
+
+	res, err = srv.Introspect(context.Background(), "revoked")
+	require.NoError(t, err)
+	assert.False(t, res.Active)
+
+	res, err = srv.Introspect(context.Background(), "garbage")
+	require.NoError(t, err)
+	assert.False(t, res.Active)
+}
+
+func TestAuth_Introspect_PersonalToken(t *testing.T) {
+	raw := pat.Prefix + "secret"
+	expiresAt := time.Now().Add(time.Hour)
+	srv := newIntrospectAuth(&mockStore{
+		getAccessTokenFunc: func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+			require.Equal(t, hashToken(raw), r.TokenHash)
+			return store.AccessToken{ID: 3, UserUID: "uid-1", Scopes: []string{"words:read"}, ExpiresAt: expiresAt}, nil
+		},
+		updateAccessTokenUsageFunc: func(ctx context.Context, r store.UpdateAccessTokenUsageRequest) error {
+			return nil
+		},
+	})
+
+	res, err := srv.Introspect(context.Background(), raw)
+	require.NoError(t, err)
+	assert.True(t, res.Active)
+	assert.Equal(t, TokenTypePersonal, res.TokenType)
+	assert.Equal(t, "uid-1", res.Claims.ID)
+	assert.Equal(t, "words:read", res.Claims.Scope)
+	assert.Equal(t, expiresAt, res.Claims.ExpiresAt)
+}
+
+func TestAuth_UserInfo(t *testing.T) {
+	st := revokedStore("jti-revoked")
+	st.getUserIdentityFunc = func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+		assert.Equal(t, "uid-1", r.UID)
+		return store.Identity{
+			Email:    "user@example.com",
+			Name:     "User",
+			Provider: "google",
+			User:     store.User{UID: "uid-1"},
+		}, nil
+	}
+	srv := newIntrospectAuth(st)
+
+	info, err := srv.UserInfo(context.Background(), "access")
+	require.NoError(t, err)
+	assert.Equal(t, UserInfo{UID: "uid-1", Email: "user@example.com", Name: "User", Provider: "google"}, info)
+
+	_, err = srv.UserInfo(context.Background(), "revoked")
+	requireStatus(t, err, http.StatusUnauthorized)
+
+	_, err = srv.UserInfo(context.Background(), "refresh")
+	requireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestAuth_Revoke(t *testing.T) {
+	var revoked []string
+	srv := newIntrospectAuth(&mockStore{
+		revokeTokenFunc: func(ctx context.Context, r store.RevokeTokenRequest) error {
+			revoked = append(revoked, r.TokenID)
+			return nil
+		},
+	})
+
+	require.NoError(t, srv.Revoke(context.Background(), "access"))
+	require.NoError(t, srv.Revoke(context.Background(), "legacy"))
+	require.NoError(t, srv.Revoke(context.Background(), "garbage"))
+	assert.Equal(t, []string{"jti-access"}, revoked)
+}
+
+func TestAuth_Revoke_PersonalToken(t *testing.T) {
+	var revoked []int64
+	srv := newIntrospectAuth(&mockStore{
+		getAccessTokenFunc: func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+			return store.AccessToken{ID: 3, UserUID: "uid-1"}, nil
+		},
+		revokeAccessTokenFunc: func(ctx context.Context, r store.RevokeAccessTokenRequest) error {
+			assert.Equal(t, "uid-1", r.UserUID)
+			revoked = append(revoked, r.ID)
+			return nil
+		},
+	})
+
+	require.NoError(t, srv.Revoke(context.Background(), pat.Prefix+"secret"))
+	assert.Equal(t, []int64{3}, revoked)
+}
+
+func TestAuth_Refresh_RevokedToken(t *testing.T) {
+	srv := newIntrospectAuth(revokedStore("jti-refresh"))
+
+	_, err := srv.Refresh(context.Background(), "refresh")
+	requireStatus(t, err, http.StatusUnauthorized)
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/pat"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+)
+
+const (
+	patSize = 32
+	// patDisplayLength is the number of leading token characters kept to help users recognize a token
+	patDisplayLength = len(pat.Prefix) + 6
+	patAttempts      = 3
+	// patUsageInterval is how stale the recorded last use of a token may get, so that not every request
+	// authenticated with it writes to the database
+	patUsageInterval = time.Minute
+)
+
+var patScopes = []string{pat.ScopeWordsRead, pat.ScopeWordsWrite}
+
+// PersonalToken describes a personal access token without its secret value
+type PersonalToken struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+	CreatedAt  time.Time
+}
+
+// TokenInfo describes the state of a token as seen by the auth service
+type TokenInfo struct {
+	Active    bool
+	UserUID   string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// Authenticate validates an access token issued by the auth service and returns the user UID
+func (s *Auth) Authenticate(ctx context.Context, accessToken string) (string, error) {
+	claims, err := s.validateAccessToken(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	return claims.ID, nil
+}
+
+type CreateAccessTokenRequest struct {
+	UserUID string
+	Name    string
+	Scopes  []string
+	// TTL of zero creates a token which never expires
+	TTL time.Duration
+}
+
+type CreateAccessTokenResponse struct {
+	Token         string
+	PersonalToken PersonalToken
+}
+
+// CreateAccessToken creates a new personal access token. The token value is returned only once.
+func (s *Auth) CreateAccessToken(ctx context.Context, r CreateAccessTokenRequest) (CreateAccessTokenResponse, error) {
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		return CreateAccessTokenResponse{}, serr.NewServiceError(nil, http.StatusBadRequest, "token name is required")
+	}
+
+	if r.TTL < 0 {
+		return CreateAccessTokenResponse{}, serr.NewServiceError(nil, http.StatusBadRequest, "token ttl must not be negative")
+	}
+
+	scopes, err := normalizeScopes(r.Scopes)
+	if err != nil {
+		return CreateAccessTokenResponse{}, err
+	}
+
+	var expiresAt time.Time
+	if r.TTL > 0 {
+		expiresAt = s.now().Add(r.TTL)
+	}
+
+	for range patAttempts {
+		raw := randPAT()
+		prefix := raw[:patDisplayLength]
+		id, err := s.store.CreateAccessToken(ctx, store.CreateAccessTokenRequest{
+			UserUID:   r.UserUID,
+			Name:      name,
+			TokenHash: hashToken(raw),
+			Prefix:    prefix,
+			Scopes:    scopes,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrExists) {
+				continue
+			}
+
+			if errors.Is(err, store.ErrNotFound) {
+				return CreateAccessTokenResponse{}, serr.NewServiceError(err, http.StatusNotFound, "user not found")
+			}
+
+			return CreateAccessTokenResponse{}, fmt.Errorf("create access token: %w", err)
+		}
+
+		return CreateAccessTokenResponse{
+			Token: raw,
+			PersonalToken: PersonalToken{
+				ID:        id,
+				Name:      name,
+				Prefix:    prefix,
+				Scopes:    scopes,
+				ExpiresAt: expiresAt,
+				CreatedAt: s.now(),
+			},
+		}, nil
+	}
+
+	return CreateAccessTokenResponse{}, errors.New("create access token: token collisions")
+}
+
+// ListAccessTokens lists the personal access tokens of the user
+func (s *Auth) ListAccessTokens(ctx context.Context, userUID string) ([]PersonalToken, error) {
+	tokens, err := s.store.ListAccessTokens(ctx, store.ListAccessTokensRequest{UserUID: userUID})
+	if err != nil {
+		return nil, fmt.Errorf("list access tokens: %w", err)
+	}
+
+	resp := make([]PersonalToken, 0, len(tokens))
+	for _, tk := range tokens {
+		resp = append(resp, PersonalToken{
+			ID:         tk.ID,
+			Name:       tk.Name,
+			Prefix:     tk.Prefix,
+			Scopes:     tk.Scopes,
+			ExpiresAt:  tk.ExpiresAt,
+			LastUsedAt: tk.LastUsedAt,
+			RevokedAt:  tk.RevokedAt,
+			CreatedAt:  tk.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// RevokeAccessToken revokes a personal access token owned by the user
+func (s *Auth) RevokeAccessToken(ctx context.Context, userUID string, id int64) error {
+	err := s.store.RevokeAccessToken(ctx, store.RevokeAccessTokenRequest{
+		ID:      id,
+		UserUID: userUID,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			sErr := serr.NewServiceError(err, http.StatusNotFound, "access token not found")
+			sErr.Env["token_id"] = fmt.Sprint(id)
+			return sErr
+		}
+
+		return fmt.Errorf("revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// IntrospectAccessToken reports whether the personal access token is active and who owns it.
+// Unknown, expired and revoked tokens are reported as inactive rather than as errors.
+func (s *Auth) IntrospectAccessToken(ctx context.Context, raw string) (TokenInfo, error) {
+	if !strings.HasPrefix(raw, pat.Prefix) {
+		return TokenInfo{}, nil
+	}
+
+	tk, err := s.store.GetAccessToken(ctx, store.GetAccessTokenRequest{TokenHash: hashToken(raw)})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return TokenInfo{}, nil
+		}
+
+		return TokenInfo{}, fmt.Errorf("get access token: %w", err)
+	}
+
+	now := s.now()
+	if !tk.Active(now) {
+		return TokenInfo{}, nil
+	}
+
+	if now.Sub(tk.LastUsedAt) >= patUsageInterval {
+		err = s.store.UpdateAccessTokenUsage(ctx, store.UpdateAccessTokenUsageRequest{
+			ID:     tk.ID,
+			UsedAt: now,
+		})
+		if err != nil {
+			return TokenInfo{}, fmt.Errorf("update access token usage: %w", err)
+		}
+	}
+
+	return TokenInfo{
+		Active:    true,
+		UserUID:   tk.UserUID,
+		Scopes:    tk.Scopes,
+		ExpiresAt: tk.ExpiresAt,
+	}, nil
+}
+
+// normalizeScopes validates the requested scopes and removes duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, serr.NewServiceError(nil, http.StatusBadRequest, "at least one scope is required")
+	}
+
+	res := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		if !slices.Contains(patScopes, sc) {
+			sErr := serr.NewServiceError(nil, http.StatusBadRequest, "unknown scope: %s", sc)
+			sErr.Env["scope"] = sc
+			return nil, sErr
+		}
+
+		if !slices.Contains(res, sc) {
+			res = append(res, sc)
+		}
+	}
+
+	slices.Sort(res)
+	return res, nil
+}
+
+// randPAT generates a new personal access token value
+func randPAT() string {
+	b := make([]byte, patSize)
+	// rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return pat.Prefix + base64.RawURLEncoding.EncodeToString(b)
+}
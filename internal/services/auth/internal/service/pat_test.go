@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/pat"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPATAuth(st *mockStore, access *mockTokenIssuer, now time.Time) *Auth {
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(st),
+		WithAccessToken(access),
+		WithRefreshToken(&mockTokenIssuer{}),
+	)
+	srv.now = func() time.Time { return now }
+	return srv
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var sErr *serr.ServiceError
+	require.ErrorAs(t, err, &sErr)
+	assert.Equal(t, status, sErr.StatusCode)
+}
+
+func TestAuth_Authenticate(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{
+		validateFunc: func(tk string) (token.UserClaims, error) {
+			switch tk {
+			case "access":
+				return token.UserClaims{ID: "uid-1"}, nil
+			case "refresh":
+				return token.UserClaims{ID: "uid-1", Type: token.TypeRefresh}, nil
+			default:
+				return token.UserClaims{}, errors.New("invalid token")
+			}
+		},
+	}, time.Now())
+
+	uid, err := srv.Authenticate(context.Background(), "access")
+	require.NoError(t, err)
+	assert.Equal(t, "uid-1", uid)
+
+	_, err = srv.Authenticate(context.Background(), "refresh")
+	requireStatus(t, err, http.StatusUnauthorized)
+
+	_, err = srv.Authenticate(context.Background(), "garbage")
+	requireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestAuth_CreateAccessToken(t *testing.T) {
+	now := time.Now()
+	var created []store.CreateAccessTokenRequest
+	srv := newPATAuth(&mockStore{
+		createAccessTokenFunc: func(ctx context.Context, r store.CreateAccessTokenRequest) (int64, error) {
+			created = append(created, r)
+			return 42, nil
+		},
+	}, &mockTokenIssuer{}, now)
+
+	resp, err := srv.CreateAccessToken(context.Background(), CreateAccessTokenRequest{
+		UserUID: "uid-1",
+		Name:    " importer ",
+		Scopes:  []string{pat.ScopeWordsWrite, pat.ScopeWordsRead, pat.ScopeWordsWrite},
+		TTL:     24 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(resp.Token, pat.Prefix))
+	assert.Equal(t, int64(42), resp.PersonalToken.ID)
+	assert.Equal(t, "importer", resp.PersonalToken.Name)
+	assert.Equal(t, resp.Token[:patDisplayLength], resp.PersonalToken.Prefix)
+	assert.Equal(t, []string{pat.ScopeWordsRead, pat.ScopeWordsWrite}, resp.PersonalToken.Scopes)
+	assert.Equal(t, now.Add(24*time.Hour), resp.PersonalToken.ExpiresAt)
+
+	require.Len(t, created, 1)
+	assert.Equal(t, "uid-1", created[0].UserUID)
+	assert.Equal(t, hashToken(resp.Token), created[0].TokenHash)
+	assert.NotContains(t, created[0].TokenHash, resp.Token)
+	assert.Equal(t, resp.PersonalToken.Prefix, created[0].Prefix)
+}
+
+func TestAuth_CreateAccessToken_NoExpiry(t *testing.T) {
+	srv := newPATAuth(&mockStore{
+		createAccessTokenFunc: func(ctx context.Context, r store.CreateAccessTokenRequest) (int64, error) {
+			assert.True(t, r.ExpiresAt.IsZero())
+			return 1, nil
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	resp, err := srv.CreateAccessToken(context.Background(), CreateAccessTokenRequest{
+		UserUID: "uid-1",
+		Name:    "script",
+		Scopes:  []string{pat.ScopeWordsRead},
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.PersonalToken.ExpiresAt.IsZero())
+}
+
+func TestAuth_CreateAccessToken_InvalidRequest(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{}, time.Now())
+
+	tests := []struct {
+		name string
+		req  CreateAccessTokenRequest
+	}{
+		{"missing name", CreateAccessTokenRequest{UserUID: "uid-1", Scopes: []string{pat.ScopeWordsRead}}},
+		{"missing scopes", CreateAccessTokenRequest{UserUID: "uid-1", Name: "script"}},
+		{"unknown scope", CreateAccessTokenRequest{UserUID: "uid-1", Name: "script", Scopes: []string{"admin"}}},
+		{"negative ttl", CreateAccessTokenRequest{UserUID: "uid-1", Name: "script", Scopes: []string{pat.ScopeWordsRead}, TTL: -time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := srv.CreateAccessToken(context.Background(), tt.req)
+			requireStatus(t, err, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestAuth_RevokeAccessToken_NotFound(t *testing.T) {
+	srv := newPATAuth(&mockStore{
+		revokeAccessTokenFunc: func(ctx context.Context, r store.RevokeAccessTokenRequest) error {
+			return store.ErrNotFound
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	err := srv.RevokeAccessToken(context.Background(), "uid-1", 7)
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestAuth_IntrospectAccessToken(t *testing.T) {
+	now := time.Now()
+	raw := pat.Prefix + "secret"
+	var usage []store.UpdateAccessTokenUsageRequest
+	srv := newPATAuth(&mockStore{
+		getAccessTokenFunc: func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+			if r.TokenHash != hashToken(raw) {
+				return store.AccessToken{}, store.ErrNotFound
+			}
+			return store.AccessToken{
+				ID:        3,
+				UserUID:   "uid-1",
+				Scopes:    []string{pat.ScopeWordsRead},
+				ExpiresAt: now.Add(time.Hour),
+			}, nil
+		},
+		updateAccessTokenUsageFunc: func(ctx context.Context, r store.UpdateAccessTokenUsageRequest) error {
+			usage = append(usage, r)
+			return nil
+		},
+	}, &mockTokenIssuer{}, now)
+
+	info, err := srv.IntrospectAccessToken(context.Background(), raw)
+	require.NoError(t, err)
+
+	assert.Equal(t, TokenInfo{
+		Active:    true,
+		UserUID:   "uid-1",
+		Scopes:    []string{pat.ScopeWordsRead},
+		ExpiresAt: now.Add(time.Hour),
+	}, info)
+	assert.Equal(t, []store.UpdateAccessTokenUsageRequest{{ID: 3, UsedAt: now}}, usage)
+}
+
+func TestAuth_IntrospectAccessToken_RecentlyUsed(t *testing.T) {
+	now := time.Now()
+	srv := newPATAuth(&mockStore{
+		getAccessTokenFunc: func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+			return store.AccessToken{ID: 3, UserUID: "uid-1", LastUsedAt: now.Add(-30 * time.Second)}, nil
+		},
+		updateAccessTokenUsageFunc: func(ctx context.Context, r store.UpdateAccessTokenUsageRequest) error {
+			t.Fatal("usage of a recently used token must not be recorded again")
+			return nil
+		},
+	}, &mockTokenIssuer{}, now)
+
+	info, err := srv.IntrospectAccessToken(context.Background(), pat.Prefix+"secret")
+	require.NoError(t, err)
+	assert.True(t, info.Active)
+}
+
+func TestAuth_IntrospectAccessToken_Inactive(t *testing.T) {
+	now := time.Now()
+	tokens := map[string]store.AccessToken{
+		hashToken(pat.Prefix + "revoked"): {ID: 1, UserUID: "uid-1", RevokedAt: now.Add(-time.Minute)},
+		hashToken(pat.Prefix + "expired"): {ID: 2, UserUID: "uid-1", ExpiresAt: now.Add(-time.Minute)},
+	}
+	srv := newPATAuth(&mockStore{
+		getAccessTokenFunc: func(ctx context.Context, r store.GetAccessTokenRequest) (store.AccessToken, error) {
+			tk, ok := tokens[r.TokenHash]
+			if !ok {
+				return store.AccessToken{}, store.ErrNotFound
+			}
+			return tk, nil
+		},
+	}, &mockTokenIssuer{}, now)
+
+	for _, raw := range []string{pat.Prefix + "revoked", pat.Prefix + "expired", pat.Prefix + "unknown", "not-a-pat"} {
+		info, err := srv.IntrospectAccessToken(context.Background(), raw)
+		require.NoError(t, err)
+		assert.False(t, info.Active, raw)
+		assert.Empty(t, info.UserUID, raw)
+	}
+}
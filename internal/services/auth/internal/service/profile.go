@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	// the runtime image ships without a zoneinfo database
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxTargetLangs       = 10
+	maxDailyGoal         = 1000
+)
+
+// langRe matches BCP 47 style language tags such as "en" or "pt-BR"
+var langRe = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Profile holds the display name and learning preferences of a user
+type Profile struct {
+	UID         string
+	DisplayName string
+	NativeLang  string
+	TargetLangs []string
+	DailyGoal   int
+	Timezone    string
+	UpdatedAt   time.Time
+}
+
+// GetProfile returns the profile of the user
+func (s *Auth) GetProfile(ctx context.Context, userUID string) (Profile, error) {
+	p, err := s.store.GetProfile(ctx, store.GetProfileRequest{UserUID: userUID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return Profile{}, serr.NewServiceError(err, http.StatusNotFound, "user not found")
+		}
+
+		return Profile{}, fmt.Errorf("get profile: %w", err)
+	}
+
+	return newProfile(p), nil
+}
+
+// UpdateProfileRequest describes a partial profile update. Nil fields are left unchanged.
+type UpdateProfileRequest struct {
+	UserUID     string
+	DisplayName *string
+	NativeLang  *string
+	TargetLangs *[]string
+	DailyGoal   *int
+	Timezone    *string
+}
+
+// UpdateProfile applies the changes to the profile of the user and returns the updated profile.
+// Tokens issued earlier keep the old preferences until they are refreshed.
+func (s *Auth) UpdateProfile(ctx context.Context, r UpdateProfileRequest) (Profile, error) {
+	var p Profile
+	err := s.store.WithTx(ctx, func(tx store.Store) error {
+		// the lock keeps concurrent updates of other fields from being overwritten
+		sp, err := tx.GetProfile(ctx, store.GetProfileRequest{UserUID: r.UserUID, ForUpdate: true})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return serr.NewServiceError(err, http.StatusNotFound, "user not found")
+			}
+
+			return fmt.Errorf("get profile: %w", err)
+		}
+
+		p = newProfile(sp)
+		if err := applyProfileChanges(&p, r); err != nil {
+			return err
+		}
+
+		err = tx.UpdateProfile(ctx, store.UpdateProfileRequest{
+			UserUID:     r.UserUID,
+			DisplayName: p.DisplayName,
+			NativeLang:  p.NativeLang,
+			TargetLangs: p.TargetLangs,
+			DailyGoal:   p.DailyGoal,
+			Timezone:    p.Timezone,
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return serr.NewServiceError(err, http.StatusNotFound, "user not found")
+			}
+
+			return fmt.Errorf("update profile: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Profile{}, err
+	}
+
+	p.UpdatedAt = s.now()
+	return p, nil
+}
+
+// applyProfileChanges validates the changes of the request and applies them to p.
+// An empty list of target languages clears them.
+func applyProfileChanges(p *Profile, r UpdateProfileRequest) error {
+	if r.DisplayName != nil {
+		p.DisplayName = strings.TrimSpace(*r.DisplayName)
+		if utf8.RuneCountInString(p.DisplayName) > maxDisplayNameLength {
+			return serr.NewServiceError(nil, http.StatusBadRequest, "display name must not exceed %d characters", maxDisplayNameLength)
+		}
+	}
+
+	if r.NativeLang != nil {
+		p.NativeLang = *r.NativeLang
+		if p.NativeLang != "" && !langRe.MatchString(p.NativeLang) {
+			sErr := serr.NewServiceError(nil, http.StatusBadRequest, "invalid language: %s", p.NativeLang)
+			sErr.Env["lang"] = p.NativeLang
+			return sErr
+		}
+	}
+
+	if r.TargetLangs != nil {
+		langs, err := normalizeLangs(*r.TargetLangs)
+		if err != nil {
+			return err
+		}
+		p.TargetLangs = langs
+	}
+
+	if r.DailyGoal != nil {
+		p.DailyGoal = *r.DailyGoal
+		if p.DailyGoal < 0 || p.DailyGoal > maxDailyGoal {
+			return serr.NewServiceError(nil, http.StatusBadRequest, "daily goal must be between 0 and %d", maxDailyGoal)
+		}
+	}
+
+	if r.Timezone != nil {
+		p.Timezone = *r.Timezone
+		if _, err := time.LoadLocation(p.Timezone); err != nil || strings.EqualFold(p.Timezone, "local") {
+			sErr := serr.NewServiceError(err, http.StatusBadRequest, "invalid timezone: %s", p.Timezone)
+			sErr.Env["timezone"] = p.Timezone
+			return sErr
+		}
+	}
+
+	return nil
+}
+
+// accessClaims builds the access token claims of the identity, including the profile of its user
+func (s *Auth) accessClaims(ctx context.Context, id store.Identity) (token.UserClaims, error) {
+	p, err := s.store.GetProfile(ctx, store.GetProfileRequest{UserUID: id.User.UID})
+	if err != nil {
+		return token.UserClaims{}, fmt.Errorf("get profile: %w", err)
+	}
+
+	name := id.Name
+	if p.DisplayName != "" {
+		name = p.DisplayName
+	}
+
+	return token.UserClaims{
+		ID:          id.User.UID,
+		Email:       id.Email,
+		Provider:    id.Provider,
+		Name:        name,
+		Picture:     id.Picture,
+		Scope:       defaultScope,
+		NativeLang:  p.NativeLang,
+		TargetLangs: p.TargetLangs,
+		DailyGoal:   p.DailyGoal,
+		Timezone:    p.Timezone,
+	}, nil
+}
+
+// normalizeLangs validates the language tags and removes duplicates, keeping the order
+func normalizeLangs(langs []string) ([]string, error) {
+	if len(langs) > maxTargetLangs {
+		return nil, serr.NewServiceError(nil, http.StatusBadRequest, "at most %d target languages are allowed", maxTargetLangs)
+	}
+
+	res := make([]string, 0, len(langs))
+	for _, l := range langs {
+		if !langRe.MatchString(l) {
+			sErr := serr.NewServiceError(nil, http.StatusBadRequest, "invalid language: %s", l)
+			sErr.Env["lang"] = l
+			return nil, sErr
+		}
+
+		if !slices.Contains(res, l) {
+			res = append(res, l)
+		}
+	}
+
+	return res, nil
+}
+
+func newProfile(p store.Profile) Profile {
+	return Profile{
+		UID:         p.UserUID,
+		DisplayName: p.DisplayName,
+		NativeLang:  p.NativeLang,
+		TargetLangs: p.TargetLangs,
+		DailyGoal:   p.DailyGoal,
+		Timezone:    p.Timezone,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/auth/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth_GetProfile_NotFound(t *testing.T) {
+	srv := newPATAuth(&mockStore{
+		getProfileFunc: func(ctx context.Context, r store.GetProfileRequest) (store.Profile, error) {
+			return store.Profile{}, store.ErrNotFound
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	_, err := srv.GetProfile(context.Background(), "uid-1")
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestAuth_UpdateProfile(t *testing.T) {
+	var updated store.UpdateProfileRequest
+	srv := newPATAuth(&mockStore{
+		getProfileFunc: func(ctx context.Context, r store.GetProfileRequest) (store.Profile, error) {
+			assert.True(t, r.ForUpdate)
+			return store.Profile{
+				UserUID:     r.UserUID,
+				DisplayName: "Ann",
+				NativeLang:  "en",
+				DailyGoal:   10,
+			}, nil
+		},
+		updateProfileFunc: func(ctx context.Context, r store.UpdateProfileRequest) error {
+			updated = r
+			return nil
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	langs := []string{"de", "fr", "de"}
+	tz := "Europe/Berlin"
+	p, err := srv.UpdateProfile(context.Background(), UpdateProfileRequest{
+		UserUID:     "uid-1",
+		TargetLangs: &langs,
+		Timezone:    &tz,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, store.UpdateProfileRequest{
+		UserUID:     "uid-1",
+		DisplayName: "Ann",
+		NativeLang:  "en",
+		TargetLangs: []string{"de", "fr"},
+		DailyGoal:   10,
+		Timezone:    "Europe/Berlin",
+	}, updated)
+	assert.Equal(t, []string{"de", "fr"}, p.TargetLangs)
+}
+
+func TestAuth_UpdateProfile_ClearTargetLangs(t *testing.T) {
+	var updated store.UpdateProfileRequest
+	srv := newPATAuth(&mockStore{
+		getProfileFunc: func(ctx context.Context, r store.GetProfileRequest) (store.Profile, error) {
+			return store.Profile{UserUID: r.UserUID, TargetLangs: []string{"de", "fr"}}, nil
+		},
+		updateProfileFunc: func(ctx context.Context, r store.UpdateProfileRequest) error {
+			updated = r
+			return nil
+		},
+	}, &mockTokenIssuer{}, time.Now())
+
+	p, err := srv.UpdateProfile(context.Background(), UpdateProfileRequest{
+		UserUID:     "uid-1",
+		TargetLangs: &[]string{},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, updated.TargetLangs)
+	assert.Empty(t, p.TargetLangs)
+}
+
+func TestAuth_UpdateProfile_InvalidRequest(t *testing.T) {
+	srv := newPATAuth(&mockStore{}, &mockTokenIssuer{}, time.Now())
+
+	badLang := "English"
+	badTZ := "Mars/Olympus"
+	local := "Local"
+	badGoal := -1
+	tooMany := make([]string, maxTargetLangs+1)
+	for i := range tooMany {
+		tooMany[i] = "en"
+	}
+
+	for _, r := range []UpdateProfileRequest{
+		{UserUID: "uid-1", NativeLang: &badLang},
+		{UserUID: "uid-1", TargetLangs: &[]string{"de", badLang}},
+		{UserUID: "uid-1", TargetLangs: &tooMany},
+		{UserUID: "uid-1", Timezone: &badTZ},
+		{UserUID: "uid-1", Timezone: &local},
+		{UserUID: "uid-1", DailyGoal: &badGoal},
+	} {
+		_, err := srv.UpdateProfile(context.Background(), r)
+		requireStatus(t, err, http.StatusBadRequest)
+	}
+}
+
+func TestAuth_Refresh_ProfileClaims(t *testing.T) {
+	var issued token.UserClaims
+	srv := NewAuth(
+		WithAuthenticator(&mockAuthenticator{}),
+		WithStore(&mockStore{
+			getUserIdentityFunc: func(ctx context.Context, r store.GetUserIdentityRequest) (store.Identity, error) {
+				return store.Identity{Provider: r.Provider, Name: "Ann Smith", User: store.User{UID: r.UID}}, nil
+			},
+			getProfileFunc: func(ctx context.Context, r store.GetProfileRequest) (store.Profile, error) {
+				return store.Profile{
+					UserUID:     r.UserUID,
+					DisplayName: "Ann",
+					NativeLang:  "en",
+					TargetLangs: []string{"de"},
+					DailyGoal:   15,
+					Timezone:    "Europe/Berlin",
+				}, nil
+			},
+		}),
+		WithAccessToken(&mockTokenIssuer{
+			issueFunc: func(claims token.UserClaims) (string, error) {
+				issued = claims
+				return "access_token", nil
+			},
+		}),
+		WithRefreshToken(&mockTokenIssuer{
+			validateFunc: func(tk string) (token.UserClaims, error) {
+				return token.UserClaims{ID: "uid-1", Type: token.TypeRefresh}, nil
+			},
+		}),
+	)
+
+	_, err := srv.Refresh(context.Background(), "refresh_token")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Ann", issued.Name)
+	assert.Equal(t, "en", issued.NativeLang)
+	assert.Equal(t, []string{"de"}, issued.TargetLangs)
+	assert.Equal(t, 15, issued.DailyGoal)
+	assert.Equal(t, "Europe/Berlin", issued.Timezone)
+}
@@ -8,11 +8,33 @@ type Model struct {
 	UpdatedAt time.Time
 }
 
+// Roles a user can have
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents a user in the system
 type User struct {
 	Model
-	ID  int64
-	UID string
+	ID   int64
+	UID  string
+	Role string
+	// DisabledAt is set while the account is disabled
+	DisabledAt time.Time
+	// SessionsRevokedAt invalidates all tokens of the user issued before it
+	SessionsRevokedAt time.Time
+}
+
+// Disabled reports whether the account has been disabled
+func (u *User) Disabled() bool {
+	return !u.DisabledAt.IsZero()
+}
+
+// RevokedSession records that all tokens of a user issued before RevokedAt are invalid
+type RevokedSession struct {
+	UserUID   string
+	RevokedAt time.Time
 }
 
 // Identity represents a user's identity from an OAuth provider
@@ -25,3 +47,68 @@ type Identity struct {
 	Name     string
 	Picture  string
 }
+
+// DeviceAuth represents an OAuth device authorization request (RFC 8628)
+type DeviceAuth struct {
+	Model
+	ID           int64
+	ClientID     string
+	Scope        string
+	UserCode     string
+	UserUID      string
+	Provider     string
+	Interval     time.Duration
+	LastPolledAt time.Time
+	ExpiresAt    time.Time
+}
+
+// Approved reports whether a user has completed the verification for this request
+func (d *DeviceAuth) Approved() bool {
+	return d.UserUID != ""
+}
+
+// AccessToken represents a long-lived personal access token. Only the hash of the token is stored.
+type AccessToken struct {
+	Model
+	ID         int64
+	UserUID    string
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+// Active reports whether the token is neither revoked nor expired at the given time
+func (t *AccessToken) Active(now time.Time) bool {
+	if !t.RevokedAt.IsZero() {
+		return false
+	}
+
+	return t.ExpiresAt.IsZero() || now.Before(t.ExpiresAt)
+}
+
+// Profile holds the learning preferences of a user. Users without a stored profile get the zero profile.
+type Profile struct {
+	Model
+	UserUID     string
+	DisplayName string
+	NativeLang  string
+	TargetLangs []string
+	DailyGoal   int
+	Timezone    string
+}
+
+// AuditEvent records a security relevant event, such as a sign in attempt
+type AuditEvent struct {
+	ID int64
+	// UserUID is empty when the event cannot be attributed to a user
+	UserUID   string
+	Type      string
+	Provider  string
+	IP        string
+	UserAgent string
+	Detail    string
+	CreatedAt time.Time
+}
@@ -5,13 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+const errUniqueViolation pq.ErrorCode = "23505"
+
 // dbtx defines the interface for database and transactions
 type dbtx interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
@@ -53,28 +58,19 @@ func NewPostgresStore(db *sql.DB) *PostgresStore {
 	return &PostgresStore{db: db}
 }
 
+// identityColumns selects an identity together with its user, see scanIdentity
+const identityColumns = `i.id, i.provider, i.email, COALESCE(i.name, ''), COALESCE(i.picture, ''), i.created_at, i.updated_at,
+		        u.id, u.uid, u.role, u.disabled_at, u.sessions_revoked_at, u.created_at, u.updated_at`
+
 // GetIdentity retrieves an identity by its ID and provider
 func (s *PostgresStore) GetIdentity(ctx context.Context, r GetIdentityRequest) (Identity, error) {
 	row := s.db.QueryRowContext(ctx,
-		`SELECT i.id, i.provider, i.email, i.name, i.picture, i.created_at, i.updated_at,
-		        u.id, u.uid, u.created_at, u.updated_at	
+		`SELECT `+identityColumns+`
 		 FROM identities AS i
 		 JOIN users AS u ON i.user_id = u.id
 		 WHERE i.id=$1 AND i.provider=$2`, r.ID, r.Provider)
 
-	id := Identity{User: User{}}
-	err := row.Scan(
-		&id.ID,
-		&id.Provider,
-		&id.Email,
-		&id.Name,
-		&id.Picture,
-		&id.CreatedAt,
-		&id.UpdatedAt,
-		&id.User.ID,
-		&id.User.UID,
-		&id.User.CreatedAt,
-		&id.User.UpdatedAt)
+	id, err := scanIdentity(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return id, ErrNotFound
@@ -89,25 +85,12 @@ func (s *PostgresStore) GetIdentity(ctx context.Context, r GetIdentityRequest) (
 // GetUserIdentity retrieves an identity by user UID and provider
 func (s *PostgresStore) GetUserIdentity(ctx context.Context, r GetUserIdentityRequest) (Identity, error) {
 	row := s.db.QueryRowContext(ctx,
-		`SELECT i.id, i.provider, i.email, i.name, i.picture, i.created_at, i.updated_at,
-		        u.id, u.uid, u.created_at, u.updated_at	
+		`SELECT `+identityColumns+`
 		 FROM identities AS i
 		 JOIN users AS u ON i.user_id = u.id
 		 WHERE u.uid=$1 AND i.provider=$2`, r.UID, r.Provider)
 
-	id := Identity{User: User{}}
-	err := row.Scan(
-		&id.ID,
-		&id.Provider,
-		&id.Email,
-		&id.Name,
-		&id.Picture,
-		&id.CreatedAt,
-		&id.UpdatedAt,
-		&id.User.ID,
-		&id.User.UID,
-		&id.User.CreatedAt,
-		&id.User.UpdatedAt)
+	id, err := scanIdentity(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return id, ErrNotFound
@@ -148,6 +131,735 @@ func (s *PostgresStore) CreateUserIdentity(ctx context.Context, r CreateUserIden
 	return id, nil
 }
 
+// CreateDeviceAuth stores a new device authorization request and returns its ID
+func (s *PostgresStore) CreateDeviceAuth(ctx context.Context, r CreateDeviceAuthRequest) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO device_codes (device_code_hash, user_code, client_id, scope, poll_interval, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		r.DeviceCodeHash,
+		r.UserCode,
+		r.ClientID,
+		r.Scope,
+		int64(r.Interval/time.Second),
+		r.ExpiresAt).Scan(&id)
+	if err != nil {
+		if isPqErr(err, errUniqueViolation) {
+			return 0, ErrExists
+		}
+
+		return 0, fmt.Errorf("insert device code: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetDeviceAuth retrieves a device authorization request by the hash of its device code
+func (s *PostgresStore) GetDeviceAuth(ctx context.Context, r GetDeviceAuthRequest) (DeviceAuth, error) {
+	query := `SELECT d.id, d.client_id, d.scope, d.user_code, COALESCE(u.uid::text, ''), COALESCE(d.provider, ''),
+		        d.poll_interval, d.last_polled_at, d.expires_at, d.created_at, d.updated_at
+		 FROM device_codes AS d
+		 LEFT JOIN users AS u ON d.user_id = u.id
+		 WHERE d.device_code_hash=$1`
+	if r.ForUpdate {
+		query += " FOR UPDATE OF d"
+	}
+
+	return scanDeviceAuth(s.db.QueryRowContext(ctx, query, r.DeviceCodeHash))
+}
+
+// GetDeviceAuthByUserCode retrieves a device authorization request by its user code
+func (s *PostgresStore) GetDeviceAuthByUserCode(ctx context.Context, r GetDeviceAuthByUserCodeRequest) (DeviceAuth, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT d.id, d.client_id, d.scope, d.user_code, COALESCE(u.uid::text, ''), COALESCE(d.provider, ''),
+		        d.poll_interval, d.last_polled_at, d.expires_at, d.created_at, d.updated_at
+		 FROM device_codes AS d
+		 LEFT JOIN users AS u ON d.user_id = u.id
+		 WHERE d.user_code=$1`, r.UserCode)
+
+	return scanDeviceAuth(row)
+}
+
+// SetDeviceAuthPending records the user who signed in to approve an unexpired device authorization request
+// which is not approved yet, replacing any earlier sign in
+func (s *PostgresStore) SetDeviceAuthPending(ctx context.Context, r SetDeviceAuthPendingRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE device_codes SET pending_user_id=$2, pending_provider=$3, confirm_hash=$4, updated_at=CURRENT_TIMESTAMP
+		 WHERE user_code=$1 AND user_id IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		r.UserCode,
+		r.UserID,
+		r.Provider,
+		r.ConfirmHash)
+	if err != nil {
+		return fmt.Errorf("update device code: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ApproveDeviceAuth binds an unexpired device authorization request to the user who signed in for it,
+// once the user confirmed it with the token matching the confirmation hash
+func (s *PostgresStore) ApproveDeviceAuth(ctx context.Context, r ApproveDeviceAuthRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE device_codes
+		 SET user_id=pending_user_id, provider=pending_provider, confirm_hash=NULL, updated_at=CURRENT_TIMESTAMP
+		 WHERE user_code=$1 AND confirm_hash=$2 AND pending_user_id IS NOT NULL
+		   AND user_id IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		r.UserCode,
+		r.ConfirmHash)
+	if err != nil {
+		return fmt.Errorf("update device code: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateDeviceAuthPoll records a token poll and the polling interval the client must respect
+func (s *PostgresStore) UpdateDeviceAuthPoll(ctx context.Context, r UpdateDeviceAuthPollRequest) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE device_codes SET last_polled_at=$2, poll_interval=$3, updated_at=CURRENT_TIMESTAMP WHERE id=$1",
+		r.ID,
+		r.PolledAt,
+		int64(r.Interval/time.Second))
+	if err != nil {
+		return fmt.Errorf("update device code poll: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeviceAuth deletes a device authorization request, or returns ErrNotFound if it is already gone
+func (s *PostgresStore) DeleteDeviceAuth(ctx context.Context, r DeleteDeviceAuthRequest) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM device_codes WHERE id=$1", r.ID)
+	if err != nil {
+		return fmt.Errorf("delete device code: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanDeviceAuth scans a device_codes row into a DeviceAuth
+func scanDeviceAuth(row *sql.Row) (DeviceAuth, error) {
+	var (
+		da         DeviceAuth
+		interval   int64
+		lastPolled sql.NullTime
+	)
+	err := row.Scan(
+		&da.ID,
+		&da.ClientID,
+		&da.Scope,
+		&da.UserCode,
+		&da.UserUID,
+		&da.Provider,
+		&interval,
+		&lastPolled,
+		&da.ExpiresAt,
+		&da.CreatedAt,
+		&da.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return da, ErrNotFound
+		}
+
+		return da, fmt.Errorf("scan: %w", err)
+	}
+
+	da.Interval = time.Duration(interval) * time.Second
+	da.LastPolledAt = lastPolled.Time
+	return da, nil
+}
+
+// CreateAccessToken stores a new personal access token for the user and returns its ID
+func (s *PostgresStore) CreateAccessToken(ctx context.Context, r CreateAccessTokenRequest) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO access_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		 SELECT u.id, $2, $3, $4, $5, $6 FROM users AS u WHERE u.uid=$1
+		 RETURNING id`,
+		r.UserUID,
+		r.Name,
+		r.TokenHash,
+		r.Prefix,
+		pq.Array(r.Scopes),
+		nullTime(r.ExpiresAt)).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		if isPqErr(err, errUniqueViolation) {
+			return 0, ErrExists
+		}
+
+		return 0, fmt.Errorf("insert access token: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetAccessToken retrieves a personal access token by its hash
+func (s *PostgresStore) GetAccessToken(ctx context.Context, r GetAccessTokenRequest) (AccessToken, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT t.id, u.uid, t.name, t.prefix, t.scopes, t.expires_at, t.last_used_at, t.revoked_at, t.created_at, t.updated_at
+		 FROM access_tokens AS t
+		 JOIN users AS u ON t.user_id = u.id
+		 WHERE t.token_hash=$1`, r.TokenHash)
+
+	tk, err := scanAccessToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tk, ErrNotFound
+		}
+
+		return tk, fmt.Errorf("scan: %w", err)
+	}
+
+	return tk, nil
+}
+
+// ListAccessTokens lists all personal access tokens of the user, newest first
+func (s *PostgresStore) ListAccessTokens(ctx context.Context, r ListAccessTokensRequest) ([]AccessToken, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT t.id, u.uid, t.name, t.prefix, t.scopes, t.expires_at, t.last_used_at, t.revoked_at, t.created_at, t.updated_at
+		 FROM access_tokens AS t
+		 JOIN users AS u ON t.user_id = u.id
+		 WHERE u.uid=$1
+		 ORDER BY t.id DESC`, r.UserUID)
+	if err != nil {
+		return nil, fmt.Errorf("query access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []AccessToken{}
+	for rows.Next() {
+		tk, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		tokens = append(tokens, tk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeAccessToken revokes a personal access token owned by the user
+func (s *PostgresStore) RevokeAccessToken(ctx context.Context, r RevokeAccessTokenRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE access_tokens AS t SET revoked_at=CURRENT_TIMESTAMP, updated_at=CURRENT_TIMESTAMP
+		 FROM users AS u
+		 WHERE t.user_id = u.id AND t.id=$1 AND u.uid=$2 AND t.revoked_at IS NULL`,
+		r.ID,
+		r.UserUID)
+	if err != nil {
+		return fmt.Errorf("update access token: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateAccessTokenUsage records the last time a personal access token was used
+func (s *PostgresStore) UpdateAccessTokenUsage(ctx context.Context, r UpdateAccessTokenUsageRequest) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE access_tokens SET last_used_at=$2 WHERE id=$1", r.ID, r.UsedAt)
+	if err != nil {
+		return fmt.Errorf("update access token usage: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeToken adds a token id to the revocation list until the token expires.
+// Entries of tokens which have already expired are purged on the way.
+func (s *PostgresStore) RevokeToken(ctx context.Context, r RevokeTokenRequest) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO revoked_tokens (token_id, expires_at) VALUES ($1, $2)
+		 ON CONFLICT (token_id) DO NOTHING`,
+		r.TokenID,
+		r.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert revoked token: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP")
+	if err != nil {
+		return fmt.Errorf("purge revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the token id is on the revocation list
+func (s *PostgresStore) IsTokenRevoked(ctx context.Context, r IsTokenRevokedRequest) (bool, error) {
+	var revoked bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id=$1)",
+		r.TokenID).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("query revoked token: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// GetProfile retrieves the profile of the user. Users without a stored profile get the zero profile.
+func (s *PostgresStore) GetProfile(ctx context.Context, r GetProfileRequest) (Profile, error) {
+	query := `SELECT u.uid, COALESCE(p.display_name, ''), COALESCE(p.native_lang, ''), COALESCE(p.target_langs, '{}'),
+		        COALESCE(p.daily_goal, 0), COALESCE(p.timezone, ''), p.created_at, p.updated_at
+		 FROM users AS u
+		 LEFT JOIN profiles AS p ON p.user_id = u.id
+		 WHERE u.uid=$1`
+	if r.ForUpdate {
+		// the profile may not exist yet, so the user is locked instead
+		query += " FOR UPDATE OF u"
+	}
+	row := s.db.QueryRowContext(ctx, query, r.UserUID)
+
+	var (
+		p                    Profile
+		createdAt, updatedAt sql.NullTime
+	)
+	err := row.Scan(
+		&p.UserUID,
+		&p.DisplayName,
+		&p.NativeLang,
+		pq.Array(&p.TargetLangs),
+		&p.DailyGoal,
+		&p.Timezone,
+		&createdAt,
+		&updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return p, ErrNotFound
+		}
+
+		return p, fmt.Errorf("scan: %w", err)
+	}
+
+	p.CreatedAt = createdAt.Time
+	p.UpdatedAt = updatedAt.Time
+	return p, nil
+}
+
+// UpdateProfile creates or replaces the profile of the user
+func (s *PostgresStore) UpdateProfile(ctx context.Context, r UpdateProfileRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO profiles (user_id, display_name, native_lang, target_langs, daily_goal, timezone)
+		 SELECT u.id, $2, $3, $4, $5, $6 FROM users AS u WHERE u.uid=$1
+		 ON CONFLICT (user_id) DO UPDATE SET
+		     display_name=EXCLUDED.display_name,
+		     native_lang=EXCLUDED.native_lang,
+		     target_langs=EXCLUDED.target_langs,
+		     daily_goal=EXCLUDED.daily_goal,
+		     timezone=EXCLUDED.timezone,
+		     updated_at=CURRENT_TIMESTAMP`,
+		r.UserUID,
+		r.DisplayName,
+		r.NativeLang,
+		pq.Array(r.TargetLangs),
+		r.DailyGoal,
+		r.Timezone)
+	if err != nil {
+		return fmt.Errorf("upsert profile: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateAuditEvent records an audit event. The user UID is kept as it is, so that the events
+// of a user outlive the user.
+func (s *PostgresStore) CreateAuditEvent(ctx context.Context, r CreateAuditEventRequest) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO audit_events (type, user_uid, provider, ip, user_agent, detail, created_at)
+		 VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6, $7)`,
+		r.Type,
+		r.UserUID,
+		r.Provider,
+		r.IP,
+		r.UserAgent,
+		r.Detail,
+		r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert audit event: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditEvents lists audit events in the half-open time range [From, To), newest first
+func (s *PostgresStore) ListAuditEvents(ctx context.Context, r ListAuditEventsRequest) ([]AuditEvent, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT e.id, COALESCE(e.user_uid::text, ''), e.type, e.provider, e.ip, e.user_agent, e.detail, e.created_at
+		 FROM audit_events AS e
+		 WHERE ($1 = '' OR e.user_uid = NULLIF($1, '')::uuid)
+		   AND ($2 = '' OR e.type = $2)
+		   AND ($3::timestamptz IS NULL OR e.created_at >= $3)
+		   AND ($4::timestamptz IS NULL OR e.created_at < $4)
+		   AND ($5 = 0 OR e.id < $5)
+		 ORDER BY e.id DESC
+		 LIMIT $6`,
+		r.UserUID,
+		r.Type,
+		nullTime(r.From),
+		nullTime(r.To),
+		r.BeforeID,
+		r.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		var e AuditEvent
+		err := rows.Scan(
+			&e.ID,
+			&e.UserUID,
+			&e.Type,
+			&e.Provider,
+			&e.IP,
+			&e.UserAgent,
+			&e.Detail,
+			&e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// userColumns selects a user, see scanUser
+const userColumns = `u.id, u.uid, u.role, u.disabled_at, u.sessions_revoked_at, u.created_at, u.updated_at`
+
+// GetUser retrieves a user by its UID
+func (s *PostgresStore) GetUser(ctx context.Context, r GetUserRequest) (User, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users AS u WHERE u.uid=$1`, r.UID)
+
+	u, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return u, ErrNotFound
+		}
+
+		return u, fmt.Errorf("scan: %w", err)
+	}
+
+	return u, nil
+}
+
+// ListUsers lists users ordered by their ID. When filters are given, only users having
+// an identity whose email starts with Email and whose provider equals Provider are listed.
+func (s *PostgresStore) ListUsers(ctx context.Context, r ListUsersRequest) ([]User, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+userColumns+`
+		 FROM users AS u
+		 WHERE u.id > $1
+		   AND (($2 = '' AND $3 = '') OR EXISTS (
+		       SELECT 1 FROM identities AS i
+		       WHERE i.user_id = u.id
+		         AND ($2 = '' OR LOWER(i.email) LIKE LOWER($2) || '%')
+		         AND ($3 = '' OR i.provider = $3)))
+		 ORDER BY u.id
+		 LIMIT $4`,
+		r.AfterID,
+		escapeLike(r.Email),
+		r.Provider,
+		r.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// ListUserIdentities lists all identities linked to the user
+func (s *PostgresStore) ListUserIdentities(ctx context.Context, r ListUserIdentitiesRequest) ([]Identity, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+identityColumns+`
+		 FROM identities AS i
+		 JOIN users AS u ON i.user_id = u.id
+		 WHERE u.uid=$1
+		 ORDER BY i.created_at`, r.UserUID)
+	if err != nil {
+		return nil, fmt.Errorf("query identities: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []Identity{}
+	for rows.Next() {
+		id, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate identities: %w", err)
+	}
+
+	return ids, nil
+}
+
+// UpdateUserStatus disables the user, or enables it again when DisabledAt is zero
+func (s *PostgresStore) UpdateUserStatus(ctx context.Context, r UpdateUserStatusRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE users SET disabled_at=$2, updated_at=CURRENT_TIMESTAMP WHERE uid=$1",
+		r.UID,
+		nullTime(r.DisabledAt))
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions invalidates all tokens of the user issued before RevokedAt
+// and revokes all of its personal access tokens
+func (s *PostgresStore) RevokeUserSessions(ctx context.Context, r RevokeUserSessionsRequest) error {
+	var id int64
+	err := s.db.QueryRowContext(ctx,
+		"UPDATE users SET sessions_revoked_at=$2, updated_at=CURRENT_TIMESTAMP WHERE uid=$1 RETURNING id",
+		r.UID,
+		r.RevokedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE access_tokens SET revoked_at=$2, updated_at=CURRENT_TIMESTAMP
+		 WHERE user_id=$1 AND revoked_at IS NULL`,
+		id,
+		r.RevokedAt)
+	if err != nil {
+		return fmt.Errorf("revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// ListRevokedSessions lists the users whose sessions have been revoked after Since,
+// including the users deleted after Since
+func (s *PostgresStore) ListRevokedSessions(ctx context.Context, r ListRevokedSessionsRequest) ([]RevokedSession, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT uid, sessions_revoked_at FROM users WHERE sessions_revoked_at > $1
+		 UNION ALL
+		 SELECT uid, deleted_at FROM deleted_users WHERE deleted_at > $1
+		 ORDER BY 2`, r.Since)
+	if err != nil {
+		return nil, fmt.Errorf("query revoked sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []RevokedSession{}
+	for rows.Next() {
+		var rs RevokedSession
+		if err := rows.Scan(&rs.UserUID, &rs.RevokedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		sessions = append(sessions, rs)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate revoked sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// DeleteUser deletes the user. Identities, profiles, personal access tokens
+// and pending device authorizations of the user are deleted with it. The user is
+// recorded in deleted_users, so that ListRevokedSessions keeps reporting it.
+func (s *PostgresStore) DeleteUser(ctx context.Context, r DeleteUserRequest) error {
+	res, err := s.db.ExecContext(ctx,
+		`WITH deleted AS (DELETE FROM users WHERE uid=$1 RETURNING uid)
+		 INSERT INTO deleted_users (uid, deleted_at) SELECT uid, $2 FROM deleted`,
+		r.UID,
+		r.DeletedAt)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans the userColumns of a row into a User
+func scanUser(row rowScanner) (User, error) {
+	var (
+		u                     User
+		disabledAt, revokedAt sql.NullTime
+	)
+	err := row.Scan(
+		&u.ID,
+		&u.UID,
+		&u.Role,
+		&disabledAt,
+		&revokedAt,
+		&u.CreatedAt,
+		&u.UpdatedAt)
+	if err != nil {
+		return u, err
+	}
+
+	u.DisabledAt = disabledAt.Time
+	u.SessionsRevokedAt = revokedAt.Time
+	return u, nil
+}
+
+// scanIdentity scans the identityColumns of a row into an Identity
+func scanIdentity(row rowScanner) (Identity, error) {
+	var (
+		id                    Identity
+		disabledAt, revokedAt sql.NullTime
+	)
+	err := row.Scan(
+		&id.ID,
+		&id.Provider,
+		&id.Email,
+		&id.Name,
+		&id.Picture,
+		&id.CreatedAt,
+		&id.UpdatedAt,
+		&id.User.ID,
+		&id.User.UID,
+		&id.User.Role,
+		&disabledAt,
+		&revokedAt,
+		&id.User.CreatedAt,
+		&id.User.UpdatedAt)
+	if err != nil {
+		return id, err
+	}
+
+	id.User.DisabledAt = disabledAt.Time
+	id.User.SessionsRevokedAt = revokedAt.Time
+	return id, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// scanAccessToken scans an access_tokens row into an AccessToken
+func scanAccessToken(row rowScanner) (AccessToken, error) {
+	var (
+		tk                            AccessToken
+		expiresAt, lastUsed, revokeAt sql.NullTime
+	)
+	err := row.Scan(
+		&tk.ID,
+		&tk.UserUID,
+		&tk.Name,
+		&tk.Prefix,
+		pq.Array(&tk.Scopes),
+		&expiresAt,
+		&lastUsed,
+		&revokeAt,
+		&tk.CreatedAt,
+		&tk.UpdatedAt)
+	if err != nil {
+		return tk, err
+	}
+
+	tk.ExpiresAt = expiresAt.Time
+	tk.LastUsedAt = lastUsed.Time
+	tk.RevokedAt = revokeAt.Time
+	return tk, nil
+}
+
+// nullTime maps the zero time to a SQL NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
 // WithTx executes the given function within a database transaction
 func (s *PostgresStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
 	db, ok := s.db.(*sql.DB)
@@ -175,3 +887,13 @@ func (s *PostgresStore) WithTx(ctx context.Context, fn func(tx Store) error) err
 
 	return nil
 }
+
+// isPqErr reports whether err is a Postgres error with the given code
+func isPqErr(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == code
+}
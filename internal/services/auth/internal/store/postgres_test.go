@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	testdb "github.com/gamma-omg/lexi-go/internal/pkg/test/db"
 	"github.com/stretchr/testify/assert"
@@ -168,3 +169,541 @@ func TestWithTxRollback(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func TestCreateDeviceAuth(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	expiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	id, err := pgs.CreateDeviceAuth(t.Context(), CreateDeviceAuthRequest{
+		DeviceCodeHash: "device_hash",
+		UserCode:       "BCDFGHJK",
+		ClientID:       "tv-app",
+		Scope:          "words",
+		Interval:       5 * time.Second,
+		ExpiresAt:      expiresAt,
+	})
+	require.NoError(t, err)
+
+	da, err := pgs.GetDeviceAuth(t.Context(), GetDeviceAuthRequest{DeviceCodeHash: "device_hash"})
+	require.NoError(t, err)
+
+	assert.Equal(t, id, da.ID)
+	assert.Equal(t, "BCDFGHJK", da.UserCode)
+	assert.Equal(t, "tv-app", da.ClientID)
+	assert.Equal(t, "words", da.Scope)
+	assert.Equal(t, 5*time.Second, da.Interval)
+	assert.True(t, expiresAt.Equal(da.ExpiresAt))
+	assert.True(t, da.LastPolledAt.IsZero())
+	assert.False(t, da.Approved())
+}
+
+func TestCreateDeviceAuth_Exists(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	req := CreateDeviceAuthRequest{
+		DeviceCodeHash: "device_hash",
+		UserCode:       "BCDFGHJK",
+		ClientID:       "tv-app",
+		Interval:       5 * time.Second,
+		ExpiresAt:      time.Now().Add(10 * time.Minute),
+	}
+	_, err := pgs.CreateDeviceAuth(t.Context(), req)
+	require.NoError(t, err)
+
+	req.DeviceCodeHash = "other_hash"
+	_, err = pgs.CreateDeviceAuth(t.Context(), req)
+	require.ErrorIs(t, err, ErrExists)
+}
+
+func TestGetDeviceAuth_NotFound(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	_, err := pgs.GetDeviceAuth(t.Context(), GetDeviceAuthRequest{DeviceCodeHash: "missing"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestApproveDeviceAuth(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		_       = testdb.Query(t, db, "INSERT INTO device_codes (device_code_hash, user_code, client_id, poll_interval, expires_at) VALUES ($1, $2, $3, $4, $5)",
+			"device_hash",
+			"BCDFGHJK",
+			"tv-app",
+			5,
+			time.Now().Add(time.Minute))
+	)
+
+	// the sign in alone does not approve the request
+	err := pgs.ApproveDeviceAuth(t.Context(), ApproveDeviceAuthRequest{UserCode: "BCDFGHJK", ConfirmHash: "confirm_hash"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = pgs.SetDeviceAuthPending(t.Context(), SetDeviceAuthPendingRequest{
+		UserCode:    "BCDFGHJK",
+		UserID:      userID,
+		Provider:    "google",
+		ConfirmHash: "confirm_hash",
+	})
+	require.NoError(t, err)
+
+	da, err := pgs.GetDeviceAuthByUserCode(t.Context(), GetDeviceAuthByUserCodeRequest{UserCode: "BCDFGHJK"})
+	require.NoError(t, err)
+	assert.False(t, da.Approved())
+
+	err = pgs.ApproveDeviceAuth(t.Context(), ApproveDeviceAuthRequest{UserCode: "BCDFGHJK", ConfirmHash: "forged_hash"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = pgs.ApproveDeviceAuth(t.Context(), ApproveDeviceAuthRequest{UserCode: "BCDFGHJK", ConfirmHash: "confirm_hash"})
+	require.NoError(t, err)
+
+	da, err = pgs.GetDeviceAuthByUserCode(t.Context(), GetDeviceAuthByUserCodeRequest{UserCode: "BCDFGHJK"})
+	require.NoError(t, err)
+	assert.True(t, da.Approved())
+	assert.Equal(t, userUID, da.UserUID)
+	assert.Equal(t, "google", da.Provider)
+
+	// the confirmation token is spent
+	err = pgs.ApproveDeviceAuth(t.Context(), ApproveDeviceAuthRequest{UserCode: "BCDFGHJK", ConfirmHash: "confirm_hash"})
+	require.ErrorIs(t, err, ErrNotFound)
+	err = pgs.SetDeviceAuthPending(t.Context(), SetDeviceAuthPendingRequest{UserCode: "BCDFGHJK", UserID: userID, Provider: "google", ConfirmHash: "other"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestApproveDeviceAuth_Expired(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO device_codes (device_code_hash, user_code, client_id, poll_interval, expires_at, pending_user_id, pending_provider, confirm_hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			"device_hash",
+			"BCDFGHJK",
+			"tv-app",
+			5,
+			time.Now().Add(-time.Minute),
+			userID,
+			"google",
+			"confirm_hash")
+	)
+
+	err := pgs.SetDeviceAuthPending(t.Context(), SetDeviceAuthPendingRequest{UserCode: "BCDFGHJK", UserID: userID, Provider: "google", ConfirmHash: "confirm_hash"})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = pgs.ApproveDeviceAuth(t.Context(), ApproveDeviceAuthRequest{UserCode: "BCDFGHJK", ConfirmHash: "confirm_hash"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdateDeviceAuthPoll(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	id := testdb.Query(t, db, "INSERT INTO device_codes (device_code_hash, user_code, client_id, poll_interval, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		"device_hash",
+		"BCDFGHJK",
+		"tv-app",
+		5,
+		time.Now().Add(time.Minute)).AsInt64()
+
+	polledAt := time.Now().Truncate(time.Second)
+	err := pgs.UpdateDeviceAuthPoll(t.Context(), UpdateDeviceAuthPollRequest{
+		ID:       id,
+		PolledAt: polledAt,
+		Interval: 10 * time.Second,
+	})
+	require.NoError(t, err)
+
+	da, err := pgs.GetDeviceAuth(t.Context(), GetDeviceAuthRequest{DeviceCodeHash: "device_hash"})
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, da.Interval)
+	assert.True(t, polledAt.Equal(da.LastPolledAt))
+}
+
+func TestDeleteDeviceAuth(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	id := testdb.Query(t, db, "INSERT INTO device_codes (device_code_hash, user_code, client_id, poll_interval, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		"device_hash",
+		"BCDFGHJK",
+		"tv-app",
+		5,
+		time.Now().Add(time.Minute)).AsInt64()
+
+	err := pgs.DeleteDeviceAuth(t.Context(), DeleteDeviceAuthRequest{ID: id})
+	require.NoError(t, err)
+
+	var count int
+	err = db.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM device_codes WHERE id=$1", id).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	err = pgs.DeleteDeviceAuth(t.Context(), DeleteDeviceAuthRequest{ID: id})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+	)
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	id, err := pgs.CreateAccessToken(t.Context(), CreateAccessTokenRequest{
+		UserUID:   userUID,
+		Name:      "script",
+		TokenHash: "token_hash",
+		Prefix:    "lxp_abcd",
+		Scopes:    []string{"words:read", "words:write"},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+
+	tk, err := pgs.GetAccessToken(t.Context(), GetAccessTokenRequest{TokenHash: "token_hash"})
+	require.NoError(t, err)
+
+	assert.Equal(t, id, tk.ID)
+	assert.Equal(t, userUID, tk.UserUID)
+	assert.Equal(t, "script", tk.Name)
+	assert.Equal(t, "lxp_abcd", tk.Prefix)
+	assert.Equal(t, []string{"words:read", "words:write"}, tk.Scopes)
+	assert.True(t, expiresAt.Equal(tk.ExpiresAt))
+	assert.True(t, tk.RevokedAt.IsZero())
+	assert.True(t, tk.Active(time.Now()))
+}
+
+func TestCreateAccessToken_UnknownUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	_, err := pgs.CreateAccessToken(t.Context(), CreateAccessTokenRequest{
+		UserUID:   "00000000-0000-0000-0000-000000000000",
+		Name:      "script",
+		TokenHash: "token_hash",
+		Prefix:    "lxp_abcd",
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListAccessTokens(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		otherID = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4)", userID, "first", "hash_1", "lxp_1")
+		_       = testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4)", userID, "second", "hash_2", "lxp_2")
+		_       = testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4)", otherID, "other", "hash_3", "lxp_3")
+	)
+
+	tokens, err := pgs.ListAccessTokens(t.Context(), ListAccessTokensRequest{UserUID: userUID})
+	require.NoError(t, err)
+
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "second", tokens[0].Name)
+	assert.Equal(t, "first", tokens[1].Name)
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		tokenID = testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4) RETURNING id", userID, "script", "token_hash", "lxp_1").AsInt64()
+	)
+
+	err := pgs.RevokeAccessToken(t.Context(), RevokeAccessTokenRequest{ID: tokenID, UserUID: userUID})
+	require.NoError(t, err)
+
+	tk, err := pgs.GetAccessToken(t.Context(), GetAccessTokenRequest{TokenHash: "token_hash"})
+	require.NoError(t, err)
+	assert.False(t, tk.RevokedAt.IsZero())
+	assert.False(t, tk.Active(time.Now()))
+
+	err = pgs.RevokeAccessToken(t.Context(), RevokeAccessTokenRequest{ID: tokenID, UserUID: userUID})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRevokeAccessToken_OtherUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID   = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		otherID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		otherUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", otherID).AsString()
+		tokenID  = testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4) RETURNING id", userID, "script", "token_hash", "lxp_1").AsInt64()
+	)
+
+	err := pgs.RevokeAccessToken(t.Context(), RevokeAccessTokenRequest{ID: tokenID, UserUID: otherUID})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRevokeToken(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	revoked, err := pgs.IsTokenRevoked(t.Context(), IsTokenRevokedRequest{TokenID: "token_1"})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	req := RevokeTokenRequest{TokenID: "token_1", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, pgs.RevokeToken(t.Context(), req))
+	require.NoError(t, pgs.RevokeToken(t.Context(), req))
+
+	revoked, err = pgs.IsTokenRevoked(t.Context(), IsTokenRevokedRequest{TokenID: "token_1"})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRevokeToken_PurgesExpired(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	testdb.Query(t, db, "INSERT INTO revoked_tokens (token_id, expires_at) VALUES ($1, $2)", "expired", time.Now().Add(-time.Hour))
+
+	err := pgs.RevokeToken(t.Context(), RevokeTokenRequest{TokenID: "token_1", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	revoked, err := pgs.IsTokenRevoked(t.Context(), IsTokenRevokedRequest{TokenID: "expired"})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestGetProfile_Default(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+	)
+
+	p, err := pgs.GetProfile(t.Context(), GetProfileRequest{UserUID: userUID})
+	require.NoError(t, err)
+	assert.Equal(t, userUID, p.UserUID)
+	assert.Empty(t, p.NativeLang)
+	assert.Empty(t, p.TargetLangs)
+	assert.True(t, p.UpdatedAt.IsZero())
+}
+
+func TestUpdateProfile(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+	)
+
+	req := UpdateProfileRequest{
+		UserUID:     userUID,
+		DisplayName: "Ann",
+		NativeLang:  "en",
+		TargetLangs: []string{"de", "fr"},
+		DailyGoal:   20,
+		Timezone:    "Europe/Berlin",
+	}
+	require.NoError(t, pgs.UpdateProfile(t.Context(), req))
+
+	req.DailyGoal = 30
+	require.NoError(t, pgs.UpdateProfile(t.Context(), req))
+
+	p, err := pgs.GetProfile(t.Context(), GetProfileRequest{UserUID: userUID})
+	require.NoError(t, err)
+	assert.Equal(t, "Ann", p.DisplayName)
+	assert.Equal(t, "en", p.NativeLang)
+	assert.Equal(t, []string{"de", "fr"}, p.TargetLangs)
+	assert.Equal(t, 30, p.DailyGoal)
+	assert.Equal(t, "Europe/Berlin", p.Timezone)
+
+	req.TargetLangs = []string{}
+	err = pgs.WithTx(t.Context(), func(tx Store) error {
+		if _, err := tx.GetProfile(t.Context(), GetProfileRequest{UserUID: userUID, ForUpdate: true}); err != nil {
+			return err
+		}
+		return tx.UpdateProfile(t.Context(), req)
+	})
+	require.NoError(t, err)
+
+	p, err = pgs.GetProfile(t.Context(), GetProfileRequest{UserUID: userUID})
+	require.NoError(t, err)
+	assert.Empty(t, p.TargetLangs)
+}
+
+func TestUpdateProfile_UserNotFound(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	err := pgs.UpdateProfile(t.Context(), UpdateProfileRequest{UserUID: "00000000-0000-0000-0000-000000000000"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListUserIdentities(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+	)
+	testdb.Query(t, db, "INSERT INTO identities (id, user_id, provider, email) VALUES ($1, $2, $3, $4) RETURNING id", "google-1", userID, "google", "user@example.com").AsString()
+
+	ids, err := pgs.ListUserIdentities(t.Context(), ListUserIdentitiesRequest{UserUID: userUID})
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Equal(t, "google-1", ids[0].ID)
+	assert.Equal(t, "user@example.com", ids[0].Email)
+	assert.Empty(t, ids[0].Name)
+}
+
+func TestDeleteUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+	)
+	testdb.Query(t, db, "INSERT INTO identities (id, user_id, provider, email) VALUES ($1, $2, $3, $4) RETURNING id", "google-1", userID, "google", "user@example.com").AsString()
+	testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4) RETURNING id", userID, "script", "token_hash", "lxp_1").AsInt64()
+	require.NoError(t, pgs.UpdateProfile(t.Context(), UpdateProfileRequest{UserUID: userUID, NativeLang: "en"}))
+
+	deletedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, pgs.DeleteUser(t.Context(), DeleteUserRequest{UID: userUID, DeletedAt: deletedAt}))
+
+	_, err := pgs.GetUser(t.Context(), GetUserRequest{UID: userUID})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// the deleted user stays on the denylist after the users row is gone
+	sessions, err := pgs.ListRevokedSessions(t.Context(), ListRevokedSessionsRequest{Since: deletedAt.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, userUID, sessions[0].UserUID)
+	assert.True(t, deletedAt.Equal(sessions[0].RevokedAt))
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM identities WHERE user_id=$1", userID).AsInt64())
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM access_tokens WHERE user_id=$1", userID).AsInt64())
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM profiles WHERE user_id=$1", userID).AsInt64())
+
+	err = pgs.DeleteUser(t.Context(), DeleteUserRequest{UID: userUID, DeletedAt: deletedAt})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListUsers(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		annID = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		bobID = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+	)
+	testdb.Query(t, db, "INSERT INTO identities (id, user_id, provider, email) VALUES ($1, $2, $3, $4) RETURNING id", "google-1", annID, "google", "Ann@example.com").AsString()
+	testdb.Query(t, db, "INSERT INTO identities (id, user_id, provider, email) VALUES ($1, $2, $3, $4) RETURNING id", "github-1", bobID, "github", "bob@example.com").AsString()
+
+	users, err := pgs.ListUsers(t.Context(), ListUsersRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, annID, users[0].ID)
+	assert.Equal(t, RoleUser, users[0].Role)
+
+	users, err = pgs.ListUsers(t.Context(), ListUsersRequest{Email: "ann@", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, annID, users[0].ID)
+
+	users, err = pgs.ListUsers(t.Context(), ListUsersRequest{Provider: "github", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, bobID, users[0].ID)
+
+	users, err = pgs.ListUsers(t.Context(), ListUsersRequest{Email: "%", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	users, err = pgs.ListUsers(t.Context(), ListUsersRequest{AfterID: annID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, bobID, users[0].ID)
+}
+
+func TestUpdateUserStatus(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		now     = time.Now().UTC().Truncate(time.Second)
+	)
+
+	require.NoError(t, pgs.UpdateUserStatus(t.Context(), UpdateUserStatusRequest{UID: userUID, DisabledAt: now}))
+	u, err := pgs.GetUser(t.Context(), GetUserRequest{UID: userUID})
+	require.NoError(t, err)
+	assert.True(t, u.Disabled())
+	assert.True(t, now.Equal(u.DisabledAt))
+
+	require.NoError(t, pgs.UpdateUserStatus(t.Context(), UpdateUserStatusRequest{UID: userUID}))
+	u, err = pgs.GetUser(t.Context(), GetUserRequest{UID: userUID})
+	require.NoError(t, err)
+	assert.False(t, u.Disabled())
+
+	err = pgs.UpdateUserStatus(t.Context(), UpdateUserStatusRequest{UID: "00000000-0000-0000-0000-000000000000"})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		now     = time.Now().UTC().Truncate(time.Second)
+	)
+	testdb.Query(t, db, "INSERT INTO access_tokens (user_id, name, token_hash, prefix) VALUES ($1, $2, $3, $4) RETURNING id", userID, "script", "token_hash", "lxp_1").AsInt64()
+
+	require.NoError(t, pgs.RevokeUserSessions(t.Context(), RevokeUserSessionsRequest{UID: userUID, RevokedAt: now}))
+
+	u, err := pgs.GetUser(t.Context(), GetUserRequest{UID: userUID})
+	require.NoError(t, err)
+	assert.True(t, now.Equal(u.SessionsRevokedAt))
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM access_tokens WHERE user_id=$1 AND revoked_at IS NULL", userID).AsInt64())
+
+	sessions, err := pgs.ListRevokedSessions(t.Context(), ListRevokedSessionsRequest{Since: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, userUID, sessions[0].UserUID)
+
+	sessions, err = pgs.ListRevokedSessions(t.Context(), ListRevokedSessionsRequest{Since: now})
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	err = pgs.RevokeUserSessions(t.Context(), RevokeUserSessionsRequest{UID: "00000000-0000-0000-0000-000000000000", RevokedAt: now})
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAuditEvents(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+	var (
+		userID  = testdb.Query(t, db, "INSERT INTO users DEFAULT VALUES RETURNING id").AsInt64()
+		userUID = testdb.Query(t, db, "SELECT uid FROM users WHERE id=$1", userID).AsString()
+		now     = time.Now().UTC().Truncate(time.Second)
+	)
+
+	require.NoError(t, pgs.CreateAuditEvent(t.Context(), CreateAuditEventRequest{
+		Type:      "login_failed",
+		Provider:  "google",
+		IP:        "10.0.0.1",
+		CreatedAt: now.Add(-time.Hour),
+	}))
+	require.NoError(t, pgs.CreateAuditEvent(t.Context(), CreateAuditEventRequest{
+		Type:      "login_succeeded",
+		UserUID:   userUID,
+		Provider:  "google",
+		IP:        "10.0.0.2",
+		UserAgent: "curl/8.0",
+		CreatedAt: now,
+	}))
+
+	events, err := pgs.ListAuditEvents(t.Context(), ListAuditEventsRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "login_succeeded", events[0].Type)
+	assert.Equal(t, userUID, events[0].UserUID)
+	assert.Equal(t, "curl/8.0", events[0].UserAgent)
+	assert.Empty(t, events[1].UserUID)
+
+	events, err = pgs.ListAuditEvents(t.Context(), ListAuditEventsRequest{UserUID: userUID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	events, err = pgs.ListAuditEvents(t.Context(), ListAuditEventsRequest{From: now.Add(-2 * time.Hour), To: now, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "login_failed", events[0].Type)
+
+	events, err = pgs.ListAuditEvents(t.Context(), ListAuditEventsRequest{Type: "login_failed", BeforeID: events[0].ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// the events of a user outlive the user
+	require.NoError(t, pgs.DeleteUser(t.Context(), DeleteUserRequest{UID: userUID, DeletedAt: now}))
+	events, err = pgs.ListAuditEvents(t.Context(), ListAuditEventsRequest{UserUID: userUID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, userUID, events[0].UserUID)
+}
@@ -3,10 +3,12 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
 	ErrNotFound = errors.New("not found")
+	ErrExists   = errors.New("already exists")
 )
 
 // Store defines the interface for user and identity storage
@@ -15,6 +17,31 @@ type Store interface {
 	GetUserIdentity(ctx context.Context, r GetUserIdentityRequest) (Identity, error)
 	CreateUser(ctx context.Context) (int64, error)
 	CreateUserIdentity(ctx context.Context, r CreateUserIdentityRequest) (string, error)
+	CreateDeviceAuth(ctx context.Context, r CreateDeviceAuthRequest) (int64, error)
+	GetDeviceAuth(ctx context.Context, r GetDeviceAuthRequest) (DeviceAuth, error)
+	GetDeviceAuthByUserCode(ctx context.Context, r GetDeviceAuthByUserCodeRequest) (DeviceAuth, error)
+	SetDeviceAuthPending(ctx context.Context, r SetDeviceAuthPendingRequest) error
+	ApproveDeviceAuth(ctx context.Context, r ApproveDeviceAuthRequest) error
+	UpdateDeviceAuthPoll(ctx context.Context, r UpdateDeviceAuthPollRequest) error
+	DeleteDeviceAuth(ctx context.Context, r DeleteDeviceAuthRequest) error
+	CreateAccessToken(ctx context.Context, r CreateAccessTokenRequest) (int64, error)
+	GetAccessToken(ctx context.Context, r GetAccessTokenRequest) (AccessToken, error)
+	ListAccessTokens(ctx context.Context, r ListAccessTokensRequest) ([]AccessToken, error)
+	RevokeAccessToken(ctx context.Context, r RevokeAccessTokenRequest) error
+	UpdateAccessTokenUsage(ctx context.Context, r UpdateAccessTokenUsageRequest) error
+	RevokeToken(ctx context.Context, r RevokeTokenRequest) error
+	IsTokenRevoked(ctx context.Context, r IsTokenRevokedRequest) (bool, error)
+	GetProfile(ctx context.Context, r GetProfileRequest) (Profile, error)
+	UpdateProfile(ctx context.Context, r UpdateProfileRequest) error
+	GetUser(ctx context.Context, r GetUserRequest) (User, error)
+	ListUserIdentities(ctx context.Context, r ListUserIdentitiesRequest) ([]Identity, error)
+	DeleteUser(ctx context.Context, r DeleteUserRequest) error
+	ListUsers(ctx context.Context, r ListUsersRequest) ([]User, error)
+	UpdateUserStatus(ctx context.Context, r UpdateUserStatusRequest) error
+	RevokeUserSessions(ctx context.Context, r RevokeUserSessionsRequest) error
+	ListRevokedSessions(ctx context.Context, r ListRevokedSessionsRequest) ([]RevokedSession, error)
+	CreateAuditEvent(ctx context.Context, r CreateAuditEventRequest) error
+	ListAuditEvents(ctx context.Context, r ListAuditEventsRequest) ([]AuditEvent, error)
 	WithTx(ctx context.Context, fn func(tx Store) error) error
 }
 
@@ -36,3 +63,153 @@ type CreateUserIdentityRequest struct {
 	Name     string
 	Picture  string
 }
+
+type CreateDeviceAuthRequest struct {
+	DeviceCodeHash string
+	UserCode       string
+	ClientID       string
+	Scope          string
+	Interval       time.Duration
+	ExpiresAt      time.Time
+}
+
+type GetDeviceAuthRequest struct {
+	DeviceCodeHash string
+	// ForUpdate locks the request until the end of the transaction
+	ForUpdate bool
+}
+
+type GetDeviceAuthByUserCodeRequest struct {
+	UserCode string
+}
+
+// SetDeviceAuthPendingRequest records the user who signed in to approve a device, the approval
+// waits for the user to confirm it with the token whose hash is ConfirmHash
+type SetDeviceAuthPendingRequest struct {
+	UserCode    string
+	UserID      int64
+	Provider    string
+	ConfirmHash string
+}
+
+type ApproveDeviceAuthRequest struct {
+	UserCode    string
+	ConfirmHash string
+}
+
+type UpdateDeviceAuthPollRequest struct {
+	ID       int64
+	PolledAt time.Time
+	Interval time.Duration
+}
+
+type DeleteDeviceAuthRequest struct {
+	ID int64
+}
+
+type CreateAccessTokenRequest struct {
+	UserUID   string
+	Name      string
+	TokenHash string
+	Prefix    string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+type GetAccessTokenRequest struct {
+	TokenHash string
+}
+
+type ListAccessTokensRequest struct {
+	UserUID string
+}
+
+type RevokeAccessTokenRequest struct {
+	ID      int64
+	UserUID string
+}
+
+type UpdateAccessTokenUsageRequest struct {
+	ID     int64
+	UsedAt time.Time
+}
+
+type RevokeTokenRequest struct {
+	TokenID   string
+	ExpiresAt time.Time
+}
+
+type IsTokenRevokedRequest struct {
+	TokenID string
+}
+
+type GetProfileRequest struct {
+	UserUID string
+	// ForUpdate locks the user until the end of the transaction
+	ForUpdate bool
+}
+
+type UpdateProfileRequest struct {
+	UserUID     string
+	DisplayName string
+	NativeLang  string
+	TargetLangs []string
+	DailyGoal   int
+	Timezone    string
+}
+
+type GetUserRequest struct {
+	UID string
+}
+
+type ListUserIdentitiesRequest struct {
+	UserUID string
+}
+
+type DeleteUserRequest struct {
+	UID string
+	// DeletedAt is kept after the user is gone, so that the tokens of the user issued before it stay revoked
+	DeletedAt time.Time
+}
+
+type ListUsersRequest struct {
+	// Email filters users by the prefix of the email of any of their identities
+	Email    string
+	Provider string
+	AfterID  int64
+	Limit    int
+}
+
+type UpdateUserStatusRequest struct {
+	UID        string
+	DisabledAt time.Time
+}
+
+type RevokeUserSessionsRequest struct {
+	UID       string
+	RevokedAt time.Time
+}
+
+type ListRevokedSessionsRequest struct {
+	Since time.Time
+}
+
+type CreateAuditEventRequest struct {
+	Type      string
+	UserUID   string
+	Provider  string
+	IP        string
+	UserAgent string
+	Detail    string
+	CreatedAt time.Time
+}
+
+// ListAuditEventsRequest filters audit events, zero values match everything
+type ListAuditEventsRequest struct {
+	UserUID  string
+	Type     string
+	From     time.Time
+	To       time.Time
+	BeforeID int64
+	Limit    int
+}
@@ -1,5 +1,7 @@
 package token
 
+import "time"
+
 // Type represents the type of token (access or refresh)
 type Type string
 
@@ -16,4 +18,16 @@ type UserClaims struct {
 	Provider string `json:"provider"`
 	Name     string `json:"name"`
 	Picture  string `json:"picture"`
+	Scope    string `json:"scope"`
+
+	// learning preferences of the user, see the profile endpoints
+	NativeLang  string   `json:"native_lang"`
+	TargetLangs []string `json:"target_langs"`
+	DailyGoal   int      `json:"daily_goal"`
+	Timezone    string   `json:"zoneinfo"`
+
+	// TokenID, IssuedAt and ExpiresAt are set by the issuer
+	TokenID   string    `json:"jti"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
 }
@@ -1,6 +1,8 @@
 package token
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -31,6 +33,12 @@ type jwtClaims struct {
 	Provider string `json:"provider"`
 	Name     string `json:"name"`
 	Picture  string `json:"picture"`
+	Scope    string `json:"scope,omitempty"`
+
+	NativeLang  string   `json:"native_lang,omitempty"`
+	TargetLangs []string `json:"target_langs,omitempty"`
+	DailyGoal   int      `json:"daily_goal,omitempty"`
+	Timezone    string   `json:"zoneinfo,omitempty"`
 }
 
 // NewJWTIssuer creates a new JwtIssuer with the given configuration
@@ -45,18 +53,26 @@ func NewJWTIssuer(cfg JwtConfig) *JwtIssuer {
 
 // Issue generates a new JWT token with the given user claims
 func (ti *JwtIssuer) Issue(claims UserClaims) (string, error) {
+	now := time.Now()
 	tk, err := jwt.NewWithClaims(jwt.GetSigningMethod(ti.algorithm), jwtClaims{
 		StandardClaims: jwt.StandardClaims{
-			Id:        claims.ID,
+			Id:        randTokenID(),
+			Subject:   claims.ID,
 			Issuer:    ti.issuer,
-			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: time.Now().Add(ti.ttl).Unix(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ti.ttl).Unix(),
 		},
 		Type:     claims.Type,
 		Email:    claims.Email,
 		Provider: claims.Provider,
 		Name:     claims.Name,
 		Picture:  claims.Picture,
+		Scope:    claims.Scope,
+
+		NativeLang:  claims.NativeLang,
+		TargetLangs: claims.TargetLangs,
+		DailyGoal:   claims.DailyGoal,
+		Timezone:    claims.Timezone,
 	}).SignedString(ti.secret.Get())
 
 	if err != nil {
@@ -83,12 +99,37 @@ func (ti *JwtIssuer) Validate(tokenStr string) (UserClaims, error) {
 		return UserClaims{}, fmt.Errorf("invalid token claims")
 	}
 
+	// tokens issued before the subject claim was introduced carry the user id in jti
+	// and have no token id of their own
+	uid, tokenID := claims.Subject, claims.Id
+	if uid == "" {
+		uid, tokenID = claims.Id, ""
+	}
+
 	return UserClaims{
 		Type:     claims.Type,
-		ID:       claims.Id,
+		ID:       uid,
 		Email:    claims.Email,
 		Provider: claims.Provider,
 		Name:     claims.Name,
 		Picture:  claims.Picture,
+		Scope:    claims.Scope,
+
+		NativeLang:  claims.NativeLang,
+		TargetLangs: claims.TargetLangs,
+		DailyGoal:   claims.DailyGoal,
+		Timezone:    claims.Timezone,
+
+		TokenID:   tokenID,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
 	}, nil
 }
+
+// randTokenID generates a unique token id used to revoke individual tokens
+func randTokenID() string {
+	b := make([]byte, 16)
+	// rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -41,3 +41,92 @@ func TestJWTIssuer(t *testing.T) {
 	assert.Equal(t, "Test User", claims.Name)
 	assert.Equal(t, "http://example.com/pic.jpg", claims.Picture)
 }
+
+func TestJWTIssuer_Subject(t *testing.T) {
+	secret := NewSecretString("test_secret")
+	issuer := NewJWTIssuer(JwtConfig{
+		Issuer:    "test-issuer",
+		Secret:    secret,
+		Algorithm: jwt.SigningMethodHS256.Name,
+		TTL:       time.Hour,
+	})
+
+	tokenStr, err := issuer.Issue(UserClaims{Type: TypeAccess, ID: "user-123"})
+	require.NoError(t, err)
+
+	var claims jwt.StandardClaims
+	_, err = jwt.ParseWithClaims(tokenStr, &claims, func(token *jwt.Token) (interface{}, error) {
+		return secret.Get(), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.Subject)
+}
+
+func TestJWTIssuer_TokenMetadata(t *testing.T) {
+	issuer := NewJWTIssuer(JwtConfig{
+		Issuer:    "test-issuer",
+		Secret:    NewSecretString("test_secret"),
+		Algorithm: jwt.SigningMethodHS256.Name,
+		TTL:       time.Hour,
+	})
+
+	first, err := issuer.Issue(UserClaims{ID: "user-123", Scope: "words:read"})
+	require.NoError(t, err)
+	second, err := issuer.Issue(UserClaims{ID: "user-123"})
+	require.NoError(t, err)
+
+	c1, err := issuer.Validate(first)
+	require.NoError(t, err)
+	c2, err := issuer.Validate(second)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, c1.TokenID)
+	assert.NotEqual(t, c1.TokenID, c2.TokenID)
+	assert.Equal(t, "words:read", c1.Scope)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), c1.ExpiresAt, 5*time.Second)
+	assert.WithinDuration(t, time.Now(), c1.IssuedAt, 5*time.Second)
+}
+
+func TestJWTIssuer_LegacyToken(t *testing.T) {
+	secret := NewSecretString("test_secret")
+	issuer := NewJWTIssuer(JwtConfig{
+		Secret:    secret,
+		Algorithm: jwt.SigningMethodHS256.Name,
+		TTL:       time.Hour,
+	})
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		Id:        "user-123",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret.Get())
+	require.NoError(t, err)
+
+	claims, err := issuer.Validate(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.ID)
+	assert.Empty(t, claims.TokenID)
+}
+
+func TestJWTIssuer_ProfileClaims(t *testing.T) {
+	issuer := NewJWTIssuer(JwtConfig{
+		Secret:    NewSecretString("test_secret"),
+		Algorithm: jwt.SigningMethodHS256.Name,
+		TTL:       time.Hour,
+	})
+
+	tk, err := issuer.Issue(UserClaims{
+		ID:          "user-123",
+		NativeLang:  "en",
+		TargetLangs: []string{"de", "fr"},
+		DailyGoal:   20,
+		Timezone:    "Europe/Berlin",
+	})
+	require.NoError(t, err)
+
+	claims, err := issuer.Validate(tk)
+	require.NoError(t, err)
+	assert.Equal(t, "en", claims.NativeLang)
+	assert.Equal(t, []string{"de", "fr"}, claims.TargetLangs)
+	assert.Equal(t, 20, claims.DailyGoal)
+	assert.Equal(t, "Europe/Berlin", claims.Timezone)
+}
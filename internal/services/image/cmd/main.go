@@ -11,11 +11,14 @@ import (
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
 	"github.com/gamma-omg/lexi-go/internal/pkg/router"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
 	"github.com/gamma-omg/lexi-go/internal/services/image/internal/config"
 	"github.com/gamma-omg/lexi-go/internal/services/image/internal/rest"
 	"github.com/gamma-omg/lexi-go/internal/services/image/internal/service"
 )
 
+const serviceName = "image"
+
 func run(ctx context.Context) error {
 	slog.Info("starting image service")
 
@@ -36,14 +39,15 @@ func run(ctx context.Context) error {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	auth := r.SubRouter("/api/v1/")
-	auth.Use(middleware.Auth(cfg.AuthSecret))
-
 	api := rest.NewAPI(
 		rest.WithImageService(srv),
 		rest.WithMaxImageSize(cfg.ImageStore.MaxSize),
 		rest.WithContentRoot(cfg.ImageStore.Root),
 	)
+
+	// only other services may upload images, serving them stays public
+	serviceAuth := middleware.ServiceAuth(svctoken.NewVerifier(serviceName, []byte(cfg.ServiceSecret)))
+	r.Handle("POST /upload", serviceAuth(api))
 	r.Handle("/", api)
 
 	httpSrv := &http.Server{
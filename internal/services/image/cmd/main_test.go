@@ -11,7 +11,7 @@ import (
 )
 
 func TestRun(t *testing.T) {
-	t.Setenv("AUTH_SECRET", "secret")
+	t.Setenv("SERVICE_SECRET", "secret")
 
 	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
 	defer cancel()
@@ -59,10 +59,15 @@ func TestRun(t *testing.T) {
 			t.Fatal("test timed out")
 		}
 	}
+
+	resp, err := http.Post("http://localhost:8080/upload", "multipart/form-data", nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 }
 
 func TestRun_Cancel(t *testing.T) {
-	t.Setenv("AUTH_SECRET", "secret")
+	t.Setenv("SERVICE_SECRET", "secret")
 
 	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
 	defer cancel()
@@ -8,9 +8,9 @@ import (
 )
 
 type Config struct {
-	AuthSecret string
-	HTTP       httpConfig
-	ImageStore imageConfig
+	ServiceSecret string
+	HTTP          httpConfig
+	ImageStore    imageConfig
 }
 
 type httpConfig struct {
@@ -32,7 +32,7 @@ type imageConfig struct {
 
 func FromEnv() Config {
 	return Config{
-		AuthSecret: env.RequireString("AUTH_SECRET"),
+		ServiceSecret: env.RequireString("SERVICE_SECRET"),
 		HTTP: httpConfig{
 			ListenAddr:      env.String("HTTP_LISTEN_ADDR", ""),
 			ListenPort:      env.Int("HTTP_LISTEN_PORT", 8080),
@@ -15,7 +15,7 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("HTTP_READ_TIMEOUT", "40s")
 	t.Setenv("HTTP_WRITE_TIMEOUT", "50s")
 	t.Setenv("HTTP_SHUTDOWN_TIMEOUT", "15s")
-	t.Setenv("AUTH_SECRET", "supersecret")
+	t.Setenv("SERVICE_SECRET", "supersecret")
 	t.Setenv("IMAGE_MAX_SIZE", "12345")
 	t.Setenv("IMAGE_MAX_WIDTH", "2560")
 	t.Setenv("IMAGE_MAX_HEIGHT", "1440")
@@ -24,7 +24,7 @@ func TestFromEnv(t *testing.T) {
 
 	cfg := config.FromEnv()
 
-	assert.Equal(t, "supersecret", cfg.AuthSecret)
+	assert.Equal(t, "supersecret", cfg.ServiceSecret)
 	assert.Equal(t, int64(12345), cfg.ImageStore.MaxSize)
 	assert.Equal(t, 2560, cfg.ImageStore.MaxWidth)
 	assert.Equal(t, 1440, cfg.ImageStore.MaxHeight)
@@ -39,10 +39,10 @@ func TestFromEnv(t *testing.T) {
 }
 
 func TestFromEnv_Defaults(t *testing.T) {
-	t.Setenv("AUTH_SECRET", "test")
+	t.Setenv("SERVICE_SECRET", "test")
 	cfg := config.FromEnv()
 
-	assert.Equal(t, "test", cfg.AuthSecret)
+	assert.Equal(t, "test", cfg.ServiceSecret)
 	assert.Equal(t, int64(5*1024*1024), cfg.ImageStore.MaxSize)
 	assert.Equal(t, 1920, cfg.ImageStore.MaxWidth)
 	assert.Equal(t, 1080, cfg.ImageStore.MaxHeight)
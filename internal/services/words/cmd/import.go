@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/config"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/importer"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+// runImport imports a dictionary file into the global dictionary, it only needs the database configuration.
+// With a checkpoint file, an import that stopped resumes after the last batch it wrote when run again.
+//
+//	words import [-format csv|jsonl|kaikki|wordnet] [-lang LANG] [-checkpoint FILE] FILE
+func runImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "format of the dictionary, csv, jsonl, kaikki or wordnet, guessed from the file extension when not given")
+	lang := fs.String("lang", "", "language to import from Kaikki extracts of Wiktionary, such as en")
+	checkpoint := fs.String("checkpoint", "", "file keeping the progress of the import, so that it can be resumed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: words import [-format csv|jsonl|kaikki|wordnet] [-lang LANG] [-checkpoint FILE] FILE")
+		fmt.Fprintln(fs.Output(), "imports the dictionary in FILE, or in the standard input when FILE is -")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected one dictionary file")
+	}
+
+	path := fs.Arg(0)
+	f := importer.Format(*format)
+	if f == "" {
+		guessed, ok := importer.FormatOf(path)
+		if !ok {
+			return fmt.Errorf("unknown format of %s, set it with -format", path)
+		}
+		f = guessed
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open dictionary: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	entries, err := importer.NewReader(f, in, importer.Options{Lang: model.Lang(*lang)})
+	if err != nil {
+		return err
+	}
+
+	req := service.ImportDictionaryRequest{Entries: entries}
+	if *checkpoint != "" {
+		req.AfterLine, err = readCheckpoint(*checkpoint)
+		if err != nil {
+			return err
+		}
+		req.Checkpoint = func(line int) error {
+			return writeCheckpoint(*checkpoint, line)
+		}
+	}
+
+	cfg := config.DBFromEnv()
+	db, err := store.NewPostgresDB(store.PostgresConfig{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		User:     cfg.User,
+		Password: cfg.Password,
+		DB:       cfg.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("connect to postgres: %w", err)
+	}
+	defer db.Close()
+
+	srv := service.NewWordsService(store.NewPostgresStore(db), service.WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	slog.Info("importing dictionary", "file", path, "format", f, "after_line", req.AfterLine)
+	report, err := srv.ImportDictionary(ctx, req)
+	for _, e := range report.Errors {
+		slog.Warn("record not imported", "line", e.Line, "error", e.Msg)
+	}
+	slog.Info("dictionary imported",
+		"records", report.Records,
+		"created", report.Created,
+		"skipped", report.Skipped,
+		"failed", report.Failed,
+		"words", report.Words,
+		"definitions", report.Definitions,
+		"images", report.Images,
+		"relations", report.Relations,
+	)
+	if err != nil {
+		return fmt.Errorf("import dictionary: %w", err)
+	}
+
+	return nil
+}
+
+// readCheckpoint returns the line an import stopped after, zero when the checkpoint file does not exist yet
+func readCheckpoint(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	line, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint in %s: %w", path, err)
+	}
+
+	return line, nil
+}
+
+// writeCheckpoint replaces the checkpoint file through a rename, so that it is never left half written
+func writeCheckpoint(path string, line int) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(line)+"\n"), 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -5,19 +5,29 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/pat"
 	"github.com/gamma-omg/lexi-go/internal/pkg/router"
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/config"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/dictionary"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/image"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/rest"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
 )
 
+const (
+	serviceName      = "words"
+	imageServiceName = "image"
+	authServiceName  = "auth"
+)
+
 func run(ctx context.Context) error {
 	slog.Info("starting words service")
 
@@ -34,11 +44,19 @@ func run(ctx context.Context) error {
 	}
 
 	store := store.NewPostgresStore(db)
-	imgStore := image.NewRemoteStore(
-		cfg.Image.Endpoint,
-		cfg.Image.FieldName,
-		cfg.Image.FileName,
-	)
+	signer := svctoken.NewSigner(serviceName, []byte(cfg.Service.Secret), cfg.Service.TokenTTL)
+	imgOpts := []image.RemoteStoreOption{
+		image.WithServiceToken(signer, imageServiceName),
+		image.WithTimeout(cfg.Image.Timeout),
+	}
+	if cfg.Image.ServeRoot != "" {
+		root, err := url.Parse(cfg.Image.ServeRoot)
+		if err != nil {
+			return fmt.Errorf("parse image serve root: %w", err)
+		}
+		imgOpts = append(imgOpts, image.WithServeRoot(root))
+	}
+	imgStore := image.NewRemoteStore(cfg.Image.Endpoint, cfg.Image.FieldName, cfg.Image.FileName, imgOpts...)
 
 	r := router.New()
 	r.Use(
@@ -52,16 +70,56 @@ func run(ctx context.Context) error {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	var authOpts []middleware.AuthOption
+	if cfg.AuthIntrospection.URL != "" {
+		introspector := middleware.NewRemoteIntrospector(
+			cfg.AuthIntrospection.URL,
+			cfg.AuthIntrospection.CacheTTL,
+			middleware.WithIntrospectionToken(signer, authServiceName),
+		)
+		authOpts = append(authOpts, middleware.WithIntrospection(pat.Prefix, introspector))
+	}
+	if cfg.AuthDenylist.URL != "" {
+		denylist := middleware.NewRemoteDenylist(
+			cfg.AuthDenylist.URL,
+			cfg.AuthDenylist.RefreshTTL,
+			middleware.WithDenylistToken(signer, authServiceName),
+		)
+		authOpts = append(authOpts, middleware.WithDenylist(denylist))
+	}
+
 	auth := r.SubRouter("/api/v1/")
-	auth.Use(middleware.Auth(cfg.AuthSecret))
+	auth.Use(
+		middleware.Auth([]byte(cfg.AuthSecret), authOpts...),
+		middleware.RequireScope(pat.ScopeWordsRead, pat.ScopeWordsWrite),
+	)
+
+	dicts, err := openDictionaries(cfg.Dictionaries)
+	if err != nil {
+		return err
+	}
 
 	srv := service.NewWordsService(store, service.WordsServiceConfig{
 		TagsCacheSize: cfg.TagsMaxKeys,
 		TagsMaxCost:   cfg.TagsMaxCost,
+		TagsCacheTTL:  cfg.TagsCacheTTL,
+		CursorSecret:  []byte(cfg.CursorSecret),
+		Dictionaries:  dicts,
 	})
 	api := rest.NewAPI(srv, imgStore)
 	auth.Handle("/", api)
 
+	// endpoints called by other services, such as account export and deletion by the auth service
+	internal := r.SubRouter("/internal/v1/")
+	internal.Use(middleware.ServiceAuth(svctoken.NewVerifier(serviceName, []byte(cfg.Service.Secret))))
+	internal.Handle("/", rest.NewInternalAPI(srv))
+
+	// dictionary maintenance, such as imports, by operators holding a token signed with the admin secret;
+	// the service secret is shared with the other services and must not unlock these endpoints
+	admin := r.SubRouter("/admin/v1/")
+	admin.Use(middleware.ServiceAuth(svctoken.NewVerifier(serviceName, []byte(cfg.Admin.Secret))))
+	admin.Handle("/", rest.NewAdminAPI(srv))
+
 	server := &http.Server{
 		Addr:         cfg.HTTP.ListenAddr,
 		IdleTimeout:  cfg.HTTP.IdleTimeout,
@@ -89,16 +147,49 @@ func run(ctx context.Context) error {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("shutdown HTTP server: %w", err)
 	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("stop import jobs: %w", err)
+	}
 
 	slog.Info("words service stopped")
 	return nil
 }
 
+// openDictionaries opens the offline dictionaries words are looked up in, their names must be unique
+// since definitions are created from their articles by name
+func openDictionaries(paths []string) ([]dictionary.Source, error) {
+	var (
+		dicts []dictionary.Source
+		names = make(map[string]string)
+	)
+	for _, path := range paths {
+		d, err := dictionary.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open dictionary: %w", err)
+		}
+		if other, ok := names[d.Name()]; ok {
+			return nil, fmt.Errorf("dictionaries %s and %s are both named %q", other, path, d.Name())
+		}
+		names[d.Name()] = path
+
+		slog.Info("opened dictionary", "path", path, "name", d.Name(), "lang", d.Lang())
+		dicts = append(dicts, d)
+	}
+
+	return dicts, nil
+}
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	if err := run(ctx); err != nil {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err = runImport(ctx, os.Args[2:])
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
 		slog.Error("words service exited with error", "error", err)
 		os.Exit(1)
 	}
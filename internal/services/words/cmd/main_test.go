@@ -69,6 +69,9 @@ func TestRun(t *testing.T) {
 
 	jwtSecret := "test-secret"
 	t.Setenv("AUTH_SECRET", jwtSecret)
+	t.Setenv("SERVICE_SECRET", "service-secret")
+	t.Setenv("CURSOR_SECRET", "cursor-secret")
+	t.Setenv("ADMIN_SECRET", "admin-secret")
 	t.Setenv("DB_HOST", db.host)
 	t.Setenv("DB_PORT", db.port)
 	t.Setenv("DB_USER", dbCfg.user)
@@ -137,6 +140,9 @@ func TestRun_Cancel(t *testing.T) {
 
 	jwtSecret := "test-secret"
 	t.Setenv("AUTH_SECRET", jwtSecret)
+	t.Setenv("SERVICE_SECRET", "service-secret")
+	t.Setenv("CURSOR_SECRET", "cursor-secret")
+	t.Setenv("ADMIN_SECRET", "admin-secret")
 	t.Setenv("DB_HOST", db.host)
 	t.Setenv("DB_PORT", db.port)
 	t.Setenv("DB_USER", dbCfg.user)
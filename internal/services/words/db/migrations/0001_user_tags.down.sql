@@ -0,0 +1,18 @@
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_user_id_tag_key;
+
+-- tags with the same text are merged into the oldest one
+UPDATE tags_map AS m
+SET tag_id = k.id
+FROM tags AS t, (SELECT tag, MIN(id) AS id FROM tags GROUP BY tag) AS k
+WHERE
+    t.id = m.tag_id AND
+    k.tag = t.tag AND
+    m.tag_id <> k.id;
+
+DELETE FROM tags AS t
+USING (SELECT tag, MIN(id) AS id FROM tags GROUP BY tag) AS k
+WHERE k.tag = t.tag AND t.id <> k.id;
+
+ALTER TABLE tags DROP COLUMN IF EXISTS user_id;
+ALTER TABLE tags ADD CONSTRAINT tags_tag_key UNIQUE (tag);
+CREATE INDEX ON tags(tag);
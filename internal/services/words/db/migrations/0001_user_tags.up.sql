@@ -0,0 +1,26 @@
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS user_id TEXT;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_tag_key;
+DROP INDEX IF EXISTS tags_tag_idx;
+
+-- every user gets its own copy of the global tags it has used
+INSERT INTO tags (user_id, tag)
+SELECT DISTINCT p.user_id, t.tag
+FROM tags_map AS m
+JOIN user_picks AS p ON p.id = m.pick_id
+JOIN tags AS t ON t.id = m.tag_id
+WHERE t.user_id IS NULL;
+
+UPDATE tags_map AS m
+SET tag_id = ut.id
+FROM user_picks AS p, tags AS gt, tags AS ut
+WHERE
+    p.id = m.pick_id AND
+    gt.id = m.tag_id AND
+    gt.user_id IS NULL AND
+    ut.user_id = p.user_id AND
+    ut.tag = gt.tag;
+
+DELETE FROM tags WHERE user_id IS NULL;
+
+ALTER TABLE tags ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE tags ADD CONSTRAINT tags_user_id_tag_key UNIQUE (user_id, tag);
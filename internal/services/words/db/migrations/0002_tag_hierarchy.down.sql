@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tags_parent_id_idx;
+ALTER TABLE tags DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,18 @@
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES tags(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS tags_parent_id_idx ON tags(parent_id);
+
+-- slash separated tags created before the hierarchy get their missing ancestors
+INSERT INTO tags (user_id, tag)
+SELECT DISTINCT t.user_id, array_to_string(s.segments[1:n], '/')
+FROM tags AS t
+CROSS JOIN LATERAL (SELECT string_to_array(t.tag, '/') AS segments) AS s
+CROSS JOIN LATERAL generate_series(1, cardinality(s.segments) - 1) AS n
+ON CONFLICT (user_id, tag) DO NOTHING;
+
+UPDATE tags AS c
+SET parent_id = p.id
+FROM tags AS p
+WHERE
+    p.user_id = c.user_id AND
+    strpos(c.tag, '/') > 0 AND
+    p.tag = regexp_replace(c.tag, '/[^/]*$', '');
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_picks_user_id_next_review_at_idx;
+ALTER TABLE user_picks DROP COLUMN IF EXISTS next_review_at;
@@ -0,0 +1,3 @@
+-- picks that were never scheduled for a review have no next review time
+ALTER TABLE user_picks ADD COLUMN IF NOT EXISTS next_review_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS user_picks_user_id_next_review_at_idx ON user_picks(user_id, next_review_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_picks_user_id_created_at_id_idx;
@@ -0,0 +1,2 @@
+-- keyset pages of picks sorted by creation time are read straight from the index
+CREATE INDEX IF NOT EXISTS user_picks_user_id_created_at_id_idx ON user_picks(user_id, created_at, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS user_picks_user_id_status_idx;
+ALTER TABLE user_picks DROP COLUMN IF EXISTS status;
+DROP TYPE IF EXISTS pick_status;
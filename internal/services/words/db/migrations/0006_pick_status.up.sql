@@ -0,0 +1,16 @@
+DO $$
+BEGIN
+    CREATE TYPE pick_status AS ENUM (
+        'new',
+        'learning',
+        'known',
+        'suspended',
+        'archived'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN
+        NULL;
+END$$;
+
+ALTER TABLE user_picks ADD COLUMN IF NOT EXISTS status pick_status NOT NULL DEFAULT 'new';
+CREATE INDEX IF NOT EXISTS user_picks_user_id_status_idx ON user_picks(user_id, status);
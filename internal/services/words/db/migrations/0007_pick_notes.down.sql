@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_picks_def_id_idx;
+DROP TABLE IF EXISTS note_votes;
+DROP TABLE IF EXISTS note_revisions;
+DROP TABLE IF EXISTS pick_notes;
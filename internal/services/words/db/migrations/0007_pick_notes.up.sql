@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS pick_notes (
+    id SERIAL PRIMARY KEY,
+    pick_id INT NOT NULL UNIQUE,
+    body TEXT NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (pick_id) REFERENCES user_picks(id) ON DELETE CASCADE
+);
+
+-- every body a note had, including the current one
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id SERIAL PRIMARY KEY,
+    note_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (note_id) REFERENCES pick_notes(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS note_revisions_note_id_idx ON note_revisions(note_id, id);
+
+CREATE TABLE IF NOT EXISTS note_votes (
+    note_id INT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (note_id, user_id),
+    FOREIGN KEY (note_id) REFERENCES pick_notes(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS note_votes_user_id_idx ON note_votes(user_id);
+
+-- mnemonics are looked up by the definition of the picks they are written on
+CREATE INDEX IF NOT EXISTS user_picks_def_id_idx ON user_picks(def_id);
@@ -0,0 +1,2 @@
+ALTER TABLE user_picks DROP COLUMN IF EXISTS image_id;
+ALTER TABLE user_picks DROP COLUMN IF EXISTS def_override;
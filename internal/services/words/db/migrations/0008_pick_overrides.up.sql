@@ -0,0 +1,3 @@
+-- personal replacements of the shared definition text and of the default image of the definition
+ALTER TABLE user_picks ADD COLUMN IF NOT EXISTS def_override TEXT;
+ALTER TABLE user_picks ADD COLUMN IF NOT EXISTS image_id INT REFERENCES images(id) ON DELETE SET NULL;
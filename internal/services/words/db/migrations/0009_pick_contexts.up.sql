@@ -0,0 +1,11 @@
+-- the sentences and sources the user came across the word of a pick in
+CREATE TABLE IF NOT EXISTS pick_contexts (
+    id SERIAL PRIMARY KEY,
+    pick_id INT NOT NULL,
+    sentence TEXT NOT NULL,
+    source_title TEXT,
+    source_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (pick_id) REFERENCES user_picks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS pick_contexts_pick_id_idx ON pick_contexts(pick_id, id);
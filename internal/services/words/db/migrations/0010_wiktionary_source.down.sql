@@ -0,0 +1,20 @@
+UPDATE definitions SET source = 'unknown' WHERE source = 'wiktionary';
+UPDATE images SET source = 'unknown' WHERE source = 'wiktionary';
+
+ALTER TYPE source_type RENAME TO source_type_old;
+CREATE TYPE source_type AS ENUM (
+    'unknown',
+    'user',
+    'ai'
+);
+
+ALTER TABLE definitions
+    ALTER COLUMN source DROP DEFAULT,
+    ALTER COLUMN source TYPE source_type USING source::text::source_type,
+    ALTER COLUMN source SET DEFAULT 'unknown';
+ALTER TABLE images
+    ALTER COLUMN source DROP DEFAULT,
+    ALTER COLUMN source TYPE source_type USING source::text::source_type,
+    ALTER COLUMN source SET DEFAULT 'unknown';
+
+DROP TYPE source_type_old;
@@ -0,0 +1 @@
+ALTER TYPE source_type ADD VALUE IF NOT EXISTS 'wiktionary';
@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS definition_relations;
+DROP TYPE IF EXISTS relation_type;
+
+UPDATE definitions SET source = 'unknown' WHERE source = 'wordnet';
+UPDATE images SET source = 'unknown' WHERE source = 'wordnet';
+
+ALTER TYPE source_type RENAME TO source_type_old;
+CREATE TYPE source_type AS ENUM (
+    'unknown',
+    'user',
+    'ai',
+    'wiktionary'
+);
+
+ALTER TABLE definitions
+    ALTER COLUMN source DROP DEFAULT,
+    ALTER COLUMN source TYPE source_type USING source::text::source_type,
+    ALTER COLUMN source SET DEFAULT 'unknown';
+ALTER TABLE images
+    ALTER COLUMN source DROP DEFAULT,
+    ALTER COLUMN source TYPE source_type USING source::text::source_type,
+    ALTER COLUMN source SET DEFAULT 'unknown';
+
+DROP TYPE source_type_old;
@@ -0,0 +1,28 @@
+ALTER TYPE source_type ADD VALUE IF NOT EXISTS 'wordnet';
+
+DO $$
+BEGIN
+    CREATE TYPE relation_type AS ENUM (
+        'synonym',
+        'antonym',
+        'hypernym',
+        'hyponym',
+        'meronym'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN
+        NULL;
+END$$;
+
+CREATE TABLE IF NOT EXISTS definition_relations (
+    id SERIAL PRIMARY KEY,
+    def_id INT NOT NULL,
+    related_def_id INT NOT NULL,
+    type relation_type NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (def_id) REFERENCES definitions(id) ON DELETE CASCADE,
+    FOREIGN KEY (related_def_id) REFERENCES definitions(id) ON DELETE CASCADE,
+    UNIQUE (def_id, related_def_id, type)
+);
+CREATE INDEX IF NOT EXISTS definition_relations_related_def_id_idx ON definition_relations(related_def_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS import_jobs;
+DROP TYPE IF EXISTS import_status;
@@ -0,0 +1,29 @@
+DO $$
+BEGIN
+    CREATE TYPE import_status AS ENUM (
+        'running',
+        'done',
+        'failed'
+    );
+EXCEPTION
+    WHEN duplicate_object THEN
+        NULL;
+END$$;
+
+-- imports of decks from other apps, which run in the background and report their progress here
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    status import_status NOT NULL DEFAULT 'running',
+    total INT NOT NULL DEFAULT 0,
+    cards INT NOT NULL DEFAULT 0,
+    created INT NOT NULL DEFAULT 0,
+    skipped INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS import_jobs_user_id_idx ON import_jobs(user_id);
@@ -0,0 +1,470 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// modelID and deckID are fixed, so that importing a newer export of the same deck updates its notes
+	// rather than adding a second note type and deck
+	modelID int64 = 1700000000001
+	deckID  int64 = 1700000000002
+	// schemaVersion is the version of the collection schema, the one of Anki 2.1 that all versions still import
+	schemaVersion = 11
+	// fieldSeparator separates the fields of a note
+	fieldSeparator = "\x1f"
+)
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Note is a note of the deck, its fields are HTML
+type Note struct {
+	// GUID identifies the note across exports, Anki updates the note with the same GUID on import
+	GUID       string
+	Word       string
+	Definition string
+	Image      string
+	Examples   string
+	// Tags are Anki tags, they cannot contain spaces and use :: to separate the levels of hierarchical tags
+	Tags []string
+}
+
+// Package writes an Anki package, an .apkg file, to a stream. Media files are written as they are added,
+// and the notes are collected in an SQLite collection in a temporary file, which is written along with
+// the list of media files when the package is closed. Every note has a single card.
+type Package struct {
+	zw    *zip.Writer
+	path  string
+	db    *sql.DB
+	tx    *sql.Tx
+	deck  string
+	now   time.Time
+	notes int64
+	// media maps the names of the media entries of the package, their position, to the names of the files
+	media map[string]string
+}
+
+// NewPackage starts writing a package with a deck of the given name to w
+func NewPackage(w io.Writer, deck string) (*Package, error) {
+	f, err := os.CreateTemp("", "anki-*.anki2")
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+	f.Close()
+
+	p := &Package{
+		zw:    zip.NewWriter(w),
+		path:  f.Name(),
+		deck:  deck,
+		now:   time.Now(),
+		media: make(map[string]string),
+	}
+	if err := p.createCollection(); err != nil {
+		p.Discard()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// AddMedia adds a media file, such as an image, that notes refer to by its name
+func (p *Package) AddMedia(name string, data io.Reader) error {
+	entry := strconv.Itoa(len(p.media))
+	w, err := p.zw.CreateHeader(&zip.FileHeader{Name: entry, Method: zip.Store, Modified: p.now})
+	if err != nil {
+		return fmt.Errorf("add media %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, data); err != nil {
+		return fmt.Errorf("add media %s: %w", name, err)
+	}
+	p.media[entry] = name
+
+	return nil
+}
+
+func (p *Package) AddNote(n Note) error {
+	id := p.now.UnixMilli() + p.notes
+	mod := p.now.Unix()
+	fields := strings.Join([]string{n.Word, n.Definition, n.Image, n.Examples}, fieldSeparator)
+	sortField := stripHTML(n.Word)
+	tags := ""
+	if len(n.Tags) > 0 {
+		tags = " " + strings.Join(n.Tags, " ") + " "
+	}
+
+	_, err := p.tx.Exec(
+		"INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')",
+		id, n.GUID, modelID, mod, tags, fields, sortField, checksum(sortField),
+	)
+	if err != nil {
+		return fmt.Errorf("insert note: %w", err)
+	}
+
+	// new cards are shown in the order of their due number
+	_, err = p.tx.Exec(
+		"INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')",
+		id, id, deckID, mod, p.notes+1,
+	)
+	if err != nil {
+		return fmt.Errorf("insert card: %w", err)
+	}
+
+	p.notes++
+	return nil
+}
+
+// Close writes the collection and the list of media files and finishes the package
+func (p *Package) Close() error {
+	defer p.Discard()
+
+	if err := p.tx.Commit(); err != nil {
+		return fmt.Errorf("commit collection: %w", err)
+	}
+	if err := p.db.Close(); err != nil {
+		return fmt.Errorf("close collection: %w", err)
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return fmt.Errorf("open collection: %w", err)
+	}
+	defer f.Close()
+
+	w, err := p.zw.CreateHeader(&zip.FileHeader{Name: "collection.anki2", Method: zip.Deflate, Modified: p.now})
+	if err != nil {
+		return fmt.Errorf("add collection: %w", err)
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("add collection: %w", err)
+	}
+
+	w, err = p.zw.CreateHeader(&zip.FileHeader{Name: "media", Method: zip.Deflate, Modified: p.now})
+	if err != nil {
+		return fmt.Errorf("add media list: %w", err)
+	}
+	if err := json.NewEncoder(w).Encode(p.media); err != nil {
+		return fmt.Errorf("add media list: %w", err)
+	}
+
+	if err := p.zw.Close(); err != nil {
+		return fmt.Errorf("finish package: %w", err)
+	}
+
+	return nil
+}
+
+// Discard removes the temporary collection of a package that is abandoned because of an error, leaving the
+// package written so far incomplete. It is safe to call after Close.
+func (p *Package) Discard() {
+	if p.tx != nil {
+		_ = p.tx.Rollback()
+	}
+	if p.db != nil {
+		_ = p.db.Close()
+	}
+	_ = os.Remove(p.path)
+}
+
+func (p *Package) createCollection() error {
+	db, err := sql.Open("sqlite", p.path)
+	if err != nil {
+		return fmt.Errorf("open collection: %w", err)
+	}
+	p.db = db
+	// the temporary collection is only used by this package
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		return fmt.Errorf("create collection schema: %w", err)
+	}
+
+	conf, models, decks, dconf, err := p.collectionConfig()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO col VALUES (1, ?, ?, ?, ?, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		p.now.Truncate(24*time.Hour).Unix(), p.now.UnixMilli(), p.now.UnixMilli(), schemaVersion,
+		conf, models, decks, dconf,
+	)
+	if err != nil {
+		return fmt.Errorf("insert collection: %w", err)
+	}
+
+	p.tx, err = db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin collection transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Package) collectionConfig() (conf, models, decks, dconf string, err error) {
+	mod := p.now.Unix()
+	marshal := func(v any) string {
+		if err != nil {
+			return ""
+		}
+		var data []byte
+		data, err = json.Marshal(v)
+		return string(data)
+	}
+
+	conf = marshal(map[string]any{
+		"activeDecks":   []int64{deckID},
+		"curDeck":       deckID,
+		"curModel":      strconv.FormatInt(modelID, 10),
+		"nextPos":       1,
+		"sortType":      "noteFld",
+		"sortBackwards": false,
+		"addToCur":      true,
+		"newSpread":     0,
+		"collapseTime":  1200,
+		"timeLim":       0,
+		"estTimes":      true,
+		"dueCounts":     true,
+	})
+
+	field := func(name string, ord int) map[string]any {
+		return map[string]any{"name": name, "ord": ord, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}}
+	}
+	models = marshal(map[string]any{
+		strconv.FormatInt(modelID, 10): map[string]any{
+			"id":    modelID,
+			"name":  "Lexi Word",
+			"type":  0,
+			"mod":   mod,
+			"usn":   -1,
+			"sortf": 0,
+			"did":   deckID,
+			"flds": []any{
+				field("Word", 0),
+				field("Definition", 1),
+				field("Image", 2),
+				field("Examples", 3),
+			},
+			"tmpls": []any{map[string]any{
+				"name":  "Card 1",
+				"ord":   0,
+				"qfmt":  frontTemplate,
+				"afmt":  backTemplate,
+				"did":   nil,
+				"bqfmt": "",
+				"bafmt": "",
+			}},
+			"css":       css,
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"latexsvg":  false,
+			"tags":      []string{},
+			"vers":      []string{},
+			// the card is generated when the first field is not empty
+			"req": []any{[]any{0, "any", []int{0}}},
+		},
+	})
+
+	deck := func(id int64, name string) map[string]any {
+		return map[string]any{
+			"id":               id,
+			"name":             name,
+			"desc":             "",
+			"mod":              mod,
+			"usn":              -1,
+			"collapsed":        false,
+			"browserCollapsed": false,
+			"newToday":         []int{0, 0},
+			"revToday":         []int{0, 0},
+			"lrnToday":         []int{0, 0},
+			"timeToday":        []int{0, 0},
+			"dyn":              0,
+			"extendNew":        10,
+			"extendRev":        50,
+			"conf":             1,
+		}
+	}
+	decks = marshal(map[string]any{
+		"1":                           deck(1, "Default"),
+		strconv.FormatInt(deckID, 10): deck(deckID, p.deck),
+	})
+
+	dconf = marshal(map[string]any{
+		"1": map[string]any{
+			"id":       1,
+			"name":     "Default",
+			"mod":      0,
+			"usn":      0,
+			"maxTaken": 60,
+			"autoplay": true,
+			"timer":    0,
+			"replayq":  true,
+			"dyn":      false,
+			"new": map[string]any{
+				"bury":          true,
+				"delays":        []float64{1, 10},
+				"initialFactor": 2500,
+				"ints":          []int{1, 4, 7},
+				"order":         1,
+				"perDay":        20,
+				"separate":      true,
+			},
+			"rev": map[string]any{
+				"bury":     true,
+				"ease4":    1.3,
+				"fuzz":     0.05,
+				"ivlFct":   1,
+				"maxIvl":   36500,
+				"minSpace": 1,
+				"perDay":   200,
+			},
+			"lapse": map[string]any{
+				"delays":      []float64{10},
+				"leechAction": 0,
+				"leechFails":  8,
+				"minInt":      1,
+				"mult":        0,
+			},
+		},
+	})
+
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("encode collection config: %w", err)
+	}
+
+	return conf, models, decks, dconf, nil
+}
+
+// checksum is the checksum Anki keeps of the sort field to find duplicates, the first 32 bits of its SHA-1 hash
+func checksum(s string) int64 {
+	sum := sha1.Sum([]byte(s))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+func stripHTML(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+}
+
+// Tag turns a tag into an Anki tag, spaces are replaced by underscores and the levels of hierarchical tags
+// are separated by :: rather than /
+func Tag(tag string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(tag), "_"), "/", "::")
+}
+
+const frontTemplate = `<div class="word">{{Word}}</div>`
+
+const backTemplate = `{{FrontSide}}
+
+<hr id="answer">
+
+<div class="definition">{{Definition}}</div>
+{{#Image}}<div class="image">{{Image}}</div>{{/Image}}
+{{#Examples}}<div class="examples">{{Examples}}</div>{{/Examples}}`
+
+const css = `.card {
+  font-family: arial;
+  font-size: 20px;
+  text-align: center;
+  color: black;
+  background-color: white;
+}
+.word {
+  font-size: 32px;
+}
+.image img {
+  max-width: 100%;
+  max-height: 300px;
+}
+.examples {
+  font-size: 16px;
+  font-style: italic;
+  text-align: left;
+}`
+
+// schema is the schema of Anki 2.1 collections
+const schema = `
+CREATE TABLE col (
+    id integer PRIMARY KEY,
+    crt integer NOT NULL,
+    mod integer NOT NULL,
+    scm integer NOT NULL,
+    ver integer NOT NULL,
+    dty integer NOT NULL,
+    usn integer NOT NULL,
+    ls integer NOT NULL,
+    conf text NOT NULL,
+    models text NOT NULL,
+    decks text NOT NULL,
+    dconf text NOT NULL,
+    tags text NOT NULL
+);
+CREATE TABLE notes (
+    id integer PRIMARY KEY,
+    guid text NOT NULL,
+    mid integer NOT NULL,
+    mod integer NOT NULL,
+    usn integer NOT NULL,
+    tags text NOT NULL,
+    flds text NOT NULL,
+    sfld integer NOT NULL,
+    csum integer NOT NULL,
+    flags integer NOT NULL,
+    data text NOT NULL
+);
+CREATE TABLE cards (
+    id integer PRIMARY KEY,
+    nid integer NOT NULL,
+    did integer NOT NULL,
+    ord integer NOT NULL,
+    mod integer NOT NULL,
+    usn integer NOT NULL,
+    type integer NOT NULL,
+    queue integer NOT NULL,
+    due integer NOT NULL,
+    ivl integer NOT NULL,
+    factor integer NOT NULL,
+    reps integer NOT NULL,
+    lapses integer NOT NULL,
+    left integer NOT NULL,
+    odue integer NOT NULL,
+    odid integer NOT NULL,
+    flags integer NOT NULL,
+    data text NOT NULL
+);
+CREATE TABLE revlog (
+    id integer PRIMARY KEY,
+    cid integer NOT NULL,
+    usn integer NOT NULL,
+    ease integer NOT NULL,
+    ivl integer NOT NULL,
+    lastIvl integer NOT NULL,
+    factor integer NOT NULL,
+    time integer NOT NULL,
+    type integer NOT NULL
+);
+CREATE TABLE graves (
+    usn integer NOT NULL,
+    oid integer NOT NULL,
+    type integer NOT NULL
+);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
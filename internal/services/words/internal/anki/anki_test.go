@@ -0,0 +1,287 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEntry(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+
+	f, err := zr.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return data
+}
+
+func TestPackage(t *testing.T) {
+	var out bytes.Buffer
+	p, err := NewPackage(&out, "Lexi")
+	require.NoError(t, err)
+
+	require.NoError(t, p.AddMedia("apple.jpg", strings.NewReader("jpeg data")))
+	require.NoError(t, p.AddNote(Note{
+		GUID:       "lexi-1",
+		Word:       "apple",
+		Definition: "A round fruit.",
+		Image:      `<img src="apple.jpg">`,
+		Tags:       []string{"fruits", "food::fruits"},
+	}))
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-2", Word: "run", Definition: "To move fast.", Examples: "<b>Run</b>!"}))
+	require.NoError(t, p.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	assert.Equal(t, "jpeg data", string(readEntry(t, zr, "0")))
+
+	var media map[string]string
+	require.NoError(t, json.Unmarshal(readEntry(t, zr, "media"), &media))
+	assert.Equal(t, map[string]string{"0": "apple.jpg"}, media)
+
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	require.NoError(t, os.WriteFile(path, readEntry(t, zr, "collection.anki2"), 0o644))
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var (
+		ver           int
+		models, decks string
+	)
+	require.NoError(t, db.QueryRow("SELECT ver, models, decks FROM col").Scan(&ver, &models, &decks))
+	assert.Equal(t, schemaVersion, ver)
+	assert.Contains(t, models, `"Lexi Word"`)
+	assert.Contains(t, decks, `"name":"Lexi"`)
+
+	rows, err := db.Query("SELECT n.guid, n.tags, n.flds, n.sfld, c.did, c.due FROM notes AS n JOIN cards AS c ON c.nid = n.id ORDER BY c.due")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type note struct {
+		guid, tags, flds, sfld string
+		did, due               int64
+	}
+	var notes []note
+	for rows.Next() {
+		var n note
+		require.NoError(t, rows.Scan(&n.guid, &n.tags, &n.flds, &n.sfld, &n.did, &n.due))
+		notes = append(notes, n)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, []note{
+		{guid: "lexi-1", tags: " fruits food::fruits ", flds: "apple\x1fA round fruit.\x1f<img src=\"apple.jpg\">\x1f", sfld: "apple", did: deckID, due: 1},
+		{guid: "lexi-2", tags: "", flds: "run\x1fTo move fast.\x1f\x1f<b>Run</b>!", sfld: "run", did: deckID, due: 2},
+	}, notes)
+}
+
+func TestPackage_Discard(t *testing.T) {
+	p, err := NewPackage(io.Discard, "Lexi")
+	require.NoError(t, err)
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-1", Word: "apple"}))
+
+	p.Discard()
+	_, err = os.Stat(p.path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTag(t *testing.T) {
+	assert.Equal(t, "food::fruits::red_fruits", Tag("food/fruits/red  fruits"))
+}
+
+// rewritePackage runs the statements on the collection of the package and writes it again, compressed
+// like the packages of newer Anki versions when compress is set
+func rewritePackage(t *testing.T, pkg []byte, compress bool, statements ...string) []byte {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	require.NoError(t, os.WriteFile(path, readEntry(t, zr, "collection.anki2"), 0o644))
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	for _, s := range statements {
+		_, err := db.Exec(s)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	name := "collection.anki2"
+	if compress {
+		name = "collection.anki21b"
+		enc, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		data = enc.EncodeAll(data, nil)
+
+		// newer packages keep a collection for older versions that only asks to upgrade
+		w, err := zw.Create("collection.anki2")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("outdated"))
+		require.NoError(t, err)
+	}
+	w, err := zw.Create(name)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return out.Bytes()
+}
+
+func TestOpenPackage(t *testing.T) {
+	var out bytes.Buffer
+	p, err := NewPackage(&out, "Lexi")
+	require.NoError(t, err)
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-1", Word: "apple", Definition: "A round <b>fruit</b>.", Tags: []string{"food::fruits"}}))
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-2", Word: "run", Definition: "To move fast."}))
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-3", Word: "pear", Definition: "A sweet fruit."}))
+	require.NoError(t, p.Close())
+
+	created := time.Date(2024, 1, 1, 4, 0, 0, 0, time.UTC)
+	learnDue := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	pkg := rewritePackage(t, out.Bytes(), false,
+		fmt.Sprintf("UPDATE col SET crt = %d", created.Unix()),
+		"UPDATE cards SET type = 2, queue = -1, due = 40, ivl = 30 WHERE due = 2",
+		fmt.Sprintf("UPDATE cards SET type = 1, queue = 1, due = %d WHERE due = 3", learnDue.Unix()),
+	)
+
+	c, err := OpenPackage(bytes.NewReader(pkg), int64(len(pkg)))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, 3, c.Len())
+	assert.Equal(t, []string{"Word", "Definition", "Image", "Examples"}, c.FieldNames())
+
+	var notes []PackageNote
+	for {
+		n, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		notes = append(notes, n)
+	}
+	require.Len(t, notes, 3)
+
+	assert.Equal(t, map[string]string{"Word": "apple", "Definition": "A round <b>fruit</b>.", "Image": "", "Examples": ""}, notes[0].Fields)
+	assert.Equal(t, []string{"food::fruits"}, notes[0].Tags)
+	assert.Equal(t, CardState{Type: CardNew}, notes[0].Card)
+
+	assert.Equal(t, "run", notes[1].Fields["Word"])
+	assert.Equal(t, CardReview, notes[1].Card.Type)
+	assert.True(t, notes[1].Card.Suspended)
+	assert.Equal(t, 30, notes[1].Card.Interval)
+	assert.True(t, created.AddDate(0, 0, 40).Equal(notes[1].Card.Due))
+
+	assert.Equal(t, CardLearning, notes[2].Card.Type)
+	assert.False(t, notes[2].Card.Suspended)
+	assert.True(t, learnDue.Equal(notes[2].Card.Due))
+}
+
+func TestOpenPackage_Compressed(t *testing.T) {
+	var out bytes.Buffer
+	p, err := NewPackage(&out, "Lexi")
+	require.NoError(t, err)
+	require.NoError(t, p.AddNote(Note{GUID: "lexi-1", Word: "apple", Definition: "A round fruit."}))
+	require.NoError(t, p.Close())
+
+	// newer schemas keep the fields of the note types in a table
+	pkg := rewritePackage(t, out.Bytes(), true,
+		"UPDATE col SET ver = 18, models = ''",
+		"CREATE TABLE fields (ntid integer NOT NULL, ord integer NOT NULL, name text NOT NULL, config blob NOT NULL)",
+		fmt.Sprintf("INSERT INTO fields VALUES (%d, 1, 'Back', ''), (%d, 0, 'Front', '')", modelID, modelID),
+	)
+
+	c, err := OpenPackage(bytes.NewReader(pkg), int64(len(pkg)))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, []string{"Front", "Back"}, c.FieldNames())
+	n, err := c.Next()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Front": "apple", "Back": "A round fruit."}, n.Fields)
+
+	_, err = c.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestOpenPackage_Invalid(t *testing.T) {
+	_, err := OpenPackage(strings.NewReader("not a zip"), 9)
+	assert.Error(t, err)
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	_, err = zw.Create("media")
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	_, err = OpenPackage(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	assert.ErrorIs(t, err, ErrNoCollection)
+}
+
+func TestTagPath(t *testing.T) {
+	assert.Equal(t, "food/fruits/red_fruits", TagPath("food::fruits::red_fruits"))
+}
+
+func TestOpenPackage_TooLarge(t *testing.T) {
+	defer func(size int64) { maxCollectionSize = size }(maxCollectionSize)
+	maxCollectionSize = 1024
+
+	data := make([]byte, 4096)
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	for name, write := range map[string]func(zw *zip.Writer) error{
+		"size in header": func(zw *zip.Writer) error {
+			w, err := zw.Create("collection.anki2")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		},
+		// the compressed collection is far smaller than what it extracts to
+		"compressed": func(zw *zip.Writer) error {
+			w, err := zw.Create("collection.anki21b")
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(enc.EncodeAll(data, nil))
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			zw := zip.NewWriter(&out)
+			require.NoError(t, write(zw))
+			require.NoError(t, zw.Close())
+
+			_, err := OpenPackage(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			assert.ErrorIs(t, err, ErrCollectionTooLarge)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package anki
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	// ErrNoCollection is returned for packages that hold no collection of notes
+	ErrNoCollection = errors.New("package has no collection")
+	// ErrCollectionTooLarge is returned for packages whose collection extracts to more than maxCollectionSize bytes
+	ErrCollectionTooLarge = errors.New("collection is too large")
+)
+
+// maxCollectionSize is the maximum size of an extracted collection, so that small packages cannot fill the disk
+var maxCollectionSize int64 = 1 << 30
+
+// collectionEntries are the names of the collection in packages, from the newest schema to the oldest one.
+// Packages with a newer collection also hold an older one that only asks to upgrade Anki, so the newest is read.
+var collectionEntries = []string{"collection.anki21b", "collection.anki21", "collection.anki2"}
+
+// CardType is the learning stage of an Anki card
+type CardType int
+
+const (
+	CardNew        CardType = 0
+	CardLearning   CardType = 1
+	CardReview     CardType = 2
+	CardRelearning CardType = 3
+)
+
+// card queues that tell how the due number of a card is counted
+const (
+	queueSuspended = -1
+	queueLearning  = 1
+	queuePreview   = 4
+)
+
+// CardState is the scheduling state of a card
+type CardState struct {
+	Type      CardType
+	Suspended bool
+	// Due is when the card is due for review next, it is zero for new cards
+	Due time.Time
+	// Interval is the number of days between the last review of a review card and Due
+	Interval int
+}
+
+// PackageNote is a note read from a package
+type PackageNote struct {
+	ID int64
+	// Fields maps the names of the fields of the note type to their HTML
+	Fields map[string]string
+	Tags   []string
+	// Card is the state of the first card of the note, notes of note types with several cards share one
+	// note and the other cards are left out
+	Card CardState
+}
+
+// Collection reads the notes of a package one at a time. The collection is copied from the package to a
+// temporary file, which is removed when the collection is closed.
+type Collection struct {
+	path string
+	db   *sql.DB
+	rows *sql.Rows
+	// created is the day the collection was created on, the due numbers of review cards count days from it
+	created time.Time
+	// fields are the names of the fields of every note type in their order
+	fields map[int64][]string
+	notes  int
+}
+
+// OpenPackage opens the collection of the package in r. Both the collections of Anki 2.1 and the compressed
+// ones of Anki 23.10 and newer are read.
+func OpenPackage(r io.ReaderAt, size int64) (*Collection, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("read package: %w", err)
+	}
+
+	var entry *zip.File
+	for _, name := range collectionEntries {
+		for _, f := range zr.File {
+			if f.Name == name {
+				entry = f
+				break
+			}
+		}
+		if entry != nil {
+			break
+		}
+	}
+	if entry == nil {
+		return nil, ErrNoCollection
+	}
+
+	tmp, err := os.CreateTemp("", "anki-*.anki2")
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+	c := &Collection{path: tmp.Name()}
+
+	err = copyCollection(tmp, entry)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("extract collection: %w", err)
+	}
+
+	if err := c.open(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// copyCollection copies the collection entry to w, decompressing the collections of newer Anki versions.
+// It returns ErrCollectionTooLarge as soon as more than maxCollectionSize bytes would be written.
+func copyCollection(w io.Writer, entry *zip.File) error {
+	if entry.UncompressedSize64 > uint64(maxCollectionSize) {
+		return ErrCollectionTooLarge
+	}
+
+	f, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var src io.Reader = f
+	if strings.HasSuffix(entry.Name, "b") {
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	// the zip reader fails entries that extract to more than their size in the header,
+	// but compressed collections decompress further
+	n, err := io.Copy(w, io.LimitReader(src, maxCollectionSize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxCollectionSize {
+		return ErrCollectionTooLarge
+	}
+
+	return nil
+}
+
+func (c *Collection) open() error {
+	db, err := sql.Open("sqlite", c.path)
+	if err != nil {
+		return fmt.Errorf("open collection: %w", err)
+	}
+	c.db = db
+	db.SetMaxOpenConns(1)
+
+	var (
+		crt    int64
+		ver    int
+		models string
+	)
+	if err := db.QueryRow("SELECT crt, ver, models FROM col").Scan(&crt, &ver, &models); err != nil {
+		return fmt.Errorf("read collection: %w", err)
+	}
+	c.created = time.Unix(crt, 0)
+
+	// newer schemas keep the note types in tables of their own rather than in the collection
+	if ver > schemaVersion && models == "" {
+		c.fields, err = c.readFieldTable()
+	} else {
+		c.fields, err = readModelFields(models)
+	}
+	if err != nil {
+		return fmt.Errorf("read note types: %w", err)
+	}
+
+	if err := db.QueryRow("SELECT COUNT(*) FROM notes").Scan(&c.notes); err != nil {
+		return fmt.Errorf("count notes: %w", err)
+	}
+
+	// the first card of every note is the one with the lowest ordinal
+	c.rows, err = db.Query(`
+		SELECT n.id, n.mid, n.tags, n.flds,
+			COALESCE(c.type, 0), COALESCE(c.queue, 0), COALESCE(c.due, 0), COALESCE(c.ivl, 0)
+		FROM notes AS n
+		LEFT JOIN cards AS c ON c.id = (SELECT id FROM cards WHERE nid = n.id ORDER BY ord LIMIT 1)
+		ORDER BY n.id
+	`)
+	if err != nil {
+		return fmt.Errorf("read notes: %w", err)
+	}
+
+	return nil
+}
+
+func readModelFields(models string) (map[int64][]string, error) {
+	var types map[string]struct {
+		Flds []struct {
+			Name string `json:"name"`
+			Ord  int    `json:"ord"`
+		} `json:"flds"`
+	}
+	if err := json.Unmarshal([]byte(models), &types); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[int64][]string, len(types))
+	for id, t := range types {
+		mid, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid note type id %q", id)
+		}
+
+		names := make([]string, len(t.Flds))
+		for _, f := range t.Flds {
+			if f.Ord < 0 || f.Ord >= len(names) {
+				return nil, fmt.Errorf("invalid ordinal %d of field %q", f.Ord, f.Name)
+			}
+			names[f.Ord] = f.Name
+		}
+		fields[mid] = names
+	}
+
+	return fields, nil
+}
+
+func (c *Collection) readFieldTable() (map[int64][]string, error) {
+	rows, err := c.db.Query("SELECT ntid, name FROM fields ORDER BY ntid, ord")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			mid  int64
+			name string
+		)
+		if err := rows.Scan(&mid, &name); err != nil {
+			return nil, err
+		}
+		fields[mid] = append(fields[mid], name)
+	}
+
+	return fields, rows.Err()
+}
+
+// Len returns the number of notes in the collection
+func (c *Collection) Len() int {
+	return c.notes
+}
+
+// FieldNames returns the names of the fields of all note types of the collection, each name once
+func (c *Collection) FieldNames() []string {
+	var (
+		names []string
+		seen  = make(map[string]bool)
+	)
+	for _, mid := range slices.Sorted(maps.Keys(c.fields)) {
+		for _, name := range c.fields[mid] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Next returns the next note of the collection in the order they were created, or io.EOF after the last one
+func (c *Collection) Next() (PackageNote, error) {
+	if !c.rows.Next() {
+		if err := c.rows.Err(); err != nil {
+			return PackageNote{}, fmt.Errorf("read notes: %w", err)
+		}
+		return PackageNote{}, io.EOF
+	}
+
+	var (
+		n                    PackageNote
+		mid                  int64
+		tags, flds           string
+		queue, due, interval int64
+		cardType             int
+	)
+	if err := c.rows.Scan(&n.ID, &mid, &tags, &flds, &cardType, &queue, &due, &interval); err != nil {
+		return PackageNote{}, fmt.Errorf("scan note: %w", err)
+	}
+
+	names, ok := c.fields[mid]
+	if !ok {
+		return PackageNote{}, fmt.Errorf("note %d has unknown note type %d", n.ID, mid)
+	}
+	n.Fields = make(map[string]string, len(names))
+	for i, v := range strings.Split(flds, fieldSeparator) {
+		if i < len(names) {
+			n.Fields[names[i]] = v
+		}
+	}
+	n.Tags = strings.Fields(tags)
+	n.Card = c.cardState(CardType(cardType), queue, due, interval)
+
+	return n, nil
+}
+
+// cardState reads the scheduling of a card. The due number of cards in learning counts seconds since the
+// epoch, while the one of review cards counts days since the collection was created. Suspended cards keep
+// the due number they had, so the one of suspended learning cards is told apart by its size.
+func (c *Collection) cardState(t CardType, queue, due, interval int64) CardState {
+	s := CardState{Type: t, Suspended: queue == queueSuspended}
+
+	switch {
+	case t == CardNew:
+	case queue == queueLearning || queue == queuePreview || (t == CardLearning && due > 1_000_000_000):
+		s.Due = time.Unix(due, 0)
+	default:
+		s.Due = c.created.AddDate(0, 0, int(due))
+	}
+	if t == CardReview {
+		s.Interval = int(interval)
+	}
+
+	return s
+}
+
+// Close closes the collection and removes its temporary file
+func (c *Collection) Close() error {
+	if c.rows != nil {
+		_ = c.rows.Close()
+	}
+	if c.db != nil {
+		_ = c.db.Close()
+	}
+
+	return os.Remove(c.path)
+}
+
+// TagPath turns an Anki tag into a tag, the levels of hierarchical tags are separated by / rather than ::
+func TagPath(tag string) string {
+	return strings.ReplaceAll(tag, "::", "/")
+}
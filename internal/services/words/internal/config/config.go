@@ -1,21 +1,50 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/env"
 )
 
 type Config struct {
-	AuthSecret  string
-	TagsMaxKeys int64
-	TagsMaxCost int64
-	DB          dbConfig
-	HTTP        httpConfig
-	Image       imageConfig
+	AuthSecret        string
+	CursorSecret      string
+	AuthIntrospection introspectionConfig
+	AuthDenylist      denylistConfig
+	Service           serviceConfig
+	Admin             adminConfig
+	TagsMaxKeys       int64
+	TagsMaxCost       int64
+	TagsCacheTTL      time.Duration
+	DB                DBConfig
+	HTTP              httpConfig
+	Image             imageConfig
+	// Dictionaries are the paths of the offline dictionaries words are looked up in
+	Dictionaries []string
 }
 
-type dbConfig struct {
+type introspectionConfig struct {
+	URL      string
+	CacheTTL time.Duration
+}
+
+type denylistConfig struct {
+	URL        string
+	RefreshTTL time.Duration
+}
+
+type serviceConfig struct {
+	Secret   string
+	TokenTTL time.Duration
+}
+
+// adminConfig holds the credential of the operators calling the admin endpoints, kept apart from the service secret
+type adminConfig struct {
+	Secret string
+}
+
+type DBConfig struct {
 	Host     string
 	Port     string
 	User     string
@@ -35,20 +64,34 @@ type imageConfig struct {
 	Endpoint  string
 	FieldName string
 	FileName  string
+	// ServeRoot is the URL the image service serves the images under, the origin of the endpoint if empty
+	ServeRoot string
+	Timeout   time.Duration
 }
 
 func FromEnv() Config {
 	return Config{
-		AuthSecret:  env.RequireString("AUTH_SECRET"),
-		TagsMaxKeys: env.Int64("TAGS_CACHE_KEYS", 10000),
-		TagsMaxCost: env.Int64("TAGS_CACHE_COST", 10000),
-		DB: dbConfig{
-			Host:     env.String("DB_HOST", "localhost"),
-			Port:     env.String("DB_PORT", "5432"),
-			User:     env.String("DB_USER", "postgres"),
-			Password: env.String("DB_PASSWORD", "password"),
-			Name:     env.String("DB_NAME", "words_service"),
+		AuthSecret:   env.RequireString("AUTH_SECRET"),
+		CursorSecret: env.RequireString("CURSOR_SECRET"),
+		AuthIntrospection: introspectionConfig{
+			URL:      env.String("AUTH_INTROSPECTION_URL", ""),
+			CacheTTL: env.Duration("AUTH_INTROSPECTION_CACHE_TTL", 30*time.Second),
+		},
+		AuthDenylist: denylistConfig{
+			URL:        env.String("AUTH_DENYLIST_URL", ""),
+			RefreshTTL: env.Duration("AUTH_DENYLIST_REFRESH_TTL", 30*time.Second),
 		},
+		Service: serviceConfig{
+			Secret:   env.RequireString("SERVICE_SECRET"),
+			TokenTTL: env.Duration("SERVICE_TOKEN_TTL", time.Minute),
+		},
+		Admin: adminConfig{
+			Secret: env.RequireString("ADMIN_SECRET"),
+		},
+		TagsMaxKeys:  env.Int64("TAGS_CACHE_KEYS", 10000),
+		TagsMaxCost:  env.Int64("TAGS_CACHE_COST", 10000),
+		TagsCacheTTL: env.Duration("TAGS_CACHE_TTL", 30*time.Second),
+		DB:           DBFromEnv(),
 		HTTP: httpConfig{
 			ListenAddr:      env.String("HTTP_LISTEN_ADDR", ":8080"),
 			IdleTimeout:     env.Duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
@@ -57,9 +100,35 @@ func FromEnv() Config {
 			ShutdownTimeout: env.Duration("HTTP_SHUTDOWN_TIMEOUT", 10*time.Second),
 		},
 		Image: imageConfig{
-			Endpoint:  env.String("IMAGE_SERVICE", "http://localhost:9999/"),
+			Endpoint:  env.String("IMAGE_SERVICE", "http://localhost:9999/upload"),
 			FieldName: env.String("IMAGE_FIELD_NAME", "image"),
 			FileName:  env.String("IMAGE_FILE_NAME", "image.jpg"),
+			ServeRoot: env.String("IMAGE_SERVE_ROOT", ""),
+			Timeout:   env.Duration("IMAGE_TIMEOUT", 30*time.Second),
 		},
+		Dictionaries: list(env.String("DICTIONARIES", "")),
+	}
+}
+
+// list splits a comma separated list, dropping empty items
+func list(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// DBFromEnv reads the database configuration only, for commands that need nothing else
+func DBFromEnv() DBConfig {
+	return DBConfig{
+		Host:     env.String("DB_HOST", "localhost"),
+		Port:     env.String("DB_PORT", "5432"),
+		User:     env.String("DB_USER", "postgres"),
+		Password: env.String("DB_PASSWORD", "password"),
+		Name:     env.String("DB_NAME", "words_service"),
 	}
 }
@@ -15,8 +15,17 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("HTTP_WRITE_TIMEOUT", "50s")
 	t.Setenv("HTTP_SHUTDOWN_TIMEOUT", "15s")
 	t.Setenv("AUTH_SECRET", "supersecret")
+	t.Setenv("SERVICE_SECRET", "servicesecret")
+	t.Setenv("CURSOR_SECRET", "cursorsecret")
+	t.Setenv("ADMIN_SECRET", "adminsecret")
+	t.Setenv("SERVICE_TOKEN_TTL", "2m")
+	t.Setenv("AUTH_INTROSPECTION_URL", "http://auth.example.com/api/v1/introspect")
+	t.Setenv("AUTH_INTROSPECTION_CACHE_TTL", "1m")
+	t.Setenv("AUTH_DENYLIST_URL", "http://auth.example.com/internal/v1/sessions/revoked")
+	t.Setenv("AUTH_DENYLIST_REFRESH_TTL", "10s")
 	t.Setenv("TAGS_CACHE_KEYS", "200")
 	t.Setenv("TAGS_CACHE_COST", "300")
+	t.Setenv("TAGS_CACHE_TTL", "5s")
 	t.Setenv("DB_HOST", "db.example.com")
 	t.Setenv("DB_PORT", "6543")
 	t.Setenv("DB_USER", "testuser")
@@ -25,12 +34,24 @@ func TestFromEnv(t *testing.T) {
 	t.Setenv("IMAGE_SERVICE", "http://example.com:8888/upload")
 	t.Setenv("IMAGE_FIELD_NAME", "img")
 	t.Setenv("IMAGE_FILE_NAME", "img.jpg")
+	t.Setenv("IMAGE_SERVE_ROOT", "http://example.com:8888/images/")
+	t.Setenv("IMAGE_TIMEOUT", "5s")
+	t.Setenv("DICTIONARIES", "/dicts/en-ru.ifo, /dicts/en-de.dsl.dz,")
 
 	cfg := config.FromEnv()
 
 	assert.Equal(t, "supersecret", cfg.AuthSecret)
+	assert.Equal(t, "servicesecret", cfg.Service.Secret)
+	assert.Equal(t, "cursorsecret", cfg.CursorSecret)
+	assert.Equal(t, "adminsecret", cfg.Admin.Secret)
+	assert.Equal(t, 2*time.Minute, cfg.Service.TokenTTL)
+	assert.Equal(t, "http://auth.example.com/api/v1/introspect", cfg.AuthIntrospection.URL)
+	assert.Equal(t, time.Minute, cfg.AuthIntrospection.CacheTTL)
+	assert.Equal(t, "http://auth.example.com/internal/v1/sessions/revoked", cfg.AuthDenylist.URL)
+	assert.Equal(t, 10*time.Second, cfg.AuthDenylist.RefreshTTL)
 	assert.Equal(t, int64(200), cfg.TagsMaxKeys)
 	assert.Equal(t, int64(300), cfg.TagsMaxCost)
+	assert.Equal(t, 5*time.Second, cfg.TagsCacheTTL)
 	assert.Equal(t, "db.example.com", cfg.DB.Host)
 	assert.Equal(t, "6543", cfg.DB.Port)
 	assert.Equal(t, "testuser", cfg.DB.User)
@@ -44,15 +65,28 @@ func TestFromEnv(t *testing.T) {
 	assert.Equal(t, "http://example.com:8888/upload", cfg.Image.Endpoint)
 	assert.Equal(t, "img", cfg.Image.FieldName)
 	assert.Equal(t, "img.jpg", cfg.Image.FileName)
+	assert.Equal(t, "http://example.com:8888/images/", cfg.Image.ServeRoot)
+	assert.Equal(t, 5*time.Second, cfg.Image.Timeout)
+	assert.Equal(t, []string{"/dicts/en-ru.ifo", "/dicts/en-de.dsl.dz"}, cfg.Dictionaries)
 }
 
 func TestFromEnv_Defaults(t *testing.T) {
 	t.Setenv("AUTH_SECRET", "test")
+	t.Setenv("SERVICE_SECRET", "service")
+	t.Setenv("CURSOR_SECRET", "cursor")
+	t.Setenv("ADMIN_SECRET", "admin")
 	cfg := config.FromEnv()
 
 	assert.Equal(t, "test", cfg.AuthSecret)
+	assert.Equal(t, "service", cfg.Service.Secret)
+	assert.Equal(t, time.Minute, cfg.Service.TokenTTL)
+	assert.Equal(t, "", cfg.AuthIntrospection.URL)
+	assert.Equal(t, 30*time.Second, cfg.AuthIntrospection.CacheTTL)
+	assert.Equal(t, "", cfg.AuthDenylist.URL)
+	assert.Equal(t, 30*time.Second, cfg.AuthDenylist.RefreshTTL)
 	assert.Equal(t, int64(10000), cfg.TagsMaxKeys)
 	assert.Equal(t, int64(10000), cfg.TagsMaxCost)
+	assert.Equal(t, 30*time.Second, cfg.TagsCacheTTL)
 	assert.Equal(t, "localhost", cfg.DB.Host)
 	assert.Equal(t, "5432", cfg.DB.Port)
 	assert.Equal(t, "postgres", cfg.DB.User)
@@ -63,7 +97,10 @@ func TestFromEnv_Defaults(t *testing.T) {
 	assert.Equal(t, 30*time.Second, cfg.HTTP.ReadTimeout)
 	assert.Equal(t, 30*time.Second, cfg.HTTP.WriteTimeout)
 	assert.Equal(t, 10*time.Second, cfg.HTTP.ShutdownTimeout)
-	assert.Equal(t, "http://localhost:9999/", cfg.Image.Endpoint)
+	assert.Equal(t, "http://localhost:9999/upload", cfg.Image.Endpoint)
 	assert.Equal(t, "image", cfg.Image.FieldName)
 	assert.Equal(t, "image.jpg", cfg.Image.FileName)
+	assert.Equal(t, "", cfg.Image.ServeRoot)
+	assert.Equal(t, 30*time.Second, cfg.Image.Timeout)
+	assert.Empty(t, cfg.Dictionaries)
 }
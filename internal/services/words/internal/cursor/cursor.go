@@ -0,0 +1,120 @@
+// Package cursor encodes pagination cursors as opaque tokens that clients can neither read nor forge.
+package cursor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// version is the first byte of every token, tokens of other versions are rejected
+const version byte = 2
+
+var (
+	// ErrInvalid is returned for tokens that are malformed, of an unknown version or not sealed with the codec key
+	ErrInvalid = errors.New("invalid cursor")
+	// ErrFilterMismatch is returned for valid tokens that were issued for different filters
+	ErrFilterMismatch = errors.New("cursor was issued for different filters")
+)
+
+// Codec seals cursors with AES-256-GCM. A token is the base64url encoding of the version byte,
+// a random nonce and the sealed JSON payload, with the version byte authenticated along with it.
+// The payload carries the cursor, which holds the sort key tuple of the last item, together with
+// the hash of the filters it was issued for.
+type Codec struct {
+	aead cipher.AEAD
+}
+
+// NewCodec creates a codec whose AES key is derived from the given secret of any length
+func NewCodec(key []byte) *Codec {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("cursor"))
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		// AES accepts the 32 byte keys derived above
+		panic(fmt.Sprintf("create cursor cipher: %v", err))
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		// GCM works with any 128 bit block cipher such as AES
+		panic(fmt.Sprintf("create cursor aead: %v", err))
+	}
+
+	return &Codec{aead: aead}
+}
+
+type payload struct {
+	Filter string          `json:"f"`
+	Cursor json.RawMessage `json:"c"`
+}
+
+// Encode encodes the cursor for the filters with the given hash, see FilterHash
+func (c *Codec) Encode(filterHash string, cursor any) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("marshal cursor: %w", err)
+	}
+
+	data, err := json.Marshal(payload{Filter: filterHash, Cursor: raw})
+	if err != nil {
+		return "", fmt.Errorf("marshal payload: %w", err)
+	}
+
+	token := make([]byte, 1+c.aead.NonceSize(), 1+c.aead.NonceSize()+len(data)+c.aead.Overhead())
+	token[0] = version
+	if _, err := rand.Read(token[1:]); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	token = c.aead.Seal(token, token[1:], data, token[:1])
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Decode opens the token and decodes its cursor into v. It returns ErrInvalid if the token
+// cannot be trusted and ErrFilterMismatch if it was issued for filters with a different hash.
+func (c *Codec) Decode(token, filterHash string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) < 1+c.aead.NonceSize()+c.aead.Overhead() || data[0] != version {
+		return ErrInvalid
+	}
+
+	nonce, sealed := data[1:1+c.aead.NonceSize()], data[1+c.aead.NonceSize():]
+	opened, err := c.aead.Open(nil, nonce, sealed, data[:1])
+	if err != nil {
+		return ErrInvalid
+	}
+
+	var p payload
+	if err := json.Unmarshal(opened, &p); err != nil {
+		return ErrInvalid
+	}
+	if p.Filter != filterHash {
+		return ErrFilterMismatch
+	}
+
+	if err := json.Unmarshal(p.Cursor, v); err != nil {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+// FilterHash hashes the filters a cursor is issued for. Callers pass every parameter that changes
+// which items are listed or their order, with slices sorted if their order does not matter.
+func FilterHash(filters ...any) (string, error) {
+	data, err := json.Marshal(filters)
+	if err != nil {
+		return "", fmt.Errorf("marshal filters: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,96 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCursor struct {
+	LastID  int64
+	LastKey string
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+
+	token, err := c.Encode("filters", testCursor{LastID: 42, LastKey: "apple"})
+	require.NoError(t, err)
+	assert.NotContains(t, token, "apple")
+
+	var decoded testCursor
+	require.NoError(t, c.Decode(token, "filters", &decoded))
+	assert.Equal(t, testCursor{LastID: 42, LastKey: "apple"}, decoded)
+}
+
+func TestCodec_Opaque(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+
+	token, err := c.Encode("filters", testCursor{LastID: 424242, LastKey: "apple"})
+	require.NoError(t, err)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	for _, plain := range []string{"424242", "apple", "LastID", "filters"} {
+		assert.NotContains(t, string(data), plain)
+	}
+
+	// the same cursor is sealed with a new nonce every time
+	again, err := c.Encode("filters", testCursor{LastID: 424242, LastKey: "apple"})
+	require.NoError(t, err)
+	assert.NotEqual(t, token, again)
+}
+
+func TestCodec_FilterMismatch(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+
+	token, err := c.Encode("filters", testCursor{LastID: 42})
+	require.NoError(t, err)
+
+	var decoded testCursor
+	assert.ErrorIs(t, c.Decode(token, "other filters", &decoded), ErrFilterMismatch)
+}
+
+func TestCodec_Invalid(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+	token, err := c.Encode("filters", testCursor{LastID: 42})
+	require.NoError(t, err)
+
+	forged, err := NewCodec([]byte("other secret")).Encode("filters", testCursor{LastID: 1})
+	require.NoError(t, err)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	tampered := append([]byte{}, data...)
+	tampered[5] ^= 1
+	otherVersion := append([]byte{}, data...)
+	otherVersion[0] = version + 1
+
+	for name, token := range map[string]string{
+		"empty":         "",
+		"not base64":    "{\"LastID\":42}",
+		"short":         base64.RawURLEncoding.EncodeToString([]byte{version, 1, 2}),
+		"forged":        forged,
+		"tampered":      base64.RawURLEncoding.EncodeToString(tampered),
+		"other version": base64.RawURLEncoding.EncodeToString(otherVersion),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var decoded testCursor
+			assert.ErrorIs(t, c.Decode(token, "filters", &decoded), ErrInvalid)
+		})
+	}
+}
+
+func TestFilterHash(t *testing.T) {
+	h1, err := FilterHash("user-1", []string{"a", "b"}, "lemma")
+	require.NoError(t, err)
+	h2, err := FilterHash("user-1", []string{"a", "b"}, "lemma")
+	require.NoError(t, err)
+	h3, err := FilterHash("user-2", []string{"a", "b"}, "lemma")
+	require.NoError(t, err)
+
+	assert.Equal(t, h1, h2)
+	assert.NotEqual(t, h1, h3)
+}
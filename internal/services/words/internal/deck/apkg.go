@@ -0,0 +1,100 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/anki"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+)
+
+var (
+	breakTag = regexp.MustCompile(`(?i)<br\s*/?>|</?(p|div|li|tr)(\s[^>]*)?>`)
+	htmlTag  = regexp.MustCompile(`<[^>]*>`)
+	// soundTag refers to an audio file of the package in a field
+	soundTag = regexp.MustCompile(`\[sound:[^\]]*\]`)
+)
+
+type ankiReader struct {
+	c     *anki.Collection
+	index int
+}
+
+// openAnki extracts the collection of the package, the package itself is closed right away
+func openAnki(f *os.File) (*ankiReader, error) {
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat package: %w", err)
+	}
+
+	c, err := anki.OpenPackage(f, info.Size())
+	if err != nil {
+		if errors.Is(err, anki.ErrCollectionTooLarge) {
+			return nil, fmt.Errorf("%w: %w", ErrTooLarge, err)
+		}
+
+		return nil, err
+	}
+
+	return &ankiReader{c: c}, nil
+}
+
+func (r *ankiReader) Next() (Card, error) {
+	n, err := r.c.Next()
+	if err != nil {
+		return Card{}, err
+	}
+	r.index++
+
+	fields := make(map[string]string, len(n.Fields))
+	for name, v := range n.Fields {
+		fields[name] = fieldText(v)
+	}
+
+	return Card{
+		Index:  r.index,
+		Fields: fields,
+		Tags:   fn.Map(n.Tags, anki.TagPath),
+		Review: &Review{
+			New:       n.Card.Type == anki.CardNew,
+			Suspended: n.Card.Suspended,
+			Due:       n.Card.Due,
+			Interval:  n.Card.Interval,
+		},
+	}, nil
+}
+
+func (r *ankiReader) Len() int {
+	return r.c.Len()
+}
+
+func (r *ankiReader) Fields() []string {
+	return r.c.FieldNames()
+}
+
+func (r *ankiReader) Close() error {
+	return r.c.Close()
+}
+
+// fieldText turns the HTML of a field into text, breaking lines at line breaks and paragraphs
+// and leaving out the sounds
+func fieldText(s string) string {
+	s = soundTag.ReplaceAllString(s, "")
+	s = breakTag.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,82 @@
+// Package deck reads the flashcards of decks exported by other apps, such as Anki packages and Quizlet sets
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown deck format")
+	// ErrTooLarge is returned for decks that extract to more than the disk space allowed for them
+	ErrTooLarge = errors.New("deck is too large")
+)
+
+type Format string
+
+const (
+	// Anki is an Anki package, an .apkg file
+	Anki Format = "apkg"
+	// TSV is a text file with a card per row and a field per column, such as a Quizlet export
+	TSV Format = "tsv"
+)
+
+// Options configure the reading of TSV decks, Anki packages have none
+type Options struct {
+	// Separator separates the fields of a card, it is a tab when empty
+	Separator string
+	// RowSeparator separates the cards, it is a line break when empty
+	RowSeparator string
+	// Header tells that the first row names the columns, which are named by their position from 1 otherwise
+	Header bool
+}
+
+// Card is a flashcard of a deck
+type Card struct {
+	// Index is the position of the card in the deck, starting at 1
+	Index int
+	// Fields maps the names of the fields of the card to their text
+	Fields map[string]string
+	Tags   []string
+	// Review is the scheduling state of the card, it is nil for decks without one
+	Review *Review
+}
+
+// Review is the scheduling state of a card
+type Review struct {
+	// New tells that the card was never studied, new cards have no due time
+	New       bool
+	Suspended bool
+	// Due is when the card is due for review next
+	Due time.Time
+	// Interval is the number of days between the last review of the card and Due,
+	// it is zero for cards still being learned
+	Interval int
+}
+
+// Reader reads the cards of a deck one at a time
+type Reader interface {
+	// Next returns the next card, or io.EOF after the last one
+	Next() (Card, error)
+	// Len returns the number of cards of the deck
+	Len() int
+	// Fields returns the names of the fields of the cards
+	Fields() []string
+	Close() error
+}
+
+// Open starts reading the deck of the given format in f. The reader takes over f and closes it when
+// it is closed or no longer needs it.
+func Open(format Format, f *os.File, opts Options) (Reader, error) {
+	switch format {
+	case Anki:
+		return openAnki(f)
+	case TSV:
+		return openTSV(f, opts)
+	default:
+		f.Close()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
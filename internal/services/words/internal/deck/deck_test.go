@@ -0,0 +1,105 @@
+package deck
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/anki"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDeck(t *testing.T, data []byte) *os.File {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "deck")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	return f
+}
+
+func readAll(t *testing.T, r Reader) []Card {
+	t.Helper()
+
+	var cards []Card
+	for {
+		c, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return cards
+		}
+		require.NoError(t, err)
+		cards = append(cards, c)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	r, err := Open(TSV, writeDeck(t, []byte("\ufeffapple\ta round fruit\r\n\n  \npear\ta sweet fruit\textra\r\n")), Options{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, 2, r.Len())
+	assert.Equal(t, []string{"1", "2", "3"}, r.Fields())
+	assert.Equal(t, []Card{
+		{Index: 1, Fields: map[string]string{"1": "apple", "2": "a round fruit"}},
+		{Index: 2, Fields: map[string]string{"1": "pear", "2": "a sweet fruit", "3": "extra"}},
+	}, readAll(t, r))
+}
+
+func TestTSV_Options(t *testing.T) {
+	r, err := Open(TSV, writeDeck(t, []byte("Term - Definition;apple - a round fruit;run - to move fast")), Options{
+		Separator:    " - ",
+		RowSeparator: ";",
+		Header:       true,
+	})
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, 2, r.Len())
+	assert.Equal(t, []string{"Term", "Definition"}, r.Fields())
+	assert.Equal(t, []Card{
+		{Index: 1, Fields: map[string]string{"Term": "apple", "Definition": "a round fruit"}},
+		{Index: 2, Fields: map[string]string{"Term": "run", "Definition": "to move fast"}},
+	}, readAll(t, r))
+}
+
+func TestTSV_Empty(t *testing.T) {
+	_, err := Open(TSV, writeDeck(t, []byte("\n\n")), Options{})
+	assert.Error(t, err)
+}
+
+func TestAnki(t *testing.T) {
+	var out bytes.Buffer
+	p, err := anki.NewPackage(&out, "Lexi")
+	require.NoError(t, err)
+	require.NoError(t, p.AddNote(anki.Note{
+		GUID:       "lexi-1",
+		Word:       "apple [sound:apple.mp3]",
+		Definition: "1. a round&nbsp;<b>fruit</b><br>2. a tree",
+		Tags:       []string{"food::fruits"},
+	}))
+	require.NoError(t, p.Close())
+
+	r, err := Open(Anki, writeDeck(t, out.Bytes()), Options{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, 1, r.Len())
+	assert.Equal(t, []string{"Word", "Definition", "Image", "Examples"}, r.Fields())
+	assert.Equal(t, []Card{{
+		Index:  1,
+		Fields: map[string]string{"Word": "apple", "Definition": "1. a round fruit\n2. a tree", "Image": "", "Examples": ""},
+		Tags:   []string{"food/fruits"},
+		Review: &Review{New: true},
+	}}, readAll(t, r))
+}
+
+func TestOpen_UnknownFormat(t *testing.T) {
+	_, err := Open("csv", writeDeck(t, nil), Options{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,163 @@
+package deck
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxRowSize is the maximum size of a row of a TSV deck in bytes
+const maxRowSize = 1 << 20
+
+var errNoFields = errors.New("deck has no fields")
+
+// tsvReader reads the rows of a TSV deck. The file is read twice, first to count the cards and to name the columns.
+type tsvReader struct {
+	f       *os.File
+	opts    Options
+	rows    *bufio.Scanner
+	columns []string
+	cards   int
+	index   int
+}
+
+func openTSV(f *os.File, opts Options) (*tsvReader, error) {
+	if opts.Separator == "" {
+		opts.Separator = "\t"
+	}
+	if opts.RowSeparator == "" {
+		opts.RowSeparator = "\n"
+	}
+	r := &tsvReader{f: f, opts: opts}
+
+	if err := r.scan(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("rewind deck: %w", err)
+	}
+	r.rows = r.newScanner()
+	if opts.Header {
+		r.nextRow()
+	}
+
+	return r, nil
+}
+
+// scan counts the cards and names the columns after the header or else after the widest row
+func (r *tsvReader) scan() error {
+	rows := r.newScanner()
+	width := 0
+	for rows.Scan() {
+		row := r.trimRow(rows.Text())
+		if row == "" {
+			continue
+		}
+
+		cols := strings.Split(row, r.opts.Separator)
+		if r.opts.Header && r.columns == nil {
+			r.columns = columnNames(cols)
+			continue
+		}
+		width = max(width, len(cols))
+		r.cards++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read deck: %w", err)
+	}
+
+	if !r.opts.Header {
+		for i := range width {
+			r.columns = append(r.columns, strconv.Itoa(i+1))
+		}
+	}
+	if len(r.columns) == 0 {
+		return errNoFields
+	}
+
+	return nil
+}
+
+// columnNames names the columns after the cells of the header
+func columnNames(cols []string) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = strings.TrimSpace(c)
+	}
+	return names
+}
+
+func (r *tsvReader) newScanner() *bufio.Scanner {
+	sep := []byte(r.opts.RowSeparator)
+	s := bufio.NewScanner(r.f)
+	s.Buffer(make([]byte, 0, 64*1024), maxRowSize)
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	return s
+}
+
+// trimRow drops byte order marks, the carriage returns of Windows line breaks and rows of whitespace only
+func (r *tsvReader) trimRow(row string) string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "\ufeff"), "\r")
+	if strings.TrimSpace(row) == "" {
+		return ""
+	}
+	return row
+}
+
+// nextRow returns the next row that is not empty, or an empty row at the end of the deck
+func (r *tsvReader) nextRow() string {
+	for r.rows.Scan() {
+		if row := r.trimRow(r.rows.Text()); row != "" {
+			return row
+		}
+	}
+	return ""
+}
+
+func (r *tsvReader) Next() (Card, error) {
+	row := r.nextRow()
+	if row == "" {
+		if err := r.rows.Err(); err != nil {
+			return Card{}, fmt.Errorf("read deck: %w", err)
+		}
+		return Card{}, io.EOF
+	}
+	r.index++
+
+	fields := make(map[string]string, len(r.columns))
+	for i, v := range strings.Split(row, r.opts.Separator) {
+		if i < len(r.columns) {
+			fields[r.columns[i]] = strings.TrimSpace(v)
+		}
+	}
+
+	return Card{Index: r.index, Fields: fields}, nil
+}
+
+func (r *tsvReader) Len() int {
+	return r.cards
+}
+
+func (r *tsvReader) Fields() []string {
+	return r.columns
+}
+
+func (r *tsvReader) Close() error {
+	return r.f.Close()
+}
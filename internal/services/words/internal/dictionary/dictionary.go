@@ -0,0 +1,59 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+var ErrUnknownFormat = errors.New("unknown dictionary format")
+
+// Article is the entry of a dictionary for a headword, as plain text with one line per paragraph
+type Article struct {
+	Headword string
+	Text     string
+}
+
+// Source is a dictionary words are looked up in. Lookups are case insensitive, and sources are safe
+// for concurrent use.
+type Source interface {
+	// Name identifies the dictionary, such as the book name of a StarDict dictionary
+	Name() string
+	// Lang is the language of the headwords, empty when the dictionary does not tell
+	Lang() model.Lang
+	// Lookup returns the articles of the headword, none when the dictionary does not have it
+	Lookup(word string) ([]Article, error)
+}
+
+// Open opens the dictionary at path, its format is given by the extension. StarDict dictionaries
+// are opened through their .ifo file, and ABBYY Lingvo ones through their .dsl or .dsl.dz file.
+func Open(path string) (Source, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".ifo"):
+		return OpenStarDict(path)
+	case strings.HasSuffix(lower, ".dsl"), strings.HasSuffix(lower, ".dsl.dz"):
+		return OpenDSL(path)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+// key is the form headwords are indexed and looked up by
+func key(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
+
+// cleanText trims the lines of text and drops the empty ones
+func cleanText(text string) string {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,207 @@
+package dictionary
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stardictArticle struct {
+	word string
+	data string
+}
+
+// writeStarDict writes a StarDict dictionary to dir and returns the path of its .ifo file. The articles are
+// compressed with dictzip in chunks of chunkLen bytes, or left uncompressed when chunkLen is zero.
+func writeStarDict(t *testing.T, dir, options string, articles []stardictArticle, chunkLen int) string {
+	t.Helper()
+
+	var idx, dict bytes.Buffer
+	for _, a := range articles {
+		idx.WriteString(a.word)
+		idx.WriteByte(0)
+		require.NoError(t, binary.Write(&idx, binary.BigEndian, uint32(dict.Len())))
+		require.NoError(t, binary.Write(&idx, binary.BigEndian, uint32(len(a.data))))
+		dict.WriteString(a.data)
+	}
+
+	base := filepath.Join(dir, "test")
+	ifo := "StarDict's dict ifo file\nversion=2.4.2\nbookname=Test Dictionary\n" + options
+	require.NoError(t, os.WriteFile(base+".ifo", []byte(ifo), 0o644))
+	require.NoError(t, os.WriteFile(base+".idx", idx.Bytes(), 0o644))
+
+	if chunkLen == 0 {
+		require.NoError(t, os.WriteFile(base+".dict", dict.Bytes(), 0o644))
+		return base + ".ifo"
+	}
+
+	var (
+		chunks [][]byte
+		data   = dict.Bytes()
+	)
+	for len(data) > 0 {
+		n := min(chunkLen, len(data))
+		var chunk bytes.Buffer
+		zw, err := flate.NewWriter(&chunk, flate.BestCompression)
+		require.NoError(t, err)
+		_, err = zw.Write(data[:n])
+		require.NoError(t, err)
+		require.NoError(t, zw.Flush())
+		chunks = append(chunks, chunk.Bytes())
+		data = data[n:]
+	}
+
+	var ra bytes.Buffer
+	for _, v := range []uint16{1, uint16(chunkLen), uint16(len(chunks))} {
+		require.NoError(t, binary.Write(&ra, binary.LittleEndian, v))
+	}
+	for _, c := range chunks {
+		require.NoError(t, binary.Write(&ra, binary.LittleEndian, uint16(len(c))))
+	}
+
+	var dz bytes.Buffer
+	dz.Write([]byte{0x1f, 0x8b, 8, gzipFlagExtra | gzipFlagName, 0, 0, 0, 0, 2, 3})
+	require.NoError(t, binary.Write(&dz, binary.LittleEndian, uint16(4+ra.Len())))
+	dz.WriteString("RA")
+	require.NoError(t, binary.Write(&dz, binary.LittleEndian, uint16(ra.Len())))
+	dz.Write(ra.Bytes())
+	dz.WriteString("test.dict\x00")
+	for _, c := range chunks {
+		dz.Write(c)
+	}
+	require.NoError(t, os.WriteFile(base+".dict.dz", dz.Bytes(), 0o644))
+
+	return base + ".ifo"
+}
+
+func TestStarDict(t *testing.T) {
+	articles := []stardictArticle{
+		{word: "apple", data: "a round fruit"},
+		{word: "Run", data: "to move fast\nto manage"},
+		{word: "run", data: "a period of running"},
+		{word: "pear", data: strings.Repeat("a sweet fruit ", 20)},
+	}
+
+	for name, chunkLen := range map[string]int{"dict": 0, "dictzip": 16} {
+		t.Run(name, func(t *testing.T) {
+			path := writeStarDict(t, t.TempDir(), "sametypesequence=m\nlang=en-ru\n", articles, chunkLen)
+
+			d, err := Open(path)
+			require.NoError(t, err)
+			assert.Equal(t, "Test Dictionary", d.Name())
+			assert.Equal(t, model.Lang("en"), d.Lang())
+
+			found, err := d.Lookup("RUN")
+			require.NoError(t, err)
+			assert.Equal(t, []Article{
+				{Headword: "Run", Text: "to move fast\nto manage"},
+				{Headword: "run", Text: "a period of running"},
+			}, found)
+
+			found, err = d.Lookup("pear")
+			require.NoError(t, err)
+			require.Len(t, found, 1)
+			assert.Equal(t, strings.TrimSpace(articles[3].data), found[0].Text)
+
+			found, err = d.Lookup("plum")
+			require.NoError(t, err)
+			assert.Empty(t, found)
+		})
+	}
+}
+
+func TestStarDict_Types(t *testing.T) {
+	picture := "P\x00\x00\x00\x03abc"
+	path := writeStarDict(t, t.TempDir(), "", []stardictArticle{
+		{word: "apple", data: "tˈæpəl\x00" + picture + "h<b>1.</b> a round&nbsp;fruit<br>2. a tree\x00"},
+	}, 0)
+
+	d, err := OpenStarDict(path)
+	require.NoError(t, err)
+	assert.Empty(t, d.Lang())
+
+	found, err := d.Lookup("apple")
+	require.NoError(t, err)
+	assert.Equal(t, []Article{{Headword: "apple", Text: "[ˈæpəl]\n1. a round fruit\n2. a tree"}}, found)
+}
+
+func TestStarDict_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.ifo")
+	require.NoError(t, os.WriteFile(path, []byte("bookname=Test\n"), 0o644))
+	_, err := OpenStarDict(path)
+	assert.Error(t, err)
+
+	path = writeStarDict(t, dir, "", []stardictArticle{{word: "apple", data: "a round fruit"}}, 0)
+	require.NoError(t, os.Remove(filepath.Join(dir, "test.dict")))
+	_, err = OpenStarDict(path)
+	assert.Error(t, err)
+
+	_, err = Open(filepath.Join(dir, "test.txt"))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestDSL(t *testing.T) {
+	dsl := `#NAME "Test Dictionary"
+#INDEX_LANGUAGE "English"
+#CONTENTS_LANGUAGE "Russian"
+
+colo(u)r
+	[m1][b]1.[/b] [trn]цвет[/trn] [s]colour.wav[/s][/m]
+	[m1][b]2.[/b] [trn]краска[/trn] {{a comment}}[/m]
+	[m2][ex]the \[true\] colour[/ex] see <<hue>>[/m]
+
+{to }run
+running
+	[m1][trn]бежать[/trn][/m]
+`
+	encoded := utf16.Encode([]rune(dsl))
+	data := []byte{0xff, 0xfe}
+	for _, u := range encoded {
+		data = binary.LittleEndian.AppendUint16(data, u)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.dsl")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	d, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Dictionary", d.Name())
+	assert.Equal(t, model.Lang("en"), d.Lang())
+
+	colour := Article{Headword: "colo(u)r", Text: "1. цвет\n2. краска\nthe [true] colour see hue"}
+	for _, word := range []string{"color", "Colour"} {
+		found, err := d.Lookup(word)
+		require.NoError(t, err)
+		assert.Equal(t, []Article{colour}, found, word)
+	}
+
+	found, err := d.Lookup("run")
+	require.NoError(t, err)
+	assert.Equal(t, []Article{{Headword: "to run", Text: "бежать"}}, found)
+
+	found, err = d.Lookup("running")
+	require.NoError(t, err)
+	assert.Equal(t, []Article{{Headword: "running", Text: "бежать"}}, found)
+
+	found, err = d.Lookup("to run")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestDSL_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.dsl")
+	require.NoError(t, os.WriteFile(path, []byte("apple\n\tan apple\n"), 0o644))
+
+	_, err := OpenDSL(path)
+	assert.Error(t, err)
+}
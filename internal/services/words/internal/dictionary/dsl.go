@@ -0,0 +1,305 @@
+package dictionary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// dslLanguages maps the language names of DSL headers to language codes
+var dslLanguages = map[string]model.Lang{
+	"chinese":    "zh",
+	"czech":      "cs",
+	"danish":     "da",
+	"dutch":      "nl",
+	"english":    "en",
+	"finnish":    "fi",
+	"french":     "fr",
+	"german":     "de",
+	"greek":      "el",
+	"hungarian":  "hu",
+	"italian":    "it",
+	"japanese":   "ja",
+	"latin":      "la",
+	"norwegian":  "no",
+	"polish":     "pl",
+	"portuguese": "pt",
+	"russian":    "ru",
+	"spanish":    "es",
+	"swedish":    "sv",
+	"turkish":    "tr",
+	"ukrainian":  "uk",
+}
+
+// DSL is a dictionary in the DSL format of ABBYY Lingvo, a text file in UTF-16 or UTF-8, possibly compressed
+// as .dsl.dz. Headers start with #, headwords start at the beginning of a line and the lines of their
+// article are indented. The whole dictionary is kept in memory as text stripped of its markup.
+type DSL struct {
+	name  string
+	lang  model.Lang
+	index map[string][]int
+	// articles are the articles of the dictionary, in the order they appear in it
+	articles []Article
+}
+
+// OpenDSL reads the DSL dictionary at path, which is gzipped when it ends with .dz
+func OpenDSL(path string) (*DSL, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasSuffix(strings.ToLower(path), ".dz") {
+		data, err = readGzip(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dsl: %w", err)
+	}
+
+	d := &DSL{index: make(map[string][]int)}
+	if err := d.parse(decodeDSL(data)); err != nil {
+		return nil, fmt.Errorf("dsl %s: %w", path, err)
+	}
+
+	return d, nil
+}
+
+func (d *DSL) Name() string {
+	return d.name
+}
+
+func (d *DSL) Lang() model.Lang {
+	return d.lang
+}
+
+func (d *DSL) Lookup(word string) ([]Article, error) {
+	ids := d.index[key(word)]
+	articles := make([]Article, 0, len(ids))
+	for _, id := range ids {
+		articles = append(articles, d.articles[id])
+	}
+
+	return articles, nil
+}
+
+func (d *DSL) parse(text string) error {
+	var (
+		headwords []string
+		body      []string
+	)
+	flush := func() {
+		if len(headwords) > 0 {
+			if text := cleanText(strings.Join(body, "\n")); text != "" {
+				for _, hw := range headwords {
+					d.add(hw, text)
+				}
+			}
+		}
+		headwords, body = nil, nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "#"):
+			d.header(line)
+		case strings.TrimSpace(removeComments(line)) == "":
+			continue
+		case line[0] == ' ' || line[0] == '\t':
+			body = append(body, dslText(line))
+		default:
+			if len(body) > 0 {
+				flush()
+			}
+			headwords = append(headwords, line)
+		}
+	}
+	flush()
+
+	if d.name == "" {
+		return errors.New("#NAME header is required")
+	}
+
+	return nil
+}
+
+// header reads the name and the language of the headwords from their headers, other headers are skipped
+func (d *DSL) header(line string) {
+	name, value, _ := strings.Cut(strings.TrimPrefix(line, "#"), " ")
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	switch strings.ToUpper(name) {
+	case "NAME":
+		d.name = value
+	case "INDEX_LANGUAGE":
+		d.lang = dslLanguages[strings.ToLower(value)]
+	}
+}
+
+// add indexes the article of a headword under every form of the headword. Parts of headwords in braces are
+// shown but not indexed, and parts in parentheses are optional, so "colo(u)r" is indexed as color and colour.
+func (d *DSL) add(headword, text string) {
+	shown := dslUnescape(strings.NewReplacer("{", "", "}", "").Replace(removeComments(headword)))
+	d.articles = append(d.articles, Article{Headword: strings.TrimSpace(shown), Text: text})
+
+	id := len(d.articles) - 1
+	for _, form := range dslForms(removeBraced(removeComments(headword), '{', '}')) {
+		k := key(form)
+		if k != "" && (len(d.index[k]) == 0 || d.index[k][len(d.index[k])-1] != id) {
+			d.index[k] = append(d.index[k], id)
+		}
+	}
+}
+
+// dslForms expands the optional parts of a headword, the parts in unescaped parentheses
+func dslForms(headword string) []string {
+	start := -1
+	for i := 0; i < len(headword); i++ {
+		switch headword[i] {
+		case '\\':
+			i++
+		case '(':
+			start = i
+		case ')':
+			if start < 0 {
+				continue
+			}
+			without := dslForms(headword[:start] + headword[i+1:])
+			with := dslForms(headword[:start] + headword[start+1:i] + headword[i+1:])
+			return append(with, without...)
+		}
+	}
+
+	return []string{dslUnescape(headword)}
+}
+
+// dslText strips a line of an article of its markup. Tags in brackets are removed along with the sound and
+// picture files between [s] and [/s], and references in double angle brackets are kept as plain text.
+func dslText(line string) string {
+	line = removeComments(line)
+
+	var (
+		b     strings.Builder
+		media bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			if !media {
+				b.WriteByte(line[i])
+			}
+		case c == '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				i = len(line)
+				continue
+			}
+			switch line[i+1 : i+end] {
+			case "s":
+				media = true
+			case "/s":
+				media = false
+			}
+			i += end
+		case strings.HasPrefix(line[i:], "<<") || strings.HasPrefix(line[i:], ">>"):
+			i++
+		case !media:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// dslUnescape removes the backslashes escaping the special characters of a headword
+func dslUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func removeComments(s string) string {
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			return s
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			return s[:start]
+		}
+		s = s[:start] + s[start+end+2:]
+	}
+}
+
+// removeBraced removes the unescaped parts of s between open and close
+func removeBraced(s string, open, close byte) string {
+	var (
+		b     strings.Builder
+		depth int
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			if depth == 0 {
+				b.WriteString(s[i : i+2])
+			}
+			i++
+		case s[i] == open:
+			depth++
+		case s[i] == close && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// decodeDSL decodes a dictionary in UTF-16, telling the byte order by the byte order mark or else by the zero
+// bytes of ASCII characters, or in UTF-8
+func decodeDSL(data []byte) string {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order, data = binary.LittleEndian, data[2:]
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order, data = binary.BigEndian, data[2:]
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return string(data[3:])
+	case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+		order = binary.LittleEndian
+	case len(data) >= 2 && data[0] == 0 && data[1] != 0:
+		order = binary.BigEndian
+	}
+
+	if order == nil {
+		if !utf8.Valid(data) {
+			return strings.ToValidUTF8(string(data), "\uFFFD")
+		}
+		return string(data)
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+
+	return string(utf16.Decode(units))
+}
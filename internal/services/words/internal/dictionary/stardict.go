@@ -0,0 +1,469 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// stardictMagic is the first line of every .ifo file
+const stardictMagic = "StarDict's dict ifo file"
+
+const (
+	// maxStarDictWordLength is the maximum length of a headword in a StarDict index in bytes
+	maxStarDictWordLength = 256
+	// maxArticleSize is the maximum size of an article in bytes, larger ones are taken for a corrupt index
+	maxArticleSize = 16 << 20
+)
+
+var (
+	breakTag  = regexp.MustCompile(`(?i)<br\s*/?>|</?(p|div|li|tr)(\s[^>]*)?>`)
+	markupTag = regexp.MustCompile(`<[^>]*>`)
+)
+
+// StarDict is a dictionary in the StarDict format, made of an .ifo file describing it, an .idx file indexing
+// the headwords and a .dict file, usually compressed with dictzip as .dict.dz, holding the articles. The index is
+// kept in memory, and articles are read from the .dict file when they are looked up.
+type StarDict struct {
+	name  string
+	lang  model.Lang
+	types string
+	index map[string][]stardictEntry
+	data  dictData
+}
+
+type stardictEntry struct {
+	word   string
+	offset int64
+	size   int64
+}
+
+// OpenStarDict opens the StarDict dictionary described by the .ifo file at path. The .idx and .dict files are
+// looked up next to it, the index may be gzipped as .idx.gz and the articles compressed with dictzip as .dict.dz.
+func OpenStarDict(path string) (*StarDict, error) {
+	info, err := readIfo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &StarDict{
+		name:  info["bookname"],
+		lang:  model.Lang(strings.ToLower(strings.SplitN(info["lang"], "-", 2)[0])),
+		types: info["sametypesequence"],
+	}
+	if d.name == "" {
+		return nil, fmt.Errorf("stardict %s: bookname is required", path)
+	}
+
+	offsetBits := 32
+	if bits := info["idxoffsetbits"]; bits != "" {
+		offsetBits, err = strconv.Atoi(bits)
+		if err != nil || (offsetBits != 32 && offsetBits != 64) {
+			return nil, fmt.Errorf("stardict %s: invalid idxoffsetbits %q", path, bits)
+		}
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	d.index, err = readIdx(base, offsetBits)
+	if err != nil {
+		return nil, fmt.Errorf("stardict %s: %w", path, err)
+	}
+
+	d.data, err = openDictData(base)
+	if err != nil {
+		return nil, fmt.Errorf("stardict %s: %w", path, err)
+	}
+
+	return d, nil
+}
+
+func (d *StarDict) Name() string {
+	return d.name
+}
+
+func (d *StarDict) Lang() model.Lang {
+	return d.lang
+}
+
+func (d *StarDict) Lookup(word string) ([]Article, error) {
+	entries := d.index[key(word)]
+	articles := make([]Article, 0, len(entries))
+	for _, e := range entries {
+		data, err := d.data.read(e.offset, e.size)
+		if err != nil {
+			return nil, fmt.Errorf("read article of %q: %w", e.word, err)
+		}
+
+		text, err := stardictText(data, d.types)
+		if err != nil {
+			return nil, fmt.Errorf("read article of %q: %w", e.word, err)
+		}
+		if text != "" {
+			articles = append(articles, Article{Headword: e.word, Text: text})
+		}
+	}
+
+	return articles, nil
+}
+
+// readIfo reads the key=value options of an .ifo file
+func readIfo(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open stardict: %w", err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	if !s.Scan() || strings.TrimPrefix(strings.TrimSpace(s.Text()), "\ufeff") != stardictMagic {
+		return nil, fmt.Errorf("stardict %s: not an .ifo file", path)
+	}
+
+	info := make(map[string]string)
+	for s.Scan() {
+		if k, v, ok := strings.Cut(s.Text(), "="); ok {
+			info[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read stardict %s: %w", path, err)
+	}
+
+	return info, nil
+}
+
+// readIdx reads the index of the headwords, which are null terminated and followed by the offset and size of
+// their article in the .dict file as big endian integers
+func readIdx(base string, offsetBits int) (map[string][]stardictEntry, error) {
+	data, err := os.ReadFile(base + ".idx")
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = readGzip(base + ".idx.gz")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+
+	index := make(map[string][]stardictEntry)
+	offsetSize := offsetBits / 8
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 || end > maxStarDictWordLength || len(data) < end+1+offsetSize+4 {
+			return nil, errors.New("read index: truncated entry")
+		}
+
+		e := stardictEntry{word: string(data[:end])}
+		data = data[end+1:]
+		if offsetSize == 8 {
+			e.offset = int64(binary.BigEndian.Uint64(data))
+		} else {
+			e.offset = int64(binary.BigEndian.Uint32(data))
+		}
+		e.size = int64(binary.BigEndian.Uint32(data[offsetSize:]))
+		data = data[offsetSize+4:]
+		if e.size > maxArticleSize {
+			return nil, fmt.Errorf("read index: article of %q is too large", e.word)
+		}
+
+		k := key(e.word)
+		index[k] = append(index[k], e)
+	}
+
+	return index, nil
+}
+
+// stardictText turns the fields of an article into text. The types of the fields are given by the sametypesequence
+// option of the dictionary, or else by a type character starting every field. Lowercase types are text ending with
+// a null character, or with the article when they come last in the sametypesequence, and uppercase ones are binary
+// data, such as sounds and pictures, starting with their size.
+func stardictText(data []byte, types string) (string, error) {
+	var fields []string
+	for i := 0; len(data) > 0; i++ {
+		var typ byte
+		if types != "" {
+			if i == len(types) {
+				break
+			}
+			typ = types[i]
+		} else {
+			typ, data = data[0], data[1:]
+		}
+
+		last := types != "" && i == len(types)-1
+		var field []byte
+		switch {
+		case typ >= 'a' && typ <= 'z':
+			end := bytes.IndexByte(data, 0)
+			if last || end < 0 {
+				end = len(data)
+			}
+			field = data[:end]
+			data = data[min(end+1, len(data)):]
+		case typ >= 'A' && typ <= 'Z':
+			size := len(data)
+			if !last {
+				if len(data) < 4 {
+					return "", errors.New("truncated field")
+				}
+				size = int(binary.BigEndian.Uint32(data))
+				data = data[4:]
+			}
+			if size > len(data) {
+				return "", errors.New("truncated field")
+			}
+			data = data[size:]
+			continue
+		default:
+			return "", fmt.Errorf("unknown field type %q", typ)
+		}
+
+		switch typ {
+		case 'm', 'l', 'y', 'k':
+			fields = append(fields, string(field))
+		case 't':
+			fields = append(fields, "["+string(field)+"]")
+		case 'g', 'h', 'x':
+			fields = append(fields, stripMarkup(string(field)))
+		}
+	}
+
+	return cleanText(strings.Join(fields, "\n")), nil
+}
+
+// stripMarkup turns HTML, XDXF and Pango markup into text, breaking lines at line breaks and paragraphs
+func stripMarkup(s string) string {
+	s = breakTag.ReplaceAllString(s, "\n")
+	s = markupTag.ReplaceAllString(s, "")
+	return html.UnescapeString(s)
+}
+
+func readGzip(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+// dictData reads the articles of a .dict file
+type dictData interface {
+	read(offset, size int64) ([]byte, error)
+}
+
+// openDictData opens the .dict.dz file of a dictionary, or else the uncompressed .dict file. Files compressed
+// with dictzip are read a chunk at a time, and gzipped files without the chunk table are read into memory.
+func openDictData(base string) (dictData, error) {
+	dz, err := readDictzipHeader(base + ".dict.dz")
+	if err == nil {
+		return dz, nil
+	}
+	if errors.Is(err, errNoChunks) {
+		data, err := readGzip(base + ".dict.dz")
+		if err != nil {
+			return nil, fmt.Errorf("read articles: %w", err)
+		}
+		return memData(data), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read articles: %w", err)
+	}
+
+	if _, err := os.Stat(base + ".dict"); err != nil {
+		return nil, fmt.Errorf("read articles: %w", err)
+	}
+
+	return fileData(base + ".dict"), nil
+}
+
+type memData []byte
+
+func (d memData) read(offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 || offset+size > int64(len(d)) {
+		return nil, errors.New("article out of range")
+	}
+
+	return d[offset : offset+size], nil
+}
+
+// fileData reads articles from an uncompressed file, which is opened on every read so that nothing stays open
+type fileData string
+
+func (d fileData) read(offset, size int64) ([]byte, error) {
+	f, err := os.Open(string(d))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	if _, err := f.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+var errNoChunks = errors.New("dictzip chunk table not found")
+
+// gzip header flags
+const (
+	gzipFlagHCRC    = 1 << 1
+	gzipFlagExtra   = 1 << 2
+	gzipFlagName    = 1 << 3
+	gzipFlagComment = 1 << 4
+)
+
+// dictzipData reads articles from a dictzip file, a gzip file whose data is compressed in chunks that are
+// flushed so that each can be decompressed on its own. The gzip header lists the compressed size of every chunk
+// in its RA extra field.
+type dictzipData struct {
+	path       string
+	chunkLen   int64
+	dataOffset int64
+	// chunkOffsets are the offsets of the chunks in the file
+	chunkOffsets []int64
+}
+
+func readDictzipHeader(path string) (*dictzipData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("read gzip header: %w", err)
+	}
+	if header[0] != 0x1f || header[1] != 0x8b || header[2] != 8 {
+		return nil, errors.New("not a gzip file")
+	}
+
+	flags := header[3]
+	offset := int64(len(header))
+	if flags&gzipFlagExtra == 0 {
+		return nil, errNoChunks
+	}
+
+	var xlen [2]byte
+	if _, err := io.ReadFull(r, xlen[:]); err != nil {
+		return nil, fmt.Errorf("read gzip header: %w", err)
+	}
+	extra := make([]byte, binary.LittleEndian.Uint16(xlen[:]))
+	if _, err := io.ReadFull(r, extra); err != nil {
+		return nil, fmt.Errorf("read gzip header: %w", err)
+	}
+	offset += 2 + int64(len(extra))
+
+	var (
+		chunkLen int64
+		sizes    []int64
+	)
+	for len(extra) >= 4 {
+		id := string(extra[:2])
+		n := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if len(extra) < 4+n {
+			break
+		}
+		field := extra[4 : 4+n]
+		extra = extra[4+n:]
+
+		if id != "RA" || len(field) < 6 {
+			continue
+		}
+		chunkLen = int64(binary.LittleEndian.Uint16(field[2:4]))
+		count := int(binary.LittleEndian.Uint16(field[4:6]))
+		if len(field) < 6+2*count {
+			return nil, errors.New("truncated dictzip chunk table")
+		}
+		for i := range count {
+			sizes = append(sizes, int64(binary.LittleEndian.Uint16(field[6+2*i:])))
+		}
+	}
+	if chunkLen == 0 {
+		return nil, errNoChunks
+	}
+
+	for _, flag := range []byte{gzipFlagName, gzipFlagComment} {
+		if flags&flag == 0 {
+			continue
+		}
+		s, err := r.ReadBytes(0)
+		if err != nil {
+			return nil, fmt.Errorf("read gzip header: %w", err)
+		}
+		offset += int64(len(s))
+	}
+	if flags&gzipFlagHCRC != 0 {
+		offset += 2
+	}
+
+	d := &dictzipData{path: path, chunkLen: chunkLen, dataOffset: offset}
+	for _, size := range sizes {
+		d.chunkOffsets = append(d.chunkOffsets, offset)
+		offset += size
+	}
+	d.chunkOffsets = append(d.chunkOffsets, offset)
+
+	return d, nil
+}
+
+func (d *dictzipData) read(offset, size int64) ([]byte, error) {
+	if offset < 0 || size < 0 {
+		return nil, errors.New("article out of range")
+	}
+
+	first := offset / d.chunkLen
+	last := (offset + size - 1) / d.chunkLen
+	if size == 0 {
+		last = first
+	}
+	if last >= int64(len(d.chunkOffsets)-1) {
+		return nil, errors.New("article out of range")
+	}
+
+	f, err := os.Open(d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	start, end := d.chunkOffsets[first], d.chunkOffsets[last+1]
+	compressed := make([]byte, end-start)
+	if _, err := f.ReadAt(compressed, start); err != nil {
+		return nil, err
+	}
+
+	// the chunks are flushed rather than finished, so the data of the last one is read without waiting for the end
+	zr := flate.NewReader(bytes.NewReader(compressed))
+	defer zr.Close()
+
+	skip := offset - first*d.chunkLen
+	buf := make([]byte, skip+size)
+	if _, err := io.ReadFull(zr, buf); err != nil {
+		return nil, fmt.Errorf("decompress chunks: %w", err)
+	}
+
+	return buf[skip:], nil
+}
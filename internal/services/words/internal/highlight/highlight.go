@@ -0,0 +1,109 @@
+// Package highlight finds where a lemma occurs in a sentence, so that clients can highlight
+// the picked word in the context sentences of picks.
+package highlight
+
+import "unicode"
+
+const (
+	// minStemLength is the minimum length of a lemma word that inflected forms are looked up for,
+	// shorter words such as "go" would otherwise match unrelated words such as "good"
+	minStemLength = 3
+	// maxSuffixLength is the maximum number of characters an inflected form adds to the stem of a lemma word
+	maxSuffixLength = 4
+)
+
+// Span is a range of a sentence in characters (Unicode code points), Start is inclusive and End is exclusive
+type Span struct {
+	Start int
+	End   int
+}
+
+type word struct {
+	start, end int
+	// text is the lowercased word
+	text []rune
+}
+
+// Find returns the span of the first occurrence of the lemma in the sentence. Occurrences that differ
+// only in case are preferred, otherwise words starting with the lemma or its stem are matched, such as
+// "running" for "run" or "studies" for "study". Lemmas of several words, such as "give up", match the
+// same words in a row. Irregular forms such as "went" for "go" are not recognized and false is returned.
+func Find(sentence, lemma string) (Span, bool) {
+	words := split([]rune(sentence))
+	parts := split([]rune(lemma))
+	if len(parts) == 0 {
+		return Span{}, false
+	}
+
+	for _, match := range []func(w, part []rune) bool{exact, inflected} {
+		for i := 0; i+len(parts) <= len(words); i++ {
+			if matchAt(words[i:i+len(parts)], parts, match) {
+				return Span{Start: words[i].start, End: words[i+len(parts)-1].end}, true
+			}
+		}
+	}
+
+	return Span{}, false
+}
+
+func matchAt(words, parts []word, match func(w, part []rune) bool) bool {
+	for i, part := range parts {
+		if !match(words[i].text, part.text) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func exact(w, part []rune) bool {
+	return string(w) == string(part)
+}
+
+// inflected matches the forms of the part as well as the part itself, so that the other words
+// of a lemma of several words may still match exactly
+func inflected(w, part []rune) bool {
+	if exact(w, part) {
+		return true
+	}
+	if len(part) < minStemLength {
+		return false
+	}
+
+	stem := part
+	if last := part[len(part)-1]; len(part) > minStemLength && (last == 'e' || last == 'y') {
+		stem = part[:len(part)-1]
+	}
+
+	return len(w) >= len(stem) && len(w)-len(stem) <= maxSuffixLength && string(w[:len(stem)]) == string(stem)
+}
+
+// split cuts the text into words of letters, digits and combining marks
+func split(text []rune) []word {
+	var words []word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, newWord(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, newWord(text, start, len(text)))
+	}
+
+	return words
+}
+
+func newWord(text []rune, start, end int) word {
+	lower := make([]rune, end-start)
+	for i, r := range text[start:end] {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	return word{start: start, end: end, text: lower}
+}
@@ -0,0 +1,38 @@
+package highlight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	for name, tc := range map[string]struct {
+		sentence string
+		lemma    string
+		want     Span
+		found    bool
+	}{
+		"exact":          {"I ate an apple today.", "apple", Span{9, 14}, true},
+		"case":           {"Apple pie is sweet.", "apple", Span{0, 5}, true},
+		"exact first":    {"Running late, I run home.", "run", Span{16, 19}, true},
+		"doubled":        {"She was running late.", "run", Span{8, 15}, true},
+		"dropped e":      {"We are making bread.", "make", Span{7, 13}, true},
+		"y to i":         {"He studies every night.", "study", Span{3, 10}, true},
+		"phrase":         {"Never give up on your dreams.", "give up", Span{6, 13}, true},
+		"phrase forms":   {"She gave up, then gives up again.", "give up", Span{18, 26}, true},
+		"short lemma":    {"This is good.", "go", Span{}, false},
+		"irregular":      {"They went home.", "go", Span{}, false},
+		"unicode":        {"Ça me plaît énormément.", "énormément", Span{12, 22}, true},
+		"cyrillic":       {"Я читаю КНИГУ.", "книгу", Span{8, 13}, true},
+		"long suffix":    {"The applesauceification.", "apple", Span{}, false},
+		"empty lemma":    {"Nothing to find.", "", Span{}, false},
+		"empty sentence": {"", "apple", Span{}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			span, found := Find(tc.sentence, tc.lemma)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.want, span)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package image
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/url"
@@ -11,3 +12,7 @@ type NoStore struct{}
 func (s *NoStore) SaveImage(img io.Reader) (*url.URL, error) {
 	return nil, errors.New("image store is not supported")
 }
+
+func (s *NoStore) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	return nil, errors.New("image store is not supported")
+}
@@ -3,28 +3,93 @@ package image
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
+// defaultTimeout bounds the requests to the image service, including reading the response
+const defaultTimeout = 30 * time.Second
+
+// ErrForeignURL is returned when an image to fetch is not served by the image service
+var ErrForeignURL = errors.New("image is not served by the image service")
+
+// tokenSource issues service tokens for calling other services
+type tokenSource interface {
+	Token(audience string) (string, error)
+}
+
 type RemoteStore struct {
 	Url       string
 	FieldName string
 	FileName  string
+	// ServeRoot is the URL the image service serves the saved images under, only images under it are fetched
+	ServeRoot *url.URL
 	client    *http.Client
+	tokens    tokenSource
+	audience  string
 }
 
-func NewRemoteStore(url, fieldName, fileName string) *RemoteStore {
-	return &RemoteStore{
-		Url:       url,
+type RemoteStoreOption func(*RemoteStore) *RemoteStore
+
+// WithServiceToken authenticates uploads with service tokens issued for the audience service
+func WithServiceToken(src tokenSource, audience string) RemoteStoreOption {
+	return func(s *RemoteStore) *RemoteStore {
+		s.tokens = src
+		s.audience = audience
+		return s
+	}
+}
+
+// WithServeRoot sets the URL the image service serves the saved images under
+func WithServeRoot(root *url.URL) RemoteStoreOption {
+	return func(s *RemoteStore) *RemoteStore {
+		s.ServeRoot = root
+		return s
+	}
+}
+
+// WithTimeout bounds the requests to the image service
+func WithTimeout(timeout time.Duration) RemoteStoreOption {
+	return func(s *RemoteStore) *RemoteStore {
+		s.client.Timeout = timeout
+		return s
+	}
+}
+
+// NewRemoteStore creates a store that saves images to the image service at the upload endpoint.
+// Unless WithServeRoot sets another one, the saved images are fetched from the origin of the endpoint.
+func NewRemoteStore(endpoint, fieldName, fileName string, opts ...RemoteStoreOption) *RemoteStore {
+	s := &RemoteStore{
+		Url:       endpoint,
 		FieldName: fieldName,
 		FileName:  fileName,
-		client:    &http.Client{},
 	}
+	if u, err := url.Parse(endpoint); err == nil {
+		s.ServeRoot = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	}
+	s.client = &http.Client{
+		Timeout: defaultTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !s.serves(req.URL) {
+				return ErrForeignURL
+			}
+			return nil
+		},
+	}
+	for _, opt := range opts {
+		s = opt(s)
+	}
+
+	return s
 }
 
 type saveImageResponse struct {
@@ -55,13 +120,21 @@ func (s *RemoteStore) SaveImage(ctx context.Context, img io.Reader) (*url.URL, e
 	}
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
+	if s.tokens != nil {
+		tk, err := s.tokens.Token(s.audience)
+		if err != nil {
+			return nil, fmt.Errorf("issue service token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+tk)
+	}
+
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("post image: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -79,3 +152,57 @@ func (s *RemoteStore) SaveImage(ctx context.Context, img io.Reader) (*url.URL, e
 
 	return imgUrl, nil
 }
+
+// FetchImage downloads an image the store saved, the caller closes the returned reader.
+// It returns ErrForeignURL for images outside of the serve root.
+func (s *RemoteStore) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse image URL: %w", err)
+	}
+	if !s.serves(u) {
+		return nil, ErrForeignURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get image: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// serves tells whether the URL is under the serve root
+func (s *RemoteStore) serves(u *url.URL) bool {
+	root := s.ServeRoot
+	if root == nil || root.Host == "" || u.User != nil {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, root.Scheme) || !strings.EqualFold(u.Host, root.Host) {
+		return false
+	}
+
+	// dot segments could climb out of the root
+	for _, seg := range strings.Split(u.Path, "/") {
+		if seg == "." || seg == ".." {
+			return false
+		}
+	}
+
+	prefix := root.EscapedPath()
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return strings.HasPrefix(u.EscapedPath(), prefix)
+}
@@ -2,27 +2,30 @@ package image
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gamma-omg/lexi-go/internal/pkg/svctoken"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestRemoteSaveImage(t *testing.T) {
-	srv := http.Server{
-		Addr: ":9999",
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			enc := json.NewEncoder(w)
-			_ = enc.Encode(saveImageResponse{
-				ImageURL: "http://localhost:9999/images/test.jpg",
-			})
-		}),
-	}
-
-	go srv.ListenAndServe()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		enc := json.NewEncoder(w)
+		_ = enc.Encode(saveImageResponse{
+			ImageURL: "http://localhost:9999/images/test.jpg",
+		})
+	}))
+	defer srv.Close()
 
-	s := NewRemoteStore("http://localhost:9999/", "image", "test.jpg")
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg")
 
 	imgURL, err := s.SaveImage(t.Context(), strings.NewReader("test image content"))
 	require.NoError(t, err)
@@ -30,18 +33,112 @@ func TestRemoteSaveImage(t *testing.T) {
 	require.Equal(t, "http://localhost:9999/images/test.jpg", imgURL.String())
 }
 
+func TestRemoteSaveImage_ServiceToken(t *testing.T) {
+	key := []byte("service-secret")
+	verifier := svctoken.NewVerifier("image", key)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tk, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+
+		claims, err := verifier.Verify(tk)
+		require.NoError(t, err)
+		assert.Equal(t, "words", claims.Service())
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(saveImageResponse{
+			ImageURL: "http://localhost:9999/images/test.jpg",
+		})
+	}))
+	defer srv.Close()
+
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg",
+		WithServiceToken(svctoken.NewSigner("words", key, time.Minute), "image"))
+
+	_, err := s.SaveImage(t.Context(), strings.NewReader("test image content"))
+	require.NoError(t, err)
+}
+
 func TestRemoteSaveImage_BadResponse(t *testing.T) {
-	srv := http.Server{
-		Addr: ":9998",
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg")
+
+	_, err := s.SaveImage(t.Context(), strings.NewReader("test image content"))
+	require.Error(t, err)
+}
+
+func TestRemoteFetchImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/test.jpg" {
 			w.WriteHeader(http.StatusNotFound)
-		}),
+			return
+		}
+		_, _ = w.Write([]byte("test image content"))
+	}))
+	defer srv.Close()
+
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg")
+	img, err := s.FetchImage(t.Context(), srv.URL+"/images/test.jpg")
+	require.NoError(t, err)
+	defer img.Close()
+
+	data, err := io.ReadAll(img)
+	require.NoError(t, err)
+	assert.Equal(t, "test image content", string(data))
+
+	_, err = s.FetchImage(t.Context(), srv.URL+"/images/missing.jpg")
+	require.Error(t, err)
+}
+
+func TestRemoteFetchImage_ServeRoot(t *testing.T) {
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		if r.URL.Path == "/images/redirect.jpg" {
+			http.Redirect(w, r, "/private/secret", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("test image content"))
+	}))
+	defer srv.Close()
+
+	root, err := url.Parse(srv.URL + "/images")
+	require.NoError(t, err)
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg", WithServeRoot(root))
+
+	img, err := s.FetchImage(t.Context(), srv.URL+"/images/test.jpg")
+	require.NoError(t, err)
+	img.Close()
+
+	for _, u := range []string{
+		srv.URL + "/private/secret",
+		srv.URL + "/imagesecret",
+		srv.URL + "/images/../private/secret",
+		srv.URL + "/images/%2e%2e/private/secret",
+		"http://169.254.169.254/images/test.jpg",
+		"file:///images/test.jpg",
+		strings.Replace(srv.URL, "http://", "http://user@", 1) + "/images/test.jpg",
+	} {
+		_, err := s.FetchImage(t.Context(), u)
+		assert.ErrorIs(t, err, ErrForeignURL, u)
 	}
 
-	go srv.ListenAndServe()
+	_, err = s.FetchImage(t.Context(), srv.URL+"/images/redirect.jpg")
+	assert.ErrorIs(t, err, ErrForeignURL)
+	assert.Equal(t, []string{"/images/test.jpg", "/images/redirect.jpg"}, fetched)
+}
 
-	s := NewRemoteStore("http://localhost:9998/", "image", "test.jpg")
+func TestRemoteFetchImage_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
 
-	_, err := s.SaveImage(t.Context(), strings.NewReader("test image content"))
+	s := NewRemoteStore(srv.URL+"/upload", "image", "test.jpg", WithTimeout(50*time.Millisecond))
+	_, err := s.FetchImage(t.Context(), srv.URL+"/images/test.jpg")
 	require.Error(t, err)
 }
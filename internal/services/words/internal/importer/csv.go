@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// csvColumns are the columns a CSV dictionary may have, the ones marked true are required
+var csvColumns = map[string]bool{
+	"lemma":      true,
+	"lang":       true,
+	"class":      true,
+	"definition": true,
+	"rarity":     false,
+	"source":     false,
+	"image_urls": false,
+}
+
+// CSVReader reads dictionaries in CSV format with one definition per row. The first row names
+// the columns: lemma, lang, class and definition, and optionally rarity, source and image_urls.
+// Image URLs are separated by whitespace. Rows of the same word add definitions to it.
+type CSVReader struct {
+	r *csv.Reader
+	// columns maps the column names to their index, it is nil until the header is read
+	columns map[string]int
+}
+
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	return &CSVReader{r: cr}
+}
+
+func (r *CSVReader) Next() (Entry, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return Entry{}, &RecordError{Line: pe.StartLine, Err: pe.Err}
+		}
+		if errors.Is(err, io.EOF) {
+			return Entry{}, io.EOF
+		}
+
+		return Entry{}, fmt.Errorf("read csv: %w", err)
+	}
+
+	line, _ := r.r.FieldPos(0)
+	def := Definition{
+		Text:      r.field(record, "definition"),
+		Source:    model.DataSource(r.field(record, "source")),
+		ImageURLs: strings.Fields(r.field(record, "image_urls")),
+	}
+	if rarity := r.field(record, "rarity"); rarity != "" {
+		v, err := strconv.Atoi(rarity)
+		if err != nil {
+			return Entry{}, &RecordError{Line: line, Err: fmt.Errorf("invalid rarity %q", rarity)}
+		}
+		def.Rarity = v
+	}
+
+	return Entry{
+		Line:        line,
+		Lemma:       r.field(record, "lemma"),
+		Lang:        model.Lang(r.field(record, "lang")),
+		Class:       model.WordClass(r.field(record, "class")),
+		Definitions: []Definition{def},
+	}, nil
+}
+
+func (r *CSVReader) readHeader() error {
+	header, err := r.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+		return fmt.Errorf("read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := csvColumns[name]; !ok {
+			return fmt.Errorf("unknown csv column %q", name)
+		}
+		columns[name] = i
+	}
+	for name, required := range csvColumns {
+		if _, ok := columns[name]; required && !ok {
+			return fmt.Errorf("missing csv column %q", name)
+		}
+	}
+
+	r.columns = columns
+	return nil
+}
+
+// field returns the value of the named column, empty when the CSV does not have the column
+func (r *CSVReader) field(record []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+
+	return record[i]
+}
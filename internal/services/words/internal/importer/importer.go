@@ -0,0 +1,109 @@
+// Package importer reads dictionary files, such as CSV and JSONL exports, Wiktionary extracts and WordNet
+// lexicons, into entries that are imported into the global dictionary of words and definitions.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+var (
+	// ErrUnknownFormat is returned for formats there is no reader for
+	ErrUnknownFormat = errors.New("unknown import format")
+	// ErrLangRequired is returned for formats of multilingual dictionaries when no language is given
+	ErrLangRequired = errors.New("language is required")
+)
+
+// Format is the file format of a dictionary
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+	// Kaikki is the JSONL format of the Wiktionary extracts published by kaikki.org
+	Kaikki Format = "kaikki"
+	// WordNet is the WN-LMF XML format of WordNet lexicons
+	WordNet Format = "wordnet"
+)
+
+// Options configure the readers of some formats
+type Options struct {
+	// Lang is the language the entries of multilingual dictionaries are read for
+	Lang model.Lang
+}
+
+// Entry is a word of a dictionary together with its definitions
+type Entry struct {
+	// Line is the line of the input the entry starts on
+	Line        int
+	Lemma       string
+	Lang        model.Lang
+	Class       model.WordClass
+	Definitions []Definition
+}
+
+type Definition struct {
+	Text   string
+	Rarity int
+	// Source is the origin of the definition and of its images, it may be empty when it is unknown
+	Source    model.DataSource
+	ImageURLs []string
+}
+
+// Reader reads the entries of a dictionary one at a time. Next returns io.EOF after the last entry
+// and a *RecordError for records that cannot be read, reading can go on after a *RecordError.
+type Reader interface {
+	Next() (Entry, error)
+}
+
+// RecordError tells why a record of the input could not be read
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// NewReader returns a reader for dictionaries in the given format
+func NewReader(f Format, r io.Reader, opts Options) (Reader, error) {
+	switch f {
+	case CSV:
+		return NewCSVReader(r), nil
+	case JSONL:
+		return NewJSONLReader(r), nil
+	case Kaikki:
+		if opts.Lang == "" {
+			return nil, fmt.Errorf("%w for %s", ErrLangRequired, f)
+		}
+		return NewKaikkiReader(r, opts.Lang), nil
+	case WordNet:
+		return NewWordNetReader(r), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, f)
+	}
+}
+
+// FormatOf guesses the format of a dictionary file from its extension
+func FormatOf(name string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV, true
+	case ".jsonl", ".ndjson":
+		return JSONL, true
+	case ".xml":
+		return WordNet, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads all entries, collecting the lines of records that could not be read
+func readAll(t *testing.T, r Reader) ([]Entry, []int) {
+	t.Helper()
+
+	var (
+		entries []Entry
+		failed  []int
+	)
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, failed
+		}
+
+		var re *RecordError
+		if errors.As(err, &re) {
+			failed = append(failed, re.Line)
+			continue
+		}
+		require.NoError(t, err)
+		entries = append(entries, e)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	r := NewCSVReader(strings.NewReader(`Lemma,lang,class,definition,rarity,image_urls
+apple,en,noun,A round fruit.,2,https://example.com/a.jpg https://example.com/b.jpg
+run,en,verb,"To move fast,
+on foot.",,
+bad,en,noun,Rarity is not a number.,often,
+fraction,en,noun,Rarity is not an integer.,1.5,
+short,en
+`))
+
+	entries, failed := readAll(t, r)
+	assert.Equal(t, []int{5, 6, 7}, failed)
+	assert.Equal(t, []Entry{
+		{
+			Line:  2,
+			Lemma: "apple",
+			Lang:  "en",
+			Class: "noun",
+			Definitions: []Definition{{
+				Text:      "A round fruit.",
+				Rarity:    2,
+				ImageURLs: []string{"https://example.com/a.jpg", "https://example.com/b.jpg"},
+			}},
+		},
+		{
+			Line:        3,
+			Lemma:       "run",
+			Lang:        "en",
+			Class:       "verb",
+			Definitions: []Definition{{Text: "To move fast,\non foot.", ImageURLs: []string{}}},
+		},
+	}, entries)
+}
+
+func TestCSVReader_Header(t *testing.T) {
+	for name, input := range map[string]string{
+		"missing column": "lemma,lang,class\napple,en,noun\n",
+		"unknown column": "lemma,lang,class,definition,color\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewCSVReader(strings.NewReader(input)).Next()
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, io.EOF)
+
+			var re *RecordError
+			assert.False(t, errors.As(err, &re))
+		})
+	}
+
+	_, err := NewCSVReader(strings.NewReader("")).Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestJSONLReader(t *testing.T) {
+	r := NewJSONLReader(strings.NewReader(`{"lemma": "apple", "lang": "en", "class": "noun", "definitions": [{"text": "A round fruit.", "rarity": 2, "source": "user", "image_urls": ["https://example.com/a.jpg"]}]}
+
+{"lemma": "broken",
+{"lemma": "run", "lang": "en", "class": "verb", "definitions": [{"text": "To move fast."}, {"text": "To manage."}]}
+{"lemma": "fraction", "lang": "en", "class": "noun", "definitions": [{"text": "Rarity is not an integer.", "rarity": 1.5}]}
+`))
+
+	entries, failed := readAll(t, r)
+	assert.Equal(t, []int{3, 5}, failed)
+	assert.Equal(t, []Entry{
+		{
+			Line:  1,
+			Lemma: "apple",
+			Lang:  "en",
+			Class: "noun",
+			Definitions: []Definition{{
+				Text:      "A round fruit.",
+				Rarity:    2,
+				Source:    "user",
+				ImageURLs: []string{"https://example.com/a.jpg"},
+			}},
+		},
+		{
+			Line:        4,
+			Lemma:       "run",
+			Lang:        "en",
+			Class:       "verb",
+			Definitions: []Definition{{Text: "To move fast."}, {Text: "To manage."}},
+		},
+	}, entries)
+}
+
+func TestJSONLReader_LongLine(t *testing.T) {
+	r := NewJSONLReader(strings.NewReader(strings.Repeat("a", maxJSONLLineSize+1)))
+	_, err := r.Next()
+	require.Error(t, err)
+
+	var re *RecordError
+	assert.False(t, errors.As(err, &re))
+}
+
+func TestKaikkiReader(t *testing.T) {
+	r := NewKaikkiReader(strings.NewReader(`{"word": "apple", "lang_code": "en", "pos": "noun", "senses": [{"glosses": ["A common, round fruit."]}, {"glosses": ["A tree.", "The tree bearing apples."]}, {"glosses": ["A common, round fruit."]}]}
+{"word": "apples", "lang_code": "en", "pos": "noun", "senses": [{"glosses": ["plural of apple"], "form_of": [{"word": "apple"}], "tags": ["form-of", "plural"]}]}
+{"word": "Apfel", "lang_code": "de", "pos": "noun", "senses": [{"glosses": ["apple"]}]}
+{"word": "London", "lang_code": "en", "pos": "name", "senses": [{"glosses": ["The capital of England."]}]}
+{"word": "run", "lang_code": "en", "pos": "verb", "senses": [{"glosses": ["To move quickly on foot."]}, {"tags": ["no-gloss"]}]}
+{"word": "broken"
+{"word": "quickly", "lang_code": "en", "pos": "adv", "senses": [{"glosses": ["  Rapidly.  "]}, {"glosses": ["archaic spelling of quick"], "alt_of": [{"word": "quick"}]}]}
+`), "en")
+
+	entries, failed := readAll(t, r)
+	assert.Equal(t, []int{6}, failed)
+	assert.Equal(t, []Entry{
+		{
+			Line:  1,
+			Lemma: "apple",
+			Lang:  "en",
+			Class: "noun",
+			Definitions: []Definition{
+				{Text: "A common, round fruit.", Source: "wiktionary"},
+				{Text: "The tree bearing apples.", Source: "wiktionary"},
+			},
+		},
+		{Line: 5, Lemma: "run", Lang: "en", Class: "verb", Definitions: []Definition{{Text: "To move quickly on foot.", Source: "wiktionary"}}},
+		{Line: 7, Lemma: "quickly", Lang: "en", Class: "adverb", Definitions: []Definition{{Text: "Rapidly.", Source: "wiktionary"}}},
+	}, entries)
+}
+
+func TestNewReader(t *testing.T) {
+	_, err := NewReader("xml", strings.NewReader(""), Options{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = NewReader(Kaikki, strings.NewReader(""), Options{})
+	assert.ErrorIs(t, err, ErrLangRequired)
+
+	f, ok := FormatOf("/data/Words.NDJSON")
+	assert.True(t, ok)
+	assert.Equal(t, JSONL, f)
+
+	_, ok = FormatOf("words.txt")
+	assert.False(t, ok)
+}
+
+func TestWordNetReader(t *testing.T) {
+	r := NewWordNetReader(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE LexicalResource SYSTEM "http://globalwordnet.github.io/schemas/WN-LMF-1.1.dtd">
+<LexicalResource xmlns:dc="https://globalwordnet.github.io/schemas/dc/">
+  <Lexicon id="test" label="Test WordNet" language="en" email="a@example.com" license="MIT" version="1">
+    <LexicalEntry id="test-dog-n">
+      <Lemma writtenForm="dog" partOfSpeech="n"/>
+      <Sense id="test-dog-n-1" synset="test-1-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-domestic_dog-n">
+      <Lemma writtenForm="domestic dog" partOfSpeech="n"/>
+      <Sense id="test-domestic_dog-n-1" synset="test-1-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-canine-n">
+      <Lemma writtenForm="canine" partOfSpeech="n"/>
+      <Sense id="test-canine-n-1" synset="test-2-n"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-hot-a">
+      <Lemma writtenForm="hot" partOfSpeech="a"/>
+      <Sense id="test-hot-a-1" synset="test-3-a">
+        <SenseRelation relType="antonym" target="test-cold-a-1"/>
+        <SenseRelation relType="also" target="test-cold-a-1"/>
+      </Sense>
+    </LexicalEntry>
+    <LexicalEntry id="test-cold-a">
+      <Lemma writtenForm="cold" partOfSpeech="a"/>
+      <Sense id="test-cold-a-1" synset="test-4-a"/>
+      <Sense id="test-cold-a-2" synset="test-missing"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-oh-u">
+      <Lemma writtenForm="oh" partOfSpeech="u"/>
+      <Sense id="test-oh-u-1" synset="test-5-u"/>
+    </LexicalEntry>
+    <Synset id="test-1-n" partOfSpeech="n" members="test-dog-n test-domestic_dog-n">
+      <Definition>a member of the genus Canis</Definition>
+      <Example>the dog barked all night</Example>
+      <SynsetRelation relType="hypernym" target="test-2-n"/>
+    </Synset>
+    <Synset id="test-2-n" partOfSpeech="n" members="test-canine-n">
+      <Definition>any of various fissiped mammals</Definition>
+      <SynsetRelation relType="hyponym" target="test-1-n"/>
+    </Synset>
+    <Synset id="test-3-a" partOfSpeech="a" members="test-hot-a">
+      <Definition> used of physical heat </Definition>
+    </Synset>
+    <Synset id="test-4-a" partOfSpeech="a" members="test-cold-a">
+      <Definition>having a low temperature</Definition>
+    </Synset>
+    <Synset id="test-5-u" partOfSpeech="u" members="test-oh-u">
+      <Definition>an exclamation</Definition>
+    </Synset>
+  </Lexicon>
+</LexicalResource>
+`))
+
+	entries, failed := readAll(t, r)
+	assert.Empty(t, failed)
+
+	def := func(text string) []Definition { return []Definition{{Text: text, Source: "wordnet"}} }
+	assert.Equal(t, []Entry{
+		{Line: 5, Lemma: "dog", Lang: "en", Class: "noun", Definitions: def("a member of the genus Canis")},
+		{Line: 9, Lemma: "domestic dog", Lang: "en", Class: "noun", Definitions: def("a member of the genus Canis")},
+		{Line: 13, Lemma: "canine", Lang: "en", Class: "noun", Definitions: def("any of various fissiped mammals")},
+		{Line: 17, Lemma: "hot", Lang: "en", Class: "adjective", Definitions: def("used of physical heat")},
+		{Line: 24, Lemma: "cold", Lang: "en", Class: "adjective", Definitions: def("having a low temperature")},
+	}, entries)
+
+	var (
+		dog       = DefinitionRef{"dog", "en", "noun", "a member of the genus Canis"}
+		domestic  = DefinitionRef{"domestic dog", "en", "noun", "a member of the genus Canis"}
+		canine    = DefinitionRef{"canine", "en", "noun", "any of various fissiped mammals"}
+		hot       = DefinitionRef{"hot", "en", "adjective", "used of physical heat"}
+		cold      = DefinitionRef{"cold", "en", "adjective", "having a low temperature"}
+		relations = r.Relations()
+	)
+	assert.Equal(t, []Relation{
+		{From: dog, To: domestic, Type: "synonym"},
+		{From: domestic, To: dog, Type: "synonym"},
+		{From: dog, To: canine, Type: "hypernym"},
+		{From: domestic, To: canine, Type: "hypernym"},
+		{From: canine, To: dog, Type: "hyponym"},
+		{From: canine, To: domestic, Type: "hyponym"},
+		{From: hot, To: cold, Type: "antonym"},
+	}, relations)
+}
+
+func TestWordNetReader_Invalid(t *testing.T) {
+	_, err := NewWordNetReader(strings.NewReader(`<LexicalResource><Lexicon language="en"><LexicalEntry>`)).Next()
+	require.Error(t, err)
+
+	var re *RecordError
+	assert.False(t, errors.As(err, &re))
+}
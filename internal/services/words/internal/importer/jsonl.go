@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// maxJSONLLineSize is the maximum size of a line of a JSONL dictionary in bytes
+const maxJSONLLineSize = 1 << 20
+
+type jsonlDefinition struct {
+	Text      string   `json:"text"`
+	Rarity    int      `json:"rarity"`
+	Source    string   `json:"source"`
+	ImageURLs []string `json:"image_urls"`
+}
+
+type jsonlEntry struct {
+	Lemma       string            `json:"lemma"`
+	Lang        string            `json:"lang"`
+	Class       string            `json:"class"`
+	Definitions []jsonlDefinition `json:"definitions"`
+}
+
+// JSONLReader reads dictionaries with one JSON object per line, such as
+// {"lemma": "apple", "lang": "en", "class": "noun", "definitions": [{"text": "A round fruit.", "rarity": 1, "source": "user", "image_urls": []}]}.
+// Blank lines are skipped.
+type JSONLReader struct {
+	lines *lineScanner
+}
+
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	return &JSONLReader{lines: newLineScanner(r, maxJSONLLineSize)}
+}
+
+func (r *JSONLReader) Next() (Entry, error) {
+	line, err := r.lines.next()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var e jsonlEntry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Entry{}, &RecordError{Line: r.lines.line, Err: err}
+	}
+
+	return Entry{
+		Line:  r.lines.line,
+		Lemma: e.Lemma,
+		Lang:  model.Lang(e.Lang),
+		Class: model.WordClass(e.Class),
+		Definitions: fn.Map(e.Definitions, func(d jsonlDefinition) Definition {
+			return Definition{
+				Text:      d.Text,
+				Rarity:    d.Rarity,
+				Source:    model.DataSource(d.Source),
+				ImageURLs: d.ImageURLs,
+			}
+		}),
+	}, nil
+}
+
+// lineScanner reads the lines of line based formats one at a time, keeping the number of the last line read
+type lineScanner struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newLineScanner(r io.Reader, maxLineSize int) *lineScanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &lineScanner{s: s}
+}
+
+// next returns the next line that is not blank without surrounding whitespace, and io.EOF after the last line.
+// The line is only valid until the next call.
+func (l *lineScanner) next() ([]byte, error) {
+	for l.s.Scan() {
+		l.line++
+		if line := bytes.TrimSpace(l.s.Bytes()); len(line) > 0 {
+			return line, nil
+		}
+	}
+
+	if err := l.s.Err(); err != nil {
+		return nil, fmt.Errorf("read line %d: %w", l.line+1, err)
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// maxKaikkiLineSize is the maximum size of a line of a Kaikki extract in bytes, entries of very common
+// words carry long lists of translations and forms
+const maxKaikkiLineSize = 32 << 20
+
+// kaikkiClasses maps the parts of speech of Kaikki extracts to word classes. Entries of other parts
+// of speech, such as proper names, numerals, affixes and phrases, are skipped.
+var kaikkiClasses = map[string]model.WordClass{
+	"noun": model.Noun,
+	"pron": model.Pronoun,
+	"verb": model.Verb,
+	"adj":  model.Adjective,
+	"adv":  model.Adverb,
+	"prep": model.Preposition,
+	"conj": model.Conjunction,
+	"intj": model.Interjection,
+}
+
+// kaikkiSkippedTags mark senses that are not definitions of their own, such as inflected forms and spellings
+var kaikkiSkippedTags = []string{"form-of", "alt-of", "no-gloss"}
+
+type kaikkiSense struct {
+	Glosses []string          `json:"glosses"`
+	Tags    []string          `json:"tags"`
+	FormOf  []json.RawMessage `json:"form_of"`
+	AltOf   []json.RawMessage `json:"alt_of"`
+}
+
+type kaikkiEntry struct {
+	Word     string        `json:"word"`
+	LangCode string        `json:"lang_code"`
+	POS      string        `json:"pos"`
+	Senses   []kaikkiSense `json:"senses"`
+}
+
+// KaikkiReader reads the Wiktionary extracts published by kaikki.org, which hold one JSON object per line
+// for every word and part of speech. Only the entries of one language with a part of speech that maps
+// to a word class are read, and every sense becomes a definition with the Wiktionary source, except for
+// senses that only refer to another word such as "plural of apple".
+type KaikkiReader struct {
+	lines *lineScanner
+	lang  model.Lang
+}
+
+func NewKaikkiReader(r io.Reader, lang model.Lang) *KaikkiReader {
+	return &KaikkiReader{lines: newLineScanner(r, maxKaikkiLineSize), lang: lang}
+}
+
+func (r *KaikkiReader) Next() (Entry, error) {
+	for {
+		line, err := r.lines.next()
+		if err != nil {
+			return Entry{}, err
+		}
+
+		var e kaikkiEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return Entry{}, &RecordError{Line: r.lines.line, Err: err}
+		}
+
+		class, ok := kaikkiClasses[e.POS]
+		if !ok || model.Lang(e.LangCode) != r.lang {
+			continue
+		}
+
+		var defs []Definition
+		for _, s := range e.Senses {
+			text := senseText(s)
+			if text == "" || slices.ContainsFunc(defs, func(d Definition) bool { return d.Text == text }) {
+				continue
+			}
+			defs = append(defs, Definition{Text: text, Source: model.SrcWiktionary})
+		}
+		if len(defs) == 0 {
+			continue
+		}
+
+		return Entry{
+			Line:        r.lines.line,
+			Lemma:       e.Word,
+			Lang:        r.lang,
+			Class:       class,
+			Definitions: defs,
+		}, nil
+	}
+}
+
+// senseText returns the definition text of a sense, or an empty string for senses that are skipped.
+// The glosses of subsenses start with the glosses of their parent sense, the last gloss is the most specific one.
+func senseText(s kaikkiSense) string {
+	if len(s.Glosses) == 0 || len(s.FormOf) > 0 || len(s.AltOf) > 0 {
+		return ""
+	}
+	if slices.ContainsFunc(s.Tags, func(tag string) bool { return slices.Contains(kaikkiSkippedTags, tag) }) {
+		return ""
+	}
+
+	return strings.TrimSpace(s.Glosses[len(s.Glosses)-1])
+}
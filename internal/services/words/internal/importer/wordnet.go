@@ -0,0 +1,256 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+// wordNetClasses maps the parts of speech of WordNet to word classes, adjective satellites are adjectives
+var wordNetClasses = map[string]model.WordClass{
+	"n": model.Noun,
+	"v": model.Verb,
+	"a": model.Adjective,
+	"s": model.Adjective,
+	"r": model.Adverb,
+	"c": model.Conjunction,
+	"p": model.Preposition,
+}
+
+// wordNetRelations maps the relation types of WordNet to the ones kept, other relations are skipped
+var wordNetRelations = map[string]model.RelationType{
+	"antonym":           model.RelAntonym,
+	"hypernym":          model.RelHypernym,
+	"instance_hypernym": model.RelHypernym,
+	"hyponym":           model.RelHyponym,
+	"instance_hyponym":  model.RelHyponym,
+	"meronym":           model.RelMeronym,
+	"mero_member":       model.RelMeronym,
+	"mero_part":         model.RelMeronym,
+	"mero_substance":    model.RelMeronym,
+}
+
+type wnRelation struct {
+	Type   string `xml:"relType,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type wnSense struct {
+	ID        string       `xml:"id,attr"`
+	Synset    string       `xml:"synset,attr"`
+	Relations []wnRelation `xml:"SenseRelation"`
+}
+
+type wnLexicalEntry struct {
+	Lemma struct {
+		WrittenForm string `xml:"writtenForm,attr"`
+		POS         string `xml:"partOfSpeech,attr"`
+	} `xml:"Lemma"`
+	Senses []wnSense `xml:"Sense"`
+}
+
+type wnSynset struct {
+	ID          string       `xml:"id,attr"`
+	Definitions []string     `xml:"Definition"`
+	Relations   []wnRelation `xml:"SynsetRelation"`
+}
+
+// wnEntry is a lexical entry together with the line it starts on and the language of its lexicon
+type wnEntry struct {
+	line  int
+	lang  model.Lang
+	entry wnLexicalEntry
+}
+
+// DefinitionRef identifies a definition of an imported entry by its word and its text
+type DefinitionRef struct {
+	Lemma string
+	Lang  model.Lang
+	Class model.WordClass
+	Text  string
+}
+
+// Relation relates the definition From to the definition To
+type Relation struct {
+	From DefinitionRef
+	To   DefinitionRef
+	Type model.RelationType
+}
+
+// RelationReader is a Reader of dictionaries that relate definitions to each other. Relations returns
+// the relations between the definitions of all entries, it is called after Next returned io.EOF.
+type RelationReader interface {
+	Reader
+	Relations() []Relation
+}
+
+// WordNetReader reads WordNet lexicons in the WN-LMF XML format, such as the Open English WordNet. Every sense
+// of a lexical entry becomes a definition with the definition of its synset as text and the WordNet source.
+// The senses of different words in a synset are synonyms, and the antonym, hypernym, hyponym and meronym
+// relations of senses and synsets relate the definitions of their senses.
+//
+// Definitions of lexical entries are found in synsets that come after them, so the whole lexicon is read
+// into memory the first time Next is called.
+type WordNetReader struct {
+	r         io.Reader
+	entries   []Entry
+	relations []Relation
+	read      bool
+	next      int
+}
+
+func NewWordNetReader(r io.Reader) *WordNetReader {
+	return &WordNetReader{r: r}
+}
+
+func (r *WordNetReader) Next() (Entry, error) {
+	if !r.read {
+		if err := r.load(); err != nil {
+			return Entry{}, err
+		}
+		r.read = true
+	}
+
+	if r.next == len(r.entries) {
+		return Entry{}, io.EOF
+	}
+
+	r.next++
+	return r.entries[r.next-1], nil
+}
+
+func (r *WordNetReader) Relations() []Relation {
+	return r.relations
+}
+
+func (r *WordNetReader) load() error {
+	var (
+		entries     []wnEntry
+		synsets     = make(map[string]wnSynset)
+		synsetOrder []string
+		lang        model.Lang
+	)
+
+	d := xml.NewDecoder(r.r)
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("parse wordnet: %w", err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch se.Name.Local {
+		case "Lexicon":
+			for _, a := range se.Attr {
+				if a.Name.Local == "language" {
+					lang = model.Lang(a.Value)
+				}
+			}
+		case "LexicalEntry":
+			line, _ := d.InputPos()
+			var e wnLexicalEntry
+			if err := d.DecodeElement(&e, &se); err != nil {
+				return fmt.Errorf("parse wordnet entry on line %d: %w", line, err)
+			}
+			entries = append(entries, wnEntry{line: line, lang: lang, entry: e})
+		case "Synset":
+			var s wnSynset
+			if err := d.DecodeElement(&s, &se); err != nil {
+				line, _ := d.InputPos()
+				return fmt.Errorf("parse wordnet synset before line %d: %w", line, err)
+			}
+			synsets[s.ID] = s
+			synsetOrder = append(synsetOrder, s.ID)
+		}
+	}
+
+	senses := make(map[string]DefinitionRef)
+	members := make(map[string][]DefinitionRef)
+	for _, we := range entries {
+		class, ok := wordNetClasses[we.entry.Lemma.POS]
+		if !ok {
+			continue
+		}
+
+		entry := Entry{Line: we.line, Lemma: we.entry.Lemma.WrittenForm, Lang: we.lang, Class: class}
+		for _, s := range we.entry.Senses {
+			text := synsetDefinition(synsets[s.Synset])
+			if text == "" {
+				continue
+			}
+
+			ref := DefinitionRef{Lemma: entry.Lemma, Lang: entry.Lang, Class: entry.Class, Text: text}
+			senses[s.ID] = ref
+			members[s.Synset] = append(members[s.Synset], ref)
+			if !slices.ContainsFunc(entry.Definitions, func(d Definition) bool { return d.Text == text }) {
+				entry.Definitions = append(entry.Definitions, Definition{Text: text, Source: model.SrcWordNet})
+			}
+		}
+
+		if len(entry.Definitions) > 0 {
+			r.entries = append(r.entries, entry)
+		}
+	}
+
+	for _, id := range synsetOrder {
+		refs := members[id]
+		for _, from := range refs {
+			for _, to := range refs {
+				if from.Lemma != to.Lemma {
+					r.relations = append(r.relations, Relation{From: from, To: to, Type: model.RelSynonym})
+				}
+			}
+		}
+
+		for _, rel := range synsets[id].Relations {
+			typ, ok := wordNetRelations[rel.Type]
+			if !ok {
+				continue
+			}
+			for _, from := range refs {
+				for _, to := range members[rel.Target] {
+					r.relations = append(r.relations, Relation{From: from, To: to, Type: typ})
+				}
+			}
+		}
+	}
+
+	for _, we := range entries {
+		for _, s := range we.entry.Senses {
+			from, ok := senses[s.ID]
+			if !ok {
+				continue
+			}
+
+			for _, rel := range s.Relations {
+				typ, ok := wordNetRelations[rel.Type]
+				to, found := senses[rel.Target]
+				if ok && found {
+					r.relations = append(r.relations, Relation{From: from, To: to, Type: typ})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func synsetDefinition(s wnSynset) string {
+	if len(s.Definitions) == 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(s.Definitions[0])
+}
@@ -0,0 +1,274 @@
+// Package markdown sanitizes user written Markdown down to the subset the clients render:
+// paragraphs, emphasis, inline code, lists and links to web pages and email addresses.
+package markdown
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	parser = goldmark.DefaultParser()
+	blank  = regexp.MustCompile(`\n{3,}`)
+	// escaped are the characters of text escaped with a backslash, so that the text never turns into markup
+	escaped = strings.NewReplacer(`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, "&lt;")
+)
+
+// Sanitize rewrites the text so that it renders only the supported subset. The text is parsed as
+// CommonMark and written back from the syntax tree: links and reference links survive only when they
+// point to http, https or mailto URLs and are replaced by their text otherwise, images are replaced
+// by their alt text, and headings, block quotes, code blocks and raw HTML are kept as plain text.
+// Everything written as text is escaped, so the result parses to the same tree.
+// Line endings are normalized, control characters are removed and the result is trimmed.
+func Sanitize(md string) string {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	md = strings.Map(func(r rune) rune {
+		if r == '\r' {
+			return '\n'
+		}
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, md)
+
+	source := []byte(md)
+	w := writer{source: source}
+	w.blocks(parser.Parse(text.NewReader(source)))
+
+	out := blank.ReplaceAllString(w.buf.String(), "\n\n")
+	return strings.TrimSpace(out)
+}
+
+// writer writes the supported subset of a syntax tree back as Markdown
+type writer struct {
+	source []byte
+	buf    strings.Builder
+}
+
+// blocks writes the child blocks of the node, separated as they were in the source
+func (w *writer) blocks(n ast.Node) {
+	first := true
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		var b writer
+		b.source = w.source
+		b.block(c)
+		if b.buf.Len() == 0 {
+			continue
+		}
+
+		if !first {
+			w.buf.WriteString("\n")
+			if c.HasBlankPreviousLines() {
+				w.buf.WriteString("\n")
+			}
+		}
+		w.buf.WriteString(b.buf.String())
+		first = false
+	}
+}
+
+func (w *writer) block(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+		w.inlines(n)
+	case *ast.Blockquote:
+		w.blocks(n)
+	case *ast.List:
+		w.list(n)
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		w.lines(n.Lines())
+	case *ast.HTMLBlock:
+		w.lines(n.Lines())
+		if n.HasClosure() {
+			w.buf.WriteString("\n")
+			w.text(bytes.TrimRight(n.ClosureLine.Value(w.source), "\n"))
+		}
+	}
+}
+
+// list writes the items of the list which are not empty, numbering ordered lists from their start
+func (w *writer) list(l *ast.List) {
+	num := l.Start
+	for item := l.FirstChild(); item != nil; item = item.NextSibling() {
+		var b writer
+		b.source = w.source
+		b.blocks(item)
+		if b.buf.Len() == 0 {
+			continue
+		}
+
+		marker := string(l.Marker)
+		if l.IsOrdered() {
+			marker = strconv.Itoa(num) + marker
+			num++
+		}
+		if w.buf.Len() > 0 {
+			w.buf.WriteString("\n")
+			if !l.IsTight {
+				w.buf.WriteString("\n")
+			}
+		}
+
+		// the lines after the first are indented to stay in the item
+		indent := "\n" + strings.Repeat(" ", len(marker)+1)
+		w.buf.WriteString(marker + " ")
+		w.buf.WriteString(strings.ReplaceAll(b.buf.String(), "\n", indent))
+	}
+}
+
+// lines writes the lines of a block as text
+func (w *writer) lines(lines *text.Segments) {
+	for i := range lines.Len() {
+		if i > 0 {
+			w.buf.WriteString("\n")
+		}
+		seg := lines.At(i)
+		w.text(bytes.TrimRight(seg.Value(w.source), "\n"))
+	}
+}
+
+func (w *writer) inlines(n ast.Node) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		w.inline(c)
+	}
+}
+
+func (w *writer) inline(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Text:
+		w.text(unescape(n.Value(w.source)))
+		if n.HardLineBreak() {
+			w.buf.WriteString("\\\n")
+		} else if n.SoftLineBreak() {
+			w.buf.WriteString("\n")
+		}
+	case *ast.String:
+		w.text(n.Value)
+	case *ast.CodeSpan:
+		w.codeSpan(n)
+	case *ast.Emphasis:
+		marker := strings.Repeat("*", n.Level)
+		w.buf.WriteString(marker)
+		w.inlines(n)
+		w.buf.WriteString(marker)
+	case *ast.Link:
+		if !allowedURL(string(n.Destination)) {
+			w.inlines(n)
+			return
+		}
+
+		w.buf.WriteString("[")
+		w.inlines(n)
+		w.buf.WriteString("](" + destination(n.Destination) + ")")
+	case *ast.Image:
+		w.inlines(n)
+	case *ast.AutoLink:
+		u := string(n.URL(w.source))
+		if n.AutoLinkType == ast.AutoLinkEmail {
+			u = "mailto:" + u
+		}
+		if !allowedURL(u) {
+			w.text(n.Label(w.source))
+			return
+		}
+
+		w.buf.WriteString("[")
+		w.text(n.Label(w.source))
+		w.buf.WriteString("](" + destination([]byte(u)) + ")")
+	case *ast.RawHTML:
+		for i := range n.Segments.Len() {
+			seg := n.Segments.At(i)
+			w.text(seg.Value(w.source))
+		}
+	default:
+		w.inlines(n)
+	}
+}
+
+// codeSpan writes the code span between backtick strings longer than any backtick string in the code
+func (w *writer) codeSpan(n *ast.CodeSpan) {
+	var code []byte
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			code = append(code, t.Value(w.source)...)
+		}
+	}
+	code = bytes.ReplaceAll(code, []byte("\n"), []byte(" "))
+
+	longest, run := 0, 0
+	for _, c := range code {
+		if c != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	pad := ""
+	if bytes.HasPrefix(code, []byte("`")) || bytes.HasSuffix(code, []byte("`")) {
+		pad = " "
+	}
+	w.buf.WriteString(fence + pad)
+	w.buf.Write(code)
+	w.buf.WriteString(pad + fence)
+}
+
+// text writes the literal text escaped
+func (w *writer) text(t []byte) {
+	s := escaped.Replace(string(t))
+	// an ampersand followed by a name or a number could start an entity reference
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' && i+1 < len(s) && (s[i+1] == '#' || isAlnum(s[i+1])) && !strings.HasPrefix(s[i:], "&lt;") {
+			b.WriteString(`\&`)
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	w.buf.WriteString(b.String())
+}
+
+// unescape returns the literal text of a text node, resolving backslash escapes and entity references
+func unescape(t []byte) []byte {
+	t = util.UnescapePunctuations(t)
+	t = util.ResolveNumericReferences(t)
+	return util.ResolveEntityNames(t)
+}
+
+// destination escapes the URL so that it is read back as the destination of a link
+func destination(u []byte) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, `<`, `\<`, `>`, `\>`, " ", "%20").Replace(string(u))
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// allowedURL tells whether links may point to the URL: web pages and email addresses
+func allowedURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+
+	return false
+}
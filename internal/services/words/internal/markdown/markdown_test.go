@@ -0,0 +1,44 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text string
+		want string
+	}{
+		"plain":              {"  think of a *yellow* **banana**  ", "think of a *yellow* **banana**"},
+		"lists":              {"- one\n- two\n1. three", "- one\n- two\n1. three"},
+		"code":               {"use `ser` for traits", "use `ser` for traits"},
+		"html":               {"<script>alert(1)</script> <b>bold</b>", "&lt;script>alert(1)&lt;/script> &lt;b>bold&lt;/b>"},
+		"web link":           {"see [the dictionary](https://example.com/apple \"Apple\")", "see [the dictionary](https://example.com/apple)"},
+		"script link":        {"[click](javascript:alert(1))", "click"},
+		"relative link":      {"[home](/home)", "home"},
+		"image":              {"![a red apple](https://example.com/apple.png)", "a red apple"},
+		"reference":          {"[x][1]\n\n[1]: javascript:alert(1)", "x"},
+		"web reference":      {"[x][1]\n\n[1]: https://example.com", "[x](https://example.com)"},
+		"quoted reference":   {"> [1]: javascript:alert(1)\n\n[x][1]", "x"},
+		"listed reference":   {"- [1]: javascript:alert(1)\n\n[x][1]", "x"},
+		"nested parentheses": {"[x](javascript:alert((1)))", "x"},
+		"mail link":          {"[write](mailto:me@example.com) or <me@example.com>", "[write](mailto:me@example.com) or [me@example.com](mailto:me@example.com)"},
+		"autolink":           {"<javascript:alert(1)> <https://example.com>", "javascript:alert(1) [https://example.com](https://example.com)"},
+		"escaped":            {"\\[x\\](javascript:alert(1)) snake\\_case", "\\[x\\](javascript:alert(1)) snake\\_case"},
+		"image in text":      {"![[x]](/a.png)(javascript:alert(1))", "\\[x\\](javascript:alert(1))"},
+		"nested list":        {"1. one\n   - two\n2. three", "1. one\n   - two\n2. three"},
+		"heading":            {"# Big\n### Smaller", "Big\nSmaller"},
+		"quote":              {"> > quoted", "quoted"},
+		"fence":              {"```go\ncode\n```", "code"},
+		"line endings":       {"one\r\ntwo\rthree\n\n\n\nfour", "one\ntwo\nthree\n\nfour"},
+		"control":            {"bell\a and\x00 null", "bell and null"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Sanitize(tc.text))
+			// sanitized text parses to the same tree
+			assert.Equal(t, tc.want, Sanitize(tc.want))
+		})
+	}
+}
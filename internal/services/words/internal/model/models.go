@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type Lang string
 type WordClass string
@@ -16,14 +19,101 @@ const (
 	Interjection WordClass = "interjection"
 )
 
+var wordClasses = []WordClass{Noun, Pronoun, Verb, Adjective, Adverb, Preposition, Conjunction, Interjection}
+
+// Valid reports whether c is a known word class
+func (c WordClass) Valid() bool {
+	return slices.Contains(wordClasses, c)
+}
+
 type DataSource string
 
 const (
 	SrcUnknown DataSource = "unknown"
 	SrcUser    DataSource = "user"
 	SrcAI      DataSource = "ai"
+	// SrcWiktionary marks definitions imported from Wiktionary extracts
+	SrcWiktionary DataSource = "wiktionary"
+	// SrcWordNet marks definitions imported from WordNet
+	SrcWordNet DataSource = "wordnet"
+	// SrcDictionary marks definitions taken from the articles of offline dictionaries, such as StarDict ones
+	SrcDictionary DataSource = "dictionary"
+)
+
+var dataSources = []DataSource{SrcUnknown, SrcUser, SrcAI, SrcWiktionary, SrcWordNet, SrcDictionary}
+
+// RelationType is the semantic relation of a definition to a related one
+type RelationType string
+
+const (
+	// RelSynonym relates definitions of different words with the same meaning
+	RelSynonym RelationType = "synonym"
+	// RelAntonym relates definitions with opposite meanings
+	RelAntonym RelationType = "antonym"
+	// RelHypernym relates a definition to a more general one, such as "dog" to "canine"
+	RelHypernym RelationType = "hypernym"
+	// RelHyponym relates a definition to a more specific one, such as "dog" to "puppy"
+	RelHyponym RelationType = "hyponym"
+	// RelMeronym relates a definition to one of its parts or members, such as "car" to "wheel"
+	RelMeronym RelationType = "meronym"
 )
 
+var relationTypes = []RelationType{RelSynonym, RelAntonym, RelHypernym, RelHyponym, RelMeronym}
+
+// Valid reports whether t is a known relation type
+func (t RelationType) Valid() bool {
+	return slices.Contains(relationTypes, t)
+}
+
+// Valid reports whether s is a known data source
+func (s DataSource) Valid() bool {
+	return slices.Contains(dataSources, s)
+}
+
+// PickSort is the field the picks of a user are ordered by
+type PickSort string
+
+const (
+	SortByCreated    PickSort = "created"
+	SortByLemma      PickSort = "lemma"
+	SortByLang       PickSort = "lang"
+	SortByClass      PickSort = "class"
+	SortByRarity     PickSort = "rarity"
+	SortByNextReview PickSort = "next_review"
+)
+
+// PickStatus is the learning state of a pick
+type PickStatus string
+
+const (
+	StatusNew       PickStatus = "new"
+	StatusLearning  PickStatus = "learning"
+	StatusKnown     PickStatus = "known"
+	StatusSuspended PickStatus = "suspended"
+	StatusArchived  PickStatus = "archived"
+)
+
+// pickTransitions lists the statuses a pick can move to from each status.
+// Picks never return to new once they left it.
+var pickTransitions = map[PickStatus][]PickStatus{
+	StatusNew:       {StatusLearning, StatusKnown, StatusSuspended, StatusArchived},
+	StatusLearning:  {StatusKnown, StatusSuspended, StatusArchived},
+	StatusKnown:     {StatusLearning, StatusSuspended, StatusArchived},
+	StatusSuspended: {StatusLearning, StatusKnown, StatusArchived},
+	StatusArchived:  {StatusLearning, StatusKnown},
+}
+
+// Valid reports whether s is a known status
+func (s PickStatus) Valid() bool {
+	_, ok := pickTransitions[s]
+	return ok
+}
+
+// CanTransition reports whether a pick with status s can be moved to the status to
+func (s PickStatus) CanTransition(to PickStatus) bool {
+	return slices.Contains(pickTransitions[s], to)
+}
+
 type TagIDMap map[string]int64
 type WordIDMap map[int64]Word
 
@@ -42,8 +132,64 @@ type Word struct {
 
 type Tag struct {
 	Model
-	ID   int64
+	ID     int64
+	UserID string
+	// Text is the full path of the tag, such as "spanish/verbs"
 	Text string
+	// ParentID is zero for top level tags
+	ParentID int64
+}
+
+// TagUsage is a tag together with the number of picks it is assigned to
+type TagUsage struct {
+	Tag
+	Picks int
+}
+
+// TagFilter is a tag expression saved by a user under a name
+type TagFilter struct {
+	Model
+	ID     int64
+	UserID string
+	Name   string
+	Expr   string
+}
+
+// Note is a personal note or mnemonic a user wrote on a pick. Public notes are shown
+// to other users as mnemonics for the definition of the pick.
+type Note struct {
+	Model
+	ID     int64
+	PickID int64
+	UserID string
+	DefID  int64
+	Body   string
+	Public bool
+}
+
+// NoteRevision is a body a note had at some point, the latest revision holds the current body
+type NoteRevision struct {
+	Model
+	ID     int64
+	NoteID int64
+	Body   string
+}
+
+// NoteVote is an upvote a user gave to the public note of another user
+type NoteVote struct {
+	Model
+	NoteID int64
+	UserID string
+	// DefID is the definition the note was written on
+	DefID int64
+}
+
+// Mnemonic is a public note together with the number of users who upvoted it
+type Mnemonic struct {
+	Note
+	Votes int
+	// Voted tells whether the user the mnemonics are listed for upvoted the note
+	Voted bool
 }
 
 type Definition struct {
@@ -55,6 +201,13 @@ type Definition struct {
 	Source DataSource
 }
 
+// RelatedDefinition is a definition related to another one, together with its word
+type RelatedDefinition struct {
+	Type       RelationType
+	Word       Word
+	Definition Definition
+}
+
 type Example struct {
 	Model
 	ID     int64
@@ -77,4 +230,62 @@ type UserPick struct {
 	Word       Word
 	Definition Definition
 	Tags       []Tag
+	Status     PickStatus
+	// DefOverride replaces the text of the shared definition for the user when not empty
+	DefOverride string
+	// ImageID is the image of the definition the user chose, zero when the user did not choose one
+	ImageID int64
+	// ImageURL is the URL of the chosen image or else of the first image attached to the definition
+	ImageURL string
+	// Contexts are the sentences the user came across the word in, oldest first
+	Contexts []PickContext
+	// NextReviewAt is nil for picks that were never scheduled for a review
+	NextReviewAt *time.Time
+}
+
+// PickContext is a sentence the user came across the word of a pick in, with the title
+// and the URL of its source when the user gave them
+type PickContext struct {
+	Model
+	ID          int64
+	PickID      int64
+	Sentence    string
+	SourceTitle string
+	SourceURL   string
+}
+
+// ImportStatus is the state of an import running in the background
+type ImportStatus string
+
+const (
+	ImportRunning ImportStatus = "running"
+	ImportDone    ImportStatus = "done"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportJob is an import of a deck of flashcards into the picks of a user, it counts the cards read so far
+// by outcome. Cards are created when they became picks and skipped when the user had picked their definition.
+type ImportJob struct {
+	Model
+	ID     int64
+	UserID string
+	Status ImportStatus
+	// Total is the number of cards of the deck
+	Total   int
+	Cards   int
+	Created int
+	Skipped int
+	Failed  int
+	// Errors tell why the first cards that failed were not imported
+	Errors []ImportJobError
+	// Error tells why a failed import stopped
+	Error string
+	// FinishedAt is nil while the import is running
+	FinishedAt *time.Time
+}
+
+// ImportJobError tells why the card at Index in the deck was not imported
+type ImportJobError struct {
+	Index int
+	Msg   string
 }
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickStatusTransitions(t *testing.T) {
+	assert.True(t, StatusNew.CanTransition(StatusLearning))
+	assert.True(t, StatusLearning.CanTransition(StatusKnown))
+	assert.True(t, StatusSuspended.CanTransition(StatusLearning))
+	assert.True(t, StatusArchived.CanTransition(StatusLearning))
+	assert.False(t, StatusLearning.CanTransition(StatusNew))
+	assert.False(t, StatusSuspended.CanTransition(StatusNew))
+	assert.False(t, StatusArchived.CanTransition(StatusNew))
+	assert.False(t, StatusArchived.CanTransition(StatusSuspended))
+	assert.False(t, StatusNew.CanTransition("forgotten"))
+	assert.True(t, StatusArchived.Valid())
+	assert.False(t, PickStatus("forgotten").Valid())
+}
+
+func TestWordClassAndSourceValid(t *testing.T) {
+	assert.True(t, Interjection.Valid())
+	assert.False(t, WordClass("particle").Valid())
+	assert.True(t, SrcAI.Valid())
+	assert.False(t, DataSource("").Valid())
+}
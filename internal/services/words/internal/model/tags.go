@@ -0,0 +1,52 @@
+package model
+
+import "strings"
+
+// TagSeparator separates the segments of hierarchical tags such as "spanish/verbs/irregular"
+const TagSeparator = "/"
+
+// NormalizeTagPath trims the segments of a tag path and drops empty ones,
+// so that " spanish//verbs/ " becomes "spanish/verbs"
+func NormalizeTagPath(path string) string {
+	segments := strings.Split(path, TagSeparator)
+	result := segments[:0]
+	for _, s := range segments {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+
+	return strings.Join(result, TagSeparator)
+}
+
+// TagParent returns the path of the parent tag, or an empty string for top level tags
+func TagParent(path string) string {
+	i := strings.LastIndex(path, TagSeparator)
+	if i < 0 {
+		return ""
+	}
+
+	return path[:i]
+}
+
+// TagName returns the last segment of a tag path
+func TagName(path string) string {
+	return path[strings.LastIndex(path, TagSeparator)+1:]
+}
+
+// TagAncestors returns the paths of all ancestors of a tag, starting from the top level one
+func TagAncestors(path string) []string {
+	var result []string
+	for i, c := range path {
+		if string(c) == TagSeparator {
+			result = append(result, path[:i])
+		}
+	}
+
+	return result
+}
+
+// IsTagDescendant tells whether the tag path lies below the ancestor path
+func IsTagDescendant(path, ancestor string) bool {
+	return strings.HasPrefix(path, ancestor+TagSeparator)
+}
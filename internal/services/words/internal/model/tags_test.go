@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTagPath(t *testing.T) {
+	assert.Equal(t, "spanish/verbs", NormalizeTagPath(" spanish//verbs/ "))
+	assert.Equal(t, "food", NormalizeTagPath("food"))
+	assert.Equal(t, "", NormalizeTagPath(" / "))
+}
+
+func TestTagPathSegments(t *testing.T) {
+	assert.Equal(t, "spanish/verbs", TagParent("spanish/verbs/irregular"))
+	assert.Equal(t, "", TagParent("spanish"))
+	assert.Equal(t, "irregular", TagName("spanish/verbs/irregular"))
+	assert.Equal(t, "spanish", TagName("spanish"))
+	assert.Equal(t, []string{"spanish", "spanish/verbs"}, TagAncestors("spanish/verbs/irregular"))
+	assert.Empty(t, TagAncestors("spanish"))
+}
+
+func TestIsTagDescendant(t *testing.T) {
+	assert.True(t, IsTagDescendant("spanish/verbs", "spanish"))
+	assert.False(t, IsTagDescendant("spanish", "spanish"))
+	assert.False(t, IsTagDescendant("spanishverbs", "spanish"))
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/importer"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+const (
+	// maxImportBodySize is the maximum size of a dictionary uploaded to the import endpoint. The import runs
+	// while the request waits for it, so the endpoint takes small dictionaries only, larger dictionaries
+	// are imported with the import command of the service.
+	maxImportBodySize = 16 << 20
+	// dictionaryImportTimeout is how long the upload and the import of a dictionary may take together,
+	// it is longer than the timeouts of the server allow
+	dictionaryImportTimeout = 5 * time.Minute
+)
+
+type dictionaryService interface {
+	ImportDictionary(ctx context.Context, r service.ImportDictionaryRequest) (service.ImportReport, error)
+}
+
+// AdminAPI serves the endpoints operators use to maintain the global dictionary
+type AdminAPI struct {
+	srv dictionaryService
+	mux http.ServeMux
+}
+
+func NewAdminAPI(srv dictionaryService) *AdminAPI {
+	api := &AdminAPI{
+		srv: srv,
+		mux: *http.NewServeMux(),
+	}
+
+	api.mount()
+	return api
+}
+
+func (api *AdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mux.ServeHTTP(w, r)
+}
+
+func (api *AdminAPI) mount() {
+	api.mux.HandleFunc("POST /dictionary/import", api.handleImportDictionary)
+}
+
+// importFormats maps the content types of dictionary uploads to their format
+var importFormats = map[string]importer.Format{
+	"text/csv":             importer.CSV,
+	"application/x-ndjson": importer.JSONL,
+	"application/jsonl":    importer.JSONL,
+	"application/xml":      importer.WordNet,
+	"text/xml":             importer.WordNet,
+}
+
+type importErrorResponse struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type importReportResponse struct {
+	Records     int                   `json:"records"`
+	Created     int                   `json:"created"`
+	Skipped     int                   `json:"skipped"`
+	Failed      int                   `json:"failed"`
+	Words       int                   `json:"words"`
+	Definitions int                   `json:"definitions"`
+	Images      int                   `json:"images"`
+	Relations   int                   `json:"relations"`
+	Errors      []importErrorResponse `json:"errors"`
+}
+
+// handleImportDictionary imports the dictionary in the request body, its format is given by the format query
+// parameter or else by the content type. The lang query parameter selects the language of Kaikki extracts,
+// and after_line resumes an import that stopped, for instance after the import ran out of time.
+func (api *AdminAPI) handleImportDictionary(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := importer.Format(query.Get("format"))
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importFormats[mediaType]
+	}
+
+	var afterLine int
+	if v := query.Get("after_line"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			se := serr.NewServiceError(err, http.StatusBadRequest, "invalid after_line parameter")
+			se.Env["after_line"] = v
+			httpx.HandleErr(w, r, se)
+			return
+		}
+		afterLine = n
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(dictionaryImportTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(dictionaryImportTimeout + time.Minute))
+	ctx, cancel := context.WithTimeout(r.Context(), dictionaryImportTimeout)
+	defer cancel()
+
+	entries, err := importer.NewReader(format, http.MaxBytesReader(w, r.Body, maxImportBodySize), importer.Options{
+		Lang: model.Lang(query.Get("lang")),
+	})
+	if err != nil {
+		se := serr.NewServiceError(err, http.StatusBadRequest, "unsupported import format")
+		if errors.Is(err, importer.ErrLangRequired) {
+			se = serr.NewServiceError(err, http.StatusBadRequest, "lang parameter is required for the format")
+		}
+		se.Env["format"] = string(format)
+		httpx.HandleErr(w, r, se)
+		return
+	}
+
+	report, err := api.srv.ImportDictionary(ctx, service.ImportDictionaryRequest{
+		Entries:   entries,
+		AfterLine: afterLine,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, importReportResponse{
+		Records:     report.Records,
+		Created:     report.Created,
+		Skipped:     report.Skipped,
+		Failed:      report.Failed,
+		Words:       report.Words,
+		Definitions: report.Definitions,
+		Images:      report.Images,
+		Relations:   report.Relations,
+		Errors: append([]importErrorResponse{}, fn.Map(report.Errors, func(e service.ImportError) importErrorResponse {
+			return importErrorResponse{Line: e.Line, Error: e.Msg}
+		})...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
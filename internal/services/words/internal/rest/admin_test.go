@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDictionaryService struct {
+	ImportDictionaryFunc func(ctx context.Context, r service.ImportDictionaryRequest) (service.ImportReport, error)
+}
+
+func (m *mockDictionaryService) ImportDictionary(ctx context.Context, r service.ImportDictionaryRequest) (service.ImportReport, error) {
+	return m.ImportDictionaryFunc(ctx, r)
+}
+
+func TestPOSTDictionaryImport(t *testing.T) {
+	api := NewAdminAPI(&mockDictionaryService{
+		ImportDictionaryFunc: func(ctx context.Context, r service.ImportDictionaryRequest) (service.ImportReport, error) {
+			e, err := r.Entries.Next()
+			require.NoError(t, err)
+			assert.Equal(t, "apple", e.Lemma)
+
+			_, err = r.Entries.Next()
+			assert.ErrorIs(t, err, io.EOF)
+
+			return service.ImportReport{
+				Records: 2,
+				Created: 1,
+				Failed:  1,
+				Words:   1,
+				Errors:  []service.ImportError{{Line: 3, Msg: "lemma is required"}},
+			}, nil
+		},
+	})
+
+	body := "lemma,lang,class,definition\napple,en,noun,A round fruit.\n"
+	for name, path := range map[string]string{
+		"content type": "/dictionary/import",
+		"query":        "/dictionary/import?format=csv",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", path, strings.NewReader(body))
+			if name == "content type" {
+				req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+			}
+			rec := httptest.NewRecorder()
+			api.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			resp := test.ParseResponse[importReportResponse](t, rec)
+			assert.Equal(t, importReportResponse{
+				Records: 2,
+				Created: 1,
+				Failed:  1,
+				Words:   1,
+				Errors:  []importErrorResponse{{Line: 3, Error: "lemma is required"}},
+			}, resp)
+		})
+	}
+}
+
+func TestPOSTDictionaryImport_Errors(t *testing.T) {
+	api := NewAdminAPI(&mockDictionaryService{
+		ImportDictionaryFunc: func(ctx context.Context, r service.ImportDictionaryRequest) (service.ImportReport, error) {
+			return service.ImportReport{}, errors.New("connection reset")
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("POST", "/dictionary/import", strings.NewReader("")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for _, path := range []string{"/dictionary/import?format=kaikki", "/dictionary/import?format=csv&after_line=-1"} {
+		rec = httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader("")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest("POST", "/dictionary/import?format=jsonl", strings.NewReader("")))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
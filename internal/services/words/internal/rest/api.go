@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
 	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
@@ -20,15 +23,41 @@ type wordsService interface {
 	AddWord(ctx context.Context, r service.AddWordRequest) (int64, error)
 	DeleteWord(ctx context.Context, wordID int64) error
 	PickWord(ctx context.Context, r service.PickWoardRequest) (int64, error)
-	UnpickWord(ctx context.Context, pickID int64) error
+	AddPickContext(ctx context.Context, r service.AddPickContextRequest) (int64, error)
+	BulkCreatePicks(ctx context.Context, r service.BulkCreatePicksRequest) (service.BulkResult, error)
+	BulkUpdatePicks(ctx context.Context, r service.BulkUpdatePicksRequest) (service.BulkResult, error)
+	UnpickWord(ctx context.Context, r service.UnpickWordRequest) error
+	UpdatePick(ctx context.Context, r service.UpdatePickRequest) error
 	GetUserPicks(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error)
+	SaveNote(ctx context.Context, r service.SaveNoteRequest) (service.Note, error)
+	GetNote(ctx context.Context, r service.GetNoteRequest) (service.Note, error)
+	DeleteNote(ctx context.Context, r service.GetNoteRequest) error
+	ListNoteRevisions(ctx context.Context, r service.GetNoteRequest) ([]service.NoteRevision, error)
+	VoteNote(ctx context.Context, r service.VoteNoteRequest) error
+	ListMnemonics(ctx context.Context, r service.ListMnemonicsRequest) ([]service.Mnemonic, error)
 	RemoveTags(ctx context.Context, r service.RemoveTagsRequest) error
+	ListTags(ctx context.Context, userID string) ([]service.Tag, error)
+	TagTree(ctx context.Context, userID string) ([]service.TagNode, error)
+	RenameTag(ctx context.Context, r service.RenameTagRequest) error
+	MoveTag(ctx context.Context, r service.MoveTagRequest) error
+	MergeTags(ctx context.Context, r service.MergeTagsRequest) error
+	DeleteTag(ctx context.Context, r service.DeleteTagRequest) error
+	SaveTagFilter(ctx context.Context, r service.SaveTagFilterRequest) (service.TagFilter, error)
+	ListTagFilters(ctx context.Context, userID string) ([]service.TagFilter, error)
+	DeleteTagFilter(ctx context.Context, r service.DeleteTagFilterRequest) error
 	CreateDefinition(ctx context.Context, r service.CreateDefinitionRequest) (int64, error)
+	ListRelatedDefinitions(ctx context.Context, defID int64) ([]model.RelatedDefinition, error)
+	Lookup(ctx context.Context, r service.LookupRequest) ([]service.DictionaryArticle, error)
+	CreateDefinitionFromArticle(ctx context.Context, r service.CreateDefinitionFromArticleRequest) (int64, error)
+	ExportAnki(ctx context.Context, w io.Writer, r service.ExportAnkiRequest) (service.ExportAnkiReport, error)
+	ImportDeck(ctx context.Context, r service.ImportDeckRequest) (model.ImportJob, error)
+	GetImportJob(ctx context.Context, userID string, jobID int64) (model.ImportJob, error)
 	AttachImage(ctx context.Context, r service.AttachImageRequest) (service.AttachImageResponse, error)
 }
 
 type imageStore interface {
 	SaveImage(ctx context.Context, img io.Reader) (*url.URL, error)
+	FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error)
 }
 
 type API struct {
@@ -57,9 +86,35 @@ func (api *API) mount() {
 	api.mux.HandleFunc("DELETE /words/{word_id}", api.handleDeleteWord)
 	api.mux.HandleFunc("PUT /picks", api.handlePickWord)
 	api.mux.HandleFunc("DELETE /picks/{pick_id}", api.handleDeletePick)
+	api.mux.HandleFunc("PATCH /picks/{pick_id}", api.handleUpdatePick)
+	api.mux.HandleFunc("PUT /picks/{pick_id}/contexts", api.handleAddPickContext)
+	api.mux.HandleFunc("POST /picks/bulk", api.handleBulkCreatePicks)
+	api.mux.HandleFunc("POST /picks/bulk/{action}", api.handleBulkUpdatePicks)
 	api.mux.HandleFunc("GET /picks", api.handleGetPicks)
+	api.mux.HandleFunc("PUT /picks/{pick_id}/note", api.handleSaveNote)
+	api.mux.HandleFunc("GET /picks/{pick_id}/note", api.handleGetNote)
+	api.mux.HandleFunc("DELETE /picks/{pick_id}/note", api.handleDeleteNote)
+	api.mux.HandleFunc("GET /picks/{pick_id}/note/revisions", api.handleListNoteRevisions)
+	api.mux.HandleFunc("PUT /notes/{note_id}/vote", api.handleVoteNote)
+	api.mux.HandleFunc("DELETE /notes/{note_id}/vote", api.handleVoteNote)
 	api.mux.HandleFunc("DELETE /tags", api.handleDeleteTag)
+	api.mux.HandleFunc("GET /tags", api.handleListTags)
+	api.mux.HandleFunc("GET /tags/tree", api.handleTagTree)
+	api.mux.HandleFunc("PATCH /tags/{tag_id}", api.handleRenameTag)
+	api.mux.HandleFunc("POST /tags/{tag_id}/move", api.handleMoveTag)
+	api.mux.HandleFunc("POST /tags/{tag_id}/merge", api.handleMergeTags)
+	api.mux.HandleFunc("DELETE /tags/{tag_id}", api.handleDeleteUserTag)
+	api.mux.HandleFunc("GET /filters", api.handleListTagFilters)
+	api.mux.HandleFunc("PUT /filters/{name}", api.handleSaveTagFilter)
+	api.mux.HandleFunc("DELETE /filters/{name}", api.handleDeleteTagFilter)
 	api.mux.HandleFunc("PUT /definitions", api.handleCreateDefinition)
+	api.mux.HandleFunc("PUT /definitions/dictionary", api.handleCreateDefinitionFromArticle)
+	api.mux.HandleFunc("GET /definitions/{def_id}/mnemonics", api.handleListMnemonics)
+	api.mux.HandleFunc("GET /definitions/{def_id}/related", api.handleListRelatedDefinitions)
+	api.mux.HandleFunc("GET /lookup", api.handleLookup)
+	api.mux.HandleFunc("GET /exports/anki", api.handleExportAnki)
+	api.mux.HandleFunc("POST /imports/decks", api.handleImportDeck)
+	api.mux.HandleFunc("GET /imports/{job_id}", api.handleGetImportJob)
 	api.mux.HandleFunc("PUT /images/{def_id}/{source}", api.handleAttachImage)
 }
 
@@ -119,6 +174,10 @@ type pickWordRequest struct {
 	WordID int64    `json:"word_id"`
 	DefID  int64    `json:"def_id"`
 	Tags   []string `json:"tags"`
+	// Context is the sentence the user came across the word in, the source is optional
+	Context     string `json:"context"`
+	SourceTitle string `json:"source_title"`
+	SourceURL   string `json:"source_url"`
 }
 
 type pickWordResponse struct {
@@ -138,6 +197,11 @@ func (api *API) handlePickWord(w http.ResponseWriter, r *http.Request) {
 		WordID: req.WordID,
 		DefID:  req.DefID,
 		Tags:   req.Tags,
+		Context: service.PickContextRequest{
+			Sentence:    req.Context,
+			SourceTitle: req.SourceTitle,
+			SourceURL:   req.SourceURL,
+		},
 	})
 	if err != nil {
 		httpx.HandleErr(w, r, err)
@@ -158,7 +222,10 @@ func (api *API) handleDeletePick(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = api.srv.UnpickWord(r.Context(), pickID)
+	err = api.srv.UnpickWord(r.Context(), service.UnpickWordRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+	})
 	if err != nil {
 		httpx.HandleErr(w, r, err)
 		return
@@ -167,62 +234,172 @@ func (api *API) handleDeletePick(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-type getPicksRequest struct {
-	UserID      string   `json:"user_id"`
-	WithTags    []string `json:"with_tags"`
-	WithoutTags []string `json:"without_tags"`
-	PageSize    int      `json:"page_size"`
-	NextCursor  string   `json:"next_cursor"`
+// updatePickRequest changes the fields that are present. An empty def restores the shared
+// definition text and a zero image_id the default image of the definition.
+type updatePickRequest struct {
+	Status  *string `json:"status,omitempty"`
+	Def     *string `json:"def,omitempty"`
+	ImageID *int64  `json:"image_id,omitempty"`
+}
+
+func (api *API) handleUpdatePick(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req updatePickRequest
+	err = httpx.ReadJSON(r, &req)
+	if err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	var status *model.PickStatus
+	if req.Status != nil {
+		s := model.PickStatus(*req.Status)
+		status = &s
+	}
+
+	err = api.srv.UpdatePick(r.Context(), service.UpdatePickRequest{
+		UserID:  middleware.UserIDFromContext(r.Context()),
+		PickID:  pickID,
+		Status:  status,
+		Def:     req.Def,
+		ImageID: req.ImageID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 type getPicksResponse struct {
 	Picks      []userPickResponse `json:"picks"`
 	NextCursor string             `json:"next_cursor,omitempty"`
+	Total      int                `json:"total"`
+	Facets     pickFacetsResponse `json:"facets"`
+}
+
+type pickFacetsResponse struct {
+	Tags    map[string]int `json:"tags"`
+	Langs   map[string]int `json:"langs"`
+	Classes map[string]int `json:"classes"`
 }
 
 type userPickResponse struct {
-	ID     int64    `json:"id"`
-	UserID string   `json:"user_id"`
-	Word   string   `json:"word"`
-	Lang   string   `json:"lang"`
-	Class  string   `json:"class"`
-	Def    string   `json:"def"`
-	Tags   []string `json:"tags"`
+	ID           int64                 `json:"id"`
+	UserID       string                `json:"user_id"`
+	Word         string                `json:"word"`
+	Lang         string                `json:"lang"`
+	Class        string                `json:"class"`
+	Def          string                `json:"def"`
+	OriginalDef  string                `json:"original_def,omitempty"`
+	ImageURL     string                `json:"image_url,omitempty"`
+	Personalized bool                  `json:"personalized"`
+	Tags         []string              `json:"tags"`
+	Contexts     []pickContextResponse `json:"contexts"`
+	Status       string                `json:"status"`
+	CreatedAt    time.Time             `json:"created_at"`
+	NextReviewAt *time.Time            `json:"next_review_at,omitempty"`
 }
 
+func newUserPickResponse(pick service.UserPick) userPickResponse {
+	return userPickResponse{
+		ID:           pick.ID,
+		UserID:       pick.UserID,
+		Word:         pick.Word,
+		Lang:         string(pick.Lang),
+		Class:        string(pick.Class),
+		Def:          pick.Def,
+		OriginalDef:  pick.OriginalDef,
+		ImageURL:     pick.ImageURL,
+		Personalized: pick.Personalized,
+		Tags:         pick.Tags,
+		Contexts:     fn.Map(pick.Contexts, newPickContextResponse),
+		Status:       string(pick.Status),
+		CreatedAt:    pick.CreatedAt,
+		NextReviewAt: pick.NextReviewAt,
+	}
+}
+
+func newPickFacetsResponse(f service.PickFacets) pickFacetsResponse {
+	resp := pickFacetsResponse{
+		Tags:    make(map[string]int, len(f.Tags)),
+		Langs:   make(map[string]int, len(f.Langs)),
+		Classes: make(map[string]int, len(f.Classes)),
+	}
+	maps.Copy(resp.Tags, f.Tags)
+	for lang, n := range f.Langs {
+		resp.Langs[string(lang)] = n
+	}
+	for class, n := range f.Classes {
+		resp.Classes[string(class)] = n
+	}
+
+	return resp
+}
+
+// handleGetPicks lists the picks of the user. Filters are passed as query parameters, list
+// parameters such as with_tags or lang are repeated, and sort=-lemma sorts in descending order.
 func (api *API) handleGetPicks(w http.ResponseWriter, r *http.Request) {
-	var req getPicksRequest
-	err := httpx.ReadJSON(r, &req)
-	if err != nil {
-		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+	q := r.URL.Query()
+	req := service.GetUserPicksRequest{
+		UserID:      middleware.UserIDFromContext(r.Context()),
+		WithTags:    q["with_tags"],
+		WithoutTags: q["without_tags"],
+		Filter:      q.Get("filter"),
+		SavedFilter: q.Get("saved_filter"),
+		Classes:     fn.Map(q["class"], func(c string) model.WordClass { return model.WordClass(c) }),
+		Statuses:    fn.Map(q["status"], func(s string) model.PickStatus { return model.PickStatus(s) }),
+		NextCursor:  q.Get("next_cursor"),
+	}
+
+	// without a lang parameter only the languages the user is learning are listed,
+	// an empty lang= lists the picks of all languages
+	langs := q["lang"]
+	if !q.Has("lang") {
+		langs = middleware.ProfileFromContext(r.Context()).TargetLangs
+	}
+	for _, l := range langs {
+		if l != "" {
+			req.Langs = append(req.Langs, model.Lang(l))
+		}
+	}
+
+	if sort := q.Get("sort"); sort != "" {
+		req.SortDesc = strings.HasPrefix(sort, "-")
+		req.SortBy = model.PickSort(strings.TrimPrefix(sort, "-"))
+	}
+
+	var err error
+	if req.PageSize, err = intFromQuery(q, "page_size"); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+	if req.CreatedAfter, err = timeFromQuery(q, "created_after"); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+	if req.CreatedBefore, err = timeFromQuery(q, "created_before"); err != nil {
+		httpx.HandleErr(w, r, err)
 		return
 	}
 
-	resp, err := api.srv.GetUserPicks(r.Context(), service.GetUserPicksRequest{
-		UserID:      req.UserID,
-		WithTags:    req.WithTags,
-		WithoutTags: req.WithoutTags,
-		PageSize:    req.PageSize,
-		NextCursor:  req.NextCursor,
-	})
+	resp, err := api.srv.GetUserPicks(r.Context(), req)
 	if err != nil {
 		httpx.HandleErr(w, r, err)
 		return
 	}
 
 	err = httpx.WriteJSON(w, http.StatusOK, getPicksResponse{
-		Picks: fn.Map(resp.Picks, func(pick service.UserPick) userPickResponse {
-			return userPickResponse{
-				ID:     pick.ID,
-				UserID: pick.UserID,
-				Word:   pick.Word,
-				Lang:   string(pick.Lang),
-				Class:  string(pick.Class),
-				Def:    pick.Def,
-				Tags:   pick.Tags,
-			}
-		}),
+		Picks:      fn.Map(resp.Picks, newUserPickResponse),
 		NextCursor: resp.NextCursor,
+		Total:      resp.Total,
+		Facets:     newPickFacetsResponse(resp.Facets),
 	})
 	if err != nil {
 		httpx.HandleErr(w, r, err)
@@ -244,6 +421,7 @@ func (api *API) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = api.srv.RemoveTags(r.Context(), service.RemoveTagsRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
 		PickID: req.PickID,
 		Tags:   req.Tags,
 	})
@@ -256,10 +434,10 @@ func (api *API) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
 }
 
 type createDefinitionRequest struct {
-	WordID int64   `json:"word_id"`
-	Def    string  `json:"def"`
-	Rarity float32 `json:"rarity"`
-	Source string  `json:"source"`
+	WordID int64  `json:"word_id"`
+	Def    string `json:"def"`
+	Rarity int    `json:"rarity"`
+	Source string `json:"source"`
 }
 
 type createDefinitionResponse struct {
@@ -277,7 +455,7 @@ func (api *API) handleCreateDefinition(w http.ResponseWriter, r *http.Request) {
 	defID, err := api.srv.CreateDefinition(r.Context(), service.CreateDefinitionRequest{
 		WordID: req.WordID,
 		Text:   req.Def,
-		Rarity: float32(req.Rarity),
+		Rarity: req.Rarity,
 		Source: model.DataSource(req.Source),
 	})
 	if err != nil {
@@ -292,6 +470,57 @@ func (api *API) handleCreateDefinition(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type relatedDefinitionResponse struct {
+	Type   string `json:"type"`
+	DefID  int64  `json:"def_id"`
+	Def    string `json:"def"`
+	Rarity int    `json:"rarity"`
+	Source string `json:"source"`
+	WordID int64  `json:"word_id"`
+	Lemma  string `json:"lemma"`
+	Lang   string `json:"lang"`
+	Class  string `json:"class"`
+}
+
+type listRelatedDefinitionsResponse struct {
+	Related []relatedDefinitionResponse `json:"related"`
+}
+
+// handleListRelatedDefinitions lists the synonyms, antonyms, hypernyms, hyponyms and meronyms of a definition
+func (api *API) handleListRelatedDefinitions(w http.ResponseWriter, r *http.Request) {
+	defID, err := idFromRequest(r, "def_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	related, err := api.srv.ListRelatedDefinitions(r.Context(), defID)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, listRelatedDefinitionsResponse{
+		Related: append([]relatedDefinitionResponse{}, fn.Map(related, func(rd model.RelatedDefinition) relatedDefinitionResponse {
+			return relatedDefinitionResponse{
+				Type:   string(rd.Type),
+				DefID:  rd.Definition.ID,
+				Def:    rd.Definition.Text,
+				Rarity: rd.Definition.Rarity,
+				Source: string(rd.Definition.Source),
+				WordID: rd.Word.ID,
+				Lemma:  rd.Word.Lemma,
+				Lang:   string(rd.Word.Lang),
+				Class:  string(rd.Word.Class),
+			}
+		})...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
 type attachImageResponse struct {
 	ImageID  int64    `json:"image_id"`
 	ImageURL *url.URL `json:"image_url"`
@@ -338,6 +567,56 @@ func (api *API) handleAttachImage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func intFromQuery(q url.Values, param string) (int, error) {
+	v := q.Get(param)
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		se := serr.NewServiceError(err, http.StatusBadRequest, "invalid %s parameter", param)
+		se.Env[param] = v
+		return 0, se
+	}
+
+	return n, nil
+}
+
+// boolFromQuery parses a boolean query parameter, returning false when it is missing
+func boolFromQuery(q url.Values, param string) (bool, error) {
+	v := q.Get(param)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		se := serr.NewServiceError(err, http.StatusBadRequest, "invalid %s parameter", param)
+		se.Env[param] = v
+		return false, se
+	}
+
+	return b, nil
+}
+
+// timeFromQuery parses an RFC 3339 time query parameter, returning the zero time when it is missing
+func timeFromQuery(q url.Values, param string) (time.Time, error) {
+	v := q.Get(param)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		se := serr.NewServiceError(err, http.StatusBadRequest, "invalid %s parameter, expected an RFC 3339 time", param)
+		se.Env[param] = v
+		return time.Time{}, se
+	}
+
+	return t, nil
+}
+
 func idFromRequest(r *http.Request, param string) (int64, error) {
 	idStr := r.PathValue(param)
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -5,25 +5,57 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
 	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/highlight"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockWordsService struct {
-	AddWordFunc          func(ctx context.Context, r service.AddWordRequest) (int64, error)
-	DeleteWordFunc       func(ctx context.Context, wordID int64) error
-	PickWordFunc         func(ctx context.Context, r service.PickWoardRequest) (int64, error)
-	UnpickWordFunc       func(ctx context.Context, pickID int64) error
-	GetUserPicksFunc     func(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error)
-	RemoveTagsFunc       func(ctx context.Context, r service.RemoveTagsRequest) error
-	CreateDefinitionFunc func(ctx context.Context, r service.CreateDefinitionRequest) (int64, error)
-	AttachImageFunc      func(ctx context.Context, r service.AttachImageRequest) (service.AttachImageResponse, error)
+	AddWordFunc                     func(ctx context.Context, r service.AddWordRequest) (int64, error)
+	DeleteWordFunc                  func(ctx context.Context, wordID int64) error
+	PickWordFunc                    func(ctx context.Context, r service.PickWoardRequest) (int64, error)
+	AddPickContextFunc              func(ctx context.Context, r service.AddPickContextRequest) (int64, error)
+	BulkCreatePicksFunc             func(ctx context.Context, r service.BulkCreatePicksRequest) (service.BulkResult, error)
+	BulkUpdatePicksFunc             func(ctx context.Context, r service.BulkUpdatePicksRequest) (service.BulkResult, error)
+	UnpickWordFunc                  func(ctx context.Context, r service.UnpickWordRequest) error
+	UpdatePickFunc                  func(ctx context.Context, r service.UpdatePickRequest) error
+	GetUserPicksFunc                func(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error)
+	SaveNoteFunc                    func(ctx context.Context, r service.SaveNoteRequest) (service.Note, error)
+	GetNoteFunc                     func(ctx context.Context, r service.GetNoteRequest) (service.Note, error)
+	DeleteNoteFunc                  func(ctx context.Context, r service.GetNoteRequest) error
+	ListNoteRevisionsFunc           func(ctx context.Context, r service.GetNoteRequest) ([]service.NoteRevision, error)
+	VoteNoteFunc                    func(ctx context.Context, r service.VoteNoteRequest) error
+	ListMnemonicsFunc               func(ctx context.Context, r service.ListMnemonicsRequest) ([]service.Mnemonic, error)
+	RemoveTagsFunc                  func(ctx context.Context, r service.RemoveTagsRequest) error
+	ListTagsFunc                    func(ctx context.Context, userID string) ([]service.Tag, error)
+	TagTreeFunc                     func(ctx context.Context, userID string) ([]service.TagNode, error)
+	RenameTagFunc                   func(ctx context.Context, r service.RenameTagRequest) error
+	MoveTagFunc                     func(ctx context.Context, r service.MoveTagRequest) error
+	MergeTagsFunc                   func(ctx context.Context, r service.MergeTagsRequest) error
+	DeleteTagFunc                   func(ctx context.Context, r service.DeleteTagRequest) error
+	SaveTagFilterFunc               func(ctx context.Context, r service.SaveTagFilterRequest) (service.TagFilter, error)
+	ListTagFiltersFunc              func(ctx context.Context, userID string) ([]service.TagFilter, error)
+	DeleteTagFilterFunc             func(ctx context.Context, r service.DeleteTagFilterRequest) error
+	CreateDefinitionFunc            func(ctx context.Context, r service.CreateDefinitionRequest) (int64, error)
+	ListRelatedDefinitionsFunc      func(ctx context.Context, defID int64) ([]model.RelatedDefinition, error)
+	LookupFunc                      func(ctx context.Context, r service.LookupRequest) ([]service.DictionaryArticle, error)
+	CreateDefinitionFromArticleFunc func(ctx context.Context, r service.CreateDefinitionFromArticleRequest) (int64, error)
+	ExportAnkiFunc                  func(ctx context.Context, w io.Writer, r service.ExportAnkiRequest) (service.ExportAnkiReport, error)
+	ImportDeckFunc                  func(ctx context.Context, r service.ImportDeckRequest) (model.ImportJob, error)
+	GetImportJobFunc                func(ctx context.Context, userID string, jobID int64) (model.ImportJob, error)
+	AttachImageFunc                 func(ctx context.Context, r service.AttachImageRequest) (service.AttachImageResponse, error)
 }
 
 func (m *mockWordsService) AddWord(ctx context.Context, r service.AddWordRequest) (int64, error) {
@@ -38,34 +70,139 @@ func (m *mockWordsService) PickWord(ctx context.Context, r service.PickWoardRequ
 	return m.PickWordFunc(ctx, r)
 }
 
-func (m *mockWordsService) UnpickWord(ctx context.Context, pickID int64) error {
-	return m.UnpickWordFunc(ctx, pickID)
+func (m *mockWordsService) AddPickContext(ctx context.Context, r service.AddPickContextRequest) (int64, error) {
+	return m.AddPickContextFunc(ctx, r)
+}
+
+func (m *mockWordsService) BulkCreatePicks(ctx context.Context, r service.BulkCreatePicksRequest) (service.BulkResult, error) {
+	return m.BulkCreatePicksFunc(ctx, r)
+}
+
+func (m *mockWordsService) BulkUpdatePicks(ctx context.Context, r service.BulkUpdatePicksRequest) (service.BulkResult, error) {
+	return m.BulkUpdatePicksFunc(ctx, r)
+}
+
+func (m *mockWordsService) UnpickWord(ctx context.Context, r service.UnpickWordRequest) error {
+	return m.UnpickWordFunc(ctx, r)
+}
+
+func (m *mockWordsService) UpdatePick(ctx context.Context, r service.UpdatePickRequest) error {
+	return m.UpdatePickFunc(ctx, r)
 }
 
 func (m *mockWordsService) GetUserPicks(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error) {
 	return m.GetUserPicksFunc(ctx, r)
 }
 
+func (m *mockWordsService) SaveNote(ctx context.Context, r service.SaveNoteRequest) (service.Note, error) {
+	return m.SaveNoteFunc(ctx, r)
+}
+
+func (m *mockWordsService) GetNote(ctx context.Context, r service.GetNoteRequest) (service.Note, error) {
+	return m.GetNoteFunc(ctx, r)
+}
+
+func (m *mockWordsService) DeleteNote(ctx context.Context, r service.GetNoteRequest) error {
+	return m.DeleteNoteFunc(ctx, r)
+}
+
+func (m *mockWordsService) ListNoteRevisions(ctx context.Context, r service.GetNoteRequest) ([]service.NoteRevision, error) {
+	return m.ListNoteRevisionsFunc(ctx, r)
+}
+
+func (m *mockWordsService) VoteNote(ctx context.Context, r service.VoteNoteRequest) error {
+	return m.VoteNoteFunc(ctx, r)
+}
+
+func (m *mockWordsService) ListMnemonics(ctx context.Context, r service.ListMnemonicsRequest) ([]service.Mnemonic, error) {
+	return m.ListMnemonicsFunc(ctx, r)
+}
+
 func (m *mockWordsService) RemoveTags(ctx context.Context, r service.RemoveTagsRequest) error {
 	return m.RemoveTagsFunc(ctx, r)
 }
 
+func (m *mockWordsService) ListTags(ctx context.Context, userID string) ([]service.Tag, error) {
+	return m.ListTagsFunc(ctx, userID)
+}
+
+func (m *mockWordsService) TagTree(ctx context.Context, userID string) ([]service.TagNode, error) {
+	return m.TagTreeFunc(ctx, userID)
+}
+
+func (m *mockWordsService) RenameTag(ctx context.Context, r service.RenameTagRequest) error {
+	return m.RenameTagFunc(ctx, r)
+}
+
+func (m *mockWordsService) MoveTag(ctx context.Context, r service.MoveTagRequest) error {
+	return m.MoveTagFunc(ctx, r)
+}
+
+func (m *mockWordsService) MergeTags(ctx context.Context, r service.MergeTagsRequest) error {
+	return m.MergeTagsFunc(ctx, r)
+}
+
+func (m *mockWordsService) DeleteTag(ctx context.Context, r service.DeleteTagRequest) error {
+	return m.DeleteTagFunc(ctx, r)
+}
+
+func (m *mockWordsService) SaveTagFilter(ctx context.Context, r service.SaveTagFilterRequest) (service.TagFilter, error) {
+	return m.SaveTagFilterFunc(ctx, r)
+}
+
+func (m *mockWordsService) ListTagFilters(ctx context.Context, userID string) ([]service.TagFilter, error) {
+	return m.ListTagFiltersFunc(ctx, userID)
+}
+
+func (m *mockWordsService) DeleteTagFilter(ctx context.Context, r service.DeleteTagFilterRequest) error {
+	return m.DeleteTagFilterFunc(ctx, r)
+}
+
 func (m *mockWordsService) CreateDefinition(ctx context.Context, r service.CreateDefinitionRequest) (int64, error) {
 	return m.CreateDefinitionFunc(ctx, r)
 }
 
+func (m *mockWordsService) ListRelatedDefinitions(ctx context.Context, defID int64) ([]model.RelatedDefinition, error) {
+	return m.ListRelatedDefinitionsFunc(ctx, defID)
+}
+
+func (m *mockWordsService) Lookup(ctx context.Context, r service.LookupRequest) ([]service.DictionaryArticle, error) {
+	return m.LookupFunc(ctx, r)
+}
+
+func (m *mockWordsService) CreateDefinitionFromArticle(ctx context.Context, r service.CreateDefinitionFromArticleRequest) (int64, error) {
+	return m.CreateDefinitionFromArticleFunc(ctx, r)
+}
+
+func (m *mockWordsService) ExportAnki(ctx context.Context, w io.Writer, r service.ExportAnkiRequest) (service.ExportAnkiReport, error) {
+	return m.ExportAnkiFunc(ctx, w, r)
+}
+
+func (m *mockWordsService) ImportDeck(ctx context.Context, r service.ImportDeckRequest) (model.ImportJob, error) {
+	return m.ImportDeckFunc(ctx, r)
+}
+
+func (m *mockWordsService) GetImportJob(ctx context.Context, userID string, jobID int64) (model.ImportJob, error) {
+	return m.GetImportJobFunc(ctx, userID, jobID)
+}
+
 func (m *mockWordsService) AttachImage(ctx context.Context, r service.AttachImageRequest) (service.AttachImageResponse, error) {
 	return m.AttachImageFunc(ctx, r)
 }
 
 type mockImageStore struct {
-	SaveImageFunc func(ctx context.Context, imgReader io.Reader) (*url.URL, error)
+	SaveImageFunc  func(ctx context.Context, imgReader io.Reader) (*url.URL, error)
+	FetchImageFunc func(ctx context.Context, imageURL string) (io.ReadCloser, error)
 }
 
 func (m *mockImageStore) SaveImage(ctx context.Context, img io.Reader) (*url.URL, error) {
 	return m.SaveImageFunc(ctx, img)
 }
 
+func (m *mockImageStore) FetchImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	return m.FetchImageFunc(ctx, imageURL)
+}
+
 func TestPUTWord(t *testing.T) {
 	req := addWordRequest{
 		Lemma: "test",
@@ -132,13 +269,21 @@ func TestDELETEWord_BadRequest(t *testing.T) {
 
 func TestPUTPick(t *testing.T) {
 	req := pickWordRequest{
-		WordID: 123,
-		DefID:  456,
+		WordID:      123,
+		DefID:       456,
+		Context:     "A test sentence.",
+		SourceTitle: "Tests",
+		SourceURL:   "https://example.com/tests",
+	}
+	wantContext := service.PickContextRequest{
+		Sentence:    "A test sentence.",
+		SourceTitle: "Tests",
+		SourceURL:   "https://example.com/tests",
 	}
 	api := NewAPI(
 		&mockWordsService{
 			PickWordFunc: func(ctx context.Context, r service.PickWoardRequest) (int64, error) {
-				if r.WordID == req.WordID && r.DefID == req.DefID {
+				if r.WordID == req.WordID && r.DefID == req.DefID && r.Context == wantContext {
 					return 42, nil
 				}
 
@@ -168,8 +313,8 @@ func TestPUTPicks_BadRequest(t *testing.T) {
 func TestDELETEPick(t *testing.T) {
 	api := NewAPI(
 		&mockWordsService{
-			UnpickWordFunc: func(ctx context.Context, pickID int64) error {
-				if pickID == 123 {
+			UnpickWordFunc: func(ctx context.Context, r service.UnpickWordRequest) error {
+				if r.PickID == 123 {
 					return nil
 				}
 
@@ -193,38 +338,95 @@ func TestDELETEPick_BadRequest(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestPATCHPick(t *testing.T) {
+	var reqs []service.UpdatePickRequest
+	api := NewAPI(
+		&mockWordsService{
+			UpdatePickFunc: func(ctx context.Context, r service.UpdatePickRequest) error {
+				reqs = append(reqs, r)
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PATCH", "/picks/123", map[string]any{"status": "known"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = test.SendRequest(t, api, "PATCH", "/picks/123", map[string]any{"def": "", "image_id": 7})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	known, empty, imageID := model.StatusKnown, "", int64(7)
+	assert.Equal(t, []service.UpdatePickRequest{
+		{PickID: 123, Status: &known},
+		{PickID: 123, Def: &empty, ImageID: &imageID},
+	}, reqs)
+}
+
+func TestPATCHPick_BadRequest(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PATCH", "/picks/invalid-id", map[string]any{"status": "known"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 func TestGETPicks(t *testing.T) {
-	req := getPicksRequest{
-		UserID: "user-123",
-	}
+	var req service.GetUserPicksRequest
 	api := NewAPI(
 		&mockWordsService{
 			GetUserPicksFunc: func(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error) {
-				if r.UserID == req.UserID {
-					return service.GetUserPicksResponse{
-						Picks: []service.UserPick{
-							{
-								ID:     1,
-								UserID: "user-123",
-								Word:   "test",
-								Def:    "A test definition",
-								Tags:   []string{"tag1", "tag2"},
-								Lang:   model.Lang("en"),
-								Class:  model.WordClass("noun"),
+				req = r
+				return service.GetUserPicksResponse{
+					Picks: []service.UserPick{
+						{
+							ID:     1,
+							UserID: "user-123",
+							Word:   "test",
+							Def:    "A test definition",
+							Tags:   []string{"tag1", "tag2"},
+							Lang:   model.Lang("en"),
+							Class:  model.WordClass("noun"),
+							Status: model.StatusLearning,
+
+							OriginalDef:  "The shared definition",
+							ImageURL:     "https://example.com/test.png",
+							Personalized: true,
+							Contexts: []service.PickContext{
+								{ID: 4, Sentence: "It was a test.", Highlight: &highlight.Span{Start: 9, End: 13}},
 							},
 						},
-						NextCursor: "",
-					}, nil
-				}
-
-				return service.GetUserPicksResponse{}, errors.New("unexpected user ID")
+					},
+					NextCursor: "next",
+					Total:      3,
+					Facets: service.PickFacets{
+						Tags:    map[string]int{"tag1": 3, "tag2": 1},
+						Langs:   map[model.Lang]int{"en": 3},
+						Classes: map[model.WordClass]int{"noun": 3},
+					},
+				}, nil
 			},
 		},
 		&mockImageStore{},
 	)
 
-	rec := test.SendRequest(t, api, "GET", "/picks", req)
+	rec := test.SendRequest(t, api, "GET", "/picks?with_tags=tag1&with_tags=tag2&without_tags=tag3&lang=en&class=noun&class=verb"+
+		"&status=new&status=learning&filter=verbs+OR+nouns&sort=-lemma&page_size=5&next_cursor=abc&created_after=2024-01-01T00:00:00Z", nil)
 	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.GetUserPicksRequest{
+		WithTags:     []string{"tag1", "tag2"},
+		WithoutTags:  []string{"tag3"},
+		Filter:       "verbs OR nouns",
+		Langs:        []model.Lang{"en"},
+		Classes:      []model.WordClass{model.Noun, model.Verb},
+		Statuses:     []model.PickStatus{model.StatusNew, model.StatusLearning},
+		CreatedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		SortBy:       model.SortByLemma,
+		SortDesc:     true,
+		NextCursor:   "abc",
+		PageSize:     5,
+	}, req)
 
 	resp := test.ParseResponse[getPicksResponse](t, rec)
 	assert.Len(t, resp.Picks, 1)
@@ -235,6 +437,56 @@ func TestGETPicks(t *testing.T) {
 	assert.Equal(t, "noun", resp.Picks[0].Class)
 	assert.Equal(t, "A test definition", resp.Picks[0].Def)
 	assert.Equal(t, []string{"tag1", "tag2"}, resp.Picks[0].Tags)
+	assert.Equal(t, "learning", resp.Picks[0].Status)
+	assert.Equal(t, "The shared definition", resp.Picks[0].OriginalDef)
+	assert.Equal(t, "https://example.com/test.png", resp.Picks[0].ImageURL)
+	assert.True(t, resp.Picks[0].Personalized)
+	assert.Equal(t, []pickContextResponse{
+		{ID: 4, Sentence: "It was a test.", Highlight: &highlightResponse{Start: 9, End: 13}},
+	}, resp.Picks[0].Contexts)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, pickFacetsResponse{
+		Tags:    map[string]int{"tag1": 3, "tag2": 1},
+		Langs:   map[string]int{"en": 3},
+		Classes: map[string]int{"noun": 3},
+	}, resp.Facets)
+}
+
+type profileIntrospector middleware.Profile
+
+func (p profileIntrospector) Introspect(ctx context.Context, token string) (middleware.TokenInfo, error) {
+	return middleware.TokenInfo{Active: true, UserID: "user-123", Profile: middleware.Profile(p)}, nil
+}
+
+func TestGETPicks_Langs(t *testing.T) {
+	var langs []model.Lang
+	api := NewAPI(
+		&mockWordsService{
+			GetUserPicksFunc: func(ctx context.Context, r service.GetUserPicksRequest) (service.GetUserPicksResponse, error) {
+				langs = r.Langs
+				return service.GetUserPicksResponse{}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+	h := middleware.Auth([]byte("key"), middleware.WithIntrospection("lxp_", profileIntrospector{TargetLangs: []string{"de", "fr"}}))(api)
+
+	for path, want := range map[string][]model.Lang{
+		"/picks":               {"de", "fr"},
+		"/picks?lang=en":       {"en"},
+		"/picks?lang=":         nil,
+		"/picks?lang=&lang=es": {"es"},
+	} {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer lxp_token")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, want, langs, path)
+	}
 }
 
 func TestGETPicks_BadRequest(t *testing.T) {
@@ -243,8 +495,45 @@ func TestGETPicks_BadRequest(t *testing.T) {
 		&mockImageStore{},
 	)
 
-	rec := test.SendRequest(t, api, "GET", "/picks", "invalid json")
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	for _, query := range []string{"page_size=ten", "created_after=yesterday", "created_before=2024-01-01"} {
+		rec := test.SendRequest(t, api, "GET", "/picks?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// picksStore serves the picks listing of a real service, the other store methods are not implemented
+type picksStore struct {
+	store.DataStore
+	pageSizes []int
+}
+
+func (s *picksStore) GetUserPicks(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+	s.pageSizes = append(s.pageSizes, r.PageSize)
+	return store.GetUserPicksResponse{}, nil
+}
+
+func (s *picksStore) CountUserPicks(ctx context.Context, r store.GetUserPicksRequest) (store.CountUserPicksResponse, error) {
+	return store.CountUserPicksResponse{}, nil
+}
+
+func TestGETPicks_PageSize(t *testing.T) {
+	st := &picksStore{}
+	api := NewAPI(
+		service.NewWordsService(st, service.WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100}),
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/picks", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = test.SendRequest(t, api, "GET", "/picks?page_size=100", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []int{20, 100}, st.pageSizes)
+
+	for _, query := range []string{"page_size=-1", "page_size=101"} {
+		rec := test.SendRequest(t, api, "GET", "/picks?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	assert.Len(t, st.pageSizes, 2)
 }
 
 func TestDELETETag(t *testing.T) {
@@ -315,6 +604,46 @@ func TestPUTDefinition_BadRequest(t *testing.T) {
 
 	rec := test.SendRequest(t, api, "PUT", "/definitions", "invalid json")
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.SendRequest(t, api, "PUT", "/definitions", map[string]any{"word_id": 123, "def": "Test definition", "rarity": 1.5})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGETRelatedDefinitions(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ListRelatedDefinitionsFunc: func(ctx context.Context, defID int64) ([]model.RelatedDefinition, error) {
+				if defID != 123 {
+					return nil, serr.NewServiceError(nil, http.StatusNotFound, "definition not found")
+				}
+
+				return []model.RelatedDefinition{{
+					Type:       model.RelSynonym,
+					Word:       model.Word{ID: 7, Lemma: "large", Lang: "en", Class: model.Adjective},
+					Definition: model.Definition{ID: 42, WordID: 7, Text: "above average in size", Source: model.SrcWordNet},
+				}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/definitions/123/related", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp := test.ParseResponse[listRelatedDefinitionsResponse](t, rec)
+	assert.Equal(t, listRelatedDefinitionsResponse{Related: []relatedDefinitionResponse{{
+		Type:   "synonym",
+		DefID:  42,
+		Def:    "above average in size",
+		Source: "wordnet",
+		WordID: 7,
+		Lemma:  "large",
+		Lang:   "en",
+		Class:  "adjective",
+	}}}, resp)
+
+	rec = test.SendRequest(t, api, "GET", "/definitions/456/related", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
 func TestPUTImage(t *testing.T) {
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+// bulkItemResponse reports one item of a bulk operation, Status is the HTTP status code the item
+// would have had as a request of its own
+type bulkItemResponse struct {
+	Index  int    `json:"index"`
+	PickID int64  `json:"pick_id,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	DryRun    bool               `json:"dry_run"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Items     []bulkItemResponse `json:"items"`
+}
+
+func newBulkResponse(result service.BulkResult, okStatus int) bulkResponse {
+	items := fn.Map(result.Items, func(item service.BulkItemResult) bulkItemResponse {
+		resp := bulkItemResponse{Index: item.Index, PickID: item.PickID, Status: okStatus}
+		if item.Err != nil {
+			resp.Status = item.Err.StatusCode
+			resp.Error = item.Err.Msg
+		}
+		return resp
+	})
+	if items == nil {
+		items = []bulkItemResponse{}
+	}
+
+	return bulkResponse{DryRun: result.DryRun, Succeeded: result.Succeeded, Failed: result.Failed, Items: items}
+}
+
+type bulkCreatePicksRequest struct {
+	Picks  []pickWordRequest `json:"picks"`
+	DryRun bool              `json:"dry_run"`
+}
+
+func (api *API) handleBulkCreatePicks(w http.ResponseWriter, r *http.Request) {
+	var req bulkCreatePicksRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	result, err := api.srv.BulkCreatePicks(r.Context(), service.BulkCreatePicksRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		Picks: fn.Map(req.Picks, func(p pickWordRequest) service.PickWoardRequest {
+			return service.PickWoardRequest{
+				WordID: p.WordID,
+				DefID:  p.DefID,
+				Tags:   p.Tags,
+				Context: service.PickContextRequest{
+					Sentence:    p.Context,
+					SourceTitle: p.SourceTitle,
+					SourceURL:   p.SourceURL,
+				},
+			}
+		}),
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newBulkResponse(result, http.StatusCreated)); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+// picksFilterRequest holds the filters of GET /picks. Unlike there, picks in all languages
+// match when lang is empty, not only the ones in the languages the user is learning.
+type picksFilterRequest struct {
+	WithTags      []string  `json:"with_tags"`
+	WithoutTags   []string  `json:"without_tags"`
+	Filter        string    `json:"filter"`
+	SavedFilter   string    `json:"saved_filter"`
+	Langs         []string  `json:"lang"`
+	Classes       []string  `json:"class"`
+	Statuses      []string  `json:"status"`
+	CreatedAfter  time.Time `json:"created_after"`
+	CreatedBefore time.Time `json:"created_before"`
+}
+
+// bulkUpdatePicksRequest selects the picks either by pick_ids or by filter
+type bulkUpdatePicksRequest struct {
+	PickIDs []int64             `json:"pick_ids"`
+	Filter  *picksFilterRequest `json:"filter"`
+	Tags    []string            `json:"tags"`
+	Status  string              `json:"status"`
+	DryRun  bool                `json:"dry_run"`
+}
+
+// handleBulkUpdatePicks applies the action of the path, one of add_tags, remove_tags, set_status and delete
+func (api *API) handleBulkUpdatePicks(w http.ResponseWriter, r *http.Request) {
+	var req bulkUpdatePicksRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	var filter *service.GetUserPicksRequest
+	if req.Filter != nil {
+		filter = &service.GetUserPicksRequest{
+			UserID:        userID,
+			WithTags:      req.Filter.WithTags,
+			WithoutTags:   req.Filter.WithoutTags,
+			Filter:        req.Filter.Filter,
+			SavedFilter:   req.Filter.SavedFilter,
+			Langs:         fn.Map(req.Filter.Langs, func(l string) model.Lang { return model.Lang(l) }),
+			Classes:       fn.Map(req.Filter.Classes, func(c string) model.WordClass { return model.WordClass(c) }),
+			Statuses:      fn.Map(req.Filter.Statuses, func(s string) model.PickStatus { return model.PickStatus(s) }),
+			CreatedAfter:  req.Filter.CreatedAfter,
+			CreatedBefore: req.Filter.CreatedBefore,
+		}
+	}
+
+	result, err := api.srv.BulkUpdatePicks(r.Context(), service.BulkUpdatePicksRequest{
+		UserID:  userID,
+		PickIDs: req.PickIDs,
+		Filter:  filter,
+		Action:  service.BulkAction(r.PathValue("action")),
+		Tags:    req.Tags,
+		Status:  model.PickStatus(req.Status),
+		DryRun:  req.DryRun,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newBulkResponse(result, http.StatusOK)); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
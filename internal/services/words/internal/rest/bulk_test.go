@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPOSTPicksBulk(t *testing.T) {
+	var req service.BulkCreatePicksRequest
+	api := NewAPI(
+		&mockWordsService{
+			BulkCreatePicksFunc: func(ctx context.Context, r service.BulkCreatePicksRequest) (service.BulkResult, error) {
+				req = r
+				return service.BulkResult{
+					Items: []service.BulkItemResult{
+						{Index: 0, PickID: 11},
+						{Index: 1, Err: serr.NewServiceError(nil, http.StatusConflict, "user pick already exists")},
+					},
+					Succeeded: 1,
+					Failed:    1,
+					DryRun:    true,
+				}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "POST", "/picks/bulk", map[string]any{
+		"picks": []map[string]any{
+			{"word_id": 1, "def_id": 2, "tags": []string{"fruit"}, "context": "An apple a day."},
+			{"word_id": 1, "def_id": 3},
+		},
+		"dry_run": true,
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.BulkCreatePicksRequest{
+		Picks: []service.PickWoardRequest{
+			{WordID: 1, DefID: 2, Tags: []string{"fruit"}, Context: service.PickContextRequest{Sentence: "An apple a day."}},
+			{WordID: 1, DefID: 3},
+		},
+		DryRun: true,
+	}, req)
+	assert.JSONEq(t, `{
+		"dry_run": true,
+		"succeeded": 1,
+		"failed": 1,
+		"items": [
+			{"index": 0, "pick_id": 11, "status": 201},
+			{"index": 1, "status": 409, "error": "user pick already exists"}
+		]
+	}`, rec.Body.String())
+}
+
+func TestPOSTPicksBulkAction(t *testing.T) {
+	var reqs []service.BulkUpdatePicksRequest
+	api := NewAPI(
+		&mockWordsService{
+			BulkUpdatePicksFunc: func(ctx context.Context, r service.BulkUpdatePicksRequest) (service.BulkResult, error) {
+				reqs = append(reqs, r)
+				return service.BulkResult{}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "POST", "/picks/bulk/set_status", map[string]any{"pick_ids": []int64{1, 2}, "status": "known"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"dry_run": false, "succeeded": 0, "failed": 0, "items": []}`, rec.Body.String())
+
+	rec = test.SendRequest(t, api, "POST", "/picks/bulk/add_tags", map[string]any{
+		"filter": map[string]any{"with_tags": []string{"fruit"}, "lang": []string{"en"}, "created_after": "2024-01-01T00:00:00Z"},
+		"tags":   []string{"food"},
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, []service.BulkUpdatePicksRequest{
+		{PickIDs: []int64{1, 2}, Action: service.BulkSetStatus, Status: model.StatusKnown},
+		{
+			Filter: &service.GetUserPicksRequest{
+				WithTags:     []string{"fruit"},
+				Langs:        []model.Lang{"en"},
+				CreatedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Action: service.BulkAddTags,
+			Tags:   []string{"food"},
+		},
+	}, reqs)
+}
+
+func TestPOSTPicksBulkAction_BadRequest(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			BulkUpdatePicksFunc: func(ctx context.Context, r service.BulkUpdatePicksRequest) (service.BulkResult, error) {
+				return service.BulkResult{}, serr.NewServiceError(nil, http.StatusBadRequest, "unknown bulk action")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "POST", "/picks/bulk/delete", "invalid json")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.SendRequest(t, api, "POST", "/picks/bulk/rename", map[string]any{"pick_ids": []int64{1}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+// highlightResponse is the span of the context sentence the word occurs in, in characters (Unicode code points)
+// from the start of the sentence, end exclusive
+type highlightResponse struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type pickContextResponse struct {
+	ID          int64              `json:"id"`
+	Sentence    string             `json:"sentence"`
+	SourceTitle string             `json:"source_title,omitempty"`
+	SourceURL   string             `json:"source_url,omitempty"`
+	Highlight   *highlightResponse `json:"highlight,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+func newPickContextResponse(c service.PickContext) pickContextResponse {
+	resp := pickContextResponse{
+		ID:          c.ID,
+		Sentence:    c.Sentence,
+		SourceTitle: c.SourceTitle,
+		SourceURL:   c.SourceURL,
+		CreatedAt:   c.CreatedAt,
+	}
+	if c.Highlight != nil {
+		resp.Highlight = &highlightResponse{Start: c.Highlight.Start, End: c.Highlight.End}
+	}
+
+	return resp
+}
+
+type addPickContextRequest struct {
+	Sentence    string `json:"sentence"`
+	SourceTitle string `json:"source_title"`
+	SourceURL   string `json:"source_url"`
+}
+
+type addPickContextResponse struct {
+	ID int64 `json:"id"`
+}
+
+func (api *API) handleAddPickContext(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req addPickContextRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	id, err := api.srv.AddPickContext(r.Context(), service.AddPickContextRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+		Context: service.PickContextRequest{
+			Sentence:    req.Sentence,
+			SourceTitle: req.SourceTitle,
+			SourceURL:   req.SourceURL,
+		},
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusCreated, addPickContextResponse{ID: id}); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
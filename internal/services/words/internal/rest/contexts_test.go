@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPUTPickContext(t *testing.T) {
+	var added service.AddPickContextRequest
+	api := NewAPI(
+		&mockWordsService{
+			AddPickContextFunc: func(ctx context.Context, r service.AddPickContextRequest) (int64, error) {
+				added = r
+				return 9, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/picks/3/contexts", addPickContextRequest{Sentence: "An apple a day.", SourceURL: "https://example.com"})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, service.AddPickContextRequest{
+		PickID:  3,
+		Context: service.PickContextRequest{Sentence: "An apple a day.", SourceURL: "https://example.com"},
+	}, added)
+	assert.JSONEq(t, `{"id":9}`, rec.Body.String())
+}
+
+func TestPUTPickContext_Errors(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			AddPickContextFunc: func(ctx context.Context, r service.AddPickContextRequest) (int64, error) {
+				return 0, serr.NewServiceError(nil, http.StatusBadRequest, "context sentence is required")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/picks/invalid-id/contexts", addPickContextRequest{Sentence: "An apple a day."})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.SendRequest(t, api, "PUT", "/picks/3/contexts", "invalid json")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.SendRequest(t, api, "PUT", "/picks/3/contexts", addPickContextRequest{})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+// ankiExportTimeout is how long the export of a deck may take, images are fetched while the deck is streamed
+const ankiExportTimeout = 10 * time.Minute
+
+// attachmentWriter sends the headers of a file download with the first write, so that an error
+// returned before anything was written can still be sent as an error response
+type attachmentWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	fileName    string
+	started     bool
+}
+
+func (a *attachmentWriter) Write(p []byte) (int, error) {
+	if !a.started {
+		a.started = true
+		a.w.Header().Set("Content-Type", a.contentType)
+		a.w.Header().Set("Content-Disposition", `attachment; filename="`+a.fileName+`"`)
+		a.w.WriteHeader(http.StatusOK)
+	}
+
+	return a.w.Write(p)
+}
+
+// handleExportAnki streams the picks of the user as an Anki package. Like for the list of picks, the with_tags
+// query parameter is repeated to export the picks with all of the tags.
+func (api *API) handleExportAnki(w http.ResponseWriter, r *http.Request) {
+	// large decks take longer to stream than the write timeout of the server allows
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(ankiExportTimeout))
+	ctx, cancel := context.WithTimeout(r.Context(), ankiExportTimeout)
+	defer cancel()
+
+	out := &attachmentWriter{w: w, contentType: "application/apkg", fileName: "lexi.apkg"}
+	report, err := api.srv.ExportAnki(ctx, out, service.ExportAnkiRequest{
+		UserID:     middleware.UserIDFromContext(r.Context()),
+		WithTags:   r.URL.Query()["with_tags"],
+		FetchImage: api.imgStore.FetchImage,
+	})
+	if err != nil {
+		if out.started {
+			// the status was sent already, the client gets an incomplete package
+			slog.Error("anki export failed", "error", err, "notes", report.Notes)
+			return
+		}
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if report.MissingImages > 0 {
+		slog.Warn("anki export is missing images", "notes", report.Notes, "missing_images", report.MissingImages)
+	}
+}
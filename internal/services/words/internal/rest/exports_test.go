@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGETExportAnki(t *testing.T) {
+	var exported service.ExportAnkiRequest
+	api := NewAPI(
+		&mockWordsService{
+			ExportAnkiFunc: func(ctx context.Context, w io.Writer, r service.ExportAnkiRequest) (service.ExportAnkiReport, error) {
+				exported = r
+				img, err := r.FetchImage(ctx, "https://images.example.com/apple.png")
+				if err != nil {
+					return service.ExportAnkiReport{}, err
+				}
+				defer img.Close()
+
+				_, err = io.Copy(w, img)
+				return service.ExportAnkiReport{Notes: 1, Images: 1}, err
+			},
+		},
+		&mockImageStore{
+			FetchImageFunc: func(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("apkg data")), nil
+			},
+		},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/exports/anki?with_tags=fruits&with_tags=food", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"fruits", "food"}, exported.WithTags)
+	assert.Equal(t, "application/apkg", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="lexi.apkg"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "apkg data", rec.Body.String())
+}
+
+func TestGETExportAnki_Errors(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ExportAnkiFunc: func(ctx context.Context, w io.Writer, r service.ExportAnkiRequest) (service.ExportAnkiReport, error) {
+				if len(r.WithTags) == 0 {
+					return service.ExportAnkiReport{}, serr.NewServiceError(nil, http.StatusBadRequest, "invalid tags")
+				}
+
+				// the package is cut short after it was started
+				if _, err := w.Write([]byte("apkg")); err != nil {
+					return service.ExportAnkiReport{}, err
+				}
+				return service.ExportAnkiReport{}, errors.New("connection reset")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/exports/anki", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+
+	rec = test.SendRequest(t, api, "GET", "/exports/anki?with_tags=fruits", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "apkg", rec.Body.String())
+}
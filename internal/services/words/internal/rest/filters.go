@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+type tagFilterResponse struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
+
+type listTagFiltersResponse struct {
+	Filters []tagFilterResponse `json:"filters"`
+}
+
+func newTagFilterResponse(f service.TagFilter) tagFilterResponse {
+	return tagFilterResponse{Name: f.Name, Expr: f.Expr}
+}
+
+func (api *API) handleListTagFilters(w http.ResponseWriter, r *http.Request) {
+	filters, err := api.srv.ListTagFilters(r.Context(), middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, listTagFiltersResponse{
+		Filters: append([]tagFilterResponse{}, fn.Map(filters, newTagFilterResponse)...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+type saveTagFilterRequest struct {
+	Expr string `json:"expr"`
+}
+
+func (api *API) handleSaveTagFilter(w http.ResponseWriter, r *http.Request) {
+	var req saveTagFilterRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	f, err := api.srv.SaveTagFilter(r.Context(), service.SaveTagFilterRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		Name:   r.PathValue("name"),
+		Expr:   req.Expr,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newTagFilterResponse(f)); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func (api *API) handleDeleteTagFilter(w http.ResponseWriter, r *http.Request) {
+	err := api.srv.DeleteTagFilter(r.Context(), service.DeleteTagFilterRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		Name:   r.PathValue("name"),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGETFilters(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ListTagFiltersFunc: func(ctx context.Context, userID string) ([]service.TagFilter, error) {
+				return []service.TagFilter{{Name: "todo", Expr: "verbs AND NOT mastered"}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/filters", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"filters":[{"name":"todo","expr":"verbs AND NOT mastered"}]}`, rec.Body.String())
+}
+
+func TestPUTFilter(t *testing.T) {
+	var saved service.SaveTagFilterRequest
+	api := NewAPI(
+		&mockWordsService{
+			SaveTagFilterFunc: func(ctx context.Context, r service.SaveTagFilterRequest) (service.TagFilter, error) {
+				saved = r
+				return service.TagFilter{Name: r.Name, Expr: "verbs AND NOT mastered"}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/filters/todo", saveTagFilterRequest{Expr: "verbs and not mastered"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.SaveTagFilterRequest{Name: "todo", Expr: "verbs and not mastered"}, saved)
+	assert.JSONEq(t, `{"name":"todo","expr":"verbs AND NOT mastered"}`, rec.Body.String())
+}
+
+func TestPUTFilter_Invalid(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			SaveTagFilterFunc: func(ctx context.Context, r service.SaveTagFilterRequest) (service.TagFilter, error) {
+				return service.TagFilter{}, serr.NewServiceError(nil, http.StatusBadRequest, "invalid tag filter")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/filters/todo", saveTagFilterRequest{Expr: "verbs AND"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDELETEFilter(t *testing.T) {
+	var deleted service.DeleteTagFilterRequest
+	api := NewAPI(
+		&mockWordsService{
+			DeleteTagFilterFunc: func(ctx context.Context, r service.DeleteTagFilterRequest) error {
+				deleted = r
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "DELETE", "/filters/todo", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, service.DeleteTagFilterRequest{Name: "todo"}, deleted)
+}
@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/deck"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+const (
+	// maxDeckSize is the maximum size of an uploaded deck, Anki packages include their media files
+	maxDeckSize = 256 << 20
+	// deckUploadTimeout is how long the upload of a deck may take, large decks take longer
+	// than the timeouts of the server allow
+	deckUploadTimeout = 10 * time.Minute
+)
+
+// deckFormats maps the content types of deck uploads to their format
+var deckFormats = map[string]deck.Format{
+	"application/apkg":          deck.Anki,
+	"application/zip":           deck.Anki,
+	"text/tab-separated-values": deck.TSV,
+	"text/plain":                deck.TSV,
+}
+
+type importJobErrorResponse struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type importJobResponse struct {
+	ID         int64                    `json:"id"`
+	Status     model.ImportStatus       `json:"status"`
+	Total      int                      `json:"total"`
+	Cards      int                      `json:"cards"`
+	Created    int                      `json:"created"`
+	Skipped    int                      `json:"skipped"`
+	Failed     int                      `json:"failed"`
+	Errors     []importJobErrorResponse `json:"errors"`
+	Error      string                   `json:"error,omitempty"`
+	CreatedAt  time.Time                `json:"created_at"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+}
+
+func newImportJobResponse(j model.ImportJob) importJobResponse {
+	return importJobResponse{
+		ID:      j.ID,
+		Status:  j.Status,
+		Total:   j.Total,
+		Cards:   j.Cards,
+		Created: j.Created,
+		Skipped: j.Skipped,
+		Failed:  j.Failed,
+		Errors: append([]importJobErrorResponse{}, fn.Map(j.Errors, func(e model.ImportJobError) importJobErrorResponse {
+			return importJobErrorResponse{Index: e.Index, Error: e.Msg}
+		})...),
+		Error:      j.Error,
+		CreatedAt:  j.CreateAt,
+		FinishedAt: j.FinishedAt,
+	}
+}
+
+// handleImportDeck starts importing the deck in the request body into the picks of the user and responds with
+// the import job. The format query parameter, or else the content type, tells an Anki package from a TSV file
+// such as a Quizlet export. The lemma_field, definition_field and class_field parameters map the fields of the
+// cards, lang and class give the language and the word class of the cards, and every tags parameter is added
+// to all picks. with_reviews carries the scheduling of Anki cards over, and separator, row_separator and header
+// describe TSV files.
+func (api *API) handleImportDeck(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := deck.Format(q.Get("format"))
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = deckFormats[mediaType]
+	}
+
+	header, err := boolFromQuery(q, "header")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+	withReviews, err := boolFromQuery(q, "with_reviews")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(deckUploadTimeout))
+	// the write timeout of the server runs from the start of the request too, the response follows the upload
+	_ = rc.SetWriteDeadline(time.Now().Add(deckUploadTimeout + time.Minute))
+
+	f, err := spoolBody(http.MaxBytesReader(w, r.Body, maxDeckSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = serr.NewServiceError(err, http.StatusRequestEntityTooLarge, "deck is too large")
+		}
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	d, err := deck.Open(format, f, deck.Options{
+		Separator:    q.Get("separator"),
+		RowSeparator: q.Get("row_separator"),
+		Header:       header,
+	})
+	if err != nil {
+		se := serr.NewServiceError(err, http.StatusBadRequest, "invalid deck")
+		if errors.Is(err, deck.ErrUnknownFormat) {
+			se = serr.NewServiceError(err, http.StatusBadRequest, "unsupported deck format")
+		}
+		if errors.Is(err, deck.ErrTooLarge) {
+			se = serr.NewServiceError(err, http.StatusRequestEntityTooLarge, "deck is too large")
+		}
+		se.Env["format"] = string(format)
+		httpx.HandleErr(w, r, se)
+		return
+	}
+
+	job, err := api.srv.ImportDeck(r.Context(), service.ImportDeckRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		Deck:   d,
+		Mapping: service.FieldMapping{
+			Lemma:      q.Get("lemma_field"),
+			Definition: q.Get("definition_field"),
+			Class:      q.Get("class_field"),
+		},
+		Lang:        model.Lang(q.Get("lang")),
+		Class:       model.WordClass(q.Get("class")),
+		Tags:        q["tags"],
+		WithReviews: withReviews,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusAccepted, newImportJobResponse(job))
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+// spoolBody copies the body of a request to a temporary file, since imports outlive their request. The file is
+// removed right away, so that it is gone once it is closed even when the service stops before the import ends.
+func spoolBody(body io.Reader) (*os.File, error) {
+	f, err := os.CreateTemp("", "deck-*")
+	if err != nil {
+		return nil, fmt.Errorf("create deck file: %w", err)
+	}
+	_ = os.Remove(f.Name())
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("save deck: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("rewind deck: %w", err)
+	}
+
+	return f, nil
+}
+
+func (api *API) handleGetImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := idFromRequest(r, "job_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	job, err := api.srv.GetImportJob(r.Context(), middleware.UserIDFromContext(r.Context()), jobID)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, newImportJobResponse(job))
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
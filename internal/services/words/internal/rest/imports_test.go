@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/deck"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSTImportDeck(t *testing.T) {
+	var (
+		imported service.ImportDeckRequest
+		cards    []deck.Card
+	)
+	api := NewAPI(
+		&mockWordsService{
+			ImportDeckFunc: func(ctx context.Context, r service.ImportDeckRequest) (model.ImportJob, error) {
+				imported = r
+				defer r.Deck.Close()
+				for {
+					c, err := r.Deck.Next()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					require.NoError(t, err)
+					cards = append(cards, c)
+				}
+				return model.ImportJob{ID: 7, Status: model.ImportRunning, Total: r.Deck.Len()}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	req := httptest.NewRequest("POST", "/imports/decks?lang=en&class=noun&lemma_field=Term&definition_field=Definition"+
+		"&tags=quizlet&tags=fruits&with_reviews=true&header=true&separator=,", strings.NewReader("Term,Definition\napple,a round fruit\n"))
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"id":7,"status":"running","total":1,"cards":0,"created":0,"skipped":0,"failed":0,"errors":[],"created_at":"0001-01-01T00:00:00Z"}`, rec.Body.String())
+	assert.Equal(t, service.FieldMapping{Lemma: "Term", Definition: "Definition"}, imported.Mapping)
+	assert.Equal(t, model.Lang("en"), imported.Lang)
+	assert.Equal(t, model.Noun, imported.Class)
+	assert.Equal(t, []string{"quizlet", "fruits"}, imported.Tags)
+	assert.True(t, imported.WithReviews)
+	assert.Equal(t, []deck.Card{{Index: 1, Fields: map[string]string{"Term": "apple", "Definition": "a round fruit"}}}, cards)
+}
+
+func TestPOSTImportDeck_Errors(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ImportDeckFunc: func(ctx context.Context, r service.ImportDeckRequest) (model.ImportJob, error) {
+				r.Deck.Close()
+				return model.ImportJob{}, serr.NewServiceError(nil, http.StatusBadRequest, "invalid language")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	for path, body := range map[string]string{
+		"/imports/decks":                   "apple\ta round fruit\n",
+		"/imports/decks?format=apkg":       "not a zip",
+		"/imports/decks?format=tsv":        "\n",
+		"/imports/decks?format=tsv&lang=":  "apple\ta round fruit\n",
+		"/imports/decks?with_reviews=perh": "apple\ta round fruit\n",
+	} {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, path)
+	}
+}
+
+func TestGETImportJob(t *testing.T) {
+	finished := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	api := NewAPI(
+		&mockWordsService{
+			GetImportJobFunc: func(ctx context.Context, userID string, jobID int64) (model.ImportJob, error) {
+				if jobID != 7 {
+					return model.ImportJob{}, serr.NewServiceError(nil, http.StatusNotFound, "import job not found")
+				}
+				return model.ImportJob{
+					ID:         7,
+					Status:     model.ImportDone,
+					Total:      3,
+					Cards:      3,
+					Created:    1,
+					Skipped:    1,
+					Failed:     1,
+					Errors:     []model.ImportJobError{{Index: 2, Msg: "lemma is required"}},
+					Model:      model.Model{CreateAt: finished.Add(-time.Minute)},
+					FinishedAt: &finished,
+				}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/imports/7", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"id": 7,
+		"status": "done",
+		"total": 3,
+		"cards": 3,
+		"created": 1,
+		"skipped": 1,
+		"failed": 1,
+		"errors": [{"index": 2, "error": "lemma is required"}],
+		"created_at": "2030-01-02T03:03:05Z",
+		"finished_at": "2030-01-02T03:04:05Z"
+	}`, rec.Body.String())
+
+	rec = test.SendRequest(t, api, "GET", "/imports/8", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = test.SendRequest(t, api, "GET", "/imports/invalid", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
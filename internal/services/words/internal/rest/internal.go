@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+type userDataService interface {
+	ExportUserData(ctx context.Context, userID string) (service.UserData, error)
+	DeleteUserData(ctx context.Context, userID string) error
+}
+
+// InternalAPI serves the endpoints other services call on behalf of a user,
+// such as the account export and deletion driven by the auth service
+type InternalAPI struct {
+	srv userDataService
+	mux http.ServeMux
+}
+
+func NewInternalAPI(srv userDataService) *InternalAPI {
+	api := &InternalAPI{
+		srv: srv,
+		mux: *http.NewServeMux(),
+	}
+
+	api.mount()
+	return api
+}
+
+func (api *InternalAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mux.ServeHTTP(w, r)
+}
+
+func (api *InternalAPI) mount() {
+	api.mux.HandleFunc("GET /users/{user_id}/export", api.handleExportUserData)
+	api.mux.HandleFunc("DELETE /users/{user_id}", api.handleDeleteUserData)
+}
+
+type userDataResponse struct {
+	UserID     string               `json:"user_id"`
+	Picks      []userPickResponse   `json:"picks"`
+	Notes      []exportNoteResponse `json:"notes"`
+	Votes      []noteVoteResponse   `json:"votes"`
+	TagFilters []tagFilterResponse  `json:"tag_filters"`
+	ImportJobs []importJobResponse  `json:"import_jobs"`
+}
+
+type exportNoteResponse struct {
+	noteResponse
+	Revisions []noteRevisionResponse `json:"revisions"`
+}
+
+func newExportNoteResponse(n service.Note) exportNoteResponse {
+	return exportNoteResponse{
+		noteResponse: newNoteResponse(n),
+		Revisions:    append([]noteRevisionResponse{}, fn.Map(n.Revisions, newNoteRevisionResponse)...),
+	}
+}
+
+type noteVoteResponse struct {
+	NoteID    int64     `json:"note_id"`
+	DefID     int64     `json:"def_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newNoteVoteResponse(v service.NoteVote) noteVoteResponse {
+	return noteVoteResponse{NoteID: v.NoteID, DefID: v.DefID, CreatedAt: v.CreatedAt}
+}
+
+func (api *InternalAPI) handleExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	if userID == "" {
+		httpx.HandleErr(w, r, serr.NewServiceError(nil, http.StatusBadRequest, "invalid user_id parameter"))
+		return
+	}
+
+	data, err := api.srv.ExportUserData(r.Context(), userID)
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	picks := fn.Map(data.Picks, newUserPickResponse)
+	if picks == nil {
+		picks = []userPickResponse{}
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, userDataResponse{
+		UserID:     data.UserID,
+		Picks:      picks,
+		Notes:      append([]exportNoteResponse{}, fn.Map(data.Notes, newExportNoteResponse)...),
+		Votes:      append([]noteVoteResponse{}, fn.Map(data.Votes, newNoteVoteResponse)...),
+		TagFilters: append([]tagFilterResponse{}, fn.Map(data.TagFilters, newTagFilterResponse)...),
+		ImportJobs: append([]importJobResponse{}, fn.Map(data.ImportJobs, newImportJobResponse)...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func (api *InternalAPI) handleDeleteUserData(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	if userID == "" {
+		httpx.HandleErr(w, r, serr.NewServiceError(nil, http.StatusBadRequest, "invalid user_id parameter"))
+		return
+	}
+
+	if err := api.srv.DeleteUserData(r.Context(), userID); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
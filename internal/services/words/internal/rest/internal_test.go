@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUserDataService struct {
+	ExportUserDataFunc func(ctx context.Context, userID string) (service.UserData, error)
+	DeleteUserDataFunc func(ctx context.Context, userID string) error
+}
+
+func (m *mockUserDataService) ExportUserData(ctx context.Context, userID string) (service.UserData, error) {
+	return m.ExportUserDataFunc(ctx, userID)
+}
+
+func (m *mockUserDataService) DeleteUserData(ctx context.Context, userID string) error {
+	return m.DeleteUserDataFunc(ctx, userID)
+}
+
+func TestGETUserExport(t *testing.T) {
+	api := NewInternalAPI(&mockUserDataService{
+		ExportUserDataFunc: func(ctx context.Context, userID string) (service.UserData, error) {
+			assert.Equal(t, "user-123", userID)
+			return service.UserData{
+				UserID: userID,
+				Picks: []service.UserPick{{
+					ID:     1,
+					UserID: userID,
+					Word:   "apple",
+					Lang:   model.Lang("en"),
+					Class:  model.Noun,
+					Def:    "A crunchy fruit.",
+					Tags:   []string{"fruit"},
+					// personalized by the user
+					OriginalDef:  "A round fruit.",
+					ImageURL:     "https://example.com/green-apple.png",
+					Personalized: true,
+					Contexts: []service.PickContext{{
+						ID:          11,
+						Sentence:    "She ate an apple.",
+						SourceTitle: "Stories",
+						SourceURL:   "https://example.com/stories",
+					}},
+				}},
+				Notes: []service.Note{{
+					ID:        7,
+					PickID:    1,
+					Body:      "An apple a day",
+					Revisions: []service.NoteRevision{{Body: "An apple a day"}, {Body: "An apple"}},
+				}},
+				Votes:      []service.NoteVote{{NoteID: 9, DefID: 4}},
+				TagFilters: []service.TagFilter{{Name: "fruits", Expr: "fruit AND NOT exotic"}},
+				ImportJobs: []model.ImportJob{{
+					ID: 4, Status: model.ImportDone, Total: 2, Cards: 2, Created: 1, Failed: 1,
+					Errors: []model.ImportJobError{{Index: 1, Msg: "lemma is required"}},
+				}},
+			}, nil
+		},
+	})
+
+	rec := test.SendRequest(t, api, "GET", "/users/user-123/export", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	resp := test.ParseResponse[userDataResponse](t, rec)
+	assert.Equal(t, "user-123", resp.UserID)
+	require.Len(t, resp.Picks, 1)
+	assert.Equal(t, "apple", resp.Picks[0].Word)
+	assert.Equal(t, []string{"fruit"}, resp.Picks[0].Tags)
+	assert.Equal(t, "A crunchy fruit.", resp.Picks[0].Def)
+	assert.Equal(t, "A round fruit.", resp.Picks[0].OriginalDef)
+	assert.Equal(t, "https://example.com/green-apple.png", resp.Picks[0].ImageURL)
+	assert.True(t, resp.Picks[0].Personalized)
+	assert.Equal(t, []pickContextResponse{{
+		ID:          11,
+		Sentence:    "She ate an apple.",
+		SourceTitle: "Stories",
+		SourceURL:   "https://example.com/stories",
+	}}, resp.Picks[0].Contexts)
+	require.Len(t, resp.Notes, 1)
+	assert.Equal(t, "An apple a day", resp.Notes[0].Body)
+	assert.Equal(t, []noteRevisionResponse{{Body: "An apple a day"}, {Body: "An apple"}}, resp.Notes[0].Revisions)
+	assert.Equal(t, []noteVoteResponse{{NoteID: 9, DefID: 4}}, resp.Votes)
+	assert.Equal(t, []tagFilterResponse{{Name: "fruits", Expr: "fruit AND NOT exotic"}}, resp.TagFilters)
+	require.Len(t, resp.ImportJobs, 1)
+	assert.Equal(t, int64(4), resp.ImportJobs[0].ID)
+	assert.Equal(t, model.ImportDone, resp.ImportJobs[0].Status)
+	assert.Equal(t, []importJobErrorResponse{{Index: 1, Error: "lemma is required"}}, resp.ImportJobs[0].Errors)
+}
+
+func TestDELETEUserData(t *testing.T) {
+	var deleted []string
+	api := NewInternalAPI(&mockUserDataService{
+		DeleteUserDataFunc: func(ctx context.Context, userID string) error {
+			deleted = append(deleted, userID)
+			return nil
+		},
+	})
+
+	rec := test.SendRequest(t, api, "DELETE", "/users/user-123", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"user-123"}, deleted)
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+type dictionaryArticleResponse struct {
+	Dict     string `json:"dict"`
+	Index    int    `json:"index"`
+	Headword string `json:"headword"`
+	Text     string `json:"text"`
+}
+
+type lookupResponse struct {
+	Articles []dictionaryArticleResponse `json:"articles"`
+}
+
+// handleLookup looks the word query parameter up in the offline dictionaries, the lang query parameter
+// limits the lookup to the dictionaries of a language
+func (api *API) handleLookup(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	articles, err := api.srv.Lookup(r.Context(), service.LookupRequest{
+		Word: q.Get("word"),
+		Lang: model.Lang(q.Get("lang")),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, lookupResponse{
+		Articles: append([]dictionaryArticleResponse{}, fn.Map(articles, func(a service.DictionaryArticle) dictionaryArticleResponse {
+			return dictionaryArticleResponse{Dict: a.Dict, Index: a.Index, Headword: a.Headword, Text: a.Text}
+		})...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+// createDefinitionFromArticleRequest picks an article by the dictionary and the index the lookup of the
+// lemma of the word returned it with
+type createDefinitionFromArticleRequest struct {
+	WordID int64  `json:"word_id"`
+	Dict   string `json:"dict"`
+	Index  int    `json:"index"`
+}
+
+func (api *API) handleCreateDefinitionFromArticle(w http.ResponseWriter, r *http.Request) {
+	var req createDefinitionFromArticleRequest
+	err := httpx.ReadJSON(r, &req)
+	if err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	defID, err := api.srv.CreateDefinitionFromArticle(r.Context(), service.CreateDefinitionFromArticleRequest{
+		WordID: req.WordID,
+		Dict:   req.Dict,
+		Index:  req.Index,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusCreated, createDefinitionResponse{ID: defID})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGETLookup(t *testing.T) {
+	var looked service.LookupRequest
+	api := NewAPI(
+		&mockWordsService{
+			LookupFunc: func(ctx context.Context, r service.LookupRequest) ([]service.DictionaryArticle, error) {
+				looked = r
+				return []service.DictionaryArticle{{Dict: "en-ru", Index: 1, Headword: "run", Text: "бежать"}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/lookup?word=run&lang=en", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.LookupRequest{Word: "run", Lang: "en"}, looked)
+	assert.JSONEq(t, `{"articles":[{"dict":"en-ru","index":1,"headword":"run","text":"бежать"}]}`, rec.Body.String())
+}
+
+func TestPUTDefinitionFromArticle(t *testing.T) {
+	var created service.CreateDefinitionFromArticleRequest
+	api := NewAPI(
+		&mockWordsService{
+			CreateDefinitionFromArticleFunc: func(ctx context.Context, r service.CreateDefinitionFromArticleRequest) (int64, error) {
+				created = r
+				if r.Dict != "en-ru" {
+					return 0, serr.NewServiceError(nil, http.StatusNotFound, "dictionary not found")
+				}
+				return 42, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/definitions/dictionary", createDefinitionFromArticleRequest{WordID: 7, Dict: "en-ru", Index: 1})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, service.CreateDefinitionFromArticleRequest{WordID: 7, Dict: "en-ru", Index: 1}, created)
+	assert.JSONEq(t, `{"id":42}`, rec.Body.String())
+
+	rec = test.SendRequest(t, api, "PUT", "/definitions/dictionary", createDefinitionFromArticleRequest{WordID: 7, Dict: "fr-ru"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = test.SendRequest(t, api, "PUT", "/definitions/dictionary", "invalid json")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
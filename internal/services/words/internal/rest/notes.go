@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+type noteResponse struct {
+	ID        int64     `json:"id"`
+	PickID    int64     `json:"pick_id"`
+	Body      string    `json:"body"`
+	Public    bool      `json:"public"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newNoteResponse(n service.Note) noteResponse {
+	return noteResponse{ID: n.ID, PickID: n.PickID, Body: n.Body, Public: n.Public, UpdatedAt: n.UpdatedAt}
+}
+
+type saveNoteRequest struct {
+	Body   string `json:"body"`
+	Public bool   `json:"public"`
+}
+
+func (api *API) handleSaveNote(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req saveNoteRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	note, err := api.srv.SaveNote(r.Context(), service.SaveNoteRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+		Body:   req.Body,
+		Public: req.Public,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newNoteResponse(note)); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func (api *API) handleGetNote(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	note, err := api.srv.GetNote(r.Context(), service.GetNoteRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	if err := httpx.WriteJSON(w, http.StatusOK, newNoteResponse(note)); err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func (api *API) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = api.srv.DeleteNote(r.Context(), service.GetNoteRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type noteRevisionResponse struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newNoteRevisionResponse(rev service.NoteRevision) noteRevisionResponse {
+	return noteRevisionResponse{Body: rev.Body, CreatedAt: rev.CreatedAt}
+}
+
+type listNoteRevisionsResponse struct {
+	Revisions []noteRevisionResponse `json:"revisions"`
+}
+
+func (api *API) handleListNoteRevisions(w http.ResponseWriter, r *http.Request) {
+	pickID, err := idFromRequest(r, "pick_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	revisions, err := api.srv.ListNoteRevisions(r.Context(), service.GetNoteRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		PickID: pickID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, listNoteRevisionsResponse{
+		Revisions: fn.Map(revisions, newNoteRevisionResponse),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func (api *API) handleVoteNote(w http.ResponseWriter, r *http.Request) {
+	noteID, err := idFromRequest(r, "note_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = api.srv.VoteNote(r.Context(), service.VoteNoteRequest{
+		UserID:   middleware.UserIDFromContext(r.Context()),
+		NoteID:   noteID,
+		Withdraw: r.Method == http.MethodDelete,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type mnemonicResponse struct {
+	ID    int64  `json:"id"`
+	Body  string `json:"body"`
+	Votes int    `json:"votes"`
+	Voted bool   `json:"voted"`
+}
+
+type listMnemonicsResponse struct {
+	Mnemonics []mnemonicResponse `json:"mnemonics"`
+}
+
+// handleListMnemonics lists the top public notes on the definition, the number of notes is set by the limit query parameter
+func (api *API) handleListMnemonics(w http.ResponseWriter, r *http.Request) {
+	defID, err := idFromRequest(r, "def_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	limit, err := intFromQuery(r.URL.Query(), "limit")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	mnemonics, err := api.srv.ListMnemonics(r.Context(), service.ListMnemonicsRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		DefID:  defID,
+		Limit:  limit,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, listMnemonicsResponse{
+		Mnemonics: append([]mnemonicResponse{}, fn.Map(mnemonics, func(m service.Mnemonic) mnemonicResponse {
+			return mnemonicResponse{ID: m.ID, Body: m.Body, Votes: m.Votes, Voted: m.Voted}
+		})...),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
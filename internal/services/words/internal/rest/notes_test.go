@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPUTNote(t *testing.T) {
+	var saved service.SaveNoteRequest
+	api := NewAPI(
+		&mockWordsService{
+			SaveNoteFunc: func(ctx context.Context, r service.SaveNoteRequest) (service.Note, error) {
+				saved = r
+				return service.Note{ID: 7, PickID: r.PickID, Body: r.Body, Public: r.Public, UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/picks/3/note", saveNoteRequest{Body: "an *apple* a day", Public: true})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.SaveNoteRequest{PickID: 3, Body: "an *apple* a day", Public: true}, saved)
+	assert.JSONEq(t, `{"id":7,"pick_id":3,"body":"an *apple* a day","public":true,"updated_at":"2024-01-01T00:00:00Z"}`, rec.Body.String())
+}
+
+func TestGETNote_NotFound(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			GetNoteFunc: func(ctx context.Context, r service.GetNoteRequest) (service.Note, error) {
+				return service.Note{}, serr.NewServiceError(nil, http.StatusNotFound, "note was not found")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/picks/3/note", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGETNoteRevisions(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ListNoteRevisionsFunc: func(ctx context.Context, r service.GetNoteRequest) ([]service.NoteRevision, error) {
+				assert.Equal(t, int64(3), r.PickID)
+				return []service.NoteRevision{{Body: "second", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/picks/3/note/revisions", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"revisions":[{"body":"second","created_at":"2024-01-02T00:00:00Z"}]}`, rec.Body.String())
+}
+
+func TestVoteNote(t *testing.T) {
+	var votes []service.VoteNoteRequest
+	api := NewAPI(
+		&mockWordsService{
+			VoteNoteFunc: func(ctx context.Context, r service.VoteNoteRequest) error {
+				votes = append(votes, r)
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PUT", "/notes/7/vote", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = test.SendRequest(t, api, "DELETE", "/notes/7/vote", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	assert.Equal(t, []service.VoteNoteRequest{{NoteID: 7}, {NoteID: 7, Withdraw: true}}, votes)
+}
+
+func TestGETMnemonics(t *testing.T) {
+	var req service.ListMnemonicsRequest
+	api := NewAPI(
+		&mockWordsService{
+			ListMnemonicsFunc: func(ctx context.Context, r service.ListMnemonicsRequest) ([]service.Mnemonic, error) {
+				req = r
+				return []service.Mnemonic{{ID: 7, Body: "An apple a day", Votes: 3}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/definitions/5/mnemonics?limit=20", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, service.ListMnemonicsRequest{DefID: 5, Limit: 20}, req)
+	assert.JSONEq(t, `{"mnemonics":[{"id":7,"body":"An apple a day","votes":3,"voted":false}]}`, rec.Body.String())
+
+	rec = test.SendRequest(t, api, "GET", "/definitions/5/mnemonics?limit=all", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
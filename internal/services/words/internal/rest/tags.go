@@ -0,0 +1,190 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/httpx"
+	"github.com/gamma-omg/lexi-go/internal/pkg/middleware"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+)
+
+type tagResponse struct {
+	ID       int64  `json:"id"`
+	ParentID int64  `json:"parent_id,omitempty"`
+	Tag      string `json:"tag"`
+	Picks    int    `json:"picks"`
+}
+
+type listTagsResponse struct {
+	Tags []tagResponse `json:"tags"`
+}
+
+func (api *API) handleListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := api.srv.ListTags(r.Context(), middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, listTagsResponse{
+		Tags: fn.Map(tags, func(t service.Tag) tagResponse {
+			return tagResponse{ID: t.ID, ParentID: t.ParentID, Tag: t.Text, Picks: t.Picks}
+		}),
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+type tagNodeResponse struct {
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	Picks    int               `json:"picks"`
+	Children []tagNodeResponse `json:"children"`
+}
+
+type tagTreeResponse struct {
+	Tags []tagNodeResponse `json:"tags"`
+}
+
+func (api *API) handleTagTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := api.srv.TagTree(r.Context(), middleware.UserIDFromContext(r.Context()))
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = httpx.WriteJSON(w, http.StatusOK, tagTreeResponse{Tags: newTagNodeResponses(tree)})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+}
+
+func newTagNodeResponses(nodes []service.TagNode) []tagNodeResponse {
+	res := make([]tagNodeResponse, 0, len(nodes))
+	for _, n := range nodes {
+		res = append(res, tagNodeResponse{
+			ID:       n.ID,
+			Name:     n.Name,
+			Path:     n.Path,
+			Picks:    n.Picks,
+			Children: newTagNodeResponses(n.Children),
+		})
+	}
+
+	return res
+}
+
+type renameTagRequest struct {
+	Name string `json:"name"`
+}
+
+func (api *API) handleRenameTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := idFromRequest(r, "tag_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req renameTagRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	err = api.srv.RenameTag(r.Context(), service.RenameTagRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		TagID:  tagID,
+		Name:   req.Name,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type moveTagRequest struct {
+	ParentID int64 `json:"parent_id"`
+}
+
+func (api *API) handleMoveTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := idFromRequest(r, "tag_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req moveTagRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	err = api.srv.MoveTag(r.Context(), service.MoveTagRequest{
+		UserID:   middleware.UserIDFromContext(r.Context()),
+		TagID:    tagID,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type mergeTagsRequest struct {
+	IntoID int64 `json:"into_id"`
+}
+
+func (api *API) handleMergeTags(w http.ResponseWriter, r *http.Request) {
+	tagID, err := idFromRequest(r, "tag_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	var req mergeTagsRequest
+	if err := httpx.ReadJSON(r, &req); err != nil {
+		httpx.HandleErr(w, r, serr.NewServiceError(err, http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	err = api.srv.MergeTags(r.Context(), service.MergeTagsRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		FromID: tagID,
+		IntoID: req.IntoID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *API) handleDeleteUserTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := idFromRequest(r, "tag_id")
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	err = api.srv.DeleteTag(r.Context(), service.DeleteTagRequest{
+		UserID: middleware.UserIDFromContext(r.Context()),
+		TagID:  tagID,
+	})
+	if err != nil {
+		httpx.HandleErr(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
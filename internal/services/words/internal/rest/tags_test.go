@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/pkg/test"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGETTags(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			ListTagsFunc: func(ctx context.Context, userID string) ([]service.Tag, error) {
+				return []service.Tag{{ID: 1, Text: "food", Picks: 3}, {ID: 2, ParentID: 1, Text: "food/fruit"}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/tags", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"tags":[{"id":1,"tag":"food","picks":3},{"id":2,"parent_id":1,"tag":"food/fruit","picks":0}]}`, rec.Body.String())
+}
+
+func TestPATCHTag(t *testing.T) {
+	var renamed service.RenameTagRequest
+	api := NewAPI(
+		&mockWordsService{
+			RenameTagFunc: func(ctx context.Context, r service.RenameTagRequest) error {
+				renamed = r
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PATCH", "/tags/7", renameTagRequest{Name: "meals"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, service.RenameTagRequest{TagID: 7, Name: "meals"}, renamed)
+}
+
+func TestPATCHTag_Conflict(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			RenameTagFunc: func(ctx context.Context, r service.RenameTagRequest) error {
+				return serr.NewServiceError(nil, http.StatusConflict, "tag already exists")
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "PATCH", "/tags/7", renameTagRequest{Name: "meals"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestGETTagTree(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{
+			TagTreeFunc: func(ctx context.Context, userID string) ([]service.TagNode, error) {
+				return []service.TagNode{{ID: 1, Name: "food", Path: "food", Picks: 3, Children: []service.TagNode{
+					{ID: 2, Name: "fruit", Path: "food/fruit", Picks: 1},
+				}}}, nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "GET", "/tags/tree", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"tags":[{"id":1,"name":"food","path":"food","picks":3,"children":[
+		{"id":2,"name":"fruit","path":"food/fruit","picks":1,"children":[]}
+	]}]}`, rec.Body.String())
+}
+
+func TestPOSTTagMove(t *testing.T) {
+	var moved service.MoveTagRequest
+	api := NewAPI(
+		&mockWordsService{
+			MoveTagFunc: func(ctx context.Context, r service.MoveTagRequest) error {
+				moved = r
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "POST", "/tags/7/move", moveTagRequest{ParentID: 3})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, service.MoveTagRequest{TagID: 7, ParentID: 3}, moved)
+}
+
+func TestPOSTTagMerge(t *testing.T) {
+	var merged service.MergeTagsRequest
+	api := NewAPI(
+		&mockWordsService{
+			MergeTagsFunc: func(ctx context.Context, r service.MergeTagsRequest) error {
+				merged = r
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "POST", "/tags/7/merge", mergeTagsRequest{IntoID: 9})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, service.MergeTagsRequest{FromID: 7, IntoID: 9}, merged)
+}
+
+func TestDELETEUserTag(t *testing.T) {
+	var deleted service.DeleteTagRequest
+	api := NewAPI(
+		&mockWordsService{
+			DeleteTagFunc: func(ctx context.Context, r service.DeleteTagRequest) error {
+				deleted = r
+				return nil
+			},
+		},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "DELETE", "/tags/7", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, service.DeleteTagRequest{TagID: 7}, deleted)
+}
+
+func TestDELETEUserTag_BadRequest(t *testing.T) {
+	api := NewAPI(
+		&mockWordsService{},
+		&mockImageStore{},
+	)
+
+	rec := test.SendRequest(t, api, "DELETE", "/tags/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/anki"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+const (
+	// ankiDeckName is the name of the deck picks are exported to
+	ankiDeckName = "Lexi"
+	// maxAnkiImageSize is the maximum size of an image added to an Anki deck in bytes, larger images are left out
+	maxAnkiImageSize = 10 << 20
+)
+
+// ankiImageExts are the extensions of the image files Anki shows, other images are saved as .jpg
+var ankiImageExts = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg"}
+
+type ExportAnkiRequest struct {
+	UserID string
+	// WithTags restricts the export to the picks with all of these tags
+	WithTags []string
+	// FetchImage downloads the image attached to a pick, picks whose image cannot be fetched are exported without it
+	FetchImage func(ctx context.Context, imageURL string) (io.ReadCloser, error)
+}
+
+// ExportAnkiReport counts the notes of an exported deck and the images that were left out of it
+type ExportAnkiReport struct {
+	Notes         int
+	Images        int
+	MissingImages int
+}
+
+// ExportAnki writes the picks of the user to w as an Anki package, an .apkg file with one note per pick. The front
+// of the card is the word, and the back its definition, its image and the context sentences of the pick with the
+// word in bold. The tags of the picks become Anki tags, and notes keep their identity across exports, so importing
+// a newer export updates the notes imported before.
+// Picks are read a page at a time and images are written as they are fetched, so decks of any size are streamed.
+// Nothing is written to w when the filters are invalid, and the error is then a ServiceError with status code 400.
+func (s *WordsService) ExportAnki(ctx context.Context, w io.Writer, r ExportAnkiRequest) (ExportAnkiReport, error) {
+	req, ok, err := s.picksFilter(ctx, GetUserPicksRequest{UserID: r.UserID, WithTags: r.WithTags})
+	if err != nil {
+		return ExportAnkiReport{}, err
+	}
+	req.PageSize = exportPageSize
+
+	var page store.GetUserPicksResponse
+	if ok {
+		page, err = s.store.GetUserPicks(ctx, req)
+		if err != nil {
+			return ExportAnkiReport{}, fmt.Errorf("get user picks: %w", err)
+		}
+	}
+
+	pkg, err := anki.NewPackage(w, ankiDeckName)
+	if err != nil {
+		return ExportAnkiReport{}, fmt.Errorf("create anki package: %w", err)
+	}
+	defer pkg.Discard()
+
+	var (
+		report ExportAnkiReport
+		media  = make(map[string]string)
+	)
+	for {
+		for _, p := range fn.Map(page.Picks, newUserPick) {
+			note := anki.Note{
+				GUID:       fmt.Sprintf("lexi-pick-%d", p.ID),
+				Word:       html.EscapeString(p.Word),
+				Definition: strings.ReplaceAll(html.EscapeString(p.Def), "\n", "<br>"),
+				Examples:   ankiExamples(p.Contexts),
+				Tags:       fn.Map(p.Tags, anki.Tag),
+			}
+
+			if p.ImageURL != "" {
+				name, ok := media[p.ImageURL]
+				if !ok {
+					name, err = s.addAnkiImage(ctx, pkg, r.FetchImage, p.ImageURL)
+					if err != nil {
+						return report, err
+					}
+					media[p.ImageURL] = name
+					if name != "" {
+						report.Images++
+					}
+				}
+				if name != "" {
+					note.Image = fmt.Sprintf(`<img src="%s">`, html.EscapeString(name))
+				} else {
+					report.MissingImages++
+				}
+			}
+
+			if err := pkg.AddNote(note); err != nil {
+				return report, fmt.Errorf("add note of pick %d: %w", p.ID, err)
+			}
+			report.Notes++
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		req.Cursor = *page.NextCursor
+		page, err = s.store.GetUserPicks(ctx, req)
+		if err != nil {
+			return report, fmt.Errorf("get user picks: %w", err)
+		}
+	}
+
+	if err := pkg.Close(); err != nil {
+		return report, fmt.Errorf("write anki package: %w", err)
+	}
+
+	return report, nil
+}
+
+// addAnkiImage adds the image to the package and returns the name of its media file, or an empty name when
+// the image cannot be fetched or is too large. It only fails when the package cannot be written.
+func (s *WordsService) addAnkiImage(
+	ctx context.Context,
+	pkg *anki.Package,
+	fetch func(ctx context.Context, imageURL string) (io.ReadCloser, error),
+	imageURL string,
+) (string, error) {
+	if fetch == nil {
+		return "", nil
+	}
+
+	img, err := fetch(ctx, imageURL)
+	if err != nil {
+		return "", nil
+	}
+	defer img.Close()
+
+	// the image is read whole before it is added, so that a failed download leaves no partial file in the package
+	data, err := io.ReadAll(io.LimitReader(img, maxAnkiImageSize+1))
+	if err != nil || len(data) > maxAnkiImageSize {
+		return "", nil
+	}
+
+	name := ankiMediaName(imageURL)
+	if err := pkg.AddMedia(name, bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("add image: %w", err)
+	}
+
+	return name, nil
+}
+
+// ankiMediaName names the media file of an image after the hash of its URL, keeping the extension of the image
+func ankiMediaName(imageURL string) string {
+	ext := ".jpg"
+	if u, err := url.Parse(imageURL); err == nil {
+		if e := strings.ToLower(path.Ext(u.Path)); slices.Contains(ankiImageExts, e) {
+			ext = e
+		}
+	}
+
+	sum := sha1.Sum([]byte(imageURL))
+	return "lexi-" + hex.EncodeToString(sum[:8]) + ext
+}
+
+// ankiExamples lists the context sentences of a pick one per line, with the word in bold where it was found
+func ankiExamples(contexts []PickContext) string {
+	lines := make([]string, 0, len(contexts))
+	for _, c := range contexts {
+		if c.Highlight == nil {
+			lines = append(lines, html.EscapeString(c.Sentence))
+			continue
+		}
+
+		runes := []rune(c.Sentence)
+		lines = append(lines, html.EscapeString(string(runes[:c.Highlight.Start]))+
+			"<b>"+html.EscapeString(string(runes[c.Highlight.Start:c.Highlight.End]))+"</b>"+
+			html.EscapeString(string(runes[c.Highlight.End:])))
+	}
+
+	return strings.Join(lines, "<br>")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAnki(t *testing.T) {
+	srv := NewWordsService(&mockStore{
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			if r.Cursor.LastPickID == 0 {
+				return store.GetUserPicksResponse{
+					Picks: []model.UserPick{{
+						ID:         1,
+						Word:       model.Word{Lemma: "apple", Lang: "en", Class: model.Noun},
+						Definition: model.Definition{Text: "A round <fruit>."},
+						ImageURL:   "https://images.example.com/apple.png",
+						Tags:       []model.Tag{{Text: "food/fruits"}},
+						Contexts:   []model.PickContext{{Sentence: "An apple a day."}},
+					}},
+					NextCursor: &store.GetUserPicksCursor{LastPickID: 1},
+				}, nil
+			}
+
+			return store.GetUserPicksResponse{
+				Picks: []model.UserPick{{
+					ID:         2,
+					Word:       model.Word{Lemma: "pear", Lang: "en", Class: model.Noun},
+					Definition: model.Definition{Text: "A sweet fruit."},
+					ImageURL:   "https://images.example.com/missing.jpg",
+				}},
+			}, nil
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	var out bytes.Buffer
+	report, err := srv.ExportAnki(context.Background(), &out, ExportAnkiRequest{
+		UserID: "user-123",
+		FetchImage: func(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+			if imageURL != "https://images.example.com/apple.png" {
+				return nil, errors.New("not found")
+			}
+			return io.NopCloser(strings.NewReader("png data")), nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ExportAnkiReport{Notes: 2, Images: 1, MissingImages: 1}, report)
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.NoError(t, err)
+
+	f, err := zr.Open("collection.anki2")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var guid, tags, flds string
+	require.NoError(t, db.QueryRow("SELECT guid, tags, flds FROM notes ORDER BY id LIMIT 1").Scan(&guid, &tags, &flds))
+	assert.Equal(t, "lexi-pick-1", guid)
+	assert.Equal(t, " food::fruits ", tags)
+	assert.Equal(t, []string{
+		"apple",
+		"A round &lt;fruit&gt;.",
+		`<img src="` + ankiMediaName("https://images.example.com/apple.png") + `">`,
+		"An <b>apple</b> a day.",
+	}, strings.Split(flds, "\x1f"))
+	assert.True(t, strings.HasSuffix(ankiMediaName("https://images.example.com/apple.png"), ".png"))
+}
+
+func TestExportAnki_Errors(t *testing.T) {
+	srv := NewWordsService(&mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return nil, errors.New("connection reset")
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	var out bytes.Buffer
+	_, err := srv.ExportAnki(context.Background(), &out, ExportAnkiRequest{UserID: "user-123", WithTags: []string{"fruits"}})
+	require.Error(t, err)
+	assert.Zero(t, out.Len())
+}
+
+func TestExportAnki_UnknownTag(t *testing.T) {
+	srv := NewWordsService(&mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	var out bytes.Buffer
+	report, err := srv.ExportAnki(context.Background(), &out, ExportAnkiRequest{UserID: "user-123", WithTags: []string{"fruits"}})
+	require.NoError(t, err)
+	assert.Zero(t, report.Notes)
+
+	_, err = zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	assert.NoError(t, err)
+}
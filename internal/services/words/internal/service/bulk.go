@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+// maxBulkPicks is the maximum number of picks a bulk operation creates or changes at once
+const maxBulkPicks = 500
+
+// errDryRun rolls back the transaction of a bulk operation that is only simulated
+var errDryRun = errors.New("dry run")
+
+// BulkAction is the operation a BulkUpdatePicksRequest applies to every pick it selects
+type BulkAction string
+
+const (
+	BulkAddTags    BulkAction = "add_tags"
+	BulkRemoveTags BulkAction = "remove_tags"
+	BulkSetStatus  BulkAction = "set_status"
+	BulkDelete     BulkAction = "delete"
+)
+
+// BulkItemResult is the outcome of a bulk operation for one pick
+type BulkItemResult struct {
+	// Index is the position of the item in the request, or of the pick among the selected ones
+	Index  int
+	PickID int64
+	// Err tells why the item failed, it is nil for items that succeeded
+	Err *serr.ServiceError
+}
+
+// BulkResult reports the outcome of a bulk operation item by item. Failed items change nothing,
+// the other items are applied together unless the operation is a dry run.
+type BulkResult struct {
+	Items     []BulkItemResult
+	Succeeded int
+	Failed    int
+	DryRun    bool
+}
+
+// add records the outcome of an item. Service errors fail the item only,
+// other errors are returned to abort the whole operation.
+func (r *BulkResult) add(item BulkItemResult, err error) error {
+	if err != nil {
+		var se *serr.ServiceError
+		if !errors.As(err, &se) {
+			return err
+		}
+
+		item.Err = se
+		r.Failed++
+	} else {
+		r.Succeeded++
+	}
+
+	r.Items = append(r.Items, item)
+	return nil
+}
+
+// runBulk runs fn in one transaction that is rolled back for dry runs, every item of fn runs in a nested
+// transaction of its own. Tags created by rolled back items are gone again, so the given tags of the user
+// are dropped from the cache afterwards.
+func (s *WordsService) runBulk(ctx context.Context, userID string, tags []string, dryRun bool, fn func(tx store.DataStore) error) error {
+	defer s.tags.Forget(userID, normalizeTags(tags)...)
+
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	return nil
+}
+
+func normalizeTags(tags []string) []string {
+	return fn.Map(tags, model.NormalizeTagPath)
+}
+
+type BulkCreatePicksRequest struct {
+	UserID string
+	// Picks are created for the user, their UserID is ignored
+	Picks  []PickWoardRequest
+	DryRun bool
+}
+
+// BulkCreatePicks picks many word definitions at once, see PickWord. Items that fail, for example because
+// the user already picked the definition, are reported in the result. It returns a ServiceError with
+// status code 400 when there are no picks or more than maxBulkPicks.
+func (s *WordsService) BulkCreatePicks(ctx context.Context, r BulkCreatePicksRequest) (BulkResult, error) {
+	if len(r.Picks) == 0 || len(r.Picks) > maxBulkPicks {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "between 1 and %d picks can be created at once", maxBulkPicks)
+		se.Env["picks"] = fmt.Sprintf("%d", len(r.Picks))
+		return BulkResult{}, se
+	}
+
+	var tags []string
+	for _, p := range r.Picks {
+		tags = append(tags, p.Tags...)
+	}
+
+	result := BulkResult{DryRun: r.DryRun}
+	err := s.runBulk(ctx, r.UserID, tags, r.DryRun, func(tx store.DataStore) error {
+		for i, p := range r.Picks {
+			p.UserID = r.UserID
+			item := BulkItemResult{Index: i}
+
+			p, err := cleanPickWordRequest(p)
+			if err == nil {
+				err = tx.WithTx(ctx, func(tx store.DataStore) error {
+					var err error
+					item.PickID, err = s.pickWord(ctx, tx, p)
+					return err
+				})
+			}
+
+			if err := result.add(item, err); err != nil {
+				return fmt.Errorf("create pick %d: %w", i, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return BulkResult{}, fmt.Errorf("bulk create picks: %w", err)
+	}
+
+	return result, nil
+}
+
+type BulkUpdatePicksRequest struct {
+	UserID string
+	// PickIDs selects the picks by ID, picks the user does not have are reported as not found
+	PickIDs []int64
+	// Filter selects the picks matching it when there are no PickIDs, its sorting and pagination are ignored
+	Filter *GetUserPicksRequest
+	Action BulkAction
+	// Tags are added or removed by BulkAddTags and BulkRemoveTags
+	Tags []string
+	// Status is the learning state BulkSetStatus moves the picks to
+	Status model.PickStatus
+	DryRun bool
+}
+
+// BulkUpdatePicks applies an action to many picks of the user at once, selected either by ID or by a filter.
+// Items that fail, for example because a pick cannot move to the requested status, are reported in the result.
+// It returns a ServiceError with status code 400 for invalid actions and selections, including selections
+// of more than maxBulkPicks picks.
+func (s *WordsService) BulkUpdatePicks(ctx context.Context, r BulkUpdatePicksRequest) (BulkResult, error) {
+	if err := checkBulkUpdate(r); err != nil {
+		return BulkResult{}, err
+	}
+
+	result := BulkResult{DryRun: r.DryRun}
+	err := s.runBulk(ctx, r.UserID, r.Tags, r.DryRun, func(tx store.DataStore) error {
+		pickIDs, owned, err := s.selectPicks(ctx, tx, r)
+		if err != nil {
+			return err
+		}
+
+		tagIDs, err := s.bulkTagIDs(ctx, tx, r)
+		if err != nil {
+			return err
+		}
+
+		for i, pickID := range pickIDs {
+			item := BulkItemResult{Index: i, PickID: pickID}
+
+			var err error
+			if owned[pickID] {
+				err = tx.WithTx(ctx, func(tx store.DataStore) error {
+					return s.applyBulkAction(ctx, tx, r, pickID, tagIDs)
+				})
+			} else {
+				err = pickNotFound(store.ErrNotFound, pickID)
+			}
+
+			if err := result.add(item, err); err != nil {
+				return fmt.Errorf("%s pick %d: %w", r.Action, pickID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return BulkResult{}, fmt.Errorf("bulk update picks: %w", err)
+	}
+
+	return result, nil
+}
+
+func checkBulkUpdate(r BulkUpdatePicksRequest) error {
+	switch r.Action {
+	case BulkAddTags, BulkRemoveTags:
+		if !slices.ContainsFunc(normalizeTags(r.Tags), func(tag string) bool { return tag != "" }) {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "tags are required")
+			se.Env["action"] = string(r.Action)
+			return se
+		}
+	case BulkSetStatus:
+		if !r.Status.Valid() {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown pick status")
+			se.Env["status"] = string(r.Status)
+			return se
+		}
+	case BulkDelete:
+	default:
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown bulk action")
+		se.Env["action"] = string(r.Action)
+		return se
+	}
+
+	if (len(r.PickIDs) == 0) == (r.Filter == nil) {
+		return serr.NewServiceError(nil, http.StatusBadRequest, "either pick IDs or a filter is required")
+	}
+	if len(r.PickIDs) > maxBulkPicks {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "at most %d picks can be changed at once", maxBulkPicks)
+		se.Env["picks"] = fmt.Sprintf("%d", len(r.PickIDs))
+		return se
+	}
+
+	return nil
+}
+
+// selectPicks returns the IDs of the picks the request selects without duplicates, together with
+// the IDs among them that belong to the user. Filters only select picks of the user. The picks
+// of the user are locked until the end of the transaction.
+func (s *WordsService) selectPicks(ctx context.Context, tx store.DataStore, r BulkUpdatePicksRequest) ([]int64, map[int64]bool, error) {
+	if len(r.PickIDs) > 0 {
+		var pickIDs []int64
+		seen := make(map[int64]bool)
+		for _, id := range r.PickIDs {
+			if !seen[id] {
+				seen[id] = true
+				pickIDs = append(pickIDs, id)
+			}
+		}
+
+		owned, err := tx.ListUserPickIDs(ctx, store.GetUserPicksRequest{
+			UserID:    r.UserID,
+			PickIDs:   pickIDs,
+			PageSize:  len(pickIDs),
+			ForUpdate: true,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("list user pick ids: %w", err)
+		}
+
+		return pickIDs, idSet(owned), nil
+	}
+
+	filter := *r.Filter
+	filter.UserID = r.UserID
+	req, ok, err := s.picksFilter(ctx, filter)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+
+	req.PageSize = maxBulkPicks + 1
+	req.ForUpdate = true
+	pickIDs, err := tx.ListUserPickIDs(ctx, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list user pick ids: %w", err)
+	}
+	if len(pickIDs) > maxBulkPicks {
+		return nil, nil, serr.NewServiceError(nil, http.StatusBadRequest, "filter matches more than %d picks", maxBulkPicks)
+	}
+
+	return pickIDs, idSet(pickIDs), nil
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// bulkTagIDs resolves the tags of the request in the transaction. Tags to add are created when they
+// do not exist yet, tags to remove that do not exist are left out.
+func (s *WordsService) bulkTagIDs(ctx context.Context, tx store.DataStore, r BulkUpdatePicksRequest) ([]int64, error) {
+	switch r.Action {
+	case BulkAddTags:
+		tags, err := s.tags.GetOrCreateTags(ctx, tx, r.UserID, r.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("get or create tags: %w", err)
+		}
+		return tags.IDs(), nil
+	case BulkRemoveTags:
+		tags, _, err := s.tags.GetTags(ctx, tx, r.UserID, r.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("get tags: %w", err)
+		}
+		return tags.IDs(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// applyBulkAction applies the action of the request to a pick of the user in the transaction
+func (s *WordsService) applyBulkAction(ctx context.Context, tx store.DataStore, r BulkUpdatePicksRequest, pickID int64, tagIDs []int64) error {
+	var err error
+	switch r.Action {
+	case BulkAddTags:
+		err = tx.AddTags(ctx, store.AddTagsRequest{UserID: r.UserID, PickID: pickID, TagIDs: tagIDs, IgnoreExisting: true})
+	case BulkRemoveTags:
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		err = tx.RemoveTags(ctx, store.RemoveTagsRequest{UserID: r.UserID, PickID: pickID, TagIDs: tagIDs})
+	case BulkSetStatus:
+		return s.updatePick(ctx, tx, UpdatePickRequest{UserID: r.UserID, PickID: pickID, Status: &r.Status})
+	case BulkDelete:
+		err = tx.DeleteUserPick(ctx, store.DeleteUserPickRequest{UserID: r.UserID, PickID: pickID})
+	}
+
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return pickNotFound(err, pickID)
+		}
+
+		return fmt.Errorf("%s: %w", r.Action, err)
+	}
+
+	return nil
+}
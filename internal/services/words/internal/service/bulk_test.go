@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkCreatePicks(t *testing.T) {
+	var created []store.CreateUserPickRequest
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		CreateTagsFunc: func(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{"fruit": 1}, nil
+		},
+		AddTagsFunc: func(ctx context.Context, r store.AddTagsRequest) error {
+			return nil
+		},
+		CreateUserPickFunc: func(ctx context.Context, r store.CreateUserPickRequest) (int64, error) {
+			switch r.DefID {
+			case 2:
+				return 0, store.ErrExists
+			case 3:
+				return 0, store.ErrNotFound
+			}
+			created = append(created, r)
+			return 10 + r.DefID, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	result, err := srv.BulkCreatePicks(context.Background(), BulkCreatePicksRequest{
+		UserID: "user-123",
+		Picks: []PickWoardRequest{
+			{UserID: "user-456", DefID: 1, Tags: []string{"fruit"}},
+			{DefID: 2},
+			{DefID: 3},
+			{DefID: 4, Context: PickContextRequest{SourceURL: "https://example.com"}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []store.CreateUserPickRequest{{UserID: "user-123", DefID: 1}}, created)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 3, result.Failed)
+	assert.False(t, result.DryRun)
+	require.Len(t, result.Items, 4)
+	assert.Equal(t, int64(11), result.Items[0].PickID)
+	assert.Nil(t, result.Items[0].Err)
+	for i, status := range []int{http.StatusConflict, http.StatusNotFound, http.StatusBadRequest} {
+		assert.Equal(t, i+1, result.Items[i+1].Index)
+		require.NotNil(t, result.Items[i+1].Err)
+		assert.Equal(t, status, result.Items[i+1].Err.StatusCode)
+	}
+}
+
+func TestBulkCreatePicks_DryRun(t *testing.T) {
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		AddTagsFunc: func(ctx context.Context, r store.AddTagsRequest) error {
+			return nil
+		},
+		CreateUserPickFunc: func(ctx context.Context, r store.CreateUserPickRequest) (int64, error) {
+			return 1, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	result, err := srv.BulkCreatePicks(context.Background(), BulkCreatePicksRequest{
+		UserID: "user-123",
+		Picks:  []PickWoardRequest{{DefID: 1}},
+		DryRun: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Succeeded)
+}
+
+func TestBulkCreatePicks_Errors(t *testing.T) {
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		CreateUserPickFunc: func(ctx context.Context, r store.CreateUserPickRequest) (int64, error) {
+			return 0, errors.New("connection reset")
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.BulkCreatePicks(context.Background(), BulkCreatePicksRequest{UserID: "user-123"})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	_, err = srv.BulkCreatePicks(context.Background(), BulkCreatePicksRequest{
+		UserID: "user-123",
+		Picks:  make([]PickWoardRequest, maxBulkPicks+1),
+	})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	// errors other than service errors abort the whole batch
+	_, err = srv.BulkCreatePicks(context.Background(), BulkCreatePicksRequest{
+		UserID: "user-123",
+		Picks:  []PickWoardRequest{{DefID: 1}},
+	})
+	require.ErrorContains(t, err, "connection reset")
+}
+
+func TestBulkUpdatePicks_SetStatus(t *testing.T) {
+	statuses := map[int64]model.PickStatus{1: model.StatusLearning, 2: model.StatusArchived}
+	var updated []store.UpdatePickRequest
+	st := &mockStore{
+		ListUserPickIDsFunc: func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			assert.Equal(t, []int64{1, 2, 3}, r.PickIDs)
+			assert.True(t, r.ForUpdate)
+			return []int64{1, 2}, nil
+		},
+		GetPickFunc: func(ctx context.Context, r store.GetPickRequest) (model.UserPick, error) {
+			return model.UserPick{ID: r.PickID, Status: statuses[r.PickID]}, nil
+		},
+		UpdatePickFunc: func(ctx context.Context, r store.UpdatePickRequest) error {
+			updated = append(updated, r)
+			return nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	result, err := srv.BulkUpdatePicks(context.Background(), BulkUpdatePicksRequest{
+		UserID:  "user-123",
+		PickIDs: []int64{1, 2, 3, 1},
+		Action:  BulkSetStatus,
+		Status:  model.StatusSuspended,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []store.UpdatePickRequest{{UserID: "user-123", PickID: 1, Status: model.StatusSuspended}}, updated)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	require.Len(t, result.Items, 3)
+	assert.Nil(t, result.Items[0].Err)
+	assert.Equal(t, http.StatusConflict, result.Items[1].Err.StatusCode)
+	assert.Equal(t, int64(3), result.Items[2].PickID)
+	assert.Equal(t, http.StatusNotFound, result.Items[2].Err.StatusCode)
+}
+
+func TestBulkUpdatePicks_Filter(t *testing.T) {
+	var tagged []store.AddTagsRequest
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{"fruit": 7}, nil
+		},
+		ListUserPickIDsFunc: func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			assert.Equal(t, []model.Lang{"en"}, r.Langs)
+			assert.Equal(t, maxBulkPicks+1, r.PageSize)
+			assert.True(t, r.ForUpdate)
+			return []int64{4, 5}, nil
+		},
+		AddTagsFunc: func(ctx context.Context, r store.AddTagsRequest) error {
+			tagged = append(tagged, r)
+			return nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	result, err := srv.BulkUpdatePicks(context.Background(), BulkUpdatePicksRequest{
+		UserID: "user-123",
+		Filter: &GetUserPicksRequest{UserID: "user-456", Langs: []model.Lang{"en"}},
+		Action: BulkAddTags,
+		Tags:   []string{" fruit "},
+		DryRun: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []store.AddTagsRequest{
+		{UserID: "user-123", PickID: 4, TagIDs: []int64{7}, IgnoreExisting: true},
+		{UserID: "user-123", PickID: 5, TagIDs: []int64{7}, IgnoreExisting: true},
+	}, tagged)
+	assert.Equal(t, BulkResult{
+		Items:     []BulkItemResult{{Index: 0, PickID: 4}, {Index: 1, PickID: 5}},
+		Succeeded: 2,
+		DryRun:    true,
+	}, result)
+}
+
+func TestBulkUpdatePicks_Delete(t *testing.T) {
+	var deleted []store.DeleteUserPickRequest
+	tx := &mockStore{
+		ListUserPickIDsFunc: func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+			assert.True(t, r.ForUpdate)
+			return []int64{1}, nil
+		},
+		DeleteUserPickFunc: func(ctx context.Context, r store.DeleteUserPickRequest) error {
+			deleted = append(deleted, r)
+			return nil
+		},
+	}
+	st := &txStore{
+		mockStore: &mockStore{
+			ListUserPickIDsFunc: func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+				t.Fatal("picks must be selected in the transaction")
+				return nil, nil
+			},
+		},
+		tx: tx,
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	result, err := srv.BulkUpdatePicks(context.Background(), BulkUpdatePicksRequest{
+		UserID:  "user-123",
+		PickIDs: []int64{1, 2},
+		Action:  BulkDelete,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []store.DeleteUserPickRequest{{UserID: "user-123", PickID: 1}}, deleted)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, http.StatusNotFound, result.Items[1].Err.StatusCode)
+}
+
+func TestBulkUpdatePicks_Errors(t *testing.T) {
+	st := &mockStore{
+		ListUserPickIDsFunc: func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+			return make([]int64, r.PageSize), nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	for name, r := range map[string]BulkUpdatePicksRequest{
+		"unknown action":  {PickIDs: []int64{1}, Action: "rename"},
+		"no tags":         {PickIDs: []int64{1}, Action: BulkAddTags, Tags: []string{" / "}},
+		"unknown status":  {PickIDs: []int64{1}, Action: BulkSetStatus, Status: "forgotten"},
+		"no selection":    {Action: BulkDelete},
+		"both selections": {PickIDs: []int64{1}, Filter: &GetUserPicksRequest{}, Action: BulkDelete},
+		"too many ids":    {PickIDs: make([]int64, maxBulkPicks+1), Action: BulkDelete},
+		"too many picks":  {Filter: &GetUserPicksRequest{}, Action: BulkDelete},
+		"invalid filter":  {Filter: &GetUserPicksRequest{Statuses: []model.PickStatus{"forgotten"}}, Action: BulkDelete},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r.UserID = "user-123"
+			_, err := srv.BulkUpdatePicks(context.Background(), r)
+			requireStatus(t, err, http.StatusBadRequest)
+		})
+	}
+}
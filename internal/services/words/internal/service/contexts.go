@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/highlight"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+const (
+	// maxContextSentenceLength is the maximum length of a context sentence in characters
+	maxContextSentenceLength = 1000
+	// maxSourceTitleLength is the maximum length of the title of a context source in characters
+	maxSourceTitleLength = 300
+	// maxSourceURLLength is the maximum length of the URL of a context source in bytes
+	maxSourceURLLength = 2048
+)
+
+// PickContextRequest describes where the user came across a word. The zero value adds no context,
+// a source can only be given together with a sentence.
+type PickContextRequest struct {
+	Sentence    string
+	SourceTitle string
+	SourceURL   string
+}
+
+func (c PickContextRequest) empty() bool {
+	return c == PickContextRequest{}
+}
+
+// PickContext is a sentence the user came across the word of a pick in
+type PickContext struct {
+	ID          int64
+	Sentence    string
+	SourceTitle string
+	SourceURL   string
+	// Highlight is the span of the sentence the word occurs in, nil when the word was not found,
+	// for example because the sentence uses an irregular form of it
+	Highlight *highlight.Span
+	CreatedAt time.Time
+}
+
+func newPickContext(c model.PickContext, lemma string) PickContext {
+	pc := PickContext{
+		ID:          c.ID,
+		Sentence:    c.Sentence,
+		SourceTitle: c.SourceTitle,
+		SourceURL:   c.SourceURL,
+		CreatedAt:   c.CreateAt,
+	}
+	if span, ok := highlight.Find(c.Sentence, lemma); ok {
+		pc.Highlight = &span
+	}
+
+	return pc
+}
+
+// cleanPickContext collapses the whitespace of the sentence and the source title and checks the lengths
+// and the source URL, which must be an absolute http or https URL. It returns a ServiceError with
+// status code 400 for invalid contexts.
+func cleanPickContext(c PickContextRequest) (PickContextRequest, error) {
+	c = PickContextRequest{
+		Sentence:    collapseSpace(c.Sentence),
+		SourceTitle: collapseSpace(c.SourceTitle),
+		SourceURL:   strings.TrimSpace(c.SourceURL),
+	}
+
+	if c.Sentence == "" {
+		return c, serr.NewServiceError(nil, http.StatusBadRequest, "context sentence is required")
+	}
+	if utf8.RuneCountInString(c.Sentence) > maxContextSentenceLength {
+		return c, serr.NewServiceError(nil, http.StatusBadRequest, "context sentence must be at most %d characters long", maxContextSentenceLength)
+	}
+	if utf8.RuneCountInString(c.SourceTitle) > maxSourceTitleLength {
+		return c, serr.NewServiceError(nil, http.StatusBadRequest, "source title must be at most %d characters long", maxSourceTitleLength)
+	}
+
+	if c.SourceURL != "" {
+		u, err := url.Parse(c.SourceURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(c.SourceURL) > maxSourceURLLength {
+			se := serr.NewServiceError(err, http.StatusBadRequest, "source URL must be an http or https URL")
+			se.Env["source_url"] = c.SourceURL
+			return c, se
+		}
+	}
+
+	return c, nil
+}
+
+// collapseSpace removes control characters and replaces runs of whitespace by single spaces
+func collapseSpace(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+type AddPickContextRequest struct {
+	UserID  string
+	PickID  int64
+	Context PickContextRequest
+}
+
+// AddPickContext adds another sentence the user came across the word of a pick in. It returns a ServiceError
+// with status code 400 for invalid contexts and 404 if the user has no such pick.
+func (s *WordsService) AddPickContext(ctx context.Context, r AddPickContextRequest) (int64, error) {
+	c, err := cleanPickContext(r.Context)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.store.AddPickContext(ctx, store.AddPickContextRequest{
+		UserID:      r.UserID,
+		PickID:      r.PickID,
+		Sentence:    c.Sentence,
+		SourceTitle: c.SourceTitle,
+		SourceURL:   c.SourceURL,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return 0, pickNotFound(err, r.PickID)
+		}
+
+		return 0, fmt.Errorf("add pick context: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/highlight"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickWord_Context(t *testing.T) {
+	var added []store.AddPickContextRequest
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		CreateTagsFunc: func(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		AddTagsFunc: func(ctx context.Context, r store.AddTagsRequest) error {
+			return nil
+		},
+		CreateUserPickFunc: func(ctx context.Context, r store.CreateUserPickRequest) (int64, error) {
+			return 42, nil
+		},
+		AddPickContextFunc: func(ctx context.Context, r store.AddPickContextRequest) (int64, error) {
+			added = append(added, r)
+			return 1, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.PickWord(context.Background(), PickWoardRequest{UserID: "user-123", DefID: 7})
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	_, err = srv.PickWord(context.Background(), PickWoardRequest{
+		UserID: "user-123",
+		DefID:  7,
+		Context: PickContextRequest{
+			Sentence:    "  She was\n\trunning\x00 late. ",
+			SourceTitle: " The Book ",
+			SourceURL:   " https://example.com/book?page=2 ",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []store.AddPickContextRequest{{
+		UserID:      "user-123",
+		PickID:      42,
+		Sentence:    "She was running late.",
+		SourceTitle: "The Book",
+		SourceURL:   "https://example.com/book?page=2",
+	}}, added)
+}
+
+func TestPickWord_InvalidContext(t *testing.T) {
+	srv := NewWordsService(&mockStore{}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	for name, c := range map[string]PickContextRequest{
+		"source only":    {SourceURL: "https://example.com"},
+		"blank sentence": {Sentence: " \n "},
+		"long sentence":  {Sentence: strings.Repeat("a", maxContextSentenceLength+1)},
+		"long title":     {Sentence: "A sentence.", SourceTitle: strings.Repeat("a", maxSourceTitleLength+1)},
+		"script url":     {Sentence: "A sentence.", SourceURL: "javascript:alert(1)"},
+		"relative url":   {Sentence: "A sentence.", SourceURL: "/books/1"},
+		"bad url":        {Sentence: "A sentence.", SourceURL: "http://[::1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := srv.PickWord(context.Background(), PickWoardRequest{UserID: "user-123", DefID: 7, Context: c})
+			requireStatus(t, err, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestAddPickContext(t *testing.T) {
+	st := &mockStore{
+		AddPickContextFunc: func(ctx context.Context, r store.AddPickContextRequest) (int64, error) {
+			if r.UserID != "user-123" {
+				return 0, store.ErrNotFound
+			}
+			return 5, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	id, err := srv.AddPickContext(context.Background(), AddPickContextRequest{
+		UserID:  "user-123",
+		PickID:  1,
+		Context: PickContextRequest{Sentence: "An apple a day."},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), id)
+
+	_, err = srv.AddPickContext(context.Background(), AddPickContextRequest{
+		UserID:  "user-456",
+		PickID:  1,
+		Context: PickContextRequest{Sentence: "An apple a day."},
+	})
+	requireStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.AddPickContext(context.Background(), AddPickContextRequest{UserID: "user-123", PickID: 1})
+	requireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestNewUserPick_Contexts(t *testing.T) {
+	pick := newUserPick(model.UserPick{
+		ID:   1,
+		Word: model.Word{Lemma: "run"},
+		Contexts: []model.PickContext{
+			{ID: 1, Sentence: "She was running late.", SourceURL: "https://example.com"},
+			{ID: 2, Sentence: "He ran home."},
+		},
+	})
+
+	require.Len(t, pick.Contexts, 2)
+	assert.Equal(t, &highlight.Span{Start: 8, End: 15}, pick.Contexts[0].Highlight)
+	assert.Equal(t, "https://example.com", pick.Contexts[0].SourceURL)
+	assert.Nil(t, pick.Contexts[1].Highlight)
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/deck"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/importer"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+const (
+	// deckBatchSize is the number of cards of a deck imported in one transaction, the progress of an import
+	// job is saved after every batch
+	deckBatchSize = 100
+	// knownInterval is the review interval in days from which imported cards are known, Anki calls them mature
+	knownInterval = 21
+	// staleImportJob is the time after which a running import job that saved no progress is reported as failed
+	staleImportJob = 10 * time.Minute
+)
+
+// FieldMapping names the fields of the cards of a deck that pick words are read from
+type FieldMapping struct {
+	// Lemma and Definition default to the first and the second field of the deck
+	Lemma      string
+	Definition string
+	// Class is optional, cards with an empty class field get the class of the import
+	Class string
+}
+
+type ImportDeckRequest struct {
+	UserID string
+	// Deck is read in the background and closed when the import is done
+	Deck    deck.Reader
+	Mapping FieldMapping
+	Lang    model.Lang
+	// Class is the word class of the cards, it is required unless the class is mapped to a field
+	Class model.WordClass
+	// Tags are added to every pick along with the tags of its card
+	Tags []string
+	// WithReviews carries the scheduling state of the cards over to the picks, which are new otherwise
+	WithReviews bool
+}
+
+// deckPick is a card of a deck turned into the pick it is imported as
+type deckPick struct {
+	index  int
+	entry  importer.Entry
+	tags   []string
+	review *deck.Review
+}
+
+// ImportDeck starts importing the cards of a deck exported by another app into the picks of the user and returns
+// the import job, GetImportJob reports its progress. Every card becomes a pick of the text of its definition
+// field, and the words and definitions of the cards are created when they do not exist yet. Cards whose
+// definition the user picked already are skipped, and invalid cards are reported as failed.
+// It returns a ServiceError with status code 400 for fields missing from the deck and for invalid languages
+// and classes, the deck is closed then.
+func (s *WordsService) ImportDeck(ctx context.Context, r ImportDeckRequest) (model.ImportJob, error) {
+	r, err := cleanImportDeckRequest(r)
+	if err != nil {
+		r.Deck.Close()
+		return model.ImportJob{}, err
+	}
+
+	id, err := s.store.CreateImportJob(ctx, store.CreateImportJobRequest{UserID: r.UserID, Total: r.Deck.Len()})
+	if err != nil {
+		r.Deck.Close()
+		return model.ImportJob{}, fmt.Errorf("create import job: %w", err)
+	}
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.runDeckImport(id, r)
+	}()
+
+	return s.GetImportJob(ctx, r.UserID, id)
+}
+
+// cleanImportDeckRequest defaults the mapping to the first fields of the deck and checks the request
+func cleanImportDeckRequest(r ImportDeckRequest) (ImportDeckRequest, error) {
+	fields := r.Deck.Fields()
+	if r.Mapping.Lemma == "" && len(fields) > 0 {
+		r.Mapping.Lemma = fields[0]
+	}
+	if r.Mapping.Definition == "" && len(fields) > 1 {
+		r.Mapping.Definition = fields[1]
+	}
+
+	if r.Mapping.Lemma == "" || r.Mapping.Definition == "" {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "lemma and definition fields are required")
+		se.Env["fields"] = strings.Join(fields, ",")
+		return r, se
+	}
+	for _, f := range []string{r.Mapping.Lemma, r.Mapping.Definition, r.Mapping.Class} {
+		if f != "" && !slices.Contains(fields, f) {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "deck has no field %q", f)
+			se.Env["fields"] = strings.Join(fields, ",")
+			return r, se
+		}
+	}
+
+	r.Lang = model.Lang(strings.TrimSpace(string(r.Lang)))
+	if r.Lang == "" || len(r.Lang) > maxLangLength {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "invalid language")
+		se.Env["lang"] = string(r.Lang)
+		return r, se
+	}
+
+	if r.Class == "" && r.Mapping.Class == "" {
+		return r, serr.NewServiceError(nil, http.StatusBadRequest, "class is required unless it is mapped to a field")
+	}
+	if r.Class != "" && !r.Class.Valid() {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown word class")
+		se.Env["class"] = string(r.Class)
+		return r, se
+	}
+
+	return r, nil
+}
+
+// GetImportJob returns an import job of the user. Running jobs that saved no progress for staleImportJob
+// are reported as failed, the instance of the service that ran them stopped. It returns a ServiceError with
+// status code 404 when the user has no such job.
+func (s *WordsService) GetImportJob(ctx context.Context, userID string, jobID int64) (model.ImportJob, error) {
+	job, err := s.store.GetImportJob(ctx, store.GetImportJobRequest{ID: jobID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "import job not found")
+			se.Env["job_id"] = fmt.Sprintf("%d", jobID)
+			return model.ImportJob{}, se
+		}
+
+		return model.ImportJob{}, fmt.Errorf("get import job: %w", err)
+	}
+
+	return markStaleImport(job), nil
+}
+
+// markStaleImport reports a running job that saved no progress for staleImportJob as failed
+func markStaleImport(job model.ImportJob) model.ImportJob {
+	if job.Status == model.ImportRunning && time.Since(job.UpdatedAt) > staleImportJob {
+		job.Status = model.ImportFailed
+		job.Error = "the import was interrupted"
+	}
+
+	return job
+}
+
+// runDeckImport imports the deck of a job and saves the outcome. Imports are stopped by Shutdown,
+// their final state is saved nonetheless.
+func (s *WordsService) runDeckImport(jobID int64, r ImportDeckRequest) {
+	defer r.Deck.Close()
+
+	job := store.UpdateImportJobRequest{ID: jobID, Status: model.ImportRunning}
+	err := s.importDeck(s.jobsCtx, r, &job)
+
+	job.Status = model.ImportDone
+	if err != nil {
+		job.Status = model.ImportFailed
+		job.Error = "the import failed"
+
+		var se *serr.ServiceError
+		switch {
+		case errors.As(err, &se):
+			job.Error = se.Msg
+		case errors.Is(err, context.Canceled):
+			job.Error = "the import was interrupted"
+		default:
+			slog.Error("deck import failed", "job_id", jobID, "user_id", r.UserID, "error", err)
+		}
+	}
+
+	if err := s.store.UpdateImportJob(context.WithoutCancel(s.jobsCtx), job); err != nil {
+		slog.Error("failed to save import job", "job_id", jobID, "status", job.Status, "error", err)
+	}
+}
+
+// importDeck reads the cards of the deck and imports them in batches of deckBatchSize, each in a transaction
+// of its own, saving the progress of the job after every batch
+func (s *WordsService) importDeck(ctx context.Context, r ImportDeckRequest, job *store.UpdateImportJobRequest) error {
+	var batch []deckPick
+	for {
+		c, err := r.Deck.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			se := serr.NewServiceError(err, http.StatusBadRequest, "failed to read the deck")
+			se.Env["cards"] = fmt.Sprintf("%d", job.Cards)
+			return se
+		}
+
+		job.Cards++
+		p, err := newDeckPick(c, r)
+		if err != nil {
+			failDeckCard(job, c.Index, err.Error())
+			continue
+		}
+
+		batch = append(batch, p)
+		if len(batch) < deckBatchSize {
+			continue
+		}
+		if err := s.importDeckBatch(ctx, r, batch, job); err != nil {
+			return err
+		}
+		batch = batch[:0]
+
+		if err := s.store.UpdateImportJob(ctx, *job); err != nil {
+			return fmt.Errorf("save import progress: %w", err)
+		}
+	}
+
+	if len(batch) > 0 {
+		return s.importDeckBatch(ctx, r, batch, job)
+	}
+
+	return nil
+}
+
+func failDeckCard(job *store.UpdateImportJobRequest, index int, msg string) {
+	job.Failed++
+	if len(job.Errors) < maxImportErrors {
+		job.Errors = append(job.Errors, model.ImportJobError{Index: index, Msg: msg})
+	}
+}
+
+// newDeckPick reads the word of a card through the mapping of the request and checks it like
+// the entries of dictionary imports, see cleanDictionaryEntry
+func newDeckPick(c deck.Card, r ImportDeckRequest) (deckPick, error) {
+	class := r.Class
+	if r.Mapping.Class != "" {
+		if v := strings.ToLower(strings.TrimSpace(c.Fields[r.Mapping.Class])); v != "" {
+			class = model.WordClass(v)
+		}
+	}
+
+	e, err := cleanDictionaryEntry(importer.Entry{
+		Line:  c.Index,
+		Lemma: c.Fields[r.Mapping.Lemma],
+		Lang:  r.Lang,
+		Class: class,
+		Definitions: []importer.Definition{{
+			Text:   c.Fields[r.Mapping.Definition],
+			Source: model.SrcUser,
+		}},
+	})
+	if err != nil {
+		return deckPick{}, err
+	}
+
+	return deckPick{
+		index:  c.Index,
+		entry:  e,
+		tags:   append(slices.Clone(r.Tags), c.Tags...),
+		review: c.Review,
+	}, nil
+}
+
+// importDeckBatch finds or creates the words and definitions of the batch and picks them, every pick in a nested
+// transaction, so that a pick that fails leaves the others as they are. The counts of the job change only when
+// the batch is written.
+func (s *WordsService) importDeckBatch(ctx context.Context, r ImportDeckRequest, batch []deckPick, job *store.UpdateImportJobRequest) error {
+	var tags []string
+	for _, p := range batch {
+		tags = append(tags, p.tags...)
+	}
+
+	next := *job
+	err := s.runBulk(ctx, r.UserID, tags, false, func(tx store.DataStore) error {
+		resp, err := tx.UpsertDictionary(ctx, store.UpsertDictionaryRequest{
+			Entries: fn.Map(batch, func(p deckPick) store.DictionaryEntry {
+				return store.DictionaryEntry{
+					Lemma: p.entry.Lemma,
+					Lang:  p.entry.Lang,
+					Class: p.entry.Class,
+					Definitions: []store.DictionaryDefinition{{
+						Text:   p.entry.Definitions[0].Text,
+						Source: p.entry.Definitions[0].Source,
+					}},
+				}
+			}),
+		})
+		if err != nil {
+			return fmt.Errorf("upsert words: %w", err)
+		}
+
+		for i, p := range batch {
+			err := tx.WithTx(ctx, func(tx store.DataStore) error {
+				return s.importDeckPick(ctx, tx, r, p, resp.WordIDs[i], resp.DefIDs[i][0])
+			})
+
+			var se *serr.ServiceError
+			switch {
+			case err == nil:
+				next.Created++
+			case errors.As(err, &se) && se.StatusCode == http.StatusConflict:
+				next.Skipped++
+			case errors.As(err, &se):
+				failDeckCard(&next, p.index, se.Msg)
+			default:
+				return fmt.Errorf("import card %d: %w", p.index, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("import cards from %d: %w", batch[0].index, err)
+	}
+
+	*job = next
+	return nil
+}
+
+func (s *WordsService) importDeckPick(ctx context.Context, tx store.DataStore, r ImportDeckRequest, p deckPick, wordID, defID int64) error {
+	pickID, err := s.pickWord(ctx, tx, PickWoardRequest{
+		UserID: r.UserID,
+		WordID: wordID,
+		DefID:  defID,
+		Tags:   p.tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !r.WithReviews || p.review == nil {
+		return nil
+	}
+	status, due := reviewSchedule(*p.review)
+	if status == model.StatusNew {
+		return nil
+	}
+
+	err = tx.SchedulePick(ctx, store.SchedulePickRequest{
+		UserID:       r.UserID,
+		PickID:       pickID,
+		Status:       status,
+		NextReviewAt: due,
+	})
+	if err != nil {
+		return fmt.Errorf("schedule pick: %w", err)
+	}
+
+	return nil
+}
+
+// reviewSchedule maps the scheduling state of a card to the status of its pick and the time of the next review.
+// Cards reviewed at intervals of knownInterval days or more are known, and the other cards that were studied
+// are learning. Suspended cards keep their due time for when they are resumed.
+func reviewSchedule(r deck.Review) (model.PickStatus, *time.Time) {
+	var due *time.Time
+	if !r.Due.IsZero() {
+		due = &r.Due
+	}
+
+	switch {
+	case r.Suspended:
+		return model.StatusSuspended, due
+	case r.New:
+		return model.StatusNew, nil
+	case r.Interval >= knownInterval:
+		return model.StatusKnown, due
+	default:
+		return model.StatusLearning, due
+	}
+}
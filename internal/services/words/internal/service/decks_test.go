@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/deck"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDeck struct {
+	fields []string
+	cards  []deck.Card
+	closed bool
+}
+
+func (d *mockDeck) Next() (deck.Card, error) {
+	if len(d.cards) == 0 {
+		return deck.Card{}, io.EOF
+	}
+	c := d.cards[0]
+	d.cards = d.cards[1:]
+	return c, nil
+}
+
+func (d *mockDeck) Len() int {
+	return len(d.cards)
+}
+
+func (d *mockDeck) Fields() []string {
+	return d.fields
+}
+
+func (d *mockDeck) Close() error {
+	d.closed = true
+	return nil
+}
+
+// importJobStore records the final state of the import jobs it runs
+func importJobStore(st *mockStore, final *store.UpdateImportJobRequest) *mockStore {
+	st.CreateImportJobFunc = func(ctx context.Context, r store.CreateImportJobRequest) (int64, error) {
+		return 7, nil
+	}
+	st.GetImportJobFunc = func(ctx context.Context, r store.GetImportJobRequest) (model.ImportJob, error) {
+		return model.ImportJob{ID: r.ID, UserID: r.UserID, Status: model.ImportRunning, Model: model.Model{UpdatedAt: time.Now()}}, nil
+	}
+	st.UpdateImportJobFunc = func(ctx context.Context, r store.UpdateImportJobRequest) error {
+		*final = r
+		return nil
+	}
+	return st
+}
+
+func TestImportDeck(t *testing.T) {
+	due := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	d := &mockDeck{
+		fields: []string{"Front", "Back", "Class"},
+		cards: []deck.Card{
+			{Index: 1, Fields: map[string]string{"Front": " apple ", "Back": "a round fruit"}, Tags: []string{"food/fruits"}, Review: &deck.Review{New: true}},
+			{Index: 2, Fields: map[string]string{"Front": "run", "Back": "to move fast", "Class": "Verb"}, Review: &deck.Review{Due: due, Interval: 30}},
+			{Index: 3, Fields: map[string]string{"Front": "", "Back": "nothing"}},
+			{Index: 4, Fields: map[string]string{"Front": "pear", "Back": "a sweet fruit"}, Review: &deck.Review{Due: due, Suspended: true}},
+			{Index: 5, Fields: map[string]string{"Front": "plum", "Back": "a fruit", "Class": "fruit"}},
+		},
+	}
+
+	var (
+		final     store.UpdateImportJobRequest
+		entries   []store.DictionaryEntry
+		picked    []store.CreateUserPickRequest
+		scheduled []store.SchedulePickRequest
+		tagged    []string
+	)
+	st := importJobStore(&mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			entries = r.Entries
+			resp := store.UpsertDictionaryResponse{}
+			for i := range r.Entries {
+				resp.WordIDs = append(resp.WordIDs, int64(i+1))
+				resp.DefIDs = append(resp.DefIDs, []int64{int64(i + 10)})
+			}
+			return resp, nil
+		},
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{}, nil
+		},
+		CreateTagsFunc: func(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error) {
+			tagged = append(tagged, r.Tags...)
+			ids := model.TagIDMap{}
+			for i, tag := range r.Tags {
+				ids[tag] = int64(i + 1)
+			}
+			return ids, nil
+		},
+		AddTagsFunc: func(ctx context.Context, r store.AddTagsRequest) error {
+			return nil
+		},
+		CreateUserPickFunc: func(ctx context.Context, r store.CreateUserPickRequest) (int64, error) {
+			// the user picked pear before
+			if r.DefID == 12 {
+				return 0, store.ErrExists
+			}
+			picked = append(picked, r)
+			return r.DefID + 100, nil
+		},
+		SchedulePickFunc: func(ctx context.Context, r store.SchedulePickRequest) error {
+			scheduled = append(scheduled, r)
+			return nil
+		},
+	}, &final)
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	job, err := srv.ImportDeck(context.Background(), ImportDeckRequest{
+		UserID:      "user-123",
+		Deck:        d,
+		Mapping:     FieldMapping{Class: "Class"},
+		Lang:        "en",
+		Class:       model.Noun,
+		Tags:        []string{"anki"},
+		WithReviews: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), job.ID)
+	assert.Equal(t, model.ImportRunning, job.Status)
+
+	srv.jobs.Wait()
+	assert.True(t, d.closed)
+
+	assert.Equal(t, store.UpdateImportJobRequest{
+		ID:      7,
+		Status:  model.ImportDone,
+		Cards:   5,
+		Created: 2,
+		Skipped: 1,
+		Failed:  2,
+		Errors: []model.ImportJobError{
+			{Index: 3, Msg: "lemma is required"},
+			{Index: 5, Msg: `unknown word class "fruit"`},
+		},
+	}, final)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, store.DictionaryEntry{
+		Lemma:       "apple",
+		Lang:        "en",
+		Class:       model.Noun,
+		Definitions: []store.DictionaryDefinition{{Text: "a round fruit", Source: model.SrcUser}},
+	}, entries[0])
+	assert.Equal(t, model.Verb, entries[1].Class)
+
+	assert.Equal(t, []store.CreateUserPickRequest{{UserID: "user-123", DefID: 10}, {UserID: "user-123", DefID: 11}}, picked)
+	assert.Contains(t, tagged, "food/fruits")
+	assert.Contains(t, tagged, "anki")
+
+	// new cards stay new, and the run card was reviewed at a month interval
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, int64(111), scheduled[0].PickID)
+	assert.Equal(t, model.StatusKnown, scheduled[0].Status)
+	assert.Equal(t, due, *scheduled[0].NextReviewAt)
+}
+
+func TestImportDeck_StoreError(t *testing.T) {
+	var final store.UpdateImportJobRequest
+	st := importJobStore(&mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			return store.UpsertDictionaryResponse{}, errors.New("connection reset")
+		},
+	}, &final)
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	d := &mockDeck{
+		fields: []string{"1", "2"},
+		cards:  []deck.Card{{Index: 1, Fields: map[string]string{"1": "apple", "2": "a round fruit"}}},
+	}
+	_, err := srv.ImportDeck(context.Background(), ImportDeckRequest{UserID: "user-123", Deck: d, Lang: "en", Class: model.Noun})
+	require.NoError(t, err)
+
+	srv.jobs.Wait()
+	assert.Equal(t, model.ImportFailed, final.Status)
+	assert.Equal(t, "the import failed", final.Error)
+	assert.Equal(t, 1, final.Cards)
+	assert.Zero(t, final.Created)
+}
+
+func TestImportDeck_Invalid(t *testing.T) {
+	srv := NewWordsService(&mockStore{}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	for name, r := range map[string]ImportDeckRequest{
+		"unknown field":  {Mapping: FieldMapping{Lemma: "Word"}, Lang: "en", Class: model.Noun},
+		"unknown class":  {Lang: "en", Class: "fruit"},
+		"no class":       {Lang: "en"},
+		"no lang":        {Class: model.Noun},
+		"single field":   {Lang: "en", Class: model.Noun},
+		"no class field": {Mapping: FieldMapping{Class: "Class"}, Lang: "en"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d := &mockDeck{fields: []string{"Front", "Back"}}
+			if name == "single field" {
+				d.fields = d.fields[:1]
+			}
+			r.Deck = d
+
+			_, err := srv.ImportDeck(context.Background(), r)
+			requireStatus(t, err, http.StatusBadRequest)
+			assert.True(t, d.closed)
+		})
+	}
+}
+
+func TestGetImportJob(t *testing.T) {
+	st := &mockStore{
+		GetImportJobFunc: func(ctx context.Context, r store.GetImportJobRequest) (model.ImportJob, error) {
+			switch r.ID {
+			case 1:
+				return model.ImportJob{ID: 1, Status: model.ImportRunning, Model: model.Model{UpdatedAt: time.Now()}}, nil
+			case 2:
+				return model.ImportJob{ID: 2, Status: model.ImportRunning, Model: model.Model{UpdatedAt: time.Now().Add(-time.Hour)}}, nil
+			}
+			return model.ImportJob{}, store.ErrNotFound
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	job, err := srv.GetImportJob(context.Background(), "user-123", 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.ImportRunning, job.Status)
+
+	// the import stopped saving its progress
+	job, err = srv.GetImportJob(context.Background(), "user-123", 2)
+	require.NoError(t, err)
+	assert.Equal(t, model.ImportFailed, job.Status)
+	assert.NotEmpty(t, job.Error)
+
+	_, err = srv.GetImportJob(context.Background(), "user-123", 3)
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestReviewSchedule(t *testing.T) {
+	due := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		review deck.Review
+		status model.PickStatus
+		due    bool
+	}{
+		{review: deck.Review{New: true}, status: model.StatusNew},
+		{review: deck.Review{Due: due}, status: model.StatusLearning, due: true},
+		{review: deck.Review{Due: due, Interval: 5}, status: model.StatusLearning, due: true},
+		{review: deck.Review{Due: due, Interval: knownInterval}, status: model.StatusKnown, due: true},
+		{review: deck.Review{New: true, Suspended: true}, status: model.StatusSuspended},
+		{review: deck.Review{Due: due, Suspended: true}, status: model.StatusSuspended, due: true},
+	} {
+		status, next := reviewSchedule(tc.review)
+		assert.Equal(t, tc.status, status, tc.review)
+		assert.Equal(t, tc.due, next != nil, tc.review)
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/importer"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+const (
+	// importBatchSize is the number of dictionary entries written to the store at once
+	importBatchSize = 500
+	// maxImportErrors is the maximum number of failed records an ImportReport lists
+	maxImportErrors = 100
+	// maxLangLength is the maximum length of a language code in bytes
+	maxLangLength = 10
+	// maxImageURLLength is the maximum length of the URL of an imported image in bytes
+	maxImageURLLength = 2048
+)
+
+type ImportDictionaryRequest struct {
+	Entries importer.Reader
+	// AfterLine resumes an import, records starting on lines up to it are skipped as imported already
+	AfterLine int
+	// Checkpoint is called after every batch of entries is written with the line of the last entry, it is optional.
+	// An import that stops can be resumed after the last line it was called with.
+	Checkpoint func(line int) error
+}
+
+// ImportError tells why the record starting on Line was not imported
+type ImportError struct {
+	Line int
+	Msg  string
+}
+
+// ImportReport counts the records of a dictionary import by outcome. A record is created when its word,
+// any of its definitions or any of their images did not exist before, and skipped when all of them did.
+type ImportReport struct {
+	Records int
+	Created int
+	Skipped int
+	Failed  int
+	// Words, Definitions and Images are the numbers of rows the import created
+	Words       int
+	Definitions int
+	Images      int
+	// Relations is the number of relations between definitions the import created
+	Relations int
+	// Errors lists the first maxImportErrors records that failed
+	Errors []ImportError
+}
+
+func (r *ImportReport) fail(line int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, ImportError{Line: line, Msg: err.Error()})
+	}
+}
+
+// ImportDictionary adds the words, definitions and images read from the entries to the global dictionary,
+// leaving the ones that exist already as they are, so importing the same entries again changes nothing.
+// Invalid records are reported as failed, and the other ones are written in batches of importBatchSize
+// entries, each in a transaction of its own. The input is streamed, so it may be of any size, and records
+// up to AfterLine are skipped without counting them.
+// When the entries relate definitions to each other, the relations are added after all entries, skipping
+// the ones between definitions that were not imported.
+// It returns a ServiceError with status code 400 when the entries cannot be read any further, the report
+// then covers the batches imported up to that point.
+func (s *WordsService) ImportDictionary(ctx context.Context, r ImportDictionaryRequest) (ImportReport, error) {
+	var (
+		report ImportReport
+		batch  []importer.Entry
+	)
+	for {
+		e, err := r.Entries.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var re *importer.RecordError
+			if errors.As(err, &re) {
+				if re.Line <= r.AfterLine {
+					continue
+				}
+				report.Records++
+				report.fail(re.Line, re.Err)
+				continue
+			}
+
+			se := serr.NewServiceError(err, http.StatusBadRequest, "failed to read dictionary entries")
+			se.Env["records"] = fmt.Sprintf("%d", report.Records)
+			return report, se
+		}
+		if e.Line <= r.AfterLine {
+			continue
+		}
+
+		report.Records++
+		e, err = cleanDictionaryEntry(e)
+		if err != nil {
+			report.fail(e.Line, err)
+			continue
+		}
+
+		batch = append(batch, e)
+		if len(batch) == importBatchSize {
+			if err := s.importBatch(ctx, r, batch, &report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.importBatch(ctx, r, batch, &report); err != nil {
+			return report, err
+		}
+	}
+
+	if rr, ok := r.Entries.(importer.RelationReader); ok {
+		if err := s.importRelations(ctx, rr.Relations(), &report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// importRelations adds the relations in batches of importBatchSize, each in a transaction of its own
+func (s *WordsService) importRelations(ctx context.Context, relations []importer.Relation, report *ImportReport) error {
+	rels := make([]store.DefinitionRelation, 0, len(relations))
+	for _, rel := range relations {
+		if !rel.Type.Valid() {
+			continue
+		}
+		rels = append(rels, store.DefinitionRelation{
+			From: cleanDefinitionRef(rel.From),
+			To:   cleanDefinitionRef(rel.To),
+			Type: rel.Type,
+		})
+	}
+
+	for start := 0; start < len(rels); start += importBatchSize {
+		batch := rels[start:min(start+importBatchSize, len(rels))]
+		err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+			n, err := tx.UpsertDefinitionRelations(ctx, store.UpsertDefinitionRelationsRequest{Relations: batch})
+			report.Relations += int(n)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("import definition relations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cleanDefinitionRef cleans a reference to a definition the same way cleanDictionaryEntry cleans the entry it refers to
+func cleanDefinitionRef(ref importer.DefinitionRef) store.DefinitionKey {
+	return store.DefinitionKey{
+		Lemma: collapseSpace(ref.Lemma),
+		Lang:  model.Lang(strings.TrimSpace(string(ref.Lang))),
+		Class: model.WordClass(strings.TrimSpace(string(ref.Class))),
+		Text:  collapseSpace(ref.Text),
+	}
+}
+
+// ListRelatedDefinitions lists the definitions related to a definition, such as its synonyms and antonyms.
+// If the definition does not exist, it returns a ServiceError with status code 404.
+func (s *WordsService) ListRelatedDefinitions(ctx context.Context, defID int64) ([]model.RelatedDefinition, error) {
+	related, err := s.store.ListRelatedDefinitions(ctx, store.ListRelatedDefinitionsRequest{DefID: defID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "definition not found")
+			se.Env["def_id"] = fmt.Sprintf("%d", defID)
+			return nil, se
+		}
+
+		return nil, fmt.Errorf("list related definitions: %w", err)
+	}
+
+	return related, nil
+}
+
+func (s *WordsService) importBatch(ctx context.Context, r ImportDictionaryRequest, batch []importer.Entry, report *ImportReport) error {
+	var resp store.UpsertDictionaryResponse
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		var err error
+		resp, err = tx.UpsertDictionary(ctx, store.UpsertDictionaryRequest{
+			Entries: fn.Map(batch, func(e importer.Entry) store.DictionaryEntry {
+				return store.DictionaryEntry{
+					Lemma: e.Lemma,
+					Lang:  e.Lang,
+					Class: e.Class,
+					Definitions: fn.Map(e.Definitions, func(d importer.Definition) store.DictionaryDefinition {
+						return store.DictionaryDefinition{
+							Text:      d.Text,
+							Rarity:    d.Rarity,
+							Source:    d.Source,
+							ImageURLs: d.ImageURLs,
+						}
+					}),
+				}
+			}),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("import entries from line %d: %w", batch[0].Line, err)
+	}
+
+	for _, created := range resp.Created {
+		if created {
+			report.Created++
+		} else {
+			report.Skipped++
+		}
+	}
+	report.Words += resp.Words
+	report.Definitions += resp.Definitions
+	report.Images += resp.Images
+
+	if r.Checkpoint != nil {
+		if err := r.Checkpoint(batch[len(batch)-1].Line); err != nil {
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cleanDictionaryEntry collapses the whitespace of the lemma and the definitions, defaults the source of
+// the definitions to unknown and checks the entry. Image URLs must be absolute http or https URLs.
+func cleanDictionaryEntry(e importer.Entry) (importer.Entry, error) {
+	e.Lemma = collapseSpace(e.Lemma)
+	e.Lang = model.Lang(strings.TrimSpace(string(e.Lang)))
+	e.Class = model.WordClass(strings.TrimSpace(string(e.Class)))
+
+	if e.Lemma == "" {
+		return e, errors.New("lemma is required")
+	}
+	if e.Lang == "" || len(e.Lang) > maxLangLength {
+		return e, fmt.Errorf("invalid language %q", e.Lang)
+	}
+	if !e.Class.Valid() {
+		return e, fmt.Errorf("unknown word class %q", e.Class)
+	}
+	if len(e.Definitions) == 0 {
+		return e, errors.New("at least one definition is required")
+	}
+
+	defs := make([]importer.Definition, 0, len(e.Definitions))
+	for _, d := range e.Definitions {
+		d.Text = collapseSpace(d.Text)
+		if d.Text == "" {
+			return e, errors.New("definition text is required")
+		}
+
+		if d.Source == "" {
+			d.Source = model.SrcUnknown
+		}
+		if !d.Source.Valid() {
+			return e, fmt.Errorf("unknown source %q", d.Source)
+		}
+
+		for _, raw := range d.ImageURLs {
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(raw) > maxImageURLLength {
+				return e, fmt.Errorf("image URL %q must be an http or https URL", raw)
+			}
+		}
+
+		defs = append(defs, d)
+	}
+	e.Definitions = defs
+
+	return e, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/importer"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportDictionary(t *testing.T) {
+	var upserted []store.DictionaryEntry
+	st := &mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			upserted = append(upserted, r.Entries...)
+			// the apple existed already
+			return store.UpsertDictionaryResponse{Created: []bool{false, true}, Words: 1, Definitions: 2, Images: 1}, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	report, err := srv.ImportDictionary(context.Background(), ImportDictionaryRequest{
+		Entries: importer.NewJSONLReader(strings.NewReader(`{"lemma": "apple", "lang": "en", "class": "noun", "definitions": [{"text": "A round fruit."}]}
+{"lemma": " run ", "lang": "en", "class": "verb", "definitions": [{"text": "To move\tfast.", "source": "ai", "image_urls": ["https://example.com/run.jpg"]}]}
+{"lemma": "walk", "lang": "en", "class": "verb"}
+{"lemma": "fly", "lang": "en", "class": "particle", "definitions": [{"text": "To move through the air."}]}
+{"lemma": "swim", "lang": "en", "class": "verb", "definitions": [{"text": "To move through water.", "source": "book"}]}
+{"lemma": "jump", "lang": "en", "class": "verb", "definitions": [{"text": "To leap.", "image_urls": ["javascript:alert(1)"]}]}
+not json
+`)),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []store.DictionaryEntry{
+		{Lemma: "apple", Lang: "en", Class: model.Noun, Definitions: []store.DictionaryDefinition{{Text: "A round fruit.", Source: model.SrcUnknown}}},
+		{Lemma: "run", Lang: "en", Class: model.Verb, Definitions: []store.DictionaryDefinition{{
+			Text:      "To move fast.",
+			Source:    model.SrcAI,
+			ImageURLs: []string{"https://example.com/run.jpg"},
+		}}},
+	}, upserted)
+
+	assert.Equal(t, 7, report.Records)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 5, report.Failed)
+	assert.Equal(t, 1, report.Words)
+	assert.Equal(t, 2, report.Definitions)
+	assert.Equal(t, 1, report.Images)
+	assert.Equal(t, []int{3, 4, 5, 6, 7}, fn.Map(report.Errors, func(e ImportError) int { return e.Line }))
+	assert.Equal(t, `unknown word class "particle"`, report.Errors[1].Msg)
+}
+
+func TestImportDictionary_Batches(t *testing.T) {
+	var batches []int
+	st := &mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			batches = append(batches, len(r.Entries))
+			return store.UpsertDictionaryResponse{Created: make([]bool, len(r.Entries))}, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	var input strings.Builder
+	for i := range importBatchSize + 1 {
+		fmt.Fprintf(&input, `{"lemma": "word%d", "lang": "en", "class": "noun", "definitions": [{"text": "A word."}]}`+"\n", i)
+	}
+
+	report, err := srv.ImportDictionary(context.Background(), ImportDictionaryRequest{
+		Entries: importer.NewJSONLReader(strings.NewReader(input.String())),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{importBatchSize, 1}, batches)
+	assert.Equal(t, importBatchSize+1, report.Skipped)
+}
+
+func TestImportDictionary_Resume(t *testing.T) {
+	var lemmas []string
+	st := &mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			lemmas = append(lemmas, fn.Map(r.Entries, func(e store.DictionaryEntry) string { return e.Lemma })...)
+			return store.UpsertDictionaryResponse{Created: make([]bool, len(r.Entries))}, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	var checkpoints []int
+	report, err := srv.ImportDictionary(context.Background(), ImportDictionaryRequest{
+		Entries: importer.NewJSONLReader(strings.NewReader(`{"lemma": "apple", "lang": "en", "class": "noun", "definitions": [{"text": "A round fruit."}]}
+not json
+{"lemma": "pear", "lang": "en", "class": "noun", "definitions": [{"text": "A sweet fruit."}]}
+{"lemma": "plum", "lang": "en", "class": "noun", "definitions": [{"text": "A small fruit."}]}
+`)),
+		AfterLine: 2,
+		Checkpoint: func(line int) error {
+			checkpoints = append(checkpoints, line)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pear", "plum"}, lemmas)
+	assert.Equal(t, []int{4}, checkpoints)
+	assert.Equal(t, 2, report.Records)
+	assert.Zero(t, report.Failed)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Next() (importer.Entry, error) {
+	return importer.Entry{}, r.err
+}
+
+func TestImportDictionary_Errors(t *testing.T) {
+	st := &mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			return store.UpsertDictionaryResponse{}, errors.New("connection reset")
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.ImportDictionary(context.Background(), ImportDictionaryRequest{Entries: failingReader{io.ErrUnexpectedEOF}})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	_, err = srv.ImportDictionary(context.Background(), ImportDictionaryRequest{
+		Entries: importer.NewJSONLReader(strings.NewReader(`{"lemma": "apple", "lang": "en", "class": "noun", "definitions": [{"text": "A round fruit."}]}`)),
+	})
+	require.ErrorContains(t, err, "connection reset")
+}
+
+func TestImportDictionary_Relations(t *testing.T) {
+	var relations []store.DefinitionRelation
+	st := &mockStore{
+		UpsertDictionaryFunc: func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+			return store.UpsertDictionaryResponse{Created: make([]bool, len(r.Entries)), Words: len(r.Entries)}, nil
+		},
+		UpsertDefinitionRelationsFunc: func(ctx context.Context, r store.UpsertDefinitionRelationsRequest) (int64, error) {
+			relations = append(relations, r.Relations...)
+			return int64(len(r.Relations)), nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	report, err := srv.ImportDictionary(context.Background(), ImportDictionaryRequest{
+		Entries: importer.NewWordNetReader(strings.NewReader(`<LexicalResource>
+  <Lexicon id="test" language="en">
+    <LexicalEntry id="test-big-a">
+      <Lemma writtenForm="big" partOfSpeech="a"/>
+      <Sense id="test-big-a-1" synset="test-1-a"/>
+    </LexicalEntry>
+    <LexicalEntry id="test-large-a">
+      <Lemma writtenForm="large" partOfSpeech="a"/>
+      <Sense id="test-large-a-1" synset="test-1-a"/>
+    </LexicalEntry>
+    <Synset id="test-1-a" partOfSpeech="a">
+      <Definition>above average in  size</Definition>
+    </Synset>
+  </Lexicon>
+</LexicalResource>`)),
+	})
+	require.NoError(t, err)
+
+	big := store.DefinitionKey{Lemma: "big", Lang: "en", Class: model.Adjective, Text: "above average in size"}
+	large := store.DefinitionKey{Lemma: "large", Lang: "en", Class: model.Adjective, Text: "above average in size"}
+	assert.Equal(t, []store.DefinitionRelation{
+		{From: big, To: large, Type: model.RelSynonym},
+		{From: large, To: big, Type: model.RelSynonym},
+	}, relations)
+	assert.Equal(t, 2, report.Words)
+	assert.Equal(t, 2, report.Relations)
+}
+
+func TestListRelatedDefinitions(t *testing.T) {
+	st := &mockStore{
+		ListRelatedDefinitionsFunc: func(ctx context.Context, r store.ListRelatedDefinitionsRequest) ([]model.RelatedDefinition, error) {
+			if r.DefID != 1 {
+				return nil, store.ErrNotFound
+			}
+			return []model.RelatedDefinition{{Type: model.RelSynonym, Word: model.Word{ID: 2, Lemma: "large"}}}, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	related, err := srv.ListRelatedDefinitions(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, related, 1)
+	assert.Equal(t, "large", related[0].Word.Lemma)
+
+	_, err = srv.ListRelatedDefinitions(context.Background(), 2)
+	requireStatus(t, err, http.StatusNotFound)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/tagexpr"
+)
+
+// maxTagFilterName is the maximum length of a saved tag filter name
+const maxTagFilterName = 100
+
+// TagFilter is a tag expression saved by a user under a name
+type TagFilter struct {
+	Name string
+	Expr string
+}
+
+type SaveTagFilterRequest struct {
+	UserID string
+	Name   string
+	Expr   string
+}
+
+// SaveTagFilter validates the tag expression and saves it under the given name, replacing
+// the filter of the user with the same name. The expression is stored in its canonical form.
+func (s *WordsService) SaveTagFilter(ctx context.Context, r SaveTagFilterRequest) (TagFilter, error) {
+	name := strings.TrimSpace(r.Name)
+	if name == "" || len(name) > maxTagFilterName {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "tag filter name must be between 1 and %d characters long", maxTagFilterName)
+		se.Env["name"] = r.Name
+		return TagFilter{}, se
+	}
+
+	expr, err := parseTagFilter(r.Expr)
+	if err != nil {
+		return TagFilter{}, err
+	}
+
+	f := TagFilter{Name: name, Expr: expr.String()}
+	_, err = s.store.SaveTagFilter(ctx, store.SaveTagFilterRequest{UserID: r.UserID, Name: f.Name, Expr: f.Expr})
+	if err != nil {
+		return TagFilter{}, fmt.Errorf("save tag filter: %w", err)
+	}
+
+	return f, nil
+}
+
+// ListTagFilters lists the saved tag filters of the user ordered by name
+func (s *WordsService) ListTagFilters(ctx context.Context, userID string) ([]TagFilter, error) {
+	filters, err := s.store.ListTagFilters(ctx, store.ListTagFiltersRequest{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("list tag filters: %w", err)
+	}
+
+	return fn.Map(filters, func(f model.TagFilter) TagFilter {
+		return TagFilter{Name: f.Name, Expr: f.Expr}
+	}), nil
+}
+
+type DeleteTagFilterRequest struct {
+	UserID string
+	Name   string
+}
+
+// DeleteTagFilter deletes a saved tag filter of the user.
+// If the filter does not exist, it returns a ServiceError with status code 404.
+func (s *WordsService) DeleteTagFilter(ctx context.Context, r DeleteTagFilterRequest) error {
+	err := s.store.DeleteTagFilter(ctx, store.DeleteTagFilterRequest{UserID: r.UserID, Name: r.Name})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return tagFilterNotFound(err, r.Name)
+		}
+
+		return fmt.Errorf("delete tag filter: %w", err)
+	}
+
+	return nil
+}
+
+// resolveTagFilter returns the tag expression of the user given either inline or by the name of a saved
+// filter, with the IDs of the referenced tags resolved. It returns nil when neither is given.
+func (s *WordsService) resolveTagFilter(ctx context.Context, userID, filter, savedFilter string) (tagexpr.Expr, error) {
+	if filter != "" && savedFilter != "" {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "either a tag filter or a saved tag filter can be used, not both")
+		se.Env["filter"] = filter
+		se.Env["saved_filter"] = savedFilter
+		return nil, se
+	}
+
+	if savedFilter != "" {
+		f, err := s.store.GetTagFilter(ctx, store.GetTagFilterRequest{UserID: userID, Name: savedFilter})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, tagFilterNotFound(err, savedFilter)
+			}
+
+			return nil, fmt.Errorf("get tag filter: %w", err)
+		}
+		filter = f.Expr
+	}
+
+	if filter == "" {
+		return nil, nil
+	}
+
+	expr, err := parseTagFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := tagexpr.Tags(expr)
+	tags, _, err := s.tags.GetTags(ctx, s.store, userID, fn.Map(nodes, func(t *tagexpr.Tag) string { return t.Path }))
+	if err != nil {
+		return nil, fmt.Errorf("get filter tags: %w", err)
+	}
+
+	// tags the user does not have keep a zero ID and match no picks
+	for _, node := range nodes {
+		node.ID, _ = tags.GetID(node.Path)
+	}
+
+	return expr, nil
+}
+
+func parseTagFilter(filter string) (tagexpr.Expr, error) {
+	expr, err := tagexpr.Parse(filter)
+	if err != nil {
+		var syntaxErr *tagexpr.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			se := serr.NewServiceError(err, http.StatusBadRequest, "invalid tag filter: %s", syntaxErr.Msg)
+			se.Env["filter"] = filter
+			se.Env["position"] = fmt.Sprintf("%d", syntaxErr.Pos)
+			return nil, se
+		}
+
+		return nil, fmt.Errorf("parse tag filter: %w", err)
+	}
+
+	return expr, nil
+}
+
+func tagFilterNotFound(err error, name string) error {
+	se := serr.NewServiceError(err, http.StatusNotFound, "tag filter not found")
+	se.Env["name"] = name
+	return se
+}
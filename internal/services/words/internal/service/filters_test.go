@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/tagexpr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserPicks_Filter(t *testing.T) {
+	var request store.GetUserPicksRequest
+	srv := newTagsTestService(&mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			assert.ElementsMatch(t, []string{"verbs", "nouns", "mastered"}, r.Tags)
+			return model.TagIDMap{"verbs": 1, "mastered": 3}, nil
+		},
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			request = r
+			return store.GetUserPicksResponse{}, nil
+		},
+		CountUserPicksFunc: countNoPicks,
+	})
+
+	_, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{
+		UserID:   "user-1",
+		Filter:   "(verbs OR nouns) AND NOT mastered",
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &tagexpr.And{
+		X: &tagexpr.Or{X: &tagexpr.Tag{Path: "verbs", ID: 1}, Y: &tagexpr.Tag{Path: "nouns"}},
+		Y: &tagexpr.Not{X: &tagexpr.Tag{Path: "mastered", ID: 3}},
+	}, request.TagExpr)
+}
+
+func TestGetUserPicks_SavedFilter(t *testing.T) {
+	var request store.GetUserPicksRequest
+	srv := newTagsTestService(&mockStore{
+		GetTagFilterFunc: func(ctx context.Context, r store.GetTagFilterRequest) (model.TagFilter, error) {
+			if r.Name != "todo" {
+				return model.TagFilter{}, store.ErrNotFound
+			}
+			return model.TagFilter{Name: "todo", Expr: "NOT mastered"}, nil
+		},
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return model.TagIDMap{"mastered": 3}, nil
+		},
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			request = r
+			return store.GetUserPicksResponse{}, nil
+		},
+		CountUserPicksFunc: countNoPicks,
+	})
+
+	_, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-1", SavedFilter: "todo", PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, &tagexpr.Not{X: &tagexpr.Tag{Path: "mastered", ID: 3}}, request.TagExpr)
+
+	_, err = srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-1", SavedFilter: "missing", PageSize: 10})
+	requireStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-1", Filter: "verbs", SavedFilter: "todo"})
+	requireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestGetUserPicks_InvalidFilter(t *testing.T) {
+	srv := newTagsTestService(&mockStore{})
+
+	_, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-1", Filter: "verbs AND (nouns"})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	var se *serr.ServiceError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, "16", se.Env["position"])
+}
+
+func TestSaveTagFilter(t *testing.T) {
+	var saved store.SaveTagFilterRequest
+	srv := newTagsTestService(&mockStore{
+		SaveTagFilterFunc: func(ctx context.Context, r store.SaveTagFilterRequest) (int64, error) {
+			saved = r
+			return 1, nil
+		},
+	})
+
+	f, err := srv.SaveTagFilter(context.Background(), SaveTagFilterRequest{UserID: "user-1", Name: " todo ", Expr: "verbs and not (mastered)"})
+	require.NoError(t, err)
+	assert.Equal(t, TagFilter{Name: "todo", Expr: "verbs AND NOT mastered"}, f)
+	assert.Equal(t, store.SaveTagFilterRequest{UserID: "user-1", Name: "todo", Expr: "verbs AND NOT mastered"}, saved)
+
+	_, err = srv.SaveTagFilter(context.Background(), SaveTagFilterRequest{UserID: "user-1", Name: " ", Expr: "verbs"})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	_, err = srv.SaveTagFilter(context.Background(), SaveTagFilterRequest{UserID: "user-1", Name: "todo", Expr: "verbs OR"})
+	requireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestDeleteTagFilter_NotFound(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		DeleteTagFilterFunc: func(ctx context.Context, r store.DeleteTagFilterRequest) error {
+			return store.ErrNotFound
+		},
+	})
+
+	err := srv.DeleteTagFilter(context.Background(), DeleteTagFilterRequest{UserID: "user-1", Name: "todo"})
+	requireStatus(t, err, http.StatusNotFound)
+}
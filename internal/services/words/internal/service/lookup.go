@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/dictionary"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+type LookupRequest struct {
+	Word string
+	// Lang limits the lookup to the dictionaries of the language and the ones that do not tell their language
+	Lang model.Lang
+}
+
+// DictionaryArticle is an article found in an offline dictionary. Index is the position of the article among
+// the ones the dictionary has for the word looked up, it picks the article to create a definition from.
+type DictionaryArticle struct {
+	Dict     string
+	Index    int
+	Headword string
+	Text     string
+}
+
+// Lookup looks the word up in the offline dictionaries and returns their articles in the order of the dictionaries.
+// If the word is empty, it returns a ServiceError with status code 400.
+func (s *WordsService) Lookup(ctx context.Context, r LookupRequest) ([]DictionaryArticle, error) {
+	word := collapseSpace(r.Word)
+	if word == "" {
+		return nil, serr.NewServiceError(errors.New("empty word"), http.StatusBadRequest, "word is required")
+	}
+
+	var found []DictionaryArticle
+	for _, d := range s.dicts {
+		if r.Lang != "" && d.Lang() != "" && d.Lang() != r.Lang {
+			continue
+		}
+
+		articles, err := d.Lookup(word)
+		if err != nil {
+			return nil, fmt.Errorf("look up %q in %s: %w", word, d.Name(), err)
+		}
+		for i, a := range articles {
+			found = append(found, DictionaryArticle{Dict: d.Name(), Index: i, Headword: a.Headword, Text: a.Text})
+		}
+	}
+
+	return found, nil
+}
+
+type CreateDefinitionFromArticleRequest struct {
+	WordID int64
+	// Dict and Index pick the article among the ones Lookup finds for the lemma of the word
+	Dict  string
+	Index int
+}
+
+// CreateDefinitionFromArticle creates a definition of the word with the text of an article the dictionary has
+// for the lemma of the word. If the word, the dictionary or the article does not exist, it returns a ServiceError
+// with status code 404. If the dictionary is of another language than the word, it returns a ServiceError with
+// status code 400. Otherwise it fails the same way as CreateDefinition.
+func (s *WordsService) CreateDefinitionFromArticle(ctx context.Context, r CreateDefinitionFromArticleRequest) (int64, error) {
+	var dict dictionary.Source
+	for _, d := range s.dicts {
+		if d.Name() == r.Dict {
+			dict = d
+			break
+		}
+	}
+	if dict == nil {
+		se := serr.NewServiceError(errors.New("unknown dictionary"), http.StatusNotFound, "dictionary not found")
+		se.Env["dict"] = r.Dict
+		return 0, se
+	}
+
+	word, err := s.store.GetWord(ctx, store.GetWordRequest{ID: r.WordID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "word not found")
+			se.Env["word_id"] = fmt.Sprintf("%d", r.WordID)
+			return 0, se
+		}
+
+		return 0, fmt.Errorf("get word: %w", err)
+	}
+	if dict.Lang() != "" && dict.Lang() != word.Lang {
+		se := serr.NewServiceError(errors.New("language mismatch"), http.StatusBadRequest, "dictionary language does not match the word")
+		se.Env["dict"] = r.Dict
+		se.Env["lang"] = string(word.Lang)
+		return 0, se
+	}
+
+	articles, err := dict.Lookup(word.Lemma)
+	if err != nil {
+		return 0, fmt.Errorf("look up %q in %s: %w", word.Lemma, r.Dict, err)
+	}
+	if r.Index < 0 || r.Index >= len(articles) {
+		se := serr.NewServiceError(errors.New("no such article"), http.StatusNotFound, "dictionary article not found")
+		se.Env["dict"] = r.Dict
+		se.Env["word"] = word.Lemma
+		se.Env["index"] = fmt.Sprintf("%d", r.Index)
+		return 0, se
+	}
+
+	return s.CreateDefinition(ctx, CreateDefinitionRequest{
+		WordID: r.WordID,
+		Text:   articles[r.Index].Text,
+		Source: model.SrcDictionary,
+	})
+}
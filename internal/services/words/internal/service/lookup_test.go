@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/dictionary"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSource struct {
+	name     string
+	lang     model.Lang
+	articles map[string][]dictionary.Article
+}
+
+func (m *mockSource) Name() string {
+	return m.name
+}
+
+func (m *mockSource) Lang() model.Lang {
+	return m.lang
+}
+
+func (m *mockSource) Lookup(word string) ([]dictionary.Article, error) {
+	return m.articles[word], nil
+}
+
+func lookupService(st store.DataStore) *WordsService {
+	return NewWordsService(st, WordsServiceConfig{
+		TagsCacheSize: 100,
+		TagsMaxCost:   100,
+		Dictionaries: []dictionary.Source{
+			&mockSource{name: "en-ru", lang: "en", articles: map[string][]dictionary.Article{
+				"run": {{Headword: "run", Text: "бежать"}, {Headword: "run", Text: "пробег"}},
+			}},
+			&mockSource{name: "de-ru", lang: "de", articles: map[string][]dictionary.Article{
+				"run": {{Headword: "run", Text: "not German"}},
+			}},
+			&mockSource{name: "misc", articles: map[string][]dictionary.Article{
+				"run": {{Headword: "to run", Text: "to move fast"}},
+			}},
+		},
+	})
+}
+
+func TestLookup(t *testing.T) {
+	srv := lookupService(&mockStore{})
+
+	found, err := srv.Lookup(context.Background(), LookupRequest{Word: " run ", Lang: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, []DictionaryArticle{
+		{Dict: "en-ru", Index: 0, Headword: "run", Text: "бежать"},
+		{Dict: "en-ru", Index: 1, Headword: "run", Text: "пробег"},
+		{Dict: "misc", Index: 0, Headword: "to run", Text: "to move fast"},
+	}, found)
+
+	found, err = srv.Lookup(context.Background(), LookupRequest{Word: "run"})
+	require.NoError(t, err)
+	assert.Len(t, found, 4)
+
+	found, err = srv.Lookup(context.Background(), LookupRequest{Word: "walk"})
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	_, err = srv.Lookup(context.Background(), LookupRequest{Word: " "})
+	requireStatus(t, err, http.StatusBadRequest)
+}
+
+func TestCreateDefinitionFromArticle(t *testing.T) {
+	var created store.CreateDefinitionRequest
+	srv := lookupService(&mockStore{
+		GetWordFunc: func(ctx context.Context, r store.GetWordRequest) (model.Word, error) {
+			switch r.ID {
+			case 7:
+				return model.Word{ID: 7, Lemma: "run", Lang: "en"}, nil
+			case 8:
+				return model.Word{ID: 8, Lemma: "courir", Lang: "fr"}, nil
+			}
+			return model.Word{}, store.ErrNotFound
+		},
+		CreateDefinitionFunc: func(ctx context.Context, r store.CreateDefinitionRequest) (int64, error) {
+			created = r
+			return 42, nil
+		},
+	})
+
+	id, err := srv.CreateDefinitionFromArticle(context.Background(), CreateDefinitionFromArticleRequest{
+		WordID: 7,
+		Dict:   "en-ru",
+		Index:  1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.Equal(t, store.CreateDefinitionRequest{WordID: 7, Text: "пробег", Source: model.SrcDictionary}, created)
+
+	_, err = srv.CreateDefinitionFromArticle(context.Background(), CreateDefinitionFromArticleRequest{WordID: 7, Dict: "fr-ru"})
+	requireStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.CreateDefinitionFromArticle(context.Background(), CreateDefinitionFromArticleRequest{WordID: 7, Dict: "en-ru", Index: 2})
+	requireStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.CreateDefinitionFromArticle(context.Background(), CreateDefinitionFromArticleRequest{WordID: 9, Dict: "en-ru"})
+	requireStatus(t, err, http.StatusNotFound)
+
+	_, err = srv.CreateDefinitionFromArticle(context.Background(), CreateDefinitionFromArticleRequest{WordID: 8, Dict: "en-ru"})
+	requireStatus(t, err, http.StatusBadRequest)
+}
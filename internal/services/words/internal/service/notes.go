@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/markdown"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+const (
+	// maxNoteLength is the maximum length of a sanitized note in characters
+	maxNoteLength = 2000
+	// defaultMnemonicsLimit is the number of mnemonics listed when no limit is given
+	defaultMnemonicsLimit = 10
+	// maxMnemonicsLimit is the maximum number of mnemonics listed at once
+	maxMnemonicsLimit = 50
+)
+
+// Note is a personal note or mnemonic on a pick, written in the Markdown subset of the markdown package
+type Note struct {
+	ID        int64
+	PickID    int64
+	Body      string
+	Public    bool
+	UpdatedAt time.Time
+	// Revisions are only set in the user data export, the current body first
+	Revisions []NoteRevision
+}
+
+func newNote(n model.Note) Note {
+	return Note{ID: n.ID, PickID: n.PickID, Body: n.Body, Public: n.Public, UpdatedAt: n.UpdatedAt}
+}
+
+// NoteRevision is a body a note had at some point
+type NoteRevision struct {
+	Body      string
+	CreatedAt time.Time
+}
+
+// NoteVote is an upvote the user gave to a mnemonic
+type NoteVote struct {
+	NoteID int64
+	// DefID is the definition the mnemonic was written on
+	DefID     int64
+	CreatedAt time.Time
+}
+
+// Mnemonic is a public note of another user on the same definition
+type Mnemonic struct {
+	ID    int64
+	Body  string
+	Votes int
+	// Voted tells whether the user listing the mnemonics upvoted it
+	Voted bool
+}
+
+type SaveNoteRequest struct {
+	UserID string
+	PickID int64
+	Body   string
+	Public bool
+}
+
+// SaveNote sanitizes the body and creates or replaces the note on a pick of the user. A revision
+// is recorded whenever the body changes. It returns a ServiceError with status code 400 if the note
+// is empty or too long, and 404 if the user has no such pick.
+func (s *WordsService) SaveNote(ctx context.Context, r SaveNoteRequest) (Note, error) {
+	body := markdown.Sanitize(r.Body)
+	if body == "" || utf8.RuneCountInString(body) > maxNoteLength {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "note must be between 1 and %d characters long", maxNoteLength)
+		se.Env["pick_id"] = fmt.Sprintf("%d", r.PickID)
+		return Note{}, se
+	}
+
+	var note model.Note
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		prev, err := tx.GetPickNote(ctx, store.GetPickNoteRequest{UserID: r.UserID, PickID: r.PickID, ForUpdate: true})
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("get pick note: %w", err)
+		}
+
+		note, err = tx.SavePickNote(ctx, store.SavePickNoteRequest{UserID: r.UserID, PickID: r.PickID, Body: body, Public: r.Public})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				se := serr.NewServiceError(err, http.StatusNotFound, "user pick was not found")
+				se.Env["pick_id"] = fmt.Sprintf("%d", r.PickID)
+				return se
+			}
+
+			return fmt.Errorf("save pick note: %w", err)
+		}
+
+		if prev.ID != 0 && prev.Body == body {
+			return nil
+		}
+
+		if _, err := tx.AddNoteRevision(ctx, store.AddNoteRevisionRequest{NoteID: note.ID, Body: body}); err != nil {
+			return fmt.Errorf("add note revision: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Note{}, fmt.Errorf("save note: %w", err)
+	}
+
+	return newNote(note), nil
+}
+
+type GetNoteRequest struct {
+	UserID string
+	PickID int64
+}
+
+// GetNote returns the note on a pick of the user.
+// If there is no such note, it returns a ServiceError with status code 404.
+func (s *WordsService) GetNote(ctx context.Context, r GetNoteRequest) (Note, error) {
+	note, err := s.store.GetPickNote(ctx, store.GetPickNoteRequest{UserID: r.UserID, PickID: r.PickID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return Note{}, noteNotFound(err, r.PickID)
+		}
+
+		return Note{}, fmt.Errorf("get pick note: %w", err)
+	}
+
+	return newNote(note), nil
+}
+
+// DeleteNote deletes the note on a pick of the user together with its history and votes.
+// If there is no such note, it returns a ServiceError with status code 404.
+func (s *WordsService) DeleteNote(ctx context.Context, r GetNoteRequest) error {
+	err := s.store.DeletePickNote(ctx, store.DeletePickNoteRequest{UserID: r.UserID, PickID: r.PickID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return noteNotFound(err, r.PickID)
+		}
+
+		return fmt.Errorf("delete pick note: %w", err)
+	}
+
+	return nil
+}
+
+// ListNoteRevisions lists the bodies the note on a pick of the user had, the current one first.
+// If there is no such note, it returns a ServiceError with status code 404.
+func (s *WordsService) ListNoteRevisions(ctx context.Context, r GetNoteRequest) ([]NoteRevision, error) {
+	// the store lists the revisions of all notes of the user for a zero pick ID
+	if r.PickID <= 0 {
+		return nil, noteNotFound(nil, r.PickID)
+	}
+
+	revisions, err := s.store.ListNoteRevisions(ctx, store.ListNoteRevisionsRequest{UserID: r.UserID, PickID: r.PickID})
+	if err != nil {
+		return nil, fmt.Errorf("list note revisions: %w", err)
+	}
+
+	// every note has at least the revision with its current body
+	if len(revisions) == 0 {
+		return nil, noteNotFound(nil, r.PickID)
+	}
+
+	return fn.Map(revisions, newNoteRevision), nil
+}
+
+func newNoteRevision(rev model.NoteRevision) NoteRevision {
+	return NoteRevision{Body: rev.Body, CreatedAt: rev.CreateAt}
+}
+
+type VoteNoteRequest struct {
+	UserID string
+	NoteID int64
+	// Withdraw removes the upvote of the user instead of adding it
+	Withdraw bool
+}
+
+// VoteNote upvotes a public note of another user or withdraws the upvote, both can be repeated safely.
+// It returns a ServiceError with status code 404 if there is no such public note and 400 if the note
+// belongs to the user.
+func (s *WordsService) VoteNote(ctx context.Context, r VoteNoteRequest) error {
+	note, err := s.store.GetPublicNote(ctx, store.GetPublicNoteRequest{NoteID: r.NoteID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "mnemonic was not found")
+			se.Env["note_id"] = fmt.Sprintf("%d", r.NoteID)
+			return se
+		}
+
+		return fmt.Errorf("get public note: %w", err)
+	}
+
+	if note.UserID == r.UserID {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "users cannot vote for their own notes")
+		se.Env["note_id"] = fmt.Sprintf("%d", r.NoteID)
+		return se
+	}
+
+	vote := store.VoteNoteRequest{UserID: r.UserID, NoteID: r.NoteID}
+	if r.Withdraw {
+		err = s.store.UnvoteNote(ctx, vote)
+	} else {
+		err = s.store.VoteNote(ctx, vote)
+	}
+	if err != nil {
+		return fmt.Errorf("vote note: %w", err)
+	}
+
+	return nil
+}
+
+type ListMnemonicsRequest struct {
+	UserID string
+	DefID  int64
+	// Limit is the number of mnemonics to list, defaultMnemonicsLimit when zero
+	Limit int
+}
+
+// ListMnemonics lists the most upvoted public notes of all users on the definition.
+// It returns a ServiceError with status code 400 if the limit is out of range.
+func (s *WordsService) ListMnemonics(ctx context.Context, r ListMnemonicsRequest) ([]Mnemonic, error) {
+	if r.Limit == 0 {
+		r.Limit = defaultMnemonicsLimit
+	}
+	if r.Limit < 0 || r.Limit > maxMnemonicsLimit {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "limit must be between 1 and %d", maxMnemonicsLimit)
+		se.Env["limit"] = fmt.Sprintf("%d", r.Limit)
+		return nil, se
+	}
+
+	mnemonics, err := s.store.ListMnemonics(ctx, store.ListMnemonicsRequest{UserID: r.UserID, DefID: r.DefID, Limit: r.Limit})
+	if err != nil {
+		return nil, fmt.Errorf("list mnemonics: %w", err)
+	}
+
+	return fn.Map(mnemonics, func(m model.Mnemonic) Mnemonic {
+		return Mnemonic{ID: m.ID, Body: m.Body, Votes: m.Votes, Voted: m.Voted}
+	}), nil
+}
+
+func noteNotFound(err error, pickID int64) error {
+	se := serr.NewServiceError(err, http.StatusNotFound, "note was not found")
+	se.Env["pick_id"] = fmt.Sprintf("%d", pickID)
+	return se
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveNote(t *testing.T) {
+	prev := model.Note{}
+	var saved []store.SavePickNoteRequest
+	var revisions []store.AddNoteRevisionRequest
+	st := &mockStore{
+		GetPickNoteFunc: func(ctx context.Context, r store.GetPickNoteRequest) (model.Note, error) {
+			assert.True(t, r.ForUpdate)
+			if prev.ID == 0 {
+				return model.Note{}, store.ErrNotFound
+			}
+			return prev, nil
+		},
+		SavePickNoteFunc: func(ctx context.Context, r store.SavePickNoteRequest) (model.Note, error) {
+			saved = append(saved, r)
+			prev = model.Note{ID: 7, PickID: r.PickID, UserID: r.UserID, Body: r.Body, Public: r.Public}
+			return prev, nil
+		},
+		AddNoteRevisionFunc: func(ctx context.Context, r store.AddNoteRevisionRequest) (int64, error) {
+			revisions = append(revisions, r)
+			return int64(len(revisions)), nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	note, err := srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: " <b>apple</b> "})
+	require.NoError(t, err)
+	assert.Equal(t, Note{ID: 7, PickID: 1, Body: "&lt;b>apple&lt;/b>"}, note)
+
+	// publishing without changing the body records no revision
+	_, err = srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: "<b>apple</b>", Public: true})
+	require.NoError(t, err)
+
+	_, err = srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: "an *apple* a day", Public: true})
+	require.NoError(t, err)
+
+	assert.Len(t, saved, 3)
+	assert.True(t, saved[1].Public)
+	assert.Equal(t, []store.AddNoteRevisionRequest{
+		{NoteID: 7, Body: "&lt;b>apple&lt;/b>"},
+		{NoteID: 7, Body: "an *apple* a day"},
+	}, revisions)
+}
+
+func TestSaveNote_Errors(t *testing.T) {
+	st := &mockStore{
+		GetPickNoteFunc: func(ctx context.Context, r store.GetPickNoteRequest) (model.Note, error) {
+			return model.Note{}, store.ErrNotFound
+		},
+		SavePickNoteFunc: func(ctx context.Context, r store.SavePickNoteRequest) (model.Note, error) {
+			return model.Note{}, store.ErrNotFound
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: "```\n```"})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	_, err = srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: strings.Repeat("a", maxNoteLength+1)})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	_, err = srv.SaveNote(context.Background(), SaveNoteRequest{UserID: "user-123", PickID: 1, Body: "apple"})
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestListNoteRevisions_NotFound(t *testing.T) {
+	st := &mockStore{
+		ListNoteRevisionsFunc: func(ctx context.Context, r store.ListNoteRevisionsRequest) ([]model.NoteRevision, error) {
+			return nil, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.ListNoteRevisions(context.Background(), GetNoteRequest{UserID: "user-123", PickID: 1})
+	requireStatus(t, err, http.StatusNotFound)
+
+	// a zero pick ID would list the revisions of all notes of the user
+	_, err = NewWordsService(&mockStore{}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100}).
+		ListNoteRevisions(context.Background(), GetNoteRequest{UserID: "user-123"})
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestVoteNote(t *testing.T) {
+	var votes, unvotes []store.VoteNoteRequest
+	st := &mockStore{
+		GetPublicNoteFunc: func(ctx context.Context, r store.GetPublicNoteRequest) (model.Note, error) {
+			if r.NoteID != 7 {
+				return model.Note{}, store.ErrNotFound
+			}
+			return model.Note{ID: 7, UserID: "author", Public: true}, nil
+		},
+		VoteNoteFunc: func(ctx context.Context, r store.VoteNoteRequest) error {
+			votes = append(votes, r)
+			return nil
+		},
+		UnvoteNoteFunc: func(ctx context.Context, r store.VoteNoteRequest) error {
+			unvotes = append(unvotes, r)
+			return nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	require.NoError(t, srv.VoteNote(context.Background(), VoteNoteRequest{UserID: "user-123", NoteID: 7}))
+	require.NoError(t, srv.VoteNote(context.Background(), VoteNoteRequest{UserID: "user-123", NoteID: 7, Withdraw: true}))
+	assert.Equal(t, []store.VoteNoteRequest{{UserID: "user-123", NoteID: 7}}, votes)
+	assert.Equal(t, []store.VoteNoteRequest{{UserID: "user-123", NoteID: 7}}, unvotes)
+
+	requireStatus(t, srv.VoteNote(context.Background(), VoteNoteRequest{UserID: "author", NoteID: 7}), http.StatusBadRequest)
+	requireStatus(t, srv.VoteNote(context.Background(), VoteNoteRequest{UserID: "user-123", NoteID: 8}), http.StatusNotFound)
+}
+
+func TestListMnemonics(t *testing.T) {
+	var limits []int
+	st := &mockStore{
+		ListMnemonicsFunc: func(ctx context.Context, r store.ListMnemonicsRequest) ([]model.Mnemonic, error) {
+			limits = append(limits, r.Limit)
+			return []model.Mnemonic{{Note: model.Note{ID: 7, Body: "An apple a day"}, Votes: 3, Voted: true}}, nil
+		},
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	mnemonics, err := srv.ListMnemonics(context.Background(), ListMnemonicsRequest{UserID: "user-123", DefID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []Mnemonic{{ID: 7, Body: "An apple a day", Votes: 3, Voted: true}}, mnemonics)
+
+	_, err = srv.ListMnemonics(context.Background(), ListMnemonicsRequest{UserID: "user-123", DefID: 1, Limit: maxMnemonicsLimit + 1})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	assert.Equal(t, []int{defaultMnemonicsLimit}, limits)
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
 )
@@ -65,12 +70,20 @@ type tagsStore interface {
 	GetTags(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error)
 }
 
+// defaultTagsCacheTTL is how long tag IDs are cached when the service is not configured otherwise
+const defaultTagsCacheTTL = 30 * time.Second
+
 // tagManager handles retrieval and creation of tags with caching.
+// Tags belong to users, so cached tag IDs are keyed by the user and the tag text.
+// Every instance of the service has a cache of its own and Forget only clears the local one, so after a tag
+// is renamed or deleted other instances may keep resolving its old path to its ID until the entry expires.
+// The TTL bounds how long that lasts.
 type tagManager struct {
 	cache *ristretto.Cache[string, int64]
+	ttl   time.Duration
 }
 
-func newTagManager(maxKeys, maxCost int64) *tagManager {
+func newTagManager(maxKeys, maxCost int64, ttl time.Duration) *tagManager {
 	c, err := ristretto.NewCache(&ristretto.Config[string, int64]{
 		NumCounters: maxKeys * 10,
 		MaxCost:     maxCost,
@@ -80,36 +93,45 @@ func newTagManager(maxKeys, maxCost int64) *tagManager {
 		panic(fmt.Sprintf("failed to create tag manager cache: %v", err))
 	}
 
-	return &tagManager{cache: c}
+	if ttl <= 0 {
+		ttl = defaultTagsCacheTTL
+	}
+
+	return &tagManager{cache: c, ttl: ttl}
 }
 
-// GetOrCreateTags retrieves tag IDs for the given tags, creating any that do not already exist.
-func (tm *tagManager) GetOrCreateTags(ctx context.Context, ts tagsStore, tags []string) (tagSet, error) {
-	existing, missing, err := tm.GetTags(ctx, ts, tags)
+// GetOrCreateTags retrieves tag IDs of the user for the given tags, creating any that do not already exist.
+func (tm *tagManager) GetOrCreateTags(ctx context.Context, ts tagsStore, userID string, tags []string) (tagSet, error) {
+	existing, missing, err := tm.GetTags(ctx, ts, userID, tags)
 	if err != nil {
 		return tagSet{}, fmt.Errorf("get tags: %w", err)
 	}
 
 	if len(missing) > 0 {
-		created, err := ts.CreateTags(ctx, store.CreateTagsRequest{Tags: missing})
+		created, err := ts.CreateTags(ctx, store.CreateTagsRequest{UserID: userID, Tags: missing})
 		if err != nil {
 			return tagSet{}, fmt.Errorf("create tags: %w", err)
 		}
 
 		existing.Merge(created)
-		tm.storeToCache(created)
+		tm.storeToCache(userID, created)
 	}
 
 	return existing, nil
 }
 
-// GetTags retrieves tag IDs for the given tags, returning any that are not found.
-func (tm *tagManager) GetTags(ctx context.Context, ts tagsStore, tags []string) (tagSet, []string, error) {
+// GetTags retrieves tag IDs of the user for the given tags, returning any that are not found.
+// Tags are slash separated paths and are normalized first, empty ones are ignored.
+func (tm *tagManager) GetTags(ctx context.Context, ts tagsStore, userID string, tags []string) (tagSet, []string, error) {
 	result := newEmptyTagSet()
 	missing := newEmptyTagSet()
 
 	for _, tag := range tags {
-		if id, found := tm.cache.Get(tag); found {
+		if tag = model.NormalizeTagPath(tag); tag == "" {
+			continue
+		}
+
+		if id, found := tm.cache.Get(tagCacheKey(userID, tag)); found {
 			result.AddTag(tag, id)
 		} else {
 			missing.AddTag(tag, 0)
@@ -117,12 +139,12 @@ func (tm *tagManager) GetTags(ctx context.Context, ts tagsStore, tags []string)
 	}
 
 	if missing.Len() > 0 {
-		fetched, err := ts.GetTags(ctx, store.GetTagsRequest{Tags: missing.Tags()})
+		fetched, err := ts.GetTags(ctx, store.GetTagsRequest{UserID: userID, Tags: missing.Tags()})
 		if err != nil && !errors.Is(err, store.ErrNotFound) {
 			return newEmptyTagSet(), nil, fmt.Errorf("failed to get tags: %w", err)
 		}
 
-		tm.storeToCache(fetched)
+		tm.storeToCache(userID, fetched)
 		missing.Remove(fetched)
 		result.Merge(fetched)
 	}
@@ -130,8 +152,271 @@ func (tm *tagManager) GetTags(ctx context.Context, ts tagsStore, tags []string)
 	return result, missing.Tags(), nil
 }
 
-func (tm *tagManager) storeToCache(tags model.TagIDMap) {
+// Forget drops the given tags of the user from the cache of this instance after they were renamed or deleted
+func (tm *tagManager) Forget(userID string, tags ...string) {
+	for _, tag := range tags {
+		tm.cache.Del(tagCacheKey(userID, tag))
+	}
+}
+
+func (tm *tagManager) storeToCache(userID string, tags model.TagIDMap) {
 	for tag, id := range tags {
-		tm.cache.Set(tag, id, 1)
+		tm.cache.SetWithTTL(tagCacheKey(userID, tag), id, 1, tm.ttl)
+	}
+}
+
+func tagCacheKey(userID, tag string) string {
+	// user IDs never contain a NUL byte, so keys of different users cannot collide
+	return userID + "\x00" + tag
+}
+
+type Tag struct {
+	ID       int64
+	ParentID int64
+	// Text is the full path of the tag, such as "spanish/verbs"
+	Text  string
+	Picks int
+}
+
+// ListTags lists the tags of the user ordered by path, together with the number of picks they are assigned to
+func (s *WordsService) ListTags(ctx context.Context, userID string) ([]Tag, error) {
+	tags, err := s.store.ListTags(ctx, store.ListTagsRequest{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	return fn.Map(tags, func(t model.TagUsage) Tag {
+		return Tag{ID: t.ID, ParentID: t.ParentID, Text: t.Text, Picks: t.Picks}
+	}), nil
+}
+
+// TagNode is a tag in the tag tree of a user
+type TagNode struct {
+	ID   int64
+	Name string
+	Path string
+	// Picks counts the picks assigned to the tag itself, not to the tags below it
+	Picks    int
+	Children []TagNode
+}
+
+// TagTree returns the tags of the user as a tree, siblings ordered by name
+func (s *WordsService) TagTree(ctx context.Context, userID string) ([]TagNode, error) {
+	tags, err := s.store.ListTags(ctx, store.ListTagsRequest{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	children := make(map[int64][]model.TagUsage)
+	for _, t := range tags {
+		children[t.ParentID] = append(children[t.ParentID], t)
+	}
+
+	var build func(parentID int64) []TagNode
+	build = func(parentID int64) []TagNode {
+		return fn.Map(children[parentID], func(t model.TagUsage) TagNode {
+			return TagNode{
+				ID:       t.ID,
+				Name:     model.TagName(t.Text),
+				Path:     t.Text,
+				Picks:    t.Picks,
+				Children: build(t.ID),
+			}
+		})
+	}
+
+	return build(0), nil
+}
+
+type RenameTagRequest struct {
+	UserID string
+	TagID  int64
+	// Name replaces the last segment of the tag path, tags are moved to another parent with MoveTag
+	Name string
+}
+
+// RenameTag renames a tag of the user together with all tags below it. If the user already has a tag
+// with the new path, it returns a ServiceError with status code 409, merging the tags is done with MergeTags.
+func (s *WordsService) RenameTag(ctx context.Context, r RenameTagRequest) error {
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		return serr.NewServiceError(nil, http.StatusBadRequest, "tag name must not be empty")
+	}
+	if strings.Contains(name, model.TagSeparator) {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "tag name must not contain %q, move the tag instead", model.TagSeparator)
+		se.Env["name"] = name
+		return se
+	}
+
+	return s.moveTag(ctx, r.UserID, r.TagID, func(tx store.DataStore, tag model.Tag) (string, error) {
+		return joinTagPath(model.TagParent(tag.Text), name), nil
+	})
+}
+
+type MoveTagRequest struct {
+	UserID string
+	TagID  int64
+	// ParentID is the new parent of the tag, the tag becomes a top level one when it is zero
+	ParentID int64
+}
+
+// MoveTag moves a tag of the user together with all tags below it under another parent
+// in a single transaction. A tag cannot be moved below itself. The tag and the new parent
+// are locked, so that concurrent moves cannot make either of them a descendant of the other.
+func (s *WordsService) MoveTag(ctx context.Context, r MoveTagRequest) error {
+	return s.moveTag(ctx, r.UserID, r.TagID, func(tx store.DataStore, tag model.Tag) (string, error) {
+		if r.ParentID == 0 {
+			return model.TagName(tag.Text), nil
+		}
+
+		parent, err := tx.GetTag(ctx, store.GetTagRequest{UserID: r.UserID, TagID: r.ParentID, ForUpdate: true})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return "", tagNotFound(err, r.ParentID)
+			}
+
+			return "", fmt.Errorf("get parent tag: %w", err)
+		}
+
+		if parent.ID == tag.ID || model.IsTagDescendant(parent.Text, tag.Text) {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "cannot move a tag below itself")
+			se.Env["tag_id"] = fmt.Sprintf("%d", r.TagID)
+			se.Env["parent_id"] = fmt.Sprintf("%d", r.ParentID)
+			return "", se
+		}
+
+		return joinTagPath(parent.Text, model.TagName(tag.Text)), nil
+	})
+}
+
+// moveTag locks the tag and changes its path to the one newPath returns in the same transaction
+func (s *WordsService) moveTag(ctx context.Context, userID string, tagID int64, newPath func(tx store.DataStore, tag model.Tag) (string, error)) error {
+	var moved []string
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		tag, err := tx.GetTag(ctx, store.GetTagRequest{UserID: userID, TagID: tagID, ForUpdate: true})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return tagNotFound(err, tagID)
+			}
+
+			return fmt.Errorf("get tag: %w", err)
+		}
+
+		path, err := newPath(tx, tag)
+		if err != nil || path == tag.Text {
+			return err
+		}
+
+		moved, err = tx.MoveTag(ctx, store.MoveTagRequest{UserID: userID, TagID: tagID, Path: path})
+		if errors.Is(err, store.ErrExists) {
+			se := serr.NewServiceError(err, http.StatusConflict, "tag already exists")
+			se.Env["tag"] = path
+			return se
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("move tag: %w", err)
+	}
+
+	s.tags.Forget(userID, moved...)
+	return nil
+}
+
+type MergeTagsRequest struct {
+	UserID string
+	FromID int64
+	IntoID int64
+}
+
+// MergeTags assigns all picks of the tag FromID to the tag IntoID, moves the tags below FromID under IntoID
+// and deletes FromID. If either tag does not belong to the user, it returns a ServiceError with status code 404.
+func (s *WordsService) MergeTags(ctx context.Context, r MergeTagsRequest) error {
+	if r.FromID == r.IntoID {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "cannot merge a tag into itself")
+		se.Env["tag_id"] = fmt.Sprintf("%d", r.FromID)
+		return se
+	}
+
+	var merged []string
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		from, err := tx.GetTag(ctx, store.GetTagRequest{UserID: r.UserID, TagID: r.FromID})
+		if err != nil {
+			return err
+		}
+		into, err := tx.GetTag(ctx, store.GetTagRequest{UserID: r.UserID, TagID: r.IntoID})
+		if err != nil {
+			return err
+		}
+
+		if model.IsTagDescendant(into.Text, from.Text) {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "cannot merge a tag into a tag below it")
+			se.Env["from_id"] = fmt.Sprintf("%d", r.FromID)
+			se.Env["into_id"] = fmt.Sprintf("%d", r.IntoID)
+			return se
+		}
+
+		merged, err = tx.MergeTags(ctx, store.MergeTagsRequest{
+			UserID: r.UserID,
+			FromID: r.FromID,
+			IntoID: r.IntoID,
+		})
+		if errors.Is(err, store.ErrExists) {
+			se := serr.NewServiceError(err, http.StatusConflict, "both tags have a child with the same name")
+			se.Env["from_id"] = fmt.Sprintf("%d", r.FromID)
+			se.Env["into_id"] = fmt.Sprintf("%d", r.IntoID)
+			return se
+		}
+
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "tag not found")
+			se.Env["from_id"] = fmt.Sprintf("%d", r.FromID)
+			se.Env["into_id"] = fmt.Sprintf("%d", r.IntoID)
+			return se
+		}
+
+		return fmt.Errorf("merge tags: %w", err)
 	}
+
+	s.tags.Forget(r.UserID, merged...)
+	return nil
+}
+
+type DeleteTagRequest struct {
+	UserID string
+	TagID  int64
+}
+
+// DeleteTag deletes a tag of the user together with all tags below it and removes them from all
+// of the user's picks. If the tag does not exist, it returns a ServiceError with status code 404.
+func (s *WordsService) DeleteTag(ctx context.Context, r DeleteTagRequest) error {
+	deleted, err := s.store.DeleteTag(ctx, store.DeleteTagRequest{UserID: r.UserID, TagID: r.TagID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return tagNotFound(err, r.TagID)
+		}
+
+		return fmt.Errorf("delete tag: %w", err)
+	}
+
+	s.tags.Forget(r.UserID, deleted...)
+	return nil
+}
+
+func tagNotFound(err error, tagID int64) error {
+	se := serr.NewServiceError(err, http.StatusNotFound, "tag not found")
+	se.Env["tag_id"] = fmt.Sprintf("%d", tagID)
+	return se
+}
+
+func joinTagPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + model.TagSeparator + name
 }
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTagsTestService(st *mockStore) *WordsService {
+	return NewWordsService(st, WordsServiceConfig{
+		TagsCacheSize: 100,
+		TagsMaxCost:   100,
+	})
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var se *serr.ServiceError
+	require.ErrorAs(t, err, &se)
+	require.Equal(t, status, se.StatusCode)
+}
+
+func TestTagManager_PerUser(t *testing.T) {
+	ids := map[string]model.TagIDMap{
+		"user-1": {"food": 1},
+		"user-2": {"food": 2},
+	}
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			return ids[r.UserID], nil
+		},
+	}
+	tm := newTagManager(100, 100, time.Minute)
+
+	for _, userID := range []string{"user-1", "user-2", "user-1"} {
+		tags, missing, err := tm.GetTags(context.Background(), st, userID, []string{"food"})
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, []int64{ids[userID]["food"]}, tags.IDs())
+		tm.cache.Wait()
+	}
+}
+
+func TestTagManager_Expires(t *testing.T) {
+	calls := 0
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			calls++
+			return model.TagIDMap{"food": 1}, nil
+		},
+	}
+	// another instance may rename the tag, the cached ID is used only until it expires
+	tm := newTagManager(100, 100, 50*time.Millisecond)
+
+	for range 2 {
+		_, _, err := tm.GetTags(context.Background(), st, "user-1", []string{"food"})
+		require.NoError(t, err)
+		tm.cache.Wait()
+	}
+	assert.Equal(t, 1, calls)
+
+	time.Sleep(100 * time.Millisecond)
+	_, _, err := tm.GetTags(context.Background(), st, "user-1", []string{"food"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestListTags(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		ListTagsFunc: func(ctx context.Context, r store.ListTagsRequest) ([]model.TagUsage, error) {
+			assert.Equal(t, "user-1", r.UserID)
+			return []model.TagUsage{{Tag: model.Tag{ID: 1, Text: "food"}, Picks: 3}}, nil
+		},
+	})
+
+	tags, err := srv.ListTags(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []Tag{{ID: 1, Text: "food", Picks: 3}}, tags)
+}
+
+func TestTagTree(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		ListTagsFunc: func(ctx context.Context, r store.ListTagsRequest) ([]model.TagUsage, error) {
+			return []model.TagUsage{
+				{Tag: model.Tag{ID: 1, Text: "spanish"}, Picks: 1},
+				{Tag: model.Tag{ID: 2, Text: "spanish/verbs", ParentID: 1}, Picks: 2},
+				{Tag: model.Tag{ID: 3, Text: "spanish/verbs/irregular", ParentID: 2}, Picks: 3},
+				{Tag: model.Tag{ID: 4, Text: "travel"}},
+			}, nil
+		},
+	})
+
+	tree, err := srv.TagTree(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []TagNode{
+		{ID: 1, Name: "spanish", Path: "spanish", Picks: 1, Children: []TagNode{
+			{ID: 2, Name: "verbs", Path: "spanish/verbs", Picks: 2, Children: []TagNode{
+				{ID: 3, Name: "irregular", Path: "spanish/verbs/irregular", Picks: 3},
+			}},
+		}},
+		{ID: 4, Name: "travel", Path: "travel"},
+	}, tree)
+}
+
+func TestTagManager_NormalizesPaths(t *testing.T) {
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			assert.Equal(t, []string{"spanish/verbs"}, r.Tags)
+			return model.TagIDMap{"spanish/verbs": 1}, nil
+		},
+	}
+	tm := newTagManager(100, 100, time.Minute)
+
+	tags, missing, err := tm.GetTags(context.Background(), st, "user-1", []string{" spanish / verbs/ ", "/"})
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, []int64{1}, tags.IDs())
+}
+
+func TestRenameTag(t *testing.T) {
+	lookups := 0
+	st := &mockStore{
+		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
+			lookups++
+			return model.TagIDMap{"spanish/verbs": 2}, nil
+		},
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			return model.Tag{ID: 2, Text: "spanish/verbs", ParentID: 1}, nil
+		},
+		MoveTagFunc: func(ctx context.Context, r store.MoveTagRequest) ([]string, error) {
+			assert.Equal(t, store.MoveTagRequest{UserID: "user-1", TagID: 2, Path: "spanish/words"}, r)
+			return []string{"spanish/verbs"}, nil
+		},
+	}
+	srv := newTagsTestService(st)
+
+	_, _, err := srv.tags.GetTags(context.Background(), st, "user-1", []string{"spanish/verbs"})
+	require.NoError(t, err)
+	srv.tags.cache.Wait()
+
+	err = srv.RenameTag(context.Background(), RenameTagRequest{UserID: "user-1", TagID: 2, Name: " words "})
+	require.NoError(t, err)
+
+	// the old path is looked up again instead of resolving to the renamed tag
+	_, _, err = srv.tags.GetTags(context.Background(), st, "user-1", []string{"spanish/verbs"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+}
+
+func TestRenameTag_Invalid(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			return model.Tag{ID: 1, Text: "food"}, nil
+		},
+		MoveTagFunc: func(ctx context.Context, r store.MoveTagRequest) ([]string, error) {
+			return nil, store.ErrExists
+		},
+	})
+
+	err := srv.RenameTag(context.Background(), RenameTagRequest{UserID: "user-1", TagID: 1, Name: "  "})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.RenameTag(context.Background(), RenameTagRequest{UserID: "user-1", TagID: 1, Name: "food/meals"})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.RenameTag(context.Background(), RenameTagRequest{UserID: "user-1", TagID: 1, Name: "meals"})
+	requireStatus(t, err, http.StatusConflict)
+}
+
+func TestMoveTag(t *testing.T) {
+	tags := map[int64]model.Tag{
+		1: {ID: 1, Text: "spanish"},
+		2: {ID: 2, Text: "spanish/verbs", ParentID: 1},
+		3: {ID: 3, Text: "grammar"},
+	}
+	var moved []store.MoveTagRequest
+	srv := newTagsTestService(&mockStore{
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			tag, ok := tags[r.TagID]
+			if !ok {
+				return model.Tag{}, store.ErrNotFound
+			}
+			return tag, nil
+		},
+		MoveTagFunc: func(ctx context.Context, r store.MoveTagRequest) ([]string, error) {
+			moved = append(moved, r)
+			return nil, nil
+		},
+	})
+
+	err := srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 2, ParentID: 3})
+	require.NoError(t, err)
+	err = srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []store.MoveTagRequest{
+		{UserID: "user-1", TagID: 2, Path: "grammar/verbs"},
+		{UserID: "user-1", TagID: 2, Path: "verbs"},
+	}, moved)
+
+	err = srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 1, ParentID: 2})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 1, ParentID: 1})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 1, ParentID: 42})
+	requireStatus(t, err, http.StatusNotFound)
+	var se *serr.ServiceError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, "42", se.Env["tag_id"])
+}
+
+// txStore runs transactions on tx, so that tests can tell reads in a transaction from the ones outside
+type txStore struct {
+	*mockStore
+	tx store.DataStore
+}
+
+func (s *txStore) WithTx(ctx context.Context, fn func(tx store.DataStore) error) error {
+	return fn(s.tx)
+}
+
+func TestMoveTag_LocksInTransaction(t *testing.T) {
+	tags := map[int64]model.Tag{
+		1: {ID: 1, Text: "spanish"},
+		2: {ID: 2, Text: "grammar"},
+	}
+	var locked []int64
+	tx := &mockStore{
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			assert.True(t, r.ForUpdate)
+			assert.Equal(t, "user-1", r.UserID)
+			locked = append(locked, r.TagID)
+
+			tag, ok := tags[r.TagID]
+			if !ok {
+				return model.Tag{}, store.ErrNotFound
+			}
+			return tag, nil
+		},
+		MoveTagFunc: func(ctx context.Context, r store.MoveTagRequest) ([]string, error) {
+			return nil, nil
+		},
+	}
+	st := &txStore{
+		mockStore: &mockStore{
+			GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+				t.Fatal("tags must be read in the transaction")
+				return model.Tag{}, nil
+			},
+		},
+		tx: tx,
+	}
+	srv := NewWordsService(st, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	err := srv.MoveTag(context.Background(), MoveTagRequest{UserID: "user-1", TagID: 1, ParentID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, locked)
+}
+
+func TestMergeTags(t *testing.T) {
+	var merged store.MergeTagsRequest
+	srv := newTagsTestService(&mockStore{
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			return model.Tag{ID: r.TagID, Text: map[int64]string{1: "food", 2: "meals"}[r.TagID]}, nil
+		},
+		MergeTagsFunc: func(ctx context.Context, r store.MergeTagsRequest) ([]string, error) {
+			merged = r
+			return []string{"food"}, nil
+		},
+	})
+
+	err := srv.MergeTags(context.Background(), MergeTagsRequest{UserID: "user-1", FromID: 1, IntoID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, store.MergeTagsRequest{UserID: "user-1", FromID: 1, IntoID: 2}, merged)
+}
+
+func TestMergeTags_Invalid(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		GetTagFunc: func(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+			switch r.TagID {
+			case 1:
+				return model.Tag{ID: 1, Text: "food"}, nil
+			case 2:
+				return model.Tag{ID: 2, Text: "food/fruit", ParentID: 1}, nil
+			}
+			return model.Tag{}, store.ErrNotFound
+		},
+	})
+
+	err := srv.MergeTags(context.Background(), MergeTagsRequest{UserID: "user-1", FromID: 1, IntoID: 1})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.MergeTags(context.Background(), MergeTagsRequest{UserID: "user-1", FromID: 1, IntoID: 2})
+	requireStatus(t, err, http.StatusBadRequest)
+
+	err = srv.MergeTags(context.Background(), MergeTagsRequest{UserID: "user-1", FromID: 1, IntoID: 3})
+	requireStatus(t, err, http.StatusNotFound)
+}
+
+func TestDeleteTag_NotFound(t *testing.T) {
+	srv := newTagsTestService(&mockStore{
+		DeleteTagFunc: func(ctx context.Context, r store.DeleteTagRequest) ([]string, error) {
+			return nil, store.ErrNotFound
+		},
+	})
+
+	err := srv.DeleteTag(context.Background(), DeleteTagRequest{UserID: "user-1", TagID: 1})
+	requireStatus(t, err, http.StatusNotFound)
+	assert.Equal(t, "1", err.(*serr.ServiceError).Env["tag_id"])
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+)
+
+// exportPageSize is the number of picks read at once while exporting user data
+const exportPageSize = 500
+
+// UserData holds everything the words service stores about a user
+type UserData struct {
+	UserID     string
+	Picks      []UserPick
+	Notes      []Note
+	Votes      []NoteVote
+	TagFilters []TagFilter
+	ImportJobs []model.ImportJob
+}
+
+// ExportUserData collects all picks of the user together with their words, definitions and tags,
+// the notes the user wrote on them with their revisions, the upvotes the user gave to mnemonics,
+// the tag filters the user saved and the decks the user imported
+func (s *WordsService) ExportUserData(ctx context.Context, userID string) (UserData, error) {
+	data := UserData{UserID: userID, Picks: []UserPick{}}
+
+	notes, err := s.store.ListUserNotes(ctx, store.ListUserNotesRequest{UserID: userID})
+	if err != nil {
+		return UserData{}, fmt.Errorf("list user notes: %w", err)
+	}
+	revisions, err := s.store.ListNoteRevisions(ctx, store.ListNoteRevisionsRequest{UserID: userID})
+	if err != nil {
+		return UserData{}, fmt.Errorf("list note revisions: %w", err)
+	}
+	byNote := make(map[int64][]NoteRevision)
+	for _, rev := range revisions {
+		byNote[rev.NoteID] = append(byNote[rev.NoteID], newNoteRevision(rev))
+	}
+	data.Notes = fn.Map(notes, func(n model.Note) Note {
+		note := newNote(n)
+		note.Revisions = byNote[n.ID]
+		return note
+	})
+
+	votes, err := s.store.ListUserVotes(ctx, store.ListUserVotesRequest{UserID: userID})
+	if err != nil {
+		return UserData{}, fmt.Errorf("list user votes: %w", err)
+	}
+	data.Votes = fn.Map(votes, func(v model.NoteVote) NoteVote {
+		return NoteVote{NoteID: v.NoteID, DefID: v.DefID, CreatedAt: v.CreateAt}
+	})
+
+	if data.TagFilters, err = s.ListTagFilters(ctx, userID); err != nil {
+		return UserData{}, err
+	}
+
+	jobs, err := s.store.ListImportJobs(ctx, store.ListImportJobsRequest{UserID: userID})
+	if err != nil {
+		return UserData{}, fmt.Errorf("list import jobs: %w", err)
+	}
+	data.ImportJobs = fn.Map(jobs, markStaleImport)
+
+	var cursor store.GetUserPicksCursor
+	for {
+		resp, err := s.store.GetUserPicks(ctx, store.GetUserPicksRequest{
+			UserID:   userID,
+			Cursor:   cursor,
+			PageSize: exportPageSize,
+		})
+		if err != nil {
+			return UserData{}, fmt.Errorf("get user picks: %w", err)
+		}
+
+		data.Picks = append(data.Picks, fn.Map(resp.Picks, newUserPick)...)
+
+		if resp.NextCursor == nil {
+			return data, nil
+		}
+		cursor = *resp.NextCursor
+	}
+}
+
+// DeleteUserData removes all picks, tags and notes of the user as well as the votes the user gave.
+// Words and definitions are shared between users and are kept. Everything is deleted in one transaction,
+// and deleting the data of an unknown user succeeds so that the auth service can safely retry.
+func (s *WordsService) DeleteUserData(ctx context.Context, userID string) error {
+	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
+		_, err := tx.DeleteUserPicks(ctx, store.DeleteUserPicksRequest{UserID: userID})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("delete user picks: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUserData(t *testing.T) {
+	var cursors []int64
+	srv := NewWordsService(&mockStore{
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			cursors = append(cursors, r.Cursor.LastPickID)
+
+			if r.Cursor.LastPickID == 0 {
+				return store.GetUserPicksResponse{
+					Picks: []model.UserPick{{
+						ID:         1,
+						UserID:     "user-123",
+						Word:       model.Word{Lemma: "apple", Lang: "en", Class: model.Noun},
+						Definition: model.Definition{Text: "A round fruit."},
+						Tags:       []model.Tag{{Text: "fruit"}},
+						// personalized by the user
+						DefOverride: "A crunchy fruit.",
+						ImageID:     5,
+						ImageURL:    "https://example.com/green-apple.png",
+						Contexts: []model.PickContext{{
+							ID:          11,
+							PickID:      1,
+							Sentence:    "She ate an apple.",
+							SourceTitle: "Stories",
+							SourceURL:   "https://example.com/stories",
+						}},
+					}},
+					NextCursor: &store.GetUserPicksCursor{LastPickID: 1},
+				}, nil
+			}
+
+			return store.GetUserPicksResponse{
+				Picks: []model.UserPick{{
+					ID:         2,
+					UserID:     "user-123",
+					Word:       model.Word{Lemma: "pear", Lang: "en", Class: model.Noun},
+					Definition: model.Definition{Text: "A sweet fruit."},
+				}},
+			}, nil
+		},
+		ListUserNotesFunc: func(ctx context.Context, r store.ListUserNotesRequest) ([]model.Note, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			return []model.Note{{ID: 7, PickID: 1, UserID: "user-123", Body: "An apple a day"}}, nil
+		},
+		ListNoteRevisionsFunc: func(ctx context.Context, r store.ListNoteRevisionsRequest) ([]model.NoteRevision, error) {
+			assert.Equal(t, store.ListNoteRevisionsRequest{UserID: "user-123"}, r)
+			return []model.NoteRevision{
+				{ID: 2, NoteID: 7, Body: "An apple a day"},
+				{ID: 1, NoteID: 7, Body: "An apple"},
+			}, nil
+		},
+		ListUserVotesFunc: func(ctx context.Context, r store.ListUserVotesRequest) ([]model.NoteVote, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			return []model.NoteVote{{NoteID: 9, UserID: "user-123", DefID: 4}}, nil
+		},
+		ListTagFiltersFunc: func(ctx context.Context, r store.ListTagFiltersRequest) ([]model.TagFilter, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			return []model.TagFilter{{ID: 3, UserID: "user-123", Name: "fruits", Expr: "fruit AND NOT exotic"}}, nil
+		},
+		ListImportJobsFunc: func(ctx context.Context, r store.ListImportJobsRequest) ([]model.ImportJob, error) {
+			assert.Equal(t, "user-123", r.UserID)
+			return []model.ImportJob{
+				{ID: 4, UserID: "user-123", Status: model.ImportDone, Total: 2, Cards: 2, Created: 1, Failed: 1,
+					Errors: []model.ImportJobError{{Index: 1, Msg: "lemma is required"}}},
+				// the instance of the service that ran the job stopped long ago
+				{ID: 5, UserID: "user-123", Status: model.ImportRunning, Total: 3,
+					Model: model.Model{UpdatedAt: time.Now().Add(-time.Hour)}},
+			}, nil
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	data, err := srv.ExportUserData(context.Background(), "user-123")
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{0, 1}, cursors)
+	assert.Equal(t, "user-123", data.UserID)
+	require.Len(t, data.Picks, 2)
+	assert.Equal(t, "apple", data.Picks[0].Word)
+	assert.Equal(t, []string{"fruit"}, data.Picks[0].Tags)
+	assert.Equal(t, "A crunchy fruit.", data.Picks[0].Def)
+	assert.Equal(t, "A round fruit.", data.Picks[0].OriginalDef)
+	assert.Equal(t, "https://example.com/green-apple.png", data.Picks[0].ImageURL)
+	assert.True(t, data.Picks[0].Personalized)
+	assert.False(t, data.Picks[1].Personalized)
+	require.Len(t, data.Picks[0].Contexts, 1)
+	assert.Equal(t, "She ate an apple.", data.Picks[0].Contexts[0].Sentence)
+	assert.Equal(t, "Stories", data.Picks[0].Contexts[0].SourceTitle)
+	assert.Equal(t, "https://example.com/stories", data.Picks[0].Contexts[0].SourceURL)
+	assert.Empty(t, data.Picks[1].Contexts)
+	assert.Equal(t, "pear", data.Picks[1].Word)
+	assert.Equal(t, []Note{{
+		ID:        7,
+		PickID:    1,
+		Body:      "An apple a day",
+		Revisions: []NoteRevision{{Body: "An apple a day"}, {Body: "An apple"}},
+	}}, data.Notes)
+	assert.Equal(t, []NoteVote{{NoteID: 9, DefID: 4}}, data.Votes)
+	assert.Equal(t, []TagFilter{{Name: "fruits", Expr: "fruit AND NOT exotic"}}, data.TagFilters)
+	require.Len(t, data.ImportJobs, 2)
+	assert.Equal(t, model.ImportDone, data.ImportJobs[0].Status)
+	assert.Equal(t, []model.ImportJobError{{Index: 1, Msg: "lemma is required"}}, data.ImportJobs[0].Errors)
+	assert.Equal(t, model.ImportFailed, data.ImportJobs[1].Status)
+}
+
+// txMockStore runs the functions passed to WithTx against the tx store, so that tests can tell
+// which calls are made in the transaction
+type txMockStore struct {
+	*mockStore
+	tx *mockStore
+}
+
+func (m *txMockStore) WithTx(ctx context.Context, fn func(tx store.DataStore) error) error {
+	return fn(m.tx)
+}
+
+func TestDeleteUserData(t *testing.T) {
+	var deleted []string
+	srv := NewWordsService(&txMockStore{
+		mockStore: &mockStore{},
+		tx: &mockStore{
+			DeleteUserPicksFunc: func(ctx context.Context, r store.DeleteUserPicksRequest) (int64, error) {
+				deleted = append(deleted, r.UserID)
+				return 0, nil
+			},
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	require.NoError(t, srv.DeleteUserData(context.Background(), "user-123"))
+	assert.Equal(t, []string{"user-123"}, deleted)
+}
@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/cursor"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/dictionary"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/store"
@@ -17,19 +22,55 @@ import (
 
 // WordsService provides access to the global word list and related operations
 type WordsService struct {
-	store store.DataStore
-	tags  *tagManager
+	store   store.DataStore
+	tags    *tagManager
+	cursors *cursor.Codec
+	dicts   []dictionary.Source
+	// jobs tracks the imports running in the background, which stop when jobsCtx is cancelled by Shutdown
+	jobs     sync.WaitGroup
+	jobsCtx  context.Context
+	stopJobs context.CancelFunc
 }
 
 type WordsServiceConfig struct {
 	TagsCacheSize int64
 	TagsMaxCost   int64
+	// TagsCacheTTL is how long other instances may resolve renamed and deleted tags, 30 seconds when zero
+	TagsCacheTTL time.Duration
+	// CursorSecret signs pagination cursors, it must be shared by all instances of the service
+	CursorSecret []byte
+	// Dictionaries are the offline dictionaries words are looked up in, their names must be unique
+	Dictionaries []dictionary.Source
 }
 
 func NewWordsService(store store.DataStore, cfg WordsServiceConfig) *WordsService {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
 	return &WordsService{
-		store: store,
-		tags:  newTagManager(cfg.TagsCacheSize, cfg.TagsMaxCost),
+		store:    store,
+		tags:     newTagManager(cfg.TagsCacheSize, cfg.TagsMaxCost, cfg.TagsCacheTTL),
+		cursors:  cursor.NewCodec(cfg.CursorSecret),
+		dicts:    cfg.Dictionaries,
+		jobsCtx:  jobsCtx,
+		stopJobs: stopJobs,
+	}
+}
+
+// Shutdown stops the imports running in the background and waits until they saved their state,
+// they are reported as failed. It returns the error of ctx when it is done before them.
+func (s *WordsService) Shutdown(ctx context.Context) error {
+	s.stopJobs()
+
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -82,47 +123,96 @@ type PickWoardRequest struct {
 	WordID int64
 	DefID  int64
 	Tags   []string
+	// Context is where the user came across the word, it is optional
+	Context PickContextRequest
 }
 
 // PickWord allows a user to pick a word definition for learning. If the pick already exists,
-// it returns a ServiceError with status code 409.
+// it returns a ServiceError with status code 409, more contexts can be added with AddPickContext.
+// It returns a ServiceError with status code 400 for invalid contexts and 404 for unknown definitions.
 func (s *WordsService) PickWord(ctx context.Context, r PickWoardRequest) (int64, error) {
+	r, err := cleanPickWordRequest(r)
+	if err != nil {
+		return 0, err
+	}
+
 	var pickID int64
-	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
-		tags, err := s.tags.GetOrCreateTags(ctx, tx, r.Tags)
-		if err != nil {
-			return fmt.Errorf("get or create tags: %w", err)
-		}
+	err = s.store.WithTx(ctx, func(tx store.DataStore) error {
+		pickID, err = s.pickWord(ctx, tx, r)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("pick word: %w", err)
+	}
 
-		pickID, err = tx.CreateUserPick(ctx, store.CreateUserPickRequest{
-			UserID: r.UserID,
-			DefID:  r.DefID,
-		})
-		if err != nil {
-			if errors.Is(err, store.ErrExists) {
-				se := serr.NewServiceError(err, http.StatusConflict, "user pick already exists")
-				se.Env["user_id"] = r.UserID
-				se.Env["word_id"] = fmt.Sprintf("%d", r.WordID)
-				se.Env["def_id"] = fmt.Sprintf("%d", r.DefID)
-				return se
-			}
+	return pickID, nil
+}
 
-			return fmt.Errorf("create user pick: %w", err)
-		}
+// cleanPickWordRequest checks and normalizes the context of the request, see cleanPickContext
+func cleanPickWordRequest(r PickWoardRequest) (PickWoardRequest, error) {
+	if r.Context.empty() {
+		return r, nil
+	}
 
-		err = tx.AddTags(ctx, store.AddTagsRequest{
-			PickID: pickID,
-			TagIDs: tags.IDs(),
-		})
-		if err != nil {
-			return fmt.Errorf("add tags to pick: %w", err)
+	c, err := cleanPickContext(r.Context)
+	if err != nil {
+		return r, err
+	}
+	r.Context = c
+
+	return r, nil
+}
+
+// pickWord creates the pick of a request cleaned by cleanPickWordRequest in the transaction
+func (s *WordsService) pickWord(ctx context.Context, tx store.DataStore, r PickWoardRequest) (int64, error) {
+	tags, err := s.tags.GetOrCreateTags(ctx, tx, r.UserID, r.Tags)
+	if err != nil {
+		return 0, fmt.Errorf("get or create tags: %w", err)
+	}
+
+	pickID, err := tx.CreateUserPick(ctx, store.CreateUserPickRequest{
+		UserID: r.UserID,
+		DefID:  r.DefID,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrExists) {
+			se := serr.NewServiceError(err, http.StatusConflict, "user pick already exists")
+			se.Env["user_id"] = r.UserID
+			se.Env["word_id"] = fmt.Sprintf("%d", r.WordID)
+			se.Env["def_id"] = fmt.Sprintf("%d", r.DefID)
+			return 0, se
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "definition was not found")
+			se.Env["def_id"] = fmt.Sprintf("%d", r.DefID)
+			return 0, se
 		}
 
-		return nil
+		return 0, fmt.Errorf("create user pick: %w", err)
+	}
+
+	err = tx.AddTags(ctx, store.AddTagsRequest{
+		UserID: r.UserID,
+		PickID: pickID,
+		TagIDs: tags.IDs(),
 	})
+	if err != nil {
+		return 0, fmt.Errorf("add tags to pick: %w", err)
+	}
+
+	if r.Context.empty() {
+		return pickID, nil
+	}
 
+	_, err = tx.AddPickContext(ctx, store.AddPickContextRequest{
+		UserID:      r.UserID,
+		PickID:      pickID,
+		Sentence:    r.Context.Sentence,
+		SourceTitle: r.Context.SourceTitle,
+		SourceURL:   r.Context.SourceURL,
+	})
 	if err != nil {
-		return 0, fmt.Errorf("pick word: %w", err)
+		return 0, fmt.Errorf("add pick context: %w", err)
 	}
 
 	return pickID, nil
@@ -132,95 +222,258 @@ type GetUserPicksRequest struct {
 	UserID      string
 	WithTags    []string
 	WithoutTags []string
-	NextCursor  string
-	PageSize    int
+	// Filter is a tag expression such as "(verbs OR nouns) AND NOT mastered", see tagexpr.Parse
+	Filter string
+	// SavedFilter is the name of a saved tag filter of the user, it cannot be combined with Filter
+	SavedFilter string
+	// Langs restricts the picks to words in these languages, all languages are returned when empty
+	Langs []model.Lang
+	// Classes restricts the picks to words of these classes, all classes are returned when empty
+	Classes []model.WordClass
+	// Statuses restricts the picks to these learning states, picks in any state are returned when empty
+	Statuses []model.PickStatus
+	// CreatedAfter and CreatedBefore bound the creation time of the picks, zero values leave the range open
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// SortBy orders the picks by the field, they are listed in the order they were picked when empty
+	SortBy     model.PickSort
+	SortDesc   bool
+	NextCursor string
+	PageSize   int
 }
 
 type GetUserPicksResponse struct {
 	Picks      []UserPick
 	NextCursor string
+	// Total is the number of picks matching the filters across all pages
+	Total  int
+	Facets PickFacets
+}
+
+// PickFacets counts the picks matching the filters by the tags assigned to them, their language and word class
+type PickFacets struct {
+	Tags    map[string]int
+	Langs   map[model.Lang]int
+	Classes map[model.WordClass]int
 }
 
+// UserPick is a pick as the user sees it. When the user replaced the text of the shared definition,
+// Def holds the personal text and OriginalDef the shared one. Personalized tells whether the user
+// replaced the definition text or chose another image of the definition.
 type UserPick struct {
-	ID     int64
-	UserID string
-	Word   string
-	Lang   model.Lang
-	Class  model.WordClass
-	Def    string
-	Tags   []string
+	ID           int64
+	UserID       string
+	Word         string
+	Lang         model.Lang
+	Class        model.WordClass
+	Def          string
+	OriginalDef  string
+	ImageURL     string
+	Personalized bool
+	Tags         []string
+	Contexts     []PickContext
+	Status       model.PickStatus
+	CreatedAt    time.Time
+	NextReviewAt *time.Time
+}
+
+func newUserPick(pick model.UserPick) UserPick {
+	p := UserPick{
+		ID:           pick.ID,
+		UserID:       pick.UserID,
+		Word:         pick.Word.Lemma,
+		Lang:         pick.Word.Lang,
+		Class:        pick.Word.Class,
+		Def:          pick.Definition.Text,
+		ImageURL:     pick.ImageURL,
+		Personalized: pick.DefOverride != "" || pick.ImageID != 0,
+		Tags:         fn.Map(pick.Tags, func(tag model.Tag) string { return tag.Text }),
+		Contexts:     fn.Map(pick.Contexts, func(c model.PickContext) PickContext { return newPickContext(c, pick.Word.Lemma) }),
+		Status:       pick.Status,
+		CreatedAt:    pick.CreateAt,
+		NextReviewAt: pick.NextReviewAt,
+	}
+	if pick.DefOverride != "" {
+		p.OriginalDef, p.Def = p.Def, pick.DefOverride
+	}
+
+	return p
+}
+
+const (
+	// defaultPicksPageSize is the number of picks listed at once when the request sets no page size
+	defaultPicksPageSize = 20
+	// maxPicksPageSize is the largest page size of a picks listing
+	maxPicksPageSize = 100
+)
+
+var pickSorts = []model.PickSort{
+	model.SortByCreated,
+	model.SortByLemma,
+	model.SortByLang,
+	model.SortByClass,
+	model.SortByRarity,
+	model.SortByNextReview,
 }
 
-// GetUserPicks retrieves a paginated list of a user's picked words, optionally filtered by tags.
+// GetUserPicks retrieves a sorted, paginated list of a user's picked words together with the number of
+// picks matching the filters. Without a page size defaultPicksPageSize picks are listed. Invalid filters
+// and page sizes outside 1..maxPicksPageSize result in a ServiceError with status code 400.
 func (s *WordsService) GetUserPicks(ctx context.Context, r GetUserPicksRequest) (resp GetUserPicksResponse, err error) {
-	withTags, missing, err := s.tags.GetTags(ctx, s.store, r.WithTags)
-	if err != nil {
-		err = fmt.Errorf("get with-tags: %w", err)
+	pageSize := r.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPicksPageSize
+	}
+	if pageSize < 0 || pageSize > maxPicksPageSize {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "page_size must be between 1 and %d", maxPicksPageSize)
+		se.Env["page_size"] = fmt.Sprintf("%d", r.PageSize)
+		err = se
 		return
 	}
-	if len(missing) > 0 {
-		// No picks can match if some of the requested tags do not exist
+
+	if r.SortBy != "" && !slices.Contains(pickSorts, r.SortBy) {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown sort field")
+		se.Env["sort"] = string(r.SortBy)
+		err = se
+		return
+	}
+
+	req, ok, err := s.picksFilter(ctx, r)
+	if err != nil || !ok {
 		return
 	}
 
-	withoutTags, _, err := s.tags.GetTags(ctx, s.store, r.WithoutTags)
+	filterHash, err := picksFilterHash(r)
 	if err != nil {
-		err = fmt.Errorf("get without-tags: %w", err)
+		err = fmt.Errorf("hash filters: %w", err)
 		return
 	}
 
-	cursor := store.GetUserPicksCursor{}
+	var after store.GetUserPicksCursor
 	if r.NextCursor != "" {
-		cursor, err = decodeCursor[store.GetUserPicksCursor](r.NextCursor)
-		if err != nil {
-			se := serr.NewServiceError(err, http.StatusBadRequest, "invalid pagination cursor")
-			se.Env["cursor"] = r.NextCursor
-			err = se
+		if err = s.cursors.Decode(r.NextCursor, filterHash, &after); err != nil {
+			err = invalidCursor(err, r.NextCursor)
 			return
 		}
 	}
 
-	response, err := s.store.GetUserPicks(ctx, store.GetUserPicksRequest{
-		UserID:      r.UserID,
-		WithTags:    withTags.IDs(),
-		WithoutTags: withoutTags.IDs(),
-		Cursor:      cursor,
-		PageSize:    r.PageSize,
-	})
+	req.SortBy = r.SortBy
+	req.SortDesc = r.SortDesc
+	req.Cursor = after
+	req.PageSize = pageSize
+	response, err := s.store.GetUserPicks(ctx, req)
 	if err != nil {
 		err = fmt.Errorf("get user picks: %w", err)
 		return
 	}
 
-	resp.Picks = fn.Map(response.Picks, func(pick model.UserPick) UserPick {
-		return UserPick{
-			ID:     pick.ID,
-			UserID: pick.UserID,
-			Word:   pick.Word.Lemma,
-			Lang:   pick.Word.Lang,
-			Class:  pick.Word.Class,
-			Def:    pick.Definition.Text,
-			Tags:   fn.Map(pick.Tags, func(tag model.Tag) string { return tag.Text }),
-		}
-	})
-
-	resp.NextCursor, err = encodeCursor(response.NextCursor)
+	counts, err := s.store.CountUserPicks(ctx, req)
 	if err != nil {
-		err = fmt.Errorf("encode cursor: %w", err)
+		err = fmt.Errorf("count user picks: %w", err)
 		return
 	}
 
+	resp.Picks = fn.Map(response.Picks, newUserPick)
+	resp.Total = counts.Total
+	resp.Facets = PickFacets{Tags: counts.Tags, Langs: counts.Langs, Classes: counts.Classes}
+
+	if response.NextCursor != nil {
+		resp.NextCursor, err = s.cursors.Encode(filterHash, response.NextCursor)
+		if err != nil {
+			err = fmt.Errorf("encode cursor: %w", err)
+			return
+		}
+	}
+
 	return
 }
 
+// picksFilter checks the filters of the request and resolves its tags and tag filters into a store request
+// without sorting and pagination. It returns false when no picks can match because some of the tags the
+// picks must have do not exist, and a ServiceError with status code 400 for invalid filters.
+func (s *WordsService) picksFilter(ctx context.Context, r GetUserPicksRequest) (store.GetUserPicksRequest, bool, error) {
+	for _, status := range r.Statuses {
+		if !status.Valid() {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown pick status")
+			se.Env["status"] = string(status)
+			return store.GetUserPicksRequest{}, false, se
+		}
+	}
+	if !r.CreatedAfter.IsZero() && !r.CreatedBefore.IsZero() && !r.CreatedAfter.Before(r.CreatedBefore) {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "created_after must be before created_before")
+		se.Env["created_after"] = r.CreatedAfter.Format(time.RFC3339)
+		se.Env["created_before"] = r.CreatedBefore.Format(time.RFC3339)
+		return store.GetUserPicksRequest{}, false, se
+	}
+
+	withTags, missing, err := s.tags.GetTags(ctx, s.store, r.UserID, r.WithTags)
+	if err != nil {
+		return store.GetUserPicksRequest{}, false, fmt.Errorf("get with-tags: %w", err)
+	}
+	if len(missing) > 0 {
+		// No picks can match if some of the requested tags do not exist
+		return store.GetUserPicksRequest{}, false, nil
+	}
+
+	withoutTags, _, err := s.tags.GetTags(ctx, s.store, r.UserID, r.WithoutTags)
+	if err != nil {
+		return store.GetUserPicksRequest{}, false, fmt.Errorf("get without-tags: %w", err)
+	}
+
+	tagExpr, err := s.resolveTagFilter(ctx, r.UserID, r.Filter, r.SavedFilter)
+	if err != nil {
+		return store.GetUserPicksRequest{}, false, err
+	}
+
+	return store.GetUserPicksRequest{
+		UserID:        r.UserID,
+		WithTags:      withTags.IDs(),
+		WithoutTags:   withoutTags.IDs(),
+		TagExpr:       tagExpr,
+		Langs:         r.Langs,
+		Classes:       r.Classes,
+		Statuses:      r.Statuses,
+		CreatedAfter:  r.CreatedAfter,
+		CreatedBefore: r.CreatedBefore,
+	}, true, nil
+}
+
+// picksFilterHash hashes the parameters of the request that select and order the picks,
+// so that a cursor can only be used to continue the listing it was issued for
+func picksFilterHash(r GetUserPicksRequest) (string, error) {
+	sorted := func(items []string) []string {
+		items = slices.Clone(items)
+		slices.Sort(items)
+		return items
+	}
+
+	return cursor.FilterHash(
+		r.UserID,
+		sorted(r.WithTags),
+		sorted(r.WithoutTags),
+		r.Filter,
+		r.SavedFilter,
+		sorted(fn.Map(r.Langs, func(l model.Lang) string { return string(l) })),
+		sorted(fn.Map(r.Classes, func(c model.WordClass) string { return string(c) })),
+		sorted(fn.Map(r.Statuses, func(s model.PickStatus) string { return string(s) })),
+		r.CreatedAfter.UTC(),
+		r.CreatedBefore.UTC(),
+		r.SortBy,
+		r.SortDesc,
+	)
+}
+
+type UnpickWordRequest struct {
+	UserID string
+	PickID int64
+}
+
 // UnpickWord allows a user to unpick a previously picked word definition.
-// If the pick does not exist, it returns a ServiceError with status code 404.
-func (s *WordsService) UnpickWord(ctx context.Context, pickID int64) error {
-	if err := s.store.DeleteUserPick(ctx, store.DeleteUserPickRequest{PickID: pickID}); err != nil {
+// If the user has no such pick, it returns a ServiceError with status code 404.
+func (s *WordsService) UnpickWord(ctx context.Context, r UnpickWordRequest) error {
+	if err := s.store.DeleteUserPick(ctx, store.DeleteUserPickRequest{UserID: r.UserID, PickID: r.PickID}); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			se := serr.NewServiceError(err, http.StatusNotFound, "user pick was not found")
-			se.Env["pick_id"] = fmt.Sprintf("%d", pickID)
-			return se
+			return pickNotFound(err, r.PickID)
 		}
 
 		return fmt.Errorf("delete user pick: %w", err)
@@ -229,7 +482,153 @@ func (s *WordsService) UnpickWord(ctx context.Context, pickID int64) error {
 	return nil
 }
 
+// maxDefOverrideLength is the maximum length of a personal definition text in characters
+const maxDefOverrideLength = 1000
+
+// UpdatePickRequest changes the fields of a pick that are set and leaves the others untouched
+type UpdatePickRequest struct {
+	UserID string
+	PickID int64
+	// Status moves the pick to another learning state
+	Status *model.PickStatus
+	// Def replaces the text of the shared definition for the user, an empty text restores the shared one
+	Def *string
+	// ImageID chooses another image attached to the definition, zero restores the default image
+	ImageID *int64
+}
+
+// UpdatePick changes the learning state of a pick of the user or personalizes its definition text and image
+// without changing the shared definition. It returns a ServiceError with status code 400 for invalid values,
+// 404 if the user has no such pick and 409 if the pick cannot move from its current status to the requested one.
+func (s *WordsService) UpdatePick(ctx context.Context, r UpdatePickRequest) error {
+	r, err := cleanUpdatePickRequest(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.WithTx(ctx, func(tx store.DataStore) error {
+		return s.updatePick(ctx, tx, r)
+	})
+	if err != nil {
+		return fmt.Errorf("update pick: %w", err)
+	}
+
+	return nil
+}
+
+// cleanUpdatePickRequest checks the values of the request and trims the definition text
+func cleanUpdatePickRequest(r UpdatePickRequest) (UpdatePickRequest, error) {
+	if r.Status == nil && r.Def == nil && r.ImageID == nil {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "nothing to update")
+		se.Env["pick_id"] = fmt.Sprintf("%d", r.PickID)
+		return r, se
+	}
+	if r.Status != nil && !r.Status.Valid() {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "unknown pick status")
+		se.Env["status"] = string(*r.Status)
+		return r, se
+	}
+
+	if r.Def != nil {
+		def := strings.TrimSpace(*r.Def)
+		if utf8.RuneCountInString(def) > maxDefOverrideLength {
+			se := serr.NewServiceError(nil, http.StatusBadRequest, "definition must be at most %d characters long", maxDefOverrideLength)
+			se.Env["pick_id"] = fmt.Sprintf("%d", r.PickID)
+			return r, se
+		}
+		r.Def = &def
+	}
+
+	return r, nil
+}
+
+// updatePick applies a request cleaned by cleanUpdatePickRequest in the transaction
+func (s *WordsService) updatePick(ctx context.Context, tx store.DataStore, r UpdatePickRequest) error {
+	pick, err := tx.GetPick(ctx, store.GetPickRequest{UserID: r.UserID, PickID: r.PickID, ForUpdate: true})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return pickNotFound(err, r.PickID)
+		}
+
+		return fmt.Errorf("get pick: %w", err)
+	}
+
+	update := store.UpdatePickRequest{
+		UserID:      r.UserID,
+		PickID:      r.PickID,
+		Status:      pick.Status,
+		DefOverride: pick.DefOverride,
+		ImageID:     pick.ImageID,
+	}
+
+	if r.Status != nil && *r.Status != pick.Status {
+		if !pick.Status.CanTransition(*r.Status) {
+			se := serr.NewServiceError(nil, http.StatusConflict, "pick status cannot be changed from %s to %s", pick.Status, *r.Status)
+			se.Env["pick_id"] = fmt.Sprintf("%d", r.PickID)
+			se.Env["from"] = string(pick.Status)
+			se.Env["to"] = string(*r.Status)
+			return se
+		}
+		update.Status = *r.Status
+	}
+
+	if r.Def != nil {
+		update.DefOverride = *r.Def
+	}
+
+	if r.ImageID != nil {
+		if err := checkPickImage(ctx, tx, pick, *r.ImageID); err != nil {
+			return err
+		}
+		update.ImageID = *r.ImageID
+	}
+
+	if err := tx.UpdatePick(ctx, update); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return pickNotFound(err, r.PickID)
+		}
+
+		return fmt.Errorf("update pick: %w", err)
+	}
+
+	return nil
+}
+
+// checkPickImage checks that the image is attached to the definition of the pick, zero restores the default image
+func checkPickImage(ctx context.Context, tx store.DataStore, pick model.UserPick, imageID int64) error {
+	if imageID == 0 {
+		return nil
+	}
+
+	img, err := tx.GetImage(ctx, store.GetImageRequest{ImageID: imageID})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			se := serr.NewServiceError(err, http.StatusNotFound, "image was not found")
+			se.Env["image_id"] = fmt.Sprintf("%d", imageID)
+			return se
+		}
+
+		return fmt.Errorf("get image: %w", err)
+	}
+
+	if img.DefID != pick.Definition.ID {
+		se := serr.NewServiceError(nil, http.StatusBadRequest, "image is not attached to the definition of the pick")
+		se.Env["image_id"] = fmt.Sprintf("%d", imageID)
+		se.Env["pick_id"] = fmt.Sprintf("%d", pick.ID)
+		return se
+	}
+
+	return nil
+}
+
+func pickNotFound(err error, pickID int64) error {
+	se := serr.NewServiceError(err, http.StatusNotFound, "user pick was not found")
+	se.Env["pick_id"] = fmt.Sprintf("%d", pickID)
+	return se
+}
+
 type AddTagsRequest struct {
+	UserID string
 	PickID int64
 	Tags   []string
 }
@@ -238,12 +637,13 @@ type AddTagsRequest struct {
 // it returns a ServiceError with status code 404.
 func (s *WordsService) AddTags(ctx context.Context, r AddTagsRequest) error {
 	err := s.store.WithTx(ctx, func(tx store.DataStore) error {
-		tagIDs, err := s.tags.GetOrCreateTags(ctx, tx, r.Tags)
+		tagIDs, err := s.tags.GetOrCreateTags(ctx, tx, r.UserID, r.Tags)
 		if err != nil {
 			return fmt.Errorf("get or create tags: %w", err)
 		}
 
 		err = tx.AddTags(ctx, store.AddTagsRequest{
+			UserID: r.UserID,
 			PickID: r.PickID,
 			TagIDs: tagIDs.IDs(),
 		})
@@ -267,6 +667,7 @@ func (s *WordsService) AddTags(ctx context.Context, r AddTagsRequest) error {
 }
 
 type RemoveTagsRequest struct {
+	UserID string
 	PickID int64
 	Tags   []string
 }
@@ -274,7 +675,7 @@ type RemoveTagsRequest struct {
 // RemoveTag removes a tag from a user's picked word. If the pick does not exist,
 // it returns a ServiceError with status code 404.
 func (s *WordsService) RemoveTags(ctx context.Context, r RemoveTagsRequest) error {
-	tags, _, err := s.tags.GetTags(ctx, s.store, r.Tags)
+	tags, _, err := s.tags.GetTags(ctx, s.store, r.UserID, r.Tags)
 	if err != nil {
 		return fmt.Errorf("get tags: %w", err)
 	}
@@ -285,6 +686,7 @@ func (s *WordsService) RemoveTags(ctx context.Context, r RemoveTagsRequest) erro
 	}
 
 	if err := s.store.RemoveTags(ctx, store.RemoveTagsRequest{
+		UserID: r.UserID,
 		PickID: r.PickID,
 		TagIDs: tags.IDs(),
 	}); err != nil {
@@ -303,7 +705,7 @@ func (s *WordsService) RemoveTags(ctx context.Context, r RemoveTagsRequest) erro
 type CreateDefinitionRequest struct {
 	WordID int64
 	Text   string
-	Rarity float32
+	Rarity int
 	Source model.DataSource
 }
 
@@ -374,16 +776,11 @@ func (s *WordsService) AttachImage(ctx context.Context, r AttachImageRequest) (A
 	}, nil
 }
 
-func decodeCursor[T any](s string) (T, error) {
-	var cursor T
-	d := json.NewDecoder(strings.NewReader(s))
-	err := d.Decode(&cursor)
-	return cursor, err
-}
-
-func encodeCursor[T any](c T) (string, error) {
-	var sb strings.Builder
-	e := json.NewEncoder(&sb)
-	err := e.Encode(c)
-	return sb.String(), err
+func invalidCursor(err error, token string) error {
+	se := serr.NewServiceError(err, http.StatusBadRequest, "invalid pagination cursor")
+	if errors.Is(err, cursor.ErrFilterMismatch) {
+		se = serr.NewServiceError(err, http.StatusBadRequest, "pagination cursor was issued for different filters")
+	}
+	se.Env["cursor"] = token
+	return se
 }
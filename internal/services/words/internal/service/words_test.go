@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"net/url"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gamma-omg/lexi-go/internal/pkg/serr"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
@@ -15,23 +18,63 @@ import (
 )
 
 type mockStore struct {
-	insertWordFunc       func(ctx context.Context, r store.InsertWordRequst) (int64, error)
-	deleteWordFunc       func(ctx context.Context, r store.DeleteWordRequest) error
-	CreateUserPickFunc   func(ctx context.Context, r store.CreateUserPickRequest) (int64, error)
-	GetUserPicksFunc     func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error)
-	DeleteUserPickFunc   func(ctx context.Context, r store.DeleteUserPickRequest) error
-	CreateTagsFunc       func(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error)
-	GetTagsFunc          func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error)
-	AddTagsFunc          func(ctx context.Context, r store.AddTagsRequest) error
-	RemoveTagsFunc       func(ctx context.Context, r store.RemoveTagsRequest) error
-	CreateDefinitionFunc func(ctx context.Context, r store.CreateDefinitionRequest) (int64, error)
-	AttachImageFunc      func(ctx context.Context, r store.AttachImageRequest) (int64, error)
+	insertWordFunc                func(ctx context.Context, r store.InsertWordRequst) (int64, error)
+	deleteWordFunc                func(ctx context.Context, r store.DeleteWordRequest) error
+	GetWordFunc                   func(ctx context.Context, r store.GetWordRequest) (model.Word, error)
+	CreateUserPickFunc            func(ctx context.Context, r store.CreateUserPickRequest) (int64, error)
+	GetUserPicksFunc              func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error)
+	CountUserPicksFunc            func(ctx context.Context, r store.GetUserPicksRequest) (store.CountUserPicksResponse, error)
+	ListUserPickIDsFunc           func(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error)
+	GetPickFunc                   func(ctx context.Context, r store.GetPickRequest) (model.UserPick, error)
+	UpdatePickFunc                func(ctx context.Context, r store.UpdatePickRequest) error
+	SchedulePickFunc              func(ctx context.Context, r store.SchedulePickRequest) error
+	AddPickContextFunc            func(ctx context.Context, r store.AddPickContextRequest) (int64, error)
+	DeleteUserPickFunc            func(ctx context.Context, r store.DeleteUserPickRequest) error
+	DeleteUserPicksFunc           func(ctx context.Context, r store.DeleteUserPicksRequest) (int64, error)
+	GetPickNoteFunc               func(ctx context.Context, r store.GetPickNoteRequest) (model.Note, error)
+	SavePickNoteFunc              func(ctx context.Context, r store.SavePickNoteRequest) (model.Note, error)
+	DeletePickNoteFunc            func(ctx context.Context, r store.DeletePickNoteRequest) error
+	ListUserNotesFunc             func(ctx context.Context, r store.ListUserNotesRequest) ([]model.Note, error)
+	AddNoteRevisionFunc           func(ctx context.Context, r store.AddNoteRevisionRequest) (int64, error)
+	ListNoteRevisionsFunc         func(ctx context.Context, r store.ListNoteRevisionsRequest) ([]model.NoteRevision, error)
+	GetPublicNoteFunc             func(ctx context.Context, r store.GetPublicNoteRequest) (model.Note, error)
+	VoteNoteFunc                  func(ctx context.Context, r store.VoteNoteRequest) error
+	UnvoteNoteFunc                func(ctx context.Context, r store.VoteNoteRequest) error
+	ListUserVotesFunc             func(ctx context.Context, r store.ListUserVotesRequest) ([]model.NoteVote, error)
+	ListMnemonicsFunc             func(ctx context.Context, r store.ListMnemonicsRequest) ([]model.Mnemonic, error)
+	CreateTagsFunc                func(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error)
+	GetTagsFunc                   func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error)
+	ListTagsFunc                  func(ctx context.Context, r store.ListTagsRequest) ([]model.TagUsage, error)
+	GetTagFunc                    func(ctx context.Context, r store.GetTagRequest) (model.Tag, error)
+	MoveTagFunc                   func(ctx context.Context, r store.MoveTagRequest) ([]string, error)
+	MergeTagsFunc                 func(ctx context.Context, r store.MergeTagsRequest) ([]string, error)
+	DeleteTagFunc                 func(ctx context.Context, r store.DeleteTagRequest) ([]string, error)
+	SaveTagFilterFunc             func(ctx context.Context, r store.SaveTagFilterRequest) (int64, error)
+	GetTagFilterFunc              func(ctx context.Context, r store.GetTagFilterRequest) (model.TagFilter, error)
+	ListTagFiltersFunc            func(ctx context.Context, r store.ListTagFiltersRequest) ([]model.TagFilter, error)
+	DeleteTagFilterFunc           func(ctx context.Context, r store.DeleteTagFilterRequest) error
+	AddTagsFunc                   func(ctx context.Context, r store.AddTagsRequest) error
+	RemoveTagsFunc                func(ctx context.Context, r store.RemoveTagsRequest) error
+	CreateDefinitionFunc          func(ctx context.Context, r store.CreateDefinitionRequest) (int64, error)
+	AttachImageFunc               func(ctx context.Context, r store.AttachImageRequest) (int64, error)
+	GetImageFunc                  func(ctx context.Context, r store.GetImageRequest) (model.Image, error)
+	UpsertDictionaryFunc          func(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error)
+	UpsertDefinitionRelationsFunc func(ctx context.Context, r store.UpsertDefinitionRelationsRequest) (int64, error)
+	ListRelatedDefinitionsFunc    func(ctx context.Context, r store.ListRelatedDefinitionsRequest) ([]model.RelatedDefinition, error)
+	CreateImportJobFunc           func(ctx context.Context, r store.CreateImportJobRequest) (int64, error)
+	UpdateImportJobFunc           func(ctx context.Context, r store.UpdateImportJobRequest) error
+	GetImportJobFunc              func(ctx context.Context, r store.GetImportJobRequest) (model.ImportJob, error)
+	ListImportJobsFunc            func(ctx context.Context, r store.ListImportJobsRequest) ([]model.ImportJob, error)
 }
 
 func (m *mockStore) InsertWord(ctx context.Context, r store.InsertWordRequst) (int64, error) {
 	return m.insertWordFunc(ctx, r)
 }
 
+func (m *mockStore) GetWord(ctx context.Context, r store.GetWordRequest) (model.Word, error) {
+	return m.GetWordFunc(ctx, r)
+}
+
 func (m *mockStore) DeleteWord(ctx context.Context, r store.DeleteWordRequest) error {
 	return m.deleteWordFunc(ctx, r)
 }
@@ -44,10 +87,78 @@ func (m *mockStore) GetUserPicks(ctx context.Context, r store.GetUserPicksReques
 	return m.GetUserPicksFunc(ctx, r)
 }
 
+func (m *mockStore) CountUserPicks(ctx context.Context, r store.GetUserPicksRequest) (store.CountUserPicksResponse, error) {
+	return m.CountUserPicksFunc(ctx, r)
+}
+
+func (m *mockStore) ListUserPickIDs(ctx context.Context, r store.GetUserPicksRequest) ([]int64, error) {
+	return m.ListUserPickIDsFunc(ctx, r)
+}
+
+func (m *mockStore) GetPick(ctx context.Context, r store.GetPickRequest) (model.UserPick, error) {
+	return m.GetPickFunc(ctx, r)
+}
+
+func (m *mockStore) UpdatePick(ctx context.Context, r store.UpdatePickRequest) error {
+	return m.UpdatePickFunc(ctx, r)
+}
+
+func (m *mockStore) AddPickContext(ctx context.Context, r store.AddPickContextRequest) (int64, error) {
+	return m.AddPickContextFunc(ctx, r)
+}
+
 func (m *mockStore) DeleteUserPick(ctx context.Context, r store.DeleteUserPickRequest) error {
 	return m.DeleteUserPickFunc(ctx, r)
 }
 
+func (m *mockStore) DeleteUserPicks(ctx context.Context, r store.DeleteUserPicksRequest) (int64, error) {
+	return m.DeleteUserPicksFunc(ctx, r)
+}
+
+func (m *mockStore) GetPickNote(ctx context.Context, r store.GetPickNoteRequest) (model.Note, error) {
+	return m.GetPickNoteFunc(ctx, r)
+}
+
+func (m *mockStore) SavePickNote(ctx context.Context, r store.SavePickNoteRequest) (model.Note, error) {
+	return m.SavePickNoteFunc(ctx, r)
+}
+
+func (m *mockStore) DeletePickNote(ctx context.Context, r store.DeletePickNoteRequest) error {
+	return m.DeletePickNoteFunc(ctx, r)
+}
+
+func (m *mockStore) ListUserNotes(ctx context.Context, r store.ListUserNotesRequest) ([]model.Note, error) {
+	return m.ListUserNotesFunc(ctx, r)
+}
+
+func (m *mockStore) AddNoteRevision(ctx context.Context, r store.AddNoteRevisionRequest) (int64, error) {
+	return m.AddNoteRevisionFunc(ctx, r)
+}
+
+func (m *mockStore) ListNoteRevisions(ctx context.Context, r store.ListNoteRevisionsRequest) ([]model.NoteRevision, error) {
+	return m.ListNoteRevisionsFunc(ctx, r)
+}
+
+func (m *mockStore) GetPublicNote(ctx context.Context, r store.GetPublicNoteRequest) (model.Note, error) {
+	return m.GetPublicNoteFunc(ctx, r)
+}
+
+func (m *mockStore) VoteNote(ctx context.Context, r store.VoteNoteRequest) error {
+	return m.VoteNoteFunc(ctx, r)
+}
+
+func (m *mockStore) UnvoteNote(ctx context.Context, r store.VoteNoteRequest) error {
+	return m.UnvoteNoteFunc(ctx, r)
+}
+
+func (m *mockStore) ListUserVotes(ctx context.Context, r store.ListUserVotesRequest) ([]model.NoteVote, error) {
+	return m.ListUserVotesFunc(ctx, r)
+}
+
+func (m *mockStore) ListMnemonics(ctx context.Context, r store.ListMnemonicsRequest) ([]model.Mnemonic, error) {
+	return m.ListMnemonicsFunc(ctx, r)
+}
+
 func (m *mockStore) CreateTags(ctx context.Context, r store.CreateTagsRequest) (model.TagIDMap, error) {
 	return m.CreateTagsFunc(ctx, r)
 }
@@ -56,6 +167,42 @@ func (m *mockStore) GetTags(ctx context.Context, r store.GetTagsRequest) (model.
 	return m.GetTagsFunc(ctx, r)
 }
 
+func (m *mockStore) ListTags(ctx context.Context, r store.ListTagsRequest) ([]model.TagUsage, error) {
+	return m.ListTagsFunc(ctx, r)
+}
+
+func (m *mockStore) GetTag(ctx context.Context, r store.GetTagRequest) (model.Tag, error) {
+	return m.GetTagFunc(ctx, r)
+}
+
+func (m *mockStore) MoveTag(ctx context.Context, r store.MoveTagRequest) ([]string, error) {
+	return m.MoveTagFunc(ctx, r)
+}
+
+func (m *mockStore) MergeTags(ctx context.Context, r store.MergeTagsRequest) ([]string, error) {
+	return m.MergeTagsFunc(ctx, r)
+}
+
+func (m *mockStore) DeleteTag(ctx context.Context, r store.DeleteTagRequest) ([]string, error) {
+	return m.DeleteTagFunc(ctx, r)
+}
+
+func (m *mockStore) SaveTagFilter(ctx context.Context, r store.SaveTagFilterRequest) (int64, error) {
+	return m.SaveTagFilterFunc(ctx, r)
+}
+
+func (m *mockStore) GetTagFilter(ctx context.Context, r store.GetTagFilterRequest) (model.TagFilter, error) {
+	return m.GetTagFilterFunc(ctx, r)
+}
+
+func (m *mockStore) ListTagFilters(ctx context.Context, r store.ListTagFiltersRequest) ([]model.TagFilter, error) {
+	return m.ListTagFiltersFunc(ctx, r)
+}
+
+func (m *mockStore) DeleteTagFilter(ctx context.Context, r store.DeleteTagFilterRequest) error {
+	return m.DeleteTagFilterFunc(ctx, r)
+}
+
 func (m *mockStore) AddTags(ctx context.Context, r store.AddTagsRequest) error {
 	return m.AddTagsFunc(ctx, r)
 }
@@ -72,6 +219,42 @@ func (m *mockStore) AttachImage(ctx context.Context, r store.AttachImageRequest)
 	return m.AttachImageFunc(ctx, r)
 }
 
+func (m *mockStore) GetImage(ctx context.Context, r store.GetImageRequest) (model.Image, error) {
+	return m.GetImageFunc(ctx, r)
+}
+
+func (m *mockStore) UpsertDictionary(ctx context.Context, r store.UpsertDictionaryRequest) (store.UpsertDictionaryResponse, error) {
+	return m.UpsertDictionaryFunc(ctx, r)
+}
+
+func (m *mockStore) UpsertDefinitionRelations(ctx context.Context, r store.UpsertDefinitionRelationsRequest) (int64, error) {
+	return m.UpsertDefinitionRelationsFunc(ctx, r)
+}
+
+func (m *mockStore) ListRelatedDefinitions(ctx context.Context, r store.ListRelatedDefinitionsRequest) ([]model.RelatedDefinition, error) {
+	return m.ListRelatedDefinitionsFunc(ctx, r)
+}
+
+func (m *mockStore) SchedulePick(ctx context.Context, r store.SchedulePickRequest) error {
+	return m.SchedulePickFunc(ctx, r)
+}
+
+func (m *mockStore) CreateImportJob(ctx context.Context, r store.CreateImportJobRequest) (int64, error) {
+	return m.CreateImportJobFunc(ctx, r)
+}
+
+func (m *mockStore) UpdateImportJob(ctx context.Context, r store.UpdateImportJobRequest) error {
+	return m.UpdateImportJobFunc(ctx, r)
+}
+
+func (m *mockStore) GetImportJob(ctx context.Context, r store.GetImportJobRequest) (model.ImportJob, error) {
+	return m.GetImportJobFunc(ctx, r)
+}
+
+func (m *mockStore) ListImportJobs(ctx context.Context, r store.ListImportJobsRequest) ([]model.ImportJob, error) {
+	return m.ListImportJobsFunc(ctx, r)
+}
+
 func (m *mockStore) WithTx(ctx context.Context, fn func(tx store.DataStore) error) error {
 	return fn(m)
 }
@@ -225,6 +408,7 @@ func TestPickWord(t *testing.T) {
 
 	require.Len(t, addedTags, 1)
 	require.Contains(t, addedTags, store.AddTagsRequest{
+		UserID: "user-123",
 		PickID: 1,
 		TagIDs: []int64{100, 200},
 	})
@@ -273,11 +457,15 @@ func TestUnpickWord(t *testing.T) {
 		TagsMaxCost:   100,
 	})
 
-	err := srv.UnpickWord(context.Background(), 456)
+	err := srv.UnpickWord(context.Background(), UnpickWordRequest{UserID: "user-123", PickID: 456})
 	require.NoError(t, err)
 
 	require.Len(t, deletedPicks, 1)
-	require.Contains(t, deletedPicks, store.DeleteUserPickRequest{PickID: 456})
+	require.Contains(t, deletedPicks, store.DeleteUserPickRequest{UserID: "user-123", PickID: 456})
+}
+
+func countNoPicks(ctx context.Context, r store.GetUserPicksRequest) (store.CountUserPicksResponse, error) {
+	return store.CountUserPicksResponse{}, nil
 }
 
 func TestGetUserPicks(t *testing.T) {
@@ -294,6 +482,7 @@ func TestGetUserPicks(t *testing.T) {
 			requests = append(requests, r)
 			return store.GetUserPicksResponse{}, nil
 		},
+		CountUserPicksFunc: countNoPicks,
 		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
 			ret := make(model.TagIDMap)
 			for _, tag := range r.Tags {
@@ -325,6 +514,90 @@ func TestGetUserPicks(t *testing.T) {
 	})
 }
 
+func TestGetUserPicks_PageSize(t *testing.T) {
+	var pageSizes []int
+	srv := NewWordsService(&mockStore{
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			pageSizes = append(pageSizes, r.PageSize)
+			return store.GetUserPicksResponse{}, nil
+		},
+		CountUserPicksFunc: countNoPicks,
+	}, WordsServiceConfig{
+		TagsCacheSize: 100,
+		TagsMaxCost:   100,
+	})
+
+	_, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-123"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{defaultPicksPageSize}, pageSizes)
+
+	for _, size := range []int{-1, maxPicksPageSize + 1} {
+		_, err = srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-123", PageSize: size})
+		var se *serr.ServiceError
+		require.ErrorAs(t, err, &se)
+		assert.Equal(t, http.StatusBadRequest, se.StatusCode)
+		assert.Equal(t, strconv.Itoa(size), se.Env["page_size"])
+	}
+}
+
+func TestGetUserPicks_SortAndFacets(t *testing.T) {
+	var request store.GetUserPicksRequest
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewWordsService(&mockStore{
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			request = r
+			return store.GetUserPicksResponse{}, nil
+		},
+		CountUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.CountUserPicksResponse, error) {
+			assert.Equal(t, request, r)
+			return store.CountUserPicksResponse{
+				Total:   3,
+				Tags:    map[string]int{"food": 2},
+				Langs:   map[model.Lang]int{"en": 3},
+				Classes: map[model.WordClass]int{model.Noun: 1, model.Verb: 2},
+			}, nil
+		},
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	resp, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{
+		UserID:       "user-123",
+		Classes:      []model.WordClass{model.Noun, model.Verb},
+		CreatedAfter: createdAfter,
+		SortBy:       model.SortByRarity,
+		SortDesc:     true,
+		PageSize:     10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.WordClass{model.Noun, model.Verb}, request.Classes)
+	assert.Equal(t, createdAfter, request.CreatedAfter)
+	assert.Equal(t, model.SortByRarity, request.SortBy)
+	assert.True(t, request.SortDesc)
+	assert.Equal(t, 3, resp.Total)
+	assert.Equal(t, PickFacets{
+		Tags:    map[string]int{"food": 2},
+		Langs:   map[model.Lang]int{"en": 3},
+		Classes: map[model.WordClass]int{model.Noun: 1, model.Verb: 2},
+	}, resp.Facets)
+}
+
+func TestGetUserPicks_InvalidSortAndDates(t *testing.T) {
+	srv := NewWordsService(&mockStore{}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100})
+
+	_, err := srv.GetUserPicks(context.Background(), GetUserPicksRequest{UserID: "user-123", SortBy: "popularity"})
+	var se *serr.ServiceError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.StatusCode)
+
+	now := time.Now()
+	_, err = srv.GetUserPicks(context.Background(), GetUserPicksRequest{
+		UserID:        "user-123",
+		CreatedAfter:  now,
+		CreatedBefore: now.Add(-time.Hour),
+	})
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.StatusCode)
+}
+
 func TestGetUserPicks_MissingTag(t *testing.T) {
 	mockStore := &mockStore{
 		GetTagsFunc: func(ctx context.Context, r store.GetTagsRequest) (model.TagIDMap, error) {
@@ -361,6 +634,7 @@ func TestGetUserPicks_InvalidExcludeTag(t *testing.T) {
 			requests = append(requests, r)
 			return store.GetUserPicksResponse{}, nil
 		},
+		CountUserPicksFunc: countNoPicks,
 	}
 
 	srv := NewWordsService(mockStore, WordsServiceConfig{
@@ -417,7 +691,7 @@ func TestUnpickWord_NotFound(t *testing.T) {
 		TagsMaxCost:   100,
 	})
 
-	err := srv.UnpickWord(context.Background(), 456)
+	err := srv.UnpickWord(context.Background(), UnpickWordRequest{UserID: "user-123", PickID: 456})
 	require.Error(t, err)
 
 	var se *serr.ServiceError
@@ -426,6 +700,113 @@ func TestUnpickWord_NotFound(t *testing.T) {
 	require.Equal(t, "456", se.Env["pick_id"])
 }
 
+func TestUpdatePick(t *testing.T) {
+	var updates []store.UpdatePickRequest
+	mockStore := &mockStore{
+		GetPickFunc: func(ctx context.Context, r store.GetPickRequest) (model.UserPick, error) {
+			assert.Equal(t, store.GetPickRequest{UserID: "user-123", PickID: 456, ForUpdate: true}, r)
+			return model.UserPick{
+				ID:          456,
+				UserID:      "user-123",
+				Definition:  model.Definition{ID: 10},
+				Status:      model.StatusLearning,
+				DefOverride: "A fruit.",
+			}, nil
+		},
+		GetImageFunc: func(ctx context.Context, r store.GetImageRequest) (model.Image, error) {
+			return model.Image{ID: r.ImageID, DefID: 10}, nil
+		},
+		UpdatePickFunc: func(ctx context.Context, r store.UpdatePickRequest) error {
+			updates = append(updates, r)
+			return nil
+		},
+	}
+
+	srv := NewWordsService(mockStore, WordsServiceConfig{
+		TagsCacheSize: 100,
+		TagsMaxCost:   100,
+	})
+
+	suspended := model.StatusSuspended
+	err := srv.UpdatePick(context.Background(), UpdatePickRequest{UserID: "user-123", PickID: 456, Status: &suspended})
+	require.NoError(t, err)
+
+	def, imageID := "  A round fruit. ", int64(7)
+	err = srv.UpdatePick(context.Background(), UpdatePickRequest{UserID: "user-123", PickID: 456, Def: &def, ImageID: &imageID})
+	require.NoError(t, err)
+
+	// an empty text restores the shared definition
+	empty := ""
+	err = srv.UpdatePick(context.Background(), UpdatePickRequest{UserID: "user-123", PickID: 456, Def: &empty})
+	require.NoError(t, err)
+
+	require.Equal(t, []store.UpdatePickRequest{
+		{UserID: "user-123", PickID: 456, Status: model.StatusSuspended, DefOverride: "A fruit."},
+		{UserID: "user-123", PickID: 456, Status: model.StatusLearning, DefOverride: "A round fruit.", ImageID: 7},
+		{UserID: "user-123", PickID: 456, Status: model.StatusLearning},
+	}, updates)
+}
+
+func TestUpdatePick_Errors(t *testing.T) {
+	mockStore := &mockStore{
+		GetPickFunc: func(ctx context.Context, r store.GetPickRequest) (model.UserPick, error) {
+			if r.PickID == 1 {
+				return model.UserPick{}, store.ErrNotFound
+			}
+			return model.UserPick{ID: r.PickID, Definition: model.Definition{ID: 10}, Status: model.StatusLearning}, nil
+		},
+		GetImageFunc: func(ctx context.Context, r store.GetImageRequest) (model.Image, error) {
+			if r.ImageID == 1 {
+				return model.Image{}, store.ErrNotFound
+			}
+			return model.Image{ID: r.ImageID, DefID: 11}, nil
+		},
+	}
+
+	srv := NewWordsService(mockStore, WordsServiceConfig{
+		TagsCacheSize: 100,
+		TagsMaxCost:   100,
+	})
+
+	status := func(s model.PickStatus) *model.PickStatus { return &s }
+	image := func(id int64) *int64 { return &id }
+	long := strings.Repeat("a", maxDefOverrideLength+1)
+
+	for name, tc := range map[string]struct {
+		req    UpdatePickRequest
+		status int
+	}{
+		"nothing":         {UpdatePickRequest{PickID: 2}, http.StatusBadRequest},
+		"unknown status":  {UpdatePickRequest{PickID: 2, Status: status("forgotten")}, http.StatusBadRequest},
+		"long definition": {UpdatePickRequest{PickID: 2, Def: &long}, http.StatusBadRequest},
+		"missing pick":    {UpdatePickRequest{PickID: 1, Status: status(model.StatusKnown)}, http.StatusNotFound},
+		"bad transition":  {UpdatePickRequest{PickID: 2, Status: status(model.StatusNew)}, http.StatusConflict},
+		"missing image":   {UpdatePickRequest{PickID: 2, ImageID: image(1)}, http.StatusNotFound},
+		"foreign image":   {UpdatePickRequest{PickID: 2, ImageID: image(2)}, http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.req.UserID = "user-123"
+			requireStatus(t, srv.UpdatePick(context.Background(), tc.req), tc.status)
+		})
+	}
+}
+
+func TestNewUserPick_Personalized(t *testing.T) {
+	pick := newUserPick(model.UserPick{
+		Definition:  model.Definition{Text: "A round fruit."},
+		DefOverride: "Red fruit.",
+		ImageURL:    "https://example.com/apple.png",
+	})
+	assert.Equal(t, "Red fruit.", pick.Def)
+	assert.Equal(t, "A round fruit.", pick.OriginalDef)
+	assert.True(t, pick.Personalized)
+
+	pick = newUserPick(model.UserPick{Definition: model.Definition{Text: "A round fruit."}})
+	assert.Equal(t, "A round fruit.", pick.Def)
+	assert.Empty(t, pick.OriginalDef)
+	assert.False(t, pick.Personalized)
+}
+
 func TestAddTag(t *testing.T) {
 	var addedTags []store.AddTagsRequest
 	mockStore := &mockStore{
@@ -450,6 +831,7 @@ func TestAddTag(t *testing.T) {
 		TagsMaxCost:   100,
 	})
 	req := AddTagsRequest{
+		UserID: "user-123",
 		PickID: 456,
 		Tags:   []string{"important", "review"},
 	}
@@ -459,6 +841,7 @@ func TestAddTag(t *testing.T) {
 
 	require.Len(t, addedTags, 1)
 	require.Contains(t, addedTags, store.AddTagsRequest{
+		UserID: "user-123",
 		PickID: 456,
 		TagIDs: []int64{789, 790},
 	})
@@ -514,6 +897,7 @@ func TestRemoveTags(t *testing.T) {
 		TagsMaxCost:   100,
 	})
 	req := RemoveTagsRequest{
+		UserID: "user-123",
 		PickID: 456,
 		Tags:   []string{"TestTag"},
 	}
@@ -523,6 +907,7 @@ func TestRemoveTags(t *testing.T) {
 
 	require.Len(t, removedTags, 1)
 	require.Contains(t, removedTags, store.RemoveTagsRequest{
+		UserID: "user-123",
 		PickID: 456,
 		TagIDs: []int64{789},
 	})
@@ -733,3 +1118,36 @@ func TestAttachImage_Exists(t *testing.T) {
 	assert.Equal(t, "123", se.Env["def_id"])
 	assert.Equal(t, "http://example.com/image.jpg", se.Env["image_url"])
 }
+
+func TestGetUserPicks_Cursor(t *testing.T) {
+	var requests []store.GetUserPicksRequest
+	srv := NewWordsService(&mockStore{
+		GetUserPicksFunc: func(ctx context.Context, r store.GetUserPicksRequest) (store.GetUserPicksResponse, error) {
+			requests = append(requests, r)
+			return store.GetUserPicksResponse{
+				NextCursor: &store.GetUserPicksCursor{LastPickID: 42, LastSortKey: "apple"},
+			}, nil
+		},
+		CountUserPicksFunc: countNoPicks,
+	}, WordsServiceConfig{TagsCacheSize: 100, TagsMaxCost: 100, CursorSecret: []byte("secret")})
+
+	req := GetUserPicksRequest{UserID: "user-123", SortBy: model.SortByLemma, PageSize: 10}
+	resp, err := srv.GetUserPicks(context.Background(), req)
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.NextCursor)
+	assert.NotContains(t, resp.NextCursor, "apple")
+
+	req.NextCursor = resp.NextCursor
+	_, err = srv.GetUserPicks(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, store.GetUserPicksCursor{LastPickID: 42, LastSortKey: "apple"}, requests[1].Cursor)
+
+	// the cursor cannot be reused with different filters or sorting
+	req.SortDesc = true
+	_, err = srv.GetUserPicks(context.Background(), req)
+	var se *serr.ServiceError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, http.StatusBadRequest, se.StatusCode)
+	assert.Len(t, requests, 2)
+}
@@ -3,9 +3,14 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/fn"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/tagexpr"
 	"github.com/lib/pq"
 )
 
@@ -71,6 +76,25 @@ func (s *PostresStore) InsertWord(ctx context.Context, r InsertWordRequst) (int6
 	return id, nil
 }
 
+// GetWord returns the word with the ID or ErrNotFound if there is none
+func (s *PostresStore) GetWord(ctx context.Context, r GetWordRequest) (model.Word, error) {
+	var w model.Word
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, lemma, lang, COALESCE(class::text, ''), created_at, updated_at
+		FROM words
+		WHERE id = $1
+	`, r.ID).Scan(&w.ID, &w.Lemma, &w.Lang, &w.Class, &w.CreateAt, &w.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Word{}, ErrNotFound
+		}
+
+		return model.Word{}, fmt.Errorf("get word: %w", err)
+	}
+
+	return w, nil
+}
+
 func (s *PostresStore) DeleteWord(ctx context.Context, r DeleteWordRequest) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM words WHERE id = $1", r.ID)
 	if err != nil {
@@ -89,6 +113,9 @@ func (s *PostresStore) CreateUserPick(ctx context.Context, r CreateUserPickReque
 		if isPqErr(err, errUniqueViolation) {
 			return 0, ErrExists
 		}
+		if isPqErr(err, errForeignKeyViolation) {
+			return 0, ErrNotFound
+		}
 
 		return 0, fmt.Errorf("create user pick: %w", err)
 	}
@@ -96,47 +123,116 @@ func (s *PostresStore) CreateUserPick(ctx context.Context, r CreateUserPickReque
 	return id, nil
 }
 
+// pickSortColumns maps the sort fields of picks to SQL expressions and the types their text values are cast back to
+var pickSortColumns = map[model.PickSort]struct{ expr, typ string }{
+	model.SortByCreated:    {"p.created_at", "timestamptz"},
+	model.SortByLemma:      {"w.lemma", "text"},
+	model.SortByLang:       {"w.lang", "text"},
+	model.SortByClass:      {"COALESCE(w.class::text, '')", "text"},
+	model.SortByRarity:     {"COALESCE(d.rarity, 0)", "int"},
+	model.SortByNextReview: {"COALESCE(p.next_review_at, 'infinity'::timestamptz)", "timestamptz"},
+}
+
+// GetUserPicks lists the picks of the user matching the filters of the request, one page at a time.
+// Picks come with the image the user chose or else with the first image attached to their definition,
+// and with their context sentences.
+// Sorting by the next review lists the review queue: only new picks and picks being learned, with the
+// picks that were never scheduled for a review last when sorting ascending.
+// The page is selected before the tags of its picks are aggregated, so the cost of a page does not
+// grow with the number of picks the user has.
 func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest) (resp GetUserPicksResponse, err error) {
-	sql := `
+	sortExpr, sortType := "p.id", "bigint"
+	if r.SortBy != "" {
+		col, ok := pickSortColumns[r.SortBy]
+		if !ok {
+			err = fmt.Errorf("unknown sort field %q", r.SortBy)
+			return
+		}
+		sortExpr, sortType = col.expr, col.typ
+	}
+
+	order, cmp := "ASC", ">"
+	if r.SortDesc {
+		order, cmp = "DESC", "<"
+	}
+
+	args := []any{}
+	conds := pickConditions(r, &args)
+	if r.SortBy == model.SortByNextReview {
+		conds += " AND p.status IN ('new', 'learning')"
+	}
+	if r.Cursor.LastPickID != 0 {
+		if r.SortBy == "" {
+			args = append(args, r.Cursor.LastPickID)
+			conds += fmt.Sprintf(" AND p.id %s $%d", cmp, len(args))
+		} else {
+			args = append(args, r.Cursor.LastSortKey, r.Cursor.LastPickID)
+			conds += fmt.Sprintf(" AND (%s, p.id) %s ($%d::%s, $%d)", sortExpr, cmp, len(args)-1, sortType, len(args))
+		}
+	}
+	args = append(args, r.PageSize+1)
+
+	sql := fmt.Sprintf(`
 		SELECT
-			p.id,
-			p.user_id,
-			p.def_id,
-			d.def,
-			d.rarity,
-			w.id,
-			w.lemma,
-			w.lang,
-			w.class,
-			COALESCE(array_agg(t.tag_id) FILTER (WHERE t.tag_id IS NOT NULL), '{}') AS tag_ids,
-			COALESCE(array_agg(tg.tag) FILTER (WHERE tg.tag IS NOT NULL), '{}') AS tag_texts
-		FROM user_picks AS p
-		LEFT JOIN tags_map AS t
-			ON p.id = t.pick_id
-		LEFT JOIN tags AS tg
-			ON t.tag_id = tg.id
-		JOIN definitions AS d
-			ON p.def_id = d.id
-		JOIN words AS w
-			ON d.word_id = w.id
-		WHERE
-			p.user_id = $1 AND
-			p.id > $2
-		GROUP BY p.id, p.user_id, p.def_id, d.def, d.rarity, w.id, w.lemma, w.lang, w.class
-		HAVING
-			COUNT (DISTINCT t.tag_id) FILTER (WHERE t.tag_id = ANY($3::int[])) = cardinality(COALESCE($3::int[], '{}')) AND
-			COUNT (*) FILTER (WHERE t.tag_id = ANY($4::int[])) = 0
-		ORDER BY p.id
-		LIMIT $5
-	`
-	rows, err := s.db.QueryContext(ctx, sql,
-		r.UserID,
-		r.Cursor.LastPickID,
-		pq.Array(r.WithTags),
-		pq.Array(r.WithoutTags),
-		r.PageSize+1,
-	)
+			pg.id,
+			pg.user_id,
+			pg.def_id,
+			pg.created_at,
+			pg.next_review_at,
+			pg.status,
+			pg.def_override,
+			pg.image_id,
+			COALESCE(
+				(SELECT i.url FROM images AS i WHERE i.id = pg.image_id),
+				(SELECT i.url FROM images AS i WHERE i.def_id = pg.def_id ORDER BY i.id LIMIT 1),
+				''
+			) AS image_url,
+			pg.def,
+			pg.rarity,
+			pg.word_id,
+			pg.lemma,
+			pg.lang,
+			pg.class,
+			COALESCE(tg.ids, '{}') AS tag_ids,
+			COALESCE(tg.texts, '{}') AS tag_texts,
+			pg.sort_value::text AS sort_key
+		FROM (
+			SELECT
+				p.id,
+				p.user_id,
+				p.def_id,
+				p.created_at,
+				p.next_review_at,
+				p.status,
+				COALESCE(p.def_override, '') AS def_override,
+				COALESCE(p.image_id, 0) AS image_id,
+				d.def,
+				d.rarity,
+				w.id AS word_id,
+				w.lemma,
+				w.lang,
+				w.class,
+				%[1]s AS sort_value
+			FROM user_picks AS p
+			JOIN definitions AS d
+				ON p.def_id = d.id
+			JOIN words AS w
+				ON d.word_id = w.id
+			WHERE %[2]s
+			ORDER BY %[1]s %[3]s, p.id %[3]s
+			LIMIT $%[4]d
+		) AS pg
+		LEFT JOIN LATERAL (
+			SELECT array_agg(t.id ORDER BY m.id) AS ids, array_agg(t.tag ORDER BY m.id) AS texts
+			FROM tags_map AS m
+			JOIN tags AS t
+				ON t.id = m.tag_id
+			WHERE m.pick_id = pg.id
+		) AS tg ON TRUE
+		ORDER BY pg.sort_value %[3]s, pg.id %[3]s
+	`, sortExpr, conds, order, len(args))
 
+	rows, err := s.db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		err = fmt.Errorf("query user picks: %w", err)
 		return
@@ -144,14 +240,22 @@ func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest)
 	defer rows.Close()
 
 	var picks []model.UserPick
+	var sortKeys []string
 	for rows.Next() {
 		var pick model.UserPick
 		var tagIDs []int64
 		var tagTexts []string
+		var sortKey string
 		err = rows.Scan(
 			&pick.ID,
 			&pick.UserID,
 			&pick.Definition.ID,
+			&pick.CreateAt,
+			&pick.NextReviewAt,
+			&pick.Status,
+			&pick.DefOverride,
+			&pick.ImageID,
+			&pick.ImageURL,
 			&pick.Definition.Text,
 			&pick.Definition.Rarity,
 			&pick.Word.ID,
@@ -160,6 +264,7 @@ func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest)
 			&pick.Word.Class,
 			pq.Array(&tagIDs),
 			pq.Array(&tagTexts),
+			&sortKey,
 		)
 		if err != nil {
 			err = fmt.Errorf("scan user pick: %w", err)
@@ -174,6 +279,7 @@ func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest)
 		}
 
 		picks = append(picks, pick)
+		sortKeys = append(sortKeys, sortKey)
 	}
 	if err = rows.Err(); err != nil {
 		err = fmt.Errorf("iterate user picks: %w", err)
@@ -183,8 +289,18 @@ func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest)
 	var nextCursor *GetUserPicksCursor
 	if len(picks) > r.PageSize {
 		picks = picks[:r.PageSize]
-		last := picks[len(picks)-1]
-		nextCursor = &GetUserPicksCursor{LastPickID: last.ID}
+		nextCursor = &GetUserPicksCursor{LastPickID: picks[len(picks)-1].ID}
+		if r.SortBy != "" {
+			nextCursor.LastSortKey = sortKeys[len(picks)-1]
+		}
+	}
+
+	contexts, err := s.pickContexts(ctx, fn.Map(picks, func(p model.UserPick) int64 { return p.ID }))
+	if err != nil {
+		return
+	}
+	for i := range picks {
+		picks[i].Contexts = contexts[picks[i].ID]
 	}
 
 	return GetUserPicksResponse{
@@ -193,176 +309,1680 @@ func (s *PostresStore) GetUserPicks(ctx context.Context, r GetUserPicksRequest)
 	}, nil
 }
 
-func (s *PostresStore) DeleteUserPick(ctx context.Context, r DeleteUserPickRequest) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM user_picks WHERE id = $1", r.PickID)
+// CountUserPicks counts the picks of the user matching the filters of the request, ignoring
+// its sorting and pagination. Tag counts include only the tags directly assigned to the picks.
+func (s *PostresStore) CountUserPicks(ctx context.Context, r GetUserPicksRequest) (CountUserPicksResponse, error) {
+	args := []any{}
+	sql := fmt.Sprintf(`
+		WITH matched AS (
+			SELECT p.id, w.lang, COALESCE(w.class::text, '') AS class
+			FROM user_picks AS p
+			JOIN definitions AS d
+				ON p.def_id = d.id
+			JOIN words AS w
+				ON d.word_id = w.id
+			WHERE %s
+		)
+		SELECT 'total', '', COUNT(*) FROM matched
+		UNION ALL
+		SELECT 'lang', lang, COUNT(*) FROM matched GROUP BY lang
+		UNION ALL
+		SELECT 'class', class, COUNT(*) FROM matched GROUP BY class
+		UNION ALL
+		SELECT 'tag', t.tag, COUNT(*)
+		FROM matched
+		JOIN tags_map AS m
+			ON m.pick_id = matched.id
+		JOIN tags AS t
+			ON t.id = m.tag_id
+		GROUP BY t.tag
+	`, pickConditions(r, &args))
+
+	rows, err := s.db.QueryContext(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("delete user pick: %w", err)
+		return CountUserPicksResponse{}, fmt.Errorf("count user picks: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	resp := CountUserPicksResponse{
+		Tags:    make(map[string]int),
+		Langs:   make(map[model.Lang]int),
+		Classes: make(map[model.WordClass]int),
+	}
+	for rows.Next() {
+		var facet, value string
+		var count int
+		if err := rows.Scan(&facet, &value, &count); err != nil {
+			return CountUserPicksResponse{}, fmt.Errorf("scan pick count: %w", err)
+		}
+
+		switch facet {
+		case "total":
+			resp.Total = count
+		case "lang":
+			resp.Langs[model.Lang(value)] = count
+		case "class":
+			resp.Classes[model.WordClass(value)] = count
+		case "tag":
+			resp.Tags[value] = count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return CountUserPicksResponse{}, fmt.Errorf("iterate pick counts: %w", err)
+	}
+
+	return resp, nil
 }
 
-func (s *PostresStore) CreateTags(ctx context.Context, r CreateTagsRequest) (model.TagIDMap, error) {
-	if len(r.Tags) == 0 {
-		return model.TagIDMap{}, nil
+// pickConditions builds the WHERE conditions on the pick p, the definition d and the word w
+// for the filters of the request, appending their arguments to args
+func pickConditions(r GetUserPicksRequest, args *[]any) string {
+	arg := func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
 	}
 
-	_, err := s.db.ExecContext(ctx, "INSERT INTO tags (tag) SELECT UNNEST($1::text[]) ON CONFLICT (tag) DO NOTHING", pq.Array(r.Tags))
-	if err != nil {
-		return nil, fmt.Errorf("insert tags: %w", err)
+	conds := []string{"p.user_id = " + arg(r.UserID)}
+	if len(r.PickIDs) > 0 {
+		conds = append(conds, "p.id = ANY("+arg(pq.Array(r.PickIDs))+"::int[])")
+	}
+	if len(r.Langs) > 0 {
+		conds = append(conds, "w.lang = ANY("+arg(pq.Array(fn.Map(r.Langs, func(l model.Lang) string { return string(l) })))+"::text[])")
+	}
+	if len(r.Classes) > 0 {
+		conds = append(conds, "w.class::text = ANY("+arg(pq.Array(fn.Map(r.Classes, func(c model.WordClass) string { return string(c) })))+"::text[])")
+	}
+	if len(r.Statuses) > 0 {
+		conds = append(conds, "p.status::text = ANY("+arg(pq.Array(fn.Map(r.Statuses, func(s model.PickStatus) string { return string(s) })))+"::text[])")
+	}
+	if !r.CreatedAfter.IsZero() {
+		conds = append(conds, "p.created_at >= "+arg(r.CreatedAfter))
+	}
+	if !r.CreatedBefore.IsZero() {
+		conds = append(conds, "p.created_at < "+arg(r.CreatedBefore))
+	}
+	// every requested tag is matched by the tag itself or by a tag below it
+	for _, tagID := range r.WithTags {
+		conds = append(conds, pickTagged(tagSubtree("sr.id = "+arg(tagID))))
+	}
+	if len(r.WithoutTags) > 0 {
+		conds = append(conds, "NOT "+pickTagged(tagSubtree("sr.id = ANY("+arg(pq.Array(r.WithoutTags))+"::int[])")))
+	}
+	if r.TagExpr != nil {
+		conds = append(conds, compileTagExpr(r.TagExpr, args))
 	}
 
-	rows, err := s.db.QueryContext(ctx, "SELECT id, tag FROM tags WHERE tag = ANY($1::text[])", pq.Array(r.Tags))
+	return strings.Join(conds, " AND\n\t\t\t")
+}
+
+// ListUserPickIDs returns the IDs of up to PageSize picks of the user matching the filters of the request
+// in ascending order, ignoring its sorting and cursor
+func (s *PostresStore) ListUserPickIDs(ctx context.Context, r GetUserPicksRequest) ([]int64, error) {
+	args := []any{}
+	conds := pickConditions(r, &args)
+	args = append(args, r.PageSize)
+
+	query := fmt.Sprintf(`
+		SELECT p.id
+		FROM user_picks AS p
+		JOIN definitions AS d
+			ON p.def_id = d.id
+		JOIN words AS w
+			ON d.word_id = w.id
+		WHERE %s
+		ORDER BY p.id
+		LIMIT $%d
+	`, conds, len(args))
+	if r.ForUpdate {
+		query += " FOR UPDATE OF p"
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("query tag ids: %w", err)
+		return nil, fmt.Errorf("query user pick ids: %w", err)
 	}
 	defer rows.Close()
 
-	tagIDMap := make(model.TagIDMap)
+	var ids []int64
 	for rows.Next() {
 		var id int64
-		var tag string
-		if err := rows.Scan(&id, &tag); err != nil {
-			return nil, fmt.Errorf("scan tag: %w", err)
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user pick id: %w", err)
 		}
-
-		tagIDMap[tag] = id
+		ids = append(ids, id)
 	}
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate tags: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user pick ids: %w", err)
 	}
 
-	return tagIDMap, nil
+	return ids, nil
 }
 
-func (s *PostresStore) GetTags(ctx context.Context, r GetTagsRequest) (model.TagIDMap, error) {
-	if len(r.Tags) == 0 {
-		return model.TagIDMap{}, nil
+// GetPick returns the state of a pick of the user without its word, definition and tags,
+// or ErrNotFound if the user has no such pick
+func (s *PostresStore) GetPick(ctx context.Context, r GetPickRequest) (model.UserPick, error) {
+	query := `
+		SELECT id, user_id, def_id, status, COALESCE(def_override, ''), COALESCE(image_id, 0)
+		FROM user_picks
+		WHERE id = $1 AND user_id = $2
+	`
+	if r.ForUpdate {
+		query += " FOR UPDATE"
 	}
 
-	rows, err := s.db.QueryContext(ctx, "SELECT id, tag FROM tags WHERE tag = ANY($1::text[])", pq.Array(r.Tags))
+	var pick model.UserPick
+	err := s.db.QueryRowContext(ctx, query, r.PickID, r.UserID).Scan(
+		&pick.ID,
+		&pick.UserID,
+		&pick.Definition.ID,
+		&pick.Status,
+		&pick.DefOverride,
+		&pick.ImageID,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("query tag ids: %w", err)
-	}
-	defer rows.Close()
-
-	tagIDMap := make(model.TagIDMap)
-	for rows.Next() {
-		var id int64
-		var tag string
-		if err := rows.Scan(&id, &tag); err != nil {
-			return nil, fmt.Errorf("scan tag: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UserPick{}, ErrNotFound
 		}
 
-		tagIDMap[tag] = id
-	}
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate tags: %w", err)
+		return model.UserPick{}, fmt.Errorf("get pick: %w", err)
 	}
 
-	return tagIDMap, nil
+	return pick, nil
 }
 
-func (s *PostresStore) AddTags(ctx context.Context, r AddTagsRequest) error {
-	_, err := s.db.ExecContext(ctx, "INSERT INTO tags_map (pick_id, tag_id) SELECT $1, UNNEST($2::int[])", r.PickID, pq.Array(r.TagIDs))
+// UpdatePick stores the status and the overrides of a pick of the user
+// or returns ErrNotFound if the user has no such pick
+func (s *PostresStore) UpdatePick(ctx context.Context, r UpdatePickRequest) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE user_picks
+		SET
+			status = $1,
+			def_override = NULLIF($2, ''),
+			image_id = NULLIF($3, 0),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND user_id = $5
+	`, r.Status, r.DefOverride, r.ImageID, r.PickID, r.UserID)
 	if err != nil {
-		if isPqErr(err, errUniqueViolation) {
-			return ErrExists
-		}
 		if isPqErr(err, errForeignKeyViolation) {
 			return ErrNotFound
 		}
 
-		return fmt.Errorf("insert tag: %w", err)
+		return fmt.Errorf("update pick: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
 	}
 
 	return nil
 }
 
-func (s *PostresStore) RemoveTags(ctx context.Context, r RemoveTagsRequest) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM tags_map WHERE pick_id = $1 AND tag_id = ANY($2::int[])", r.PickID, pq.Array(r.TagIDs))
+// SchedulePick sets the status and the next review time of a pick of the user
+// or returns ErrNotFound if the user has no such pick
+func (s *PostresStore) SchedulePick(ctx context.Context, r SchedulePickRequest) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE user_picks
+		SET status = $1, next_review_at = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND user_id = $4
+	`, r.Status, r.NextReviewAt, r.PickID, r.UserID)
 	if err != nil {
-		return fmt.Errorf("delete tag: %w", err)
+		return fmt.Errorf("schedule pick: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
 	}
 
 	return nil
 }
 
-func (s *PostresStore) CreateDefinition(ctx context.Context, r CreateDefinitionRequest) (int64, error) {
-	res := s.db.QueryRowContext(ctx, "INSERT INTO definitions (word_id, def, rarity, source) VALUES ($1, $2, $3, $4) RETURNING id",
-		r.WordID,
-		r.Text,
-		r.Rarity,
-		r.Source)
-
+// AddPickContext adds a context sentence to a pick of the user and returns its ID
+// or ErrNotFound if the user has no such pick
+func (s *PostresStore) AddPickContext(ctx context.Context, r AddPickContextRequest) (int64, error) {
 	var id int64
-	err := res.Scan(&id)
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO pick_contexts (pick_id, sentence, source_title, source_url)
+		SELECT id, $3, NULLIF($4, ''), NULLIF($5, '')
+		FROM user_picks
+		WHERE id = $1 AND user_id = $2
+		RETURNING id
+	`, r.PickID, r.UserID, r.Sentence, r.SourceTitle, r.SourceURL).Scan(&id)
 	if err != nil {
-		if isPqErr(err, errForeignKeyViolation) {
+		if errors.Is(err, sql.ErrNoRows) {
 			return 0, ErrNotFound
 		}
-		if isPqErr(err, errUniqueViolation) {
-			return 0, ErrExists
-		}
 
-		return 0, fmt.Errorf("insert definition: %w", err)
+		return 0, fmt.Errorf("add pick context: %w", err)
 	}
 
 	return id, nil
 }
 
-func (s *PostresStore) AttachImage(ctx context.Context, r AttachImageRequest) (int64, error) {
-	res := s.db.QueryRowContext(ctx, "INSERT INTO images(def_id, url, source) VALUES ($1, $2, $3) RETURNING id",
-		r.DefID,
-		r.ImageURL,
-		r.Source)
+// pickContexts returns the contexts of the picks by pick ID, oldest first
+func (s *PostresStore) pickContexts(ctx context.Context, pickIDs []int64) (map[int64][]model.PickContext, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, pick_id, sentence, COALESCE(source_title, ''), COALESCE(source_url, ''), created_at
+		FROM pick_contexts
+		WHERE pick_id = ANY($1)
+		ORDER BY pick_id, id
+	`, pq.Array(pickIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query pick contexts: %w", err)
+	}
+	defer rows.Close()
 
-	var id int64
-	if err := res.Scan(&id); err != nil {
-		if isPqErr(err, errForeignKeyViolation) {
-			return 0, ErrNotFound
-		}
-		if isPqErr(err, errUniqueViolation) {
-			return 0, ErrExists
+	contexts := make(map[int64][]model.PickContext)
+	for rows.Next() {
+		var c model.PickContext
+		if err := rows.Scan(&c.ID, &c.PickID, &c.Sentence, &c.SourceTitle, &c.SourceURL, &c.CreateAt); err != nil {
+			return nil, fmt.Errorf("scan pick context: %w", err)
 		}
+		contexts[c.PickID] = append(contexts[c.PickID], c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pick contexts: %w", err)
+	}
 
-		return 0, fmt.Errorf("insert image: %w", err)
+	return contexts, nil
+}
+
+// DeleteUserPick deletes a pick of the user or returns ErrNotFound if the user has no such pick
+func (s *PostresStore) DeleteUserPick(ctx context.Context, r DeleteUserPickRequest) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM user_picks WHERE id = $1 AND user_id = $2", r.PickID, r.UserID)
+	if err != nil {
+		return fmt.Errorf("delete user pick: %w", err)
 	}
 
-	return id, nil
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
 }
 
-func (s *PostresStore) WithTx(ctx context.Context, fn func(tx DataStore) error) error {
-	db, ok := s.db.(*sql.DB)
-	if !ok {
-		return fmt.Errorf("begin tx: already in tx")
+// DeleteUserPicks deletes all picks, tags, tag filters, notes, note votes and import jobs of the user
+// and returns the number of deleted picks. The statements are not atomic on their own, so it is meant
+// to run in a transaction.
+func (s *PostresStore) DeleteUserPicks(ctx context.Context, r DeleteUserPicksRequest) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM user_picks WHERE user_id = $1", r.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("delete user picks: %w", err)
 	}
 
-	tx, err := db.BeginTx(ctx, nil)
+	n, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("tx begin: %w", err)
+		return 0, fmt.Errorf("rows affected: %w", err)
 	}
 
-	txStore := &PostresStore{db: tx}
-	if err := fn(txStore); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx rollback: %v after: %w", rbErr, err)
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM tags WHERE user_id = $1", r.UserID); err != nil {
+		return 0, fmt.Errorf("delete user tags: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM tag_filters WHERE user_id = $1", r.UserID); err != nil {
+		return 0, fmt.Errorf("delete user tag filters: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM note_votes WHERE user_id = $1", r.UserID); err != nil {
+		return 0, fmt.Errorf("delete user note votes: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM import_jobs WHERE user_id = $1", r.UserID); err != nil {
+		return 0, fmt.Errorf("delete user import jobs: %w", err)
+	}
+
+	return n, nil
+}
+
+// noteColumns selects the note n of the pick p in the order scanNote reads them
+const noteColumns = "n.id, n.pick_id, p.user_id, p.def_id, n.body, n.public, n.created_at, n.updated_at"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNote(row rowScanner) (model.Note, error) {
+	var n model.Note
+	err := row.Scan(&n.ID, &n.PickID, &n.UserID, &n.DefID, &n.Body, &n.Public, &n.CreateAt, &n.UpdatedAt)
+	return n, err
+}
+
+// GetPickNote returns the note on a pick of the user or ErrNotFound if there is none
+func (s *PostresStore) GetPickNote(ctx context.Context, r GetPickNoteRequest) (model.Note, error) {
+	query := `
+		SELECT ` + noteColumns + `
+		FROM pick_notes AS n
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		WHERE n.pick_id = $1 AND p.user_id = $2
+	`
+	if r.ForUpdate {
+		query += " FOR UPDATE OF n"
+	}
+
+	n, err := scanNote(s.db.QueryRowContext(ctx, query, r.PickID, r.UserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Note{}, ErrNotFound
 		}
-		return err
+
+		return model.Note{}, fmt.Errorf("get pick note: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("tx commit: %w", err)
+	return n, nil
+}
+
+// SavePickNote creates or replaces the note on a pick of the user.
+// It returns ErrNotFound if the user has no such pick.
+func (s *PostresStore) SavePickNote(ctx context.Context, r SavePickNoteRequest) (model.Note, error) {
+	n, err := scanNote(s.db.QueryRowContext(ctx, `
+		WITH n AS (
+			INSERT INTO pick_notes (pick_id, body, public)
+			SELECT id, $3, $4
+			FROM user_picks
+			WHERE id = $1 AND user_id = $2
+			ON CONFLICT (pick_id) DO UPDATE SET
+				body = EXCLUDED.body,
+				public = EXCLUDED.public,
+				updated_at = CURRENT_TIMESTAMP
+			RETURNING *
+		)
+		SELECT `+noteColumns+`
+		FROM n
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+	`, r.PickID, r.UserID, r.Body, r.Public))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Note{}, ErrNotFound
+		}
+
+		return model.Note{}, fmt.Errorf("save pick note: %w", err)
+	}
+
+	return n, nil
+}
+
+// DeletePickNote deletes the note on a pick of the user together with its revisions and votes.
+// It returns ErrNotFound if there is no such note.
+func (s *PostresStore) DeletePickNote(ctx context.Context, r DeletePickNoteRequest) error {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM pick_notes AS n
+		USING user_picks AS p
+		WHERE p.id = n.pick_id AND n.pick_id = $1 AND p.user_id = $2
+	`, r.PickID, r.UserID)
+	if err != nil {
+		return fmt.Errorf("delete pick note: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
 	}
 
 	return nil
 }
 
-func isPqErr(err error, code pq.ErrorCode) bool {
-	pqErr, ok := err.(*pq.Error)
-	if !ok {
-		return false
+// ListUserNotes lists the notes on all picks of the user ordered by pick
+func (s *PostresStore) ListUserNotes(ctx context.Context, r ListUserNotesRequest) ([]model.Note, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+noteColumns+`
+		FROM pick_notes AS n
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		WHERE p.user_id = $1
+		ORDER BY n.pick_id
+	`, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query user notes: %w", err)
 	}
+	defer rows.Close()
 
-	return pqErr.Code == code
+	var notes []model.Note
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user note: %w", err)
+		}
+
+		notes = append(notes, n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+func (s *PostresStore) AddNoteRevision(ctx context.Context, r AddNoteRevisionRequest) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, "INSERT INTO note_revisions (note_id, body) VALUES ($1, $2) RETURNING id", r.NoteID, r.Body).Scan(&id)
+	if err != nil {
+		if isPqErr(err, errForeignKeyViolation) {
+			return 0, ErrNotFound
+		}
+
+		return 0, fmt.Errorf("add note revision: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListNoteRevisions lists the revisions of the note on a pick of the user, the latest revision first.
+// The list is empty if there is no such note.
+func (s *PostresStore) ListNoteRevisions(ctx context.Context, r ListNoteRevisionsRequest) ([]model.NoteRevision, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.note_id, r.body, r.created_at
+		FROM note_revisions AS r
+		JOIN pick_notes AS n
+			ON n.id = r.note_id
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		WHERE ($1 = 0 OR n.pick_id = $1) AND p.user_id = $2
+		ORDER BY r.id DESC
+	`, r.PickID, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query note revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []model.NoteRevision
+	for rows.Next() {
+		var rev model.NoteRevision
+		if err := rows.Scan(&rev.ID, &rev.NoteID, &rev.Body, &rev.CreateAt); err != nil {
+			return nil, fmt.Errorf("scan note revision: %w", err)
+		}
+
+		revisions = append(revisions, rev)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate note revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetPublicNote returns a public note of any user or ErrNotFound if there is no such note
+func (s *PostresStore) GetPublicNote(ctx context.Context, r GetPublicNoteRequest) (model.Note, error) {
+	n, err := scanNote(s.db.QueryRowContext(ctx, `
+		SELECT `+noteColumns+`
+		FROM pick_notes AS n
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		WHERE n.id = $1 AND n.public
+	`, r.NoteID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Note{}, ErrNotFound
+		}
+
+		return model.Note{}, fmt.Errorf("get public note: %w", err)
+	}
+
+	return n, nil
+}
+
+// VoteNote upvotes a note on behalf of the user, voting twice has no effect.
+// It returns ErrNotFound if there is no such note.
+func (s *PostresStore) VoteNote(ctx context.Context, r VoteNoteRequest) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO note_votes (note_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", r.NoteID, r.UserID)
+	if err != nil {
+		if isPqErr(err, errForeignKeyViolation) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("vote note: %w", err)
+	}
+
+	return nil
+}
+
+// UnvoteNote withdraws the upvote of the user, withdrawing a vote that was never given has no effect
+func (s *PostresStore) UnvoteNote(ctx context.Context, r VoteNoteRequest) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM note_votes WHERE note_id = $1 AND user_id = $2", r.NoteID, r.UserID)
+	if err != nil {
+		return fmt.Errorf("unvote note: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserVotes lists the upvotes the user gave, oldest first
+func (s *PostresStore) ListUserVotes(ctx context.Context, r ListUserVotesRequest) ([]model.NoteVote, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT v.note_id, v.user_id, p.def_id, v.created_at
+		FROM note_votes AS v
+		JOIN pick_notes AS n
+			ON n.id = v.note_id
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		WHERE v.user_id = $1
+		ORDER BY v.created_at, v.note_id
+	`, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query user votes: %w", err)
+	}
+	defer rows.Close()
+
+	var votes []model.NoteVote
+	for rows.Next() {
+		var v model.NoteVote
+		if err := rows.Scan(&v.NoteID, &v.UserID, &v.DefID, &v.CreateAt); err != nil {
+			return nil, fmt.Errorf("scan user vote: %w", err)
+		}
+
+		votes = append(votes, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user votes: %w", err)
+	}
+
+	return votes, nil
+}
+
+// ListMnemonics lists the public notes written on picks of the definition, the most upvoted
+// first and recently updated notes first among notes with the same number of votes
+func (s *PostresStore) ListMnemonics(ctx context.Context, r ListMnemonicsRequest) ([]model.Mnemonic, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+noteColumns+`, COUNT(v.user_id) AS votes, COALESCE(bool_or(v.user_id = $2), FALSE)
+		FROM pick_notes AS n
+		JOIN user_picks AS p
+			ON p.id = n.pick_id
+		LEFT JOIN note_votes AS v
+			ON v.note_id = n.id
+		WHERE p.def_id = $1 AND n.public
+		GROUP BY n.id, p.user_id, p.def_id
+		ORDER BY votes DESC, n.updated_at DESC, n.id DESC
+		LIMIT $3
+	`, r.DefID, r.UserID, r.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("query mnemonics: %w", err)
+	}
+	defer rows.Close()
+
+	var mnemonics []model.Mnemonic
+	for rows.Next() {
+		var m model.Mnemonic
+		err := rows.Scan(&m.ID, &m.PickID, &m.UserID, &m.DefID, &m.Body, &m.Public, &m.CreateAt, &m.UpdatedAt, &m.Votes, &m.Voted)
+		if err != nil {
+			return nil, fmt.Errorf("scan mnemonic: %w", err)
+		}
+
+		mnemonics = append(mnemonics, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mnemonics: %w", err)
+	}
+
+	return mnemonics, nil
+}
+
+// CreateTags creates the given tags of the user together with their missing ancestors
+// and returns the IDs of the given tags
+func (s *PostresStore) CreateTags(ctx context.Context, r CreateTagsRequest) (model.TagIDMap, error) {
+	if len(r.Tags) == 0 {
+		return model.TagIDMap{}, nil
+	}
+
+	// parents are created level by level before their children, so that children can reference them
+	for _, level := range tagLevels(r.Tags) {
+		parents := fn.Map(level, model.TagParent)
+		_, err := s.db.ExecContext(ctx, `
+			INSERT INTO tags (user_id, tag, parent_id)
+			SELECT $1, n.tag, (SELECT id FROM tags WHERE user_id = $1 AND tag = n.parent)
+			FROM UNNEST($2::text[], $3::text[]) AS n(tag, parent)
+			ON CONFLICT (user_id, tag) DO NOTHING
+		`, r.UserID, pq.Array(level), pq.Array(parents))
+		if err != nil {
+			return nil, fmt.Errorf("insert tags: %w", err)
+		}
+	}
+
+	return s.GetTags(ctx, GetTagsRequest{UserID: r.UserID, Tags: r.Tags})
+}
+
+func (s *PostresStore) GetTags(ctx context.Context, r GetTagsRequest) (model.TagIDMap, error) {
+	if len(r.Tags) == 0 {
+		return model.TagIDMap{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, tag FROM tags WHERE user_id = $1 AND tag = ANY($2::text[])", r.UserID, pq.Array(r.Tags))
+	if err != nil {
+		return nil, fmt.Errorf("query tag ids: %w", err)
+	}
+	defer rows.Close()
+
+	tagIDMap := make(model.TagIDMap)
+	for rows.Next() {
+		var id int64
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+
+		tagIDMap[tag] = id
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+
+	return tagIDMap, nil
+}
+
+func (s *PostresStore) GetTag(ctx context.Context, r GetTagRequest) (model.Tag, error) {
+	query := `
+		SELECT id, user_id, tag, COALESCE(parent_id, 0), created_at, updated_at
+		FROM tags
+		WHERE id = $1 AND user_id = $2
+	`
+	if r.ForUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var t model.Tag
+	err := s.db.QueryRowContext(ctx, query, r.TagID, r.UserID).Scan(&t.ID, &t.UserID, &t.Text, &t.ParentID, &t.CreateAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Tag{}, ErrNotFound
+		}
+
+		return model.Tag{}, fmt.Errorf("get tag: %w", err)
+	}
+
+	return t, nil
+}
+
+// ListTags lists the tags of the user ordered by path, together with the number of picks using them
+func (s *PostresStore) ListTags(ctx context.Context, r ListTagsRequest) ([]model.TagUsage, error) {
+	query := `
+		SELECT t.id, t.user_id, t.tag, COALESCE(t.parent_id, 0), t.created_at, t.updated_at, COUNT(m.pick_id)
+		FROM tags AS t
+		LEFT JOIN tags_map AS m
+			ON m.tag_id = t.id
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY t.tag
+	`
+	rows, err := s.db.QueryContext(ctx, query, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []model.TagUsage
+	for rows.Next() {
+		var t model.TagUsage
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Text, &t.ParentID, &t.CreateAt, &t.UpdatedAt, &t.Picks); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+
+		tags = append(tags, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// MoveTag changes the path of a tag of the user together with the paths of all tags below it,
+// creating the missing ancestors of the new path. It returns the previous paths of the moved tags.
+// The moved tags stay locked until the end of the transaction it must be called in.
+func (s *PostresStore) MoveTag(ctx context.Context, r MoveTagRequest) ([]string, error) {
+	tag, err := s.GetTag(ctx, GetTagRequest{UserID: r.UserID, TagID: r.TagID, ForUpdate: true})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		SELECT id FROM tags
+		WHERE user_id = $1 AND left(tag, length($2::text) + 1) = $2::text || '/'
+		ORDER BY id
+		FOR UPDATE
+	`, r.UserID, tag.Text)
+	if err != nil {
+		return nil, fmt.Errorf("lock tags below: %w", err)
+	}
+
+	var parentID sql.NullInt64
+	if parent := model.TagParent(r.Path); parent != "" {
+		ids, err := s.CreateTags(ctx, CreateTagsRequest{UserID: r.UserID, Tags: []string{parent}})
+		if err != nil {
+			return nil, fmt.Errorf("create parent tag: %w", err)
+		}
+		parentID = sql.NullInt64{Int64: ids[parent], Valid: true}
+	}
+
+	moved, err := s.repathTags(ctx, r.UserID, tag.Text, r.Path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE tags SET parent_id = $2 WHERE id = $1", r.TagID, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("update parent: %w", err)
+	}
+
+	return moved, nil
+}
+
+// MergeTags moves all picks of one tag of the user to another one, moves the tags below the first tag
+// under the second one and deletes the first tag. It returns the previous paths of the first tag
+// and the tags below it. Must be called in a transaction.
+func (s *PostresStore) MergeTags(ctx context.Context, r MergeTagsRequest) ([]string, error) {
+	from, err := s.GetTag(ctx, GetTagRequest{UserID: r.UserID, TagID: r.FromID})
+	if err != nil {
+		return nil, err
+	}
+	into, err := s.GetTag(ctx, GetTagRequest{UserID: r.UserID, TagID: r.IntoID})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tags_map (pick_id, tag_id)
+		SELECT pick_id, $2 FROM tags_map WHERE tag_id = $1
+		ON CONFLICT (pick_id, tag_id) DO NOTHING
+	`, from.ID, into.ID)
+	if err != nil {
+		return nil, fmt.Errorf("move picks: %w", err)
+	}
+
+	moved, err := s.repathTags(ctx, r.UserID, from.Text, into.Text, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE tags SET parent_id = $2 WHERE parent_id = $1", from.ID, into.ID)
+	if err != nil {
+		return nil, fmt.Errorf("move children: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM tags WHERE id = $1", from.ID); err != nil {
+		return nil, fmt.Errorf("delete tag: %w", err)
+	}
+
+	return append(moved, from.Text), nil
+}
+
+// DeleteTag deletes a tag of the user and all tags below it, removing them from all picks.
+// It returns the paths of the deleted tags.
+func (s *PostresStore) DeleteTag(ctx context.Context, r DeleteTagRequest) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		DELETE FROM tags AS t
+		USING tags AS root
+		WHERE
+			root.id = $1 AND
+			root.user_id = $2 AND
+			t.user_id = root.user_id AND
+			(t.id = root.id OR left(t.tag, length(root.tag) + 1) = root.tag || '/')
+		RETURNING t.tag
+	`, r.TagID, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("delete tags: %w", err)
+	}
+
+	deleted, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan deleted tags: %w", err)
+	}
+	if len(deleted) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return deleted, nil
+}
+
+// repathTags replaces the path prefix from with to in the tags below from and, when withRoot is set,
+// in from itself. It returns the previous paths of the changed tags.
+func (s *PostresStore) repathTags(ctx context.Context, userID, from, to string, withRoot bool) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE tags
+		SET tag = $3::text || substr(tag, length($2::text) + 1), updated_at = CURRENT_TIMESTAMP
+		WHERE
+			user_id = $1 AND
+			((tag = $2::text AND $4) OR left(tag, length($2::text) + 1) = $2::text || '/')
+		RETURNING $2::text || substr(tag, length($3::text) + 1)
+	`, userID, from, to, withRoot)
+	if err != nil {
+		if isPqErr(err, errUniqueViolation) {
+			return nil, ErrExists
+		}
+
+		return nil, fmt.Errorf("update tag paths: %w", err)
+	}
+
+	moved, err := scanStrings(rows)
+	if err != nil {
+		if isPqErr(err, errUniqueViolation) {
+			return nil, ErrExists
+		}
+
+		return nil, fmt.Errorf("scan tag paths: %w", err)
+	}
+
+	return moved, nil
+}
+
+// SaveTagFilter creates or replaces the tag filter of the user with the given name and returns its ID
+func (s *PostresStore) SaveTagFilter(ctx context.Context, r SaveTagFilterRequest) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO tag_filters (user_id, name, expr)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, name) DO UPDATE
+		SET expr = EXCLUDED.expr, updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, r.UserID, r.Name, r.Expr).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("save tag filter: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *PostresStore) GetTagFilter(ctx context.Context, r GetTagFilterRequest) (model.TagFilter, error) {
+	var f model.TagFilter
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, user_id, name, expr, created_at, updated_at
+		FROM tag_filters
+		WHERE user_id = $1 AND name = $2
+	`, r.UserID, r.Name).Scan(&f.ID, &f.UserID, &f.Name, &f.Expr, &f.CreateAt, &f.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.TagFilter{}, ErrNotFound
+		}
+
+		return model.TagFilter{}, fmt.Errorf("get tag filter: %w", err)
+	}
+
+	return f, nil
+}
+
+// ListTagFilters lists the tag filters of the user ordered by name
+func (s *PostresStore) ListTagFilters(ctx context.Context, r ListTagFiltersRequest) ([]model.TagFilter, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, expr, created_at, updated_at
+		FROM tag_filters
+		WHERE user_id = $1
+		ORDER BY name
+	`, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query tag filters: %w", err)
+	}
+	defer rows.Close()
+
+	var filters []model.TagFilter
+	for rows.Next() {
+		var f model.TagFilter
+		if err := rows.Scan(&f.ID, &f.UserID, &f.Name, &f.Expr, &f.CreateAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan tag filter: %w", err)
+		}
+
+		filters = append(filters, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tag filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+func (s *PostresStore) DeleteTagFilter(ctx context.Context, r DeleteTagFilterRequest) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM tag_filters WHERE user_id = $1 AND name = $2", r.UserID, r.Name)
+	if err != nil {
+		return fmt.Errorf("delete tag filter: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AddTags assigns tags to a pick of the user. It returns ErrNotFound if the user
+// has no such pick or a tag does not exist.
+func (s *PostresStore) AddTags(ctx context.Context, r AddTagsRequest) error {
+	var owned bool
+	err := s.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM user_picks WHERE id = $1 AND user_id = $2)",
+		r.PickID, r.UserID).Scan(&owned)
+	if err != nil {
+		return fmt.Errorf("check user pick: %w", err)
+	}
+	if !owned {
+		return ErrNotFound
+	}
+
+	query := "INSERT INTO tags_map (pick_id, tag_id) SELECT $1, UNNEST($2::int[])"
+	if r.IgnoreExisting {
+		query += " ON CONFLICT DO NOTHING"
+	}
+
+	_, err = s.db.ExecContext(ctx, query, r.PickID, pq.Array(r.TagIDs))
+	if err != nil {
+		if isPqErr(err, errUniqueViolation) {
+			return ErrExists
+		}
+		if isPqErr(err, errForeignKeyViolation) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("insert tag: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTags removes tags from a pick of the user, picks of other users are left untouched
+func (s *PostresStore) RemoveTags(ctx context.Context, r RemoveTagsRequest) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM tags_map AS m
+		USING user_picks AS p
+		WHERE m.pick_id = $1 AND m.tag_id = ANY($3::int[]) AND p.id = m.pick_id AND p.user_id = $2
+	`, r.PickID, r.UserID, pq.Array(r.TagIDs))
+	if err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostresStore) CreateDefinition(ctx context.Context, r CreateDefinitionRequest) (int64, error) {
+	res := s.db.QueryRowContext(ctx, "INSERT INTO definitions (word_id, def, rarity, source) VALUES ($1, $2, $3, $4) RETURNING id",
+		r.WordID,
+		r.Text,
+		r.Rarity,
+		r.Source)
+
+	var id int64
+	err := res.Scan(&id)
+	if err != nil {
+		if isPqErr(err, errForeignKeyViolation) {
+			return 0, ErrNotFound
+		}
+		if isPqErr(err, errUniqueViolation) {
+			return 0, ErrExists
+		}
+
+		return 0, fmt.Errorf("insert definition: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *PostresStore) AttachImage(ctx context.Context, r AttachImageRequest) (int64, error) {
+	res := s.db.QueryRowContext(ctx, "INSERT INTO images(def_id, url, source) VALUES ($1, $2, $3) RETURNING id",
+		r.DefID,
+		r.ImageURL,
+		r.Source)
+
+	var id int64
+	if err := res.Scan(&id); err != nil {
+		if isPqErr(err, errForeignKeyViolation) {
+			return 0, ErrNotFound
+		}
+		if isPqErr(err, errUniqueViolation) {
+			return 0, ErrExists
+		}
+
+		return 0, fmt.Errorf("insert image: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetImage returns an image attached to a definition or ErrNotFound if there is no such image
+func (s *PostresStore) GetImage(ctx context.Context, r GetImageRequest) (model.Image, error) {
+	var img model.Image
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, def_id, url, source, created_at, updated_at
+		FROM images
+		WHERE id = $1
+	`, r.ImageID).Scan(&img.ID, &img.DefID, &img.URL, &img.Source, &img.CreateAt, &img.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Image{}, ErrNotFound
+		}
+
+		return model.Image{}, fmt.Errorf("get image: %w", err)
+	}
+
+	return img, nil
+}
+
+type dictWordKey struct {
+	lemma string
+	lang  model.Lang
+	class model.WordClass
+}
+
+type dictDefKey struct {
+	wordID int64
+	text   string
+}
+
+type dictImageKey struct {
+	defID int64
+	url   string
+}
+
+// UpsertDictionary inserts the words, definitions and images of the entries that do not exist yet and leaves
+// the existing ones as they are, words are matched by lemma, language and class and definitions by their word
+// and text. Each table is written with a single statement, whatever the number of entries.
+func (s *PostresStore) UpsertDictionary(ctx context.Context, r UpsertDictionaryRequest) (UpsertDictionaryResponse, error) {
+	resp := UpsertDictionaryResponse{Created: make([]bool, len(r.Entries))}
+	if len(r.Entries) == 0 {
+		return resp, nil
+	}
+
+	wordIDs, newWords, err := s.upsertWords(ctx, r.Entries)
+	if err != nil {
+		return UpsertDictionaryResponse{}, err
+	}
+
+	defIDs, newDefs, err := s.upsertDefinitions(ctx, r.Entries, wordIDs)
+	if err != nil {
+		return UpsertDictionaryResponse{}, err
+	}
+
+	var (
+		imgDefIDs []int64
+		imgURLs   []string
+		imgSrcs   []string
+	)
+	for _, e := range r.Entries {
+		wordID := wordIDs[dictWordKey{e.Lemma, e.Lang, e.Class}]
+		for _, d := range e.Definitions {
+			for _, u := range d.ImageURLs {
+				imgDefIDs = append(imgDefIDs, defIDs[dictDefKey{wordID, d.Text}])
+				imgURLs = append(imgURLs, u)
+				imgSrcs = append(imgSrcs, string(d.Source))
+			}
+		}
+	}
+
+	newImages := make(map[dictImageKey]bool)
+	if len(imgDefIDs) > 0 {
+		rows, err := s.db.QueryContext(ctx, `
+			INSERT INTO images (def_id, url, source)
+			SELECT * FROM unnest($1::int[], $2::text[], $3::source_type[])
+			ON CONFLICT (def_id, url) DO NOTHING
+			RETURNING def_id, url
+		`, pq.Array(imgDefIDs), pq.Array(imgURLs), pq.Array(imgSrcs))
+		if err != nil {
+			return UpsertDictionaryResponse{}, fmt.Errorf("insert images: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var k dictImageKey
+			if err := rows.Scan(&k.defID, &k.url); err != nil {
+				return UpsertDictionaryResponse{}, fmt.Errorf("scan image: %w", err)
+			}
+			newImages[k] = true
+			resp.Images++
+		}
+		if err := rows.Err(); err != nil {
+			return UpsertDictionaryResponse{}, fmt.Errorf("insert images: %w", err)
+		}
+	}
+
+	resp.WordIDs = make([]int64, len(r.Entries))
+	resp.DefIDs = make([][]int64, len(r.Entries))
+	for i, e := range r.Entries {
+		key := dictWordKey{e.Lemma, e.Lang, e.Class}
+		created := newWords[key]
+		resp.WordIDs[i] = wordIDs[key]
+		for _, d := range e.Definitions {
+			defID := defIDs[dictDefKey{wordIDs[key], d.Text}]
+			created = created || newDefs[defID]
+			for _, u := range d.ImageURLs {
+				created = created || newImages[dictImageKey{defID, u}]
+			}
+			resp.DefIDs[i] = append(resp.DefIDs[i], defID)
+		}
+		resp.Created[i] = created
+	}
+	resp.Words = len(newWords)
+	resp.Definitions = len(newDefs)
+
+	return resp, nil
+}
+
+// upsertWords inserts the words of the entries that do not exist yet and returns the IDs of all of them,
+// together with the words that were inserted
+func (s *PostresStore) upsertWords(ctx context.Context, entries []DictionaryEntry) (map[dictWordKey]int64, map[dictWordKey]bool, error) {
+	lemmas := make([]string, 0, len(entries))
+	langs := make([]string, 0, len(entries))
+	classes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lemmas = append(lemmas, e.Lemma)
+		langs = append(langs, string(e.Lang))
+		classes = append(classes, string(e.Class))
+	}
+	args := []any{pq.Array(lemmas), pq.Array(langs), pq.Array(classes)}
+
+	created := make(map[dictWordKey]bool)
+	err := s.scanWordKeys(ctx, func(k dictWordKey, _ int64) { created[k] = true }, `
+		INSERT INTO words (lemma, lang, class)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::lemma_class[])
+		ON CONFLICT (lemma, lang, class) DO NOTHING
+		RETURNING lemma, lang, class, id
+	`, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("insert words: %w", err)
+	}
+
+	// words inserted concurrently by others since the insert are found by the query of their IDs
+	ids := make(map[dictWordKey]int64)
+	err = s.scanWordKeys(ctx, func(k dictWordKey, id int64) { ids[k] = id }, `
+		SELECT w.lemma, w.lang, w.class, w.id
+		FROM words w
+		JOIN unnest($1::text[], $2::text[], $3::lemma_class[]) AS i(lemma, lang, class)
+			ON w.lemma = i.lemma AND w.lang = i.lang AND w.class = i.class
+	`, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get word ids: %w", err)
+	}
+
+	return ids, created, nil
+}
+
+func (s *PostresStore) scanWordKeys(ctx context.Context, fn func(k dictWordKey, id int64), query string, args ...any) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			k  dictWordKey
+			id int64
+		)
+		if err := rows.Scan(&k.lemma, &k.lang, &k.class, &id); err != nil {
+			return err
+		}
+		fn(k, id)
+	}
+
+	return rows.Err()
+}
+
+// upsertDefinitions inserts the definitions of the entries that do not exist yet and returns the IDs of all
+// of them, together with the IDs of the definitions that were inserted
+func (s *PostresStore) upsertDefinitions(ctx context.Context, entries []DictionaryEntry, wordIDs map[dictWordKey]int64) (map[dictDefKey]int64, map[int64]bool, error) {
+	var (
+		ids      []int64
+		texts    []string
+		rarities []int64
+		sources  []string
+	)
+	for _, e := range entries {
+		wordID, ok := wordIDs[dictWordKey{e.Lemma, e.Lang, e.Class}]
+		if !ok {
+			return nil, nil, fmt.Errorf("word %q (%s, %s) was not upserted", e.Lemma, e.Lang, e.Class)
+		}
+
+		for _, d := range e.Definitions {
+			ids = append(ids, wordID)
+			texts = append(texts, d.Text)
+			rarities = append(rarities, int64(d.Rarity))
+			sources = append(sources, string(d.Source))
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	created := make(map[int64]bool)
+	err := s.scanDefKeys(ctx, func(_ dictDefKey, id int64) { created[id] = true }, `
+		INSERT INTO definitions (word_id, def, rarity, source)
+		SELECT * FROM unnest($1::int[], $2::text[], $3::int[], $4::source_type[])
+		ON CONFLICT (word_id, def) DO NOTHING
+		RETURNING word_id, def, id
+	`, pq.Array(ids), pq.Array(texts), pq.Array(rarities), pq.Array(sources))
+	if err != nil {
+		return nil, nil, fmt.Errorf("insert definitions: %w", err)
+	}
+
+	defIDs := make(map[dictDefKey]int64)
+	err = s.scanDefKeys(ctx, func(k dictDefKey, id int64) { defIDs[k] = id }, `
+		SELECT d.word_id, d.def, d.id
+		FROM definitions d
+		JOIN unnest($1::int[], $2::text[]) AS i(word_id, def) ON d.word_id = i.word_id AND d.def = i.def
+	`, pq.Array(ids), pq.Array(texts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get definition ids: %w", err)
+	}
+
+	return defIDs, created, nil
+}
+
+func (s *PostresStore) scanDefKeys(ctx context.Context, fn func(k dictDefKey, id int64), query string, args ...any) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			k  dictDefKey
+			id int64
+		)
+		if err := rows.Scan(&k.wordID, &k.text, &id); err != nil {
+			return err
+		}
+		fn(k, id)
+	}
+
+	return rows.Err()
+}
+
+// UpsertDefinitionRelations adds the relations that do not exist yet and returns the number of relations added.
+// Relations between definitions that do not exist are skipped.
+func (s *PostresStore) UpsertDefinitionRelations(ctx context.Context, r UpsertDefinitionRelationsRequest) (int64, error) {
+	if len(r.Relations) == 0 {
+		return 0, nil
+	}
+
+	var cols [9][]string
+	for _, rel := range r.Relations {
+		for i, v := range []string{
+			rel.From.Lemma, string(rel.From.Lang), string(rel.From.Class), rel.From.Text,
+			rel.To.Lemma, string(rel.To.Lang), string(rel.To.Class), rel.To.Text,
+			string(rel.Type),
+		} {
+			cols[i] = append(cols[i], v)
+		}
+	}
+
+	args := make([]any, 0, len(cols))
+	for _, c := range cols {
+		args = append(args, pq.Array(c))
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO definition_relations (def_id, related_def_id, type)
+		SELECT fd.id, td.id, i.type
+		FROM unnest(
+			$1::text[], $2::text[], $3::lemma_class[], $4::text[],
+			$5::text[], $6::text[], $7::lemma_class[], $8::text[],
+			$9::relation_type[]
+		) AS i(from_lemma, from_lang, from_class, from_def, to_lemma, to_lang, to_class, to_def, type)
+		JOIN words AS fw
+			ON fw.lemma = i.from_lemma AND fw.lang = i.from_lang AND fw.class = i.from_class
+		JOIN definitions AS fd
+			ON fd.word_id = fw.id AND fd.def = i.from_def
+		JOIN words AS tw
+			ON tw.lemma = i.to_lemma AND tw.lang = i.to_lang AND tw.class = i.to_class
+		JOIN definitions AS td
+			ON td.word_id = tw.id AND td.def = i.to_def
+		WHERE fd.id <> td.id
+		ON CONFLICT (def_id, related_def_id, type) DO NOTHING
+	`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("insert definition relations: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("insert definition relations: %w", err)
+	}
+
+	return n, nil
+}
+
+// ListRelatedDefinitions lists the definitions related to a definition ordered by relation type and lemma,
+// or returns ErrNotFound if there is no such definition
+func (s *PostresStore) ListRelatedDefinitions(ctx context.Context, r ListRelatedDefinitionsRequest) ([]model.RelatedDefinition, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.type, d.id, d.def, d.rarity, d.source, w.id, w.lemma, w.lang, w.class
+		FROM definition_relations AS r
+		JOIN definitions AS d
+			ON d.id = r.related_def_id
+		JOIN words AS w
+			ON w.id = d.word_id
+		WHERE r.def_id = $1
+		ORDER BY r.type, w.lemma, d.id
+	`, r.DefID)
+	if err != nil {
+		return nil, fmt.Errorf("query related definitions: %w", err)
+	}
+	defer rows.Close()
+
+	var related []model.RelatedDefinition
+	for rows.Next() {
+		var rd model.RelatedDefinition
+		err := rows.Scan(
+			&rd.Type,
+			&rd.Definition.ID,
+			&rd.Definition.Text,
+			&rd.Definition.Rarity,
+			&rd.Definition.Source,
+			&rd.Word.ID,
+			&rd.Word.Lemma,
+			&rd.Word.Lang,
+			&rd.Word.Class,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan related definition: %w", err)
+		}
+		rd.Definition.WordID = rd.Word.ID
+		related = append(related, rd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate related definitions: %w", err)
+	}
+
+	if len(related) == 0 {
+		var exists bool
+		if err := s.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM definitions WHERE id = $1)", r.DefID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check definition: %w", err)
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	return related, nil
+}
+
+// WithTx runs fn in a transaction that is committed when fn succeeds and rolled back otherwise.
+// Called on a store that is already in a transaction, it runs fn in a savepoint instead, so that
+// a failing fn undoes only its own changes and leaves the enclosing transaction usable.
+func (s *PostresStore) WithTx(ctx context.Context, fn func(tx DataStore) error) error {
+	if tx, ok := s.db.(*sql.Tx); ok {
+		return withSavepoint(ctx, tx, fn)
+	}
+
+	db, ok := s.db.(*sql.DB)
+	if !ok {
+		return fmt.Errorf("begin tx: unsupported db %T", s.db)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("tx begin: %w", err)
+	}
+
+	txStore := &PostresStore{db: tx}
+	if err := fn(txStore); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx rollback: %v after: %w", rbErr, err)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("tx commit: %w", err)
+	}
+
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the transaction. Savepoints of nested calls share the name,
+// which is fine because releasing or rolling back a savepoint refers to the latest one of that name.
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func(tx DataStore) error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT nested_tx"); err != nil {
+		return fmt.Errorf("savepoint: %w", err)
+	}
+
+	if err := fn(&PostresStore{db: tx}); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT nested_tx"); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint: %v after: %w", rbErr, err)
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT nested_tx"); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// compileTagExpr compiles a tag expression into a condition on the pick p, appending the tag IDs to args.
+// A tag matches when the pick is tagged with it or with a tag below it, tags without an ID match nothing.
+func compileTagExpr(e tagexpr.Expr, args *[]any) string {
+	switch e := e.(type) {
+	case *tagexpr.Tag:
+		if e.ID == 0 {
+			return "FALSE"
+		}
+
+		*args = append(*args, e.ID)
+		return pickTagged(tagSubtree(fmt.Sprintf("sr.id = $%d", len(*args))))
+	case *tagexpr.Not:
+		return "NOT " + compileTagExpr(e.X, args)
+	case *tagexpr.And:
+		return "(" + compileTagExpr(e.X, args) + " AND " + compileTagExpr(e.Y, args) + ")"
+	case *tagexpr.Or:
+		return "(" + compileTagExpr(e.X, args) + " OR " + compileTagExpr(e.Y, args) + ")"
+	default:
+		return "TRUE"
+	}
+}
+
+// pickTagged is a condition on the pick p that holds when the pick is tagged with any of the tags
+// listed by the subquery. It probes the (pick_id, tag_id) index of tags_map for the pick instead of
+// aggregating all of its tags.
+func pickTagged(tagIDs string) string {
+	return fmt.Sprintf(`EXISTS (
+				SELECT 1
+				FROM tags_map AS m
+				WHERE m.pick_id = p.id AND m.tag_id IN (%s)
+			)`, tagIDs)
+}
+
+// tagSubtree is a subquery listing the IDs of the tags sr matching the condition and of all tags
+// below them. It does not depend on the pick, so it is evaluated once per query.
+func tagSubtree(cond string) string {
+	return fmt.Sprintf(`
+					SELECT st.id
+					FROM tags AS sr
+					JOIN tags AS st
+						ON st.user_id = sr.user_id AND
+						(st.id = sr.id OR left(st.tag, length(sr.tag) + 1) = sr.tag || '/')
+					WHERE %s
+				`, cond)
+}
+
+// tagLevels groups the tags and all of their ancestors by depth, top level tags first
+func tagLevels(tags []string) [][]string {
+	seen := make(map[string]bool)
+	var levels [][]string
+	for _, tag := range tags {
+		for depth, path := range append(model.TagAncestors(tag), tag) {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			for len(levels) <= depth {
+				levels = append(levels, nil)
+			}
+			levels[depth] = append(levels[depth], path)
+		}
+	}
+
+	return levels
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+
+		result = append(result, s)
+	}
+
+	return result, rows.Err()
+}
+
+func isPqErr(err error, code pq.ErrorCode) bool {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+
+	return pqErr.Code == code
+}
+
+// importJobError is an error of an import job as it is kept in the errors column
+type importJobError struct {
+	Index int    `json:"index"`
+	Msg   string `json:"msg"`
+}
+
+// CreateImportJob creates a running import job for the user and returns its ID
+func (s *PostresStore) CreateImportJob(ctx context.Context, r CreateImportJobRequest) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, "INSERT INTO import_jobs (user_id, total) VALUES ($1, $2) RETURNING id", r.UserID, r.Total).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("create import job: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateImportJob saves the progress of an import job or returns ErrNotFound if there is no such job.
+// The job is finished when its status is no longer running.
+func (s *PostresStore) UpdateImportJob(ctx context.Context, r UpdateImportJobRequest) error {
+	errs, err := json.Marshal(append([]importJobError{}, fn.Map(r.Errors, func(e model.ImportJobError) importJobError {
+		return importJobError{Index: e.Index, Msg: e.Msg}
+	})...))
+	if err != nil {
+		return fmt.Errorf("encode import errors: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE import_jobs
+		SET
+			status = $1,
+			cards = $2,
+			created = $3,
+			skipped = $4,
+			failed = $5,
+			errors = $6,
+			error = NULLIF($7, ''),
+			updated_at = CURRENT_TIMESTAMP,
+			finished_at = CASE WHEN $1 = 'running' THEN NULL ELSE CURRENT_TIMESTAMP END
+		WHERE id = $8
+	`, r.Status, r.Cards, r.Created, r.Skipped, r.Failed, errs, r.Error, r.ID)
+	if err != nil {
+		return fmt.Errorf("update import job: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get affected rows: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// importJobColumns selects an import job in the order scanImportJob reads them
+const importJobColumns = "id, user_id, status, total, cards, created, skipped, failed, errors, error, created_at, updated_at, finished_at"
+
+func scanImportJob(row rowScanner) (model.ImportJob, error) {
+	var (
+		j    model.ImportJob
+		errs []byte
+		msg  sql.NullString
+	)
+	err := row.Scan(
+		&j.ID, &j.UserID, &j.Status, &j.Total, &j.Cards, &j.Created, &j.Skipped, &j.Failed,
+		&errs, &msg, &j.CreateAt, &j.UpdatedAt, &j.FinishedAt,
+	)
+	if err != nil {
+		return model.ImportJob{}, err
+	}
+	j.Error = msg.String
+
+	var jobErrs []importJobError
+	if err := json.Unmarshal(errs, &jobErrs); err != nil {
+		return model.ImportJob{}, fmt.Errorf("decode import errors: %w", err)
+	}
+	j.Errors = fn.Map(jobErrs, func(e importJobError) model.ImportJobError {
+		return model.ImportJobError{Index: e.Index, Msg: e.Msg}
+	})
+
+	return j, nil
+}
+
+// GetImportJob returns an import job of the user or ErrNotFound if the user has no such job
+func (s *PostresStore) GetImportJob(ctx context.Context, r GetImportJobRequest) (model.ImportJob, error) {
+	j, err := scanImportJob(s.db.QueryRowContext(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		WHERE id = $1 AND user_id = $2
+	`, r.ID, r.UserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ImportJob{}, ErrNotFound
+		}
+
+		return model.ImportJob{}, fmt.Errorf("get import job: %w", err)
+	}
+
+	return j, nil
+}
+
+// ListImportJobs lists the import jobs of the user, oldest first
+func (s *PostresStore) ListImportJobs(ctx context.Context, r ListImportJobsRequest) ([]model.ImportJob, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`, r.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("query import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []model.ImportJob
+	for rows.Next() {
+		j, err := scanImportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan import job: %w", err)
+		}
+
+		jobs = append(jobs, j)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate import jobs: %w", err)
+	}
+
+	return jobs, nil
 }
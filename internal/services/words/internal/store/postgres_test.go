@@ -3,12 +3,15 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	testdb "github.com/gamma-omg/lexi-go/internal/pkg/test/db"
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/tagexpr"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -85,6 +88,23 @@ func TestInsertWord_Exists(t *testing.T) {
 	require.Equal(t, ErrExists, err)
 }
 
+func TestGetWord(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	id, err := pgstore.InsertWord(t.Context(), InsertWordRequst{Lemma: "run", Lang: "en", Class: "verb"})
+	require.NoError(t, err)
+
+	w, err := pgstore.GetWord(t.Context(), GetWordRequest{ID: id})
+	require.NoError(t, err)
+	assert.Equal(t, id, w.ID)
+	assert.Equal(t, "run", w.Lemma)
+	assert.Equal(t, model.Lang("en"), w.Lang)
+	assert.Equal(t, model.WordClass("verb"), w.Class)
+
+	_, err = pgstore.GetWord(t.Context(), GetWordRequest{ID: id + 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestDeleteWord(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
@@ -173,7 +193,15 @@ func TestDeleteUserPick(t *testing.T) {
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
 	)
 
+	// picks of other users are not deleted
 	err := pgstore.DeleteUserPick(t.Context(), DeleteUserPickRequest{
+		UserID: "user-456",
+		PickID: pickID,
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = pgstore.DeleteUserPick(t.Context(), DeleteUserPickRequest{
+		UserID: userID,
 		PickID: pickID,
 	})
 	require.NoError(t, err)
@@ -186,6 +214,30 @@ func TestDeleteUserPick(t *testing.T) {
 	require.Equal(t, 0, count)
 }
 
+func TestDeleteUserPicks(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+		def2ID = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A tech company.").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-123", defID).AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-123", "fruit").AsInt64()
+	)
+	testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-123", def2ID).AsInt64()
+	testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-456", defID).AsInt64()
+	testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING pick_id", pickID, tagID).AsInt64()
+
+	n, err := pgstore.DeleteUserPicks(t.Context(), DeleteUserPicksRequest{UserID: "user-123"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM user_picks WHERE user_id = $1", "user-123").AsInt64())
+	assert.Equal(t, int64(1), testdb.Query(t, db, "SELECT COUNT(1) FROM user_picks WHERE user_id = $1", "user-456").AsInt64())
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM tags WHERE id = $1", tagID).AsInt64())
+}
+
 func TestGetUserPicks(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
@@ -214,6 +266,37 @@ func TestGetUserPicks(t *testing.T) {
 	assert.Empty(t, response.Picks[0].Tags)
 }
 
+func TestGetUserPicks_Langs(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		userID = "user-123"
+		enID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "banana", "en", "noun").AsInt64()
+		deID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "Banane", "de", "noun").AsInt64()
+		enDef  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", enID, "A yellow fruit.").AsInt64()
+		deDef  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", deID, "Eine gelbe Frucht.").AsInt64()
+		dePick = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, deDef).AsInt64()
+	)
+	testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, enDef).AsInt64()
+
+	response, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		Langs:    []model.Lang{"de"},
+		PageSize: 100,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 1)
+	assert.Equal(t, dePick, response.Picks[0].ID)
+	assert.Equal(t, model.Lang("de"), response.Picks[0].Word.Lang)
+
+	response, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		PageSize: 100,
+	})
+	require.NoError(t, err)
+	assert.Len(t, response.Picks, 2)
+}
+
 func TestGetUserPicks_WithTags(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
@@ -222,7 +305,7 @@ func TestGetUserPicks_WithTags(t *testing.T) {
 		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "banana", "en", "noun").AsInt64()
 		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A yellow fruit.").AsInt64()
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
-		tagID  = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "testtag").AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "testtag").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A common fruit.").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID).AsInt64()
@@ -259,7 +342,7 @@ func TestGetUserPicks_WithoutTags(t *testing.T) {
 		defID1  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A thin metal fastener").AsInt64()
 		defID2  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "The hard part at the tip of a finger or toe").AsInt64()
 		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID1).AsInt64()
-		tagID   = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "unwantedtag").AsInt64()
+		tagID   = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "unwantedtag").AsInt64()
 		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID2).AsInt64()
 		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, tagID).AsInt64()
 	)
@@ -328,16 +411,18 @@ func TestDeleteUserPick_NotFound(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	err := pgstore.DeleteUserPick(t.Context(), DeleteUserPickRequest{
+		UserID: "user-123",
 		PickID: 999999,
 	})
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestCreateTags(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
-		Tags: []string{"tag1", "tag2", "tag3"},
+		UserID: "user-123",
+		Tags:   []string{"tag1", "tag2", "tag3"},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 3)
@@ -359,12 +444,14 @@ func TestCreateTags_ExistingTags(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	_, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
-		Tags: []string{"tag1", "tag2"},
+		UserID: "user-123",
+		Tags:   []string{"tag1", "tag2"},
 	})
 	require.NoError(t, err)
 
 	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
-		Tags: []string{"tag2", "tag3", "tag4"},
+		UserID: "user-123",
+		Tags:   []string{"tag2", "tag3", "tag4"},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 3)
@@ -384,7 +471,7 @@ func TestCreateTags_ExistingTags(t *testing.T) {
 func TestCreateTags_EmptyInput(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
-	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{Tags: []string{}})
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{UserID: "user-123", Tags: []string{}})
 	require.NoError(t, err)
 	require.Len(t, tags, 0)
 }
@@ -393,12 +480,14 @@ func TestGetTags(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	_, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
-		Tags: []string{"tagA", "tagB", "tagC"},
+		UserID: "user-123",
+		Tags:   []string{"tagA", "tagB", "tagC"},
 	})
 	require.NoError(t, err)
 
 	tags, err := pgstore.GetTags(t.Context(), GetTagsRequest{
-		Tags: []string{"tagA", "tagC"},
+		UserID: "user-123",
+		Tags:   []string{"tagA", "tagC"},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 2)
@@ -420,12 +509,14 @@ func TestGetTags_PartialMissing(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	_, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
-		Tags: []string{"tagX", "tagY"},
+		UserID: "user-123",
+		Tags:   []string{"tagX", "tagY"},
 	})
 	require.NoError(t, err)
 
 	tags, err := pgstore.GetTags(t.Context(), GetTagsRequest{
-		Tags: []string{"tagX", "tagZ"},
+		UserID: "user-123",
+		Tags:   []string{"tagX", "tagZ"},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 1)
@@ -445,7 +536,8 @@ func TestGetTags_AllMissing(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	tags, err := pgstore.GetTags(t.Context(), GetTagsRequest{
-		Tags: []string{"missingTag1", "missingTag2"},
+		UserID: "user-123",
+		Tags:   []string{"missingTag1", "missingTag2"},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 0)
@@ -455,7 +547,8 @@ func TestGetTags_EmptyInput(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	tags, err := pgstore.GetTags(t.Context(), GetTagsRequest{
-		Tags: []string{},
+		UserID: "user-123",
+		Tags:   []string{},
 	})
 	require.NoError(t, err)
 	require.Len(t, tags, 0)
@@ -469,11 +562,12 @@ func TestAddTags(t *testing.T) {
 		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "taggedwordtoadd", "en", "noun").AsInt64()
 		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A word used for testing tag addition.").AsInt64()
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
-		tagID1 = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tagToAdd1").AsInt64()
-		tagID2 = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tagToAdd2").AsInt64()
+		tagID1 = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tagToAdd1").AsInt64()
+		tagID2 = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tagToAdd2").AsInt64()
 	)
 
 	err := pgstore.AddTags(t.Context(), AddTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{tagID1, tagID2},
 	})
@@ -492,11 +586,31 @@ func TestAddTags(t *testing.T) {
 	require.Equal(t, 1, count2)
 }
 
+func TestAddTags_OtherUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "theirword", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A word of another user.").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-456", defID).AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-123", "mine").AsInt64()
+	)
+
+	err := pgstore.AddTags(t.Context(), AddTagsRequest{
+		UserID: "user-123",
+		PickID: pickID,
+		TagIDs: []int64{tagID},
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+}
+
 func TestAddTags_PickNotFound(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
-	var tagID = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tagForNonExistentPick").AsInt64()
+	var tagID = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-123", "tagForNonExistentPick").AsInt64()
 	err := pgstore.AddTags(t.Context(), AddTagsRequest{
+		UserID: "user-123",
 		PickID: 888888,
 		TagIDs: []int64{tagID},
 	})
@@ -515,6 +629,7 @@ func TestAddTags_TagNotFound(t *testing.T) {
 	)
 
 	err := pgstore.AddTags(t.Context(), AddTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{777777},
 	})
@@ -530,11 +645,12 @@ func TestAddTags_ExistingTag(t *testing.T) {
 		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "taggedwordwithexistingtag", "en", "noun").AsInt64()
 		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A word used for testing existing tags.").AsInt64()
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
-		tagID  = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "existingTag").AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "existingTag").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID).AsInt64()
 	)
 
 	err := pgstore.AddTags(t.Context(), AddTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{tagID},
 	})
@@ -550,15 +666,16 @@ func TestRemoveTags_MultipleTags(t *testing.T) {
 		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "banana", "en", "noun").AsInt64()
 		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A yellow fruit").AsInt64()
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
-		tagID1 = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tag1").AsInt64()
-		tagID2 = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tag2").AsInt64()
-		tagID3 = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tag3").AsInt64()
+		tagID1 = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tag1").AsInt64()
+		tagID2 = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tag2").AsInt64()
+		tagID3 = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tag3").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID1).AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID2).AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID3).AsInt64()
 	)
 
 	err := pgstore.RemoveTags(t.Context(), RemoveTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{tagID1, tagID3},
 	})
@@ -589,10 +706,11 @@ func TestRemoveTags_SingleTag(t *testing.T) {
 		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "taggedwordtoremove", "en", "noun").AsInt64()
 		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A word used for testing tag removal.").AsInt64()
 		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
-		tagID  = testdb.Query(t, db, "INSERT INTO tags (tag) VALUES ($1) RETURNING id", "tagtoremove").AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "tagtoremove").AsInt64()
 		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID).AsInt64()
 	)
 	err := pgstore.RemoveTags(t.Context(), RemoveTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{tagID},
 	})
@@ -606,10 +724,31 @@ func TestRemoveTags_SingleTag(t *testing.T) {
 	require.Equal(t, 0, count)
 }
 
+func TestRemoveTags_OtherUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "theirtaggedword", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A word of another user.").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-456", defID).AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-456", "theirs").AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID).AsInt64()
+	)
+
+	err := pgstore.RemoveTags(t.Context(), RemoveTagsRequest{
+		UserID: "user-123",
+		PickID: pickID,
+		TagIDs: []int64{tagID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), testdb.Query(t, db, "SELECT COUNT(1) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+}
+
 func TestRemoveTag_PickNotFound(t *testing.T) {
 	testdb.RunMigrations(t, db, migrationsFolder)
 
 	err := pgstore.RemoveTags(t.Context(), RemoveTagsRequest{
+		UserID: "user-123",
 		PickID: 888888,
 		TagIDs: []int64{888888},
 	})
@@ -627,6 +766,7 @@ func TestRemoveTags_TagNotFound(t *testing.T) {
 	)
 
 	err := pgstore.RemoveTags(t.Context(), RemoveTagsRequest{
+		UserID: userID,
 		PickID: pickID,
 		TagIDs: []int64{888888},
 	})
@@ -746,3 +886,941 @@ func TestAttachImage_Exists(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, ErrExists, err)
 }
+
+func TestTags_PerUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	tags1, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{UserID: "user-1", Tags: []string{"food"}})
+	require.NoError(t, err)
+	tags2, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{UserID: "user-2", Tags: []string{"food"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, tags1["food"], tags2["food"])
+
+	tags, err := pgstore.GetTags(t.Context(), GetTagsRequest{UserID: "user-2", Tags: []string{"food"}})
+	require.NoError(t, err)
+	assert.Equal(t, tags2, tags)
+}
+
+func TestListTags(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		userID  = "user-123"
+		wordID  = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "cherry", "en", "noun").AsInt64()
+		defID1  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A small red fruit").AsInt64()
+		defID2  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A tree bearing cherries").AsInt64()
+		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID1).AsInt64()
+		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID2).AsInt64()
+		fruitID = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "fruit").AsInt64()
+		plantID = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "plant").AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-456", "other").AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, fruitID).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID2, fruitID).AsInt64()
+	)
+
+	tags, err := pgstore.ListTags(t.Context(), ListTagsRequest{UserID: userID})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	assert.Equal(t, fruitID, tags[0].ID)
+	assert.Equal(t, 2, tags[0].Picks)
+	assert.Equal(t, plantID, tags[1].ID)
+	assert.Equal(t, 0, tags[1].Picks)
+}
+
+func TestCreateTags_Hierarchy(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
+		UserID: "user-123",
+		Tags:   []string{"spanish/verbs/irregular", "spanish/nouns"},
+	})
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	verbsID := testdb.Query(t, db, "SELECT parent_id FROM tags WHERE id = $1", tags["spanish/verbs/irregular"]).AsInt64()
+	spanishID := testdb.Query(t, db, "SELECT parent_id FROM tags WHERE tag = $1", "spanish/verbs").AsInt64()
+	assert.Equal(t, "spanish/verbs", testdb.Query(t, db, "SELECT tag FROM tags WHERE id = $1", verbsID).AsString())
+	assert.Equal(t, "spanish", testdb.Query(t, db, "SELECT tag FROM tags WHERE id = $1", spanishID).AsString())
+	assert.Equal(t, spanishID, testdb.Query(t, db, "SELECT parent_id FROM tags WHERE id = $1", tags["spanish/nouns"]).AsInt64())
+}
+
+func TestGetUserPicks_WithTags_Descendants(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
+		UserID: userID,
+		Tags:   []string{"spanish/verbs/irregular", "spanish/nouns"},
+	})
+	require.NoError(t, err)
+
+	var (
+		wordID  = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "ir", "es", "verb").AsInt64()
+		defID1  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "to go").AsInt64()
+		defID2  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "to be going to").AsInt64()
+		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID1).AsInt64()
+		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID2).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, tags["spanish/verbs/irregular"]).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID2, tags["spanish/nouns"]).AsInt64()
+	)
+
+	verbs, err := pgstore.GetTags(t.Context(), GetTagsRequest{UserID: userID, Tags: []string{"spanish/verbs", "spanish"}})
+	require.NoError(t, err)
+
+	resp, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		WithTags: []int64{verbs["spanish/verbs"]},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Picks, 1)
+	assert.Equal(t, pickID1, resp.Picks[0].ID)
+
+	resp, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:      userID,
+		WithTags:    []int64{verbs["spanish"]},
+		WithoutTags: []int64{verbs["spanish/verbs"]},
+		PageSize:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Picks, 1)
+	assert.Equal(t, pickID2, resp.Picks[0].ID)
+}
+
+func TestMoveTag(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
+		UserID: userID,
+		Tags:   []string{"spanish/verbs/irregular", "grammar/nouns"},
+	})
+	require.NoError(t, err)
+	verbs, err := pgstore.GetTags(t.Context(), GetTagsRequest{UserID: userID, Tags: []string{"spanish/verbs"}})
+	require.NoError(t, err)
+
+	moved, err := pgstore.MoveTag(t.Context(), MoveTagRequest{UserID: userID, TagID: verbs["spanish/verbs"], Path: "grammar/verbs"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"spanish/verbs", "spanish/verbs/irregular"}, moved)
+
+	assert.Equal(t, "grammar/verbs/irregular", testdb.Query(t, db, "SELECT tag FROM tags WHERE id = $1", tags["spanish/verbs/irregular"]).AsString())
+	assert.Equal(t, testdb.Query(t, db, "SELECT id FROM tags WHERE tag = $1", "grammar").AsInt64(),
+		testdb.Query(t, db, "SELECT parent_id FROM tags WHERE id = $1", verbs["spanish/verbs"]).AsInt64())
+
+	_, err = pgstore.MoveTag(t.Context(), MoveTagRequest{UserID: userID, TagID: verbs["spanish/verbs"], Path: "grammar/nouns"})
+	assert.Equal(t, ErrExists, err)
+
+	_, err = pgstore.MoveTag(t.Context(), MoveTagRequest{UserID: "user-456", TagID: verbs["spanish/verbs"], Path: "stolen"})
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestMergeTags(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		userID  = "user-123"
+		wordID  = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "plum", "en", "noun").AsInt64()
+		defID1  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A purple fruit").AsInt64()
+		defID2  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "Something desirable").AsInt64()
+		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID1).AsInt64()
+		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID2).AsInt64()
+		fromID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "fruits").AsInt64()
+		intoID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "fruit").AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, fromID).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, intoID).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID2, fromID).AsInt64()
+	)
+
+	merged, err := pgstore.MergeTags(t.Context(), MergeTagsRequest{UserID: userID, FromID: fromID, IntoID: intoID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fruits"}, merged)
+
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM tags WHERE id = $1", fromID).AsInt64())
+	assert.Equal(t, int64(2), testdb.Query(t, db, "SELECT COUNT(1) FROM tags_map WHERE tag_id = $1", intoID).AsInt64())
+}
+
+func TestMergeTags_OtherUser(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		fromID = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-123", "mine").AsInt64()
+		intoID = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-456", "theirs").AsInt64()
+	)
+
+	_, err := pgstore.MergeTags(t.Context(), MergeTagsRequest{UserID: "user-123", FromID: fromID, IntoID: intoID})
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestDeleteTag(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		userID = "user-123"
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "pear", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A green fruit").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", userID, "fruit").AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID, tagID).AsInt64()
+	)
+
+	_, err := pgstore.DeleteTag(t.Context(), DeleteTagRequest{UserID: "user-456", TagID: tagID})
+	assert.Equal(t, ErrNotFound, err)
+
+	deleted, err := pgstore.DeleteTag(t.Context(), DeleteTagRequest{UserID: userID, TagID: tagID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fruit"}, deleted)
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+}
+
+func TestDeleteTag_Subtree(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	_, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{UserID: userID, Tags: []string{"spanish/verbs/irregular", "spanish2"}})
+	require.NoError(t, err)
+	spanish, err := pgstore.GetTags(t.Context(), GetTagsRequest{UserID: userID, Tags: []string{"spanish"}})
+	require.NoError(t, err)
+
+	deleted, err := pgstore.DeleteTag(t.Context(), DeleteTagRequest{UserID: userID, TagID: spanish["spanish"]})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"spanish", "spanish/verbs", "spanish/verbs/irregular"}, deleted)
+	assert.Equal(t, int64(1), testdb.Query(t, db, "SELECT COUNT(1) FROM tags WHERE user_id = $1", userID).AsInt64())
+}
+
+func TestGetUserPicks_TagExpr(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{
+		UserID: userID,
+		Tags:   []string{"verbs/irregular", "nouns", "mastered"},
+	})
+	require.NoError(t, err)
+	verbs, err := pgstore.GetTags(t.Context(), GetTagsRequest{UserID: userID, Tags: []string{"verbs"}})
+	require.NoError(t, err)
+
+	var (
+		wordID  = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "ir", "es", "verb").AsInt64()
+		defID1  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "to go").AsInt64()
+		defID2  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "to leave").AsInt64()
+		defID3  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "to be going to").AsInt64()
+		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID1).AsInt64()
+		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID2).AsInt64()
+		pickID3 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID3).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID1, tags["verbs/irregular"]).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID2, tags["nouns"]).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID2, tags["mastered"]).AsInt64()
+		_       = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", pickID3, tags["mastered"]).AsInt64()
+	)
+
+	// (verbs OR nouns) AND NOT mastered
+	expr := &tagexpr.And{
+		X: &tagexpr.Or{X: &tagexpr.Tag{Path: "verbs", ID: verbs["verbs"]}, Y: &tagexpr.Tag{Path: "nouns", ID: tags["nouns"]}},
+		Y: &tagexpr.Not{X: &tagexpr.Tag{Path: "mastered", ID: tags["mastered"]}},
+	}
+	resp, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{UserID: userID, TagExpr: expr, PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.Picks, 1)
+	assert.Equal(t, pickID1, resp.Picks[0].ID)
+
+	// unknown tags match nothing
+	resp, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		TagExpr:  &tagexpr.Or{X: &tagexpr.Tag{Path: "unknown"}, Y: &tagexpr.Tag{Path: "nouns", ID: tags["nouns"]}},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Picks, 1)
+	assert.Equal(t, pickID2, resp.Picks[0].ID)
+}
+
+func TestTagFilters(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	id, err := pgstore.SaveTagFilter(t.Context(), SaveTagFilterRequest{UserID: "user-1", Name: "todo", Expr: "verbs"})
+	require.NoError(t, err)
+	_, err = pgstore.SaveTagFilter(t.Context(), SaveTagFilterRequest{UserID: "user-2", Name: "todo", Expr: "nouns"})
+	require.NoError(t, err)
+
+	replacedID, err := pgstore.SaveTagFilter(t.Context(), SaveTagFilterRequest{UserID: "user-1", Name: "todo", Expr: "verbs AND NOT mastered"})
+	require.NoError(t, err)
+	assert.Equal(t, id, replacedID)
+
+	f, err := pgstore.GetTagFilter(t.Context(), GetTagFilterRequest{UserID: "user-1", Name: "todo"})
+	require.NoError(t, err)
+	assert.Equal(t, "verbs AND NOT mastered", f.Expr)
+
+	filters, err := pgstore.ListTagFilters(t.Context(), ListTagFiltersRequest{UserID: "user-2"})
+	require.NoError(t, err)
+	require.Len(t, filters, 1)
+	assert.Equal(t, "nouns", filters[0].Expr)
+
+	require.NoError(t, pgstore.DeleteTagFilter(t.Context(), DeleteTagFilterRequest{UserID: "user-1", Name: "todo"}))
+	_, err = pgstore.GetTagFilter(t.Context(), GetTagFilterRequest{UserID: "user-1", Name: "todo"})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, pgstore.DeleteTagFilter(t.Context(), DeleteTagFilterRequest{UserID: "user-1", Name: "todo"}), ErrNotFound)
+}
+
+func TestGetUserPicks_Sort(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	var pickIDs []int64
+	for _, lemma := range []string{"cherry", "apple", "banana", "date", "apricot"} {
+		wordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", lemma, "en", "noun").AsInt64()
+		defID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A fruit.").AsInt64()
+		pickIDs = append(pickIDs, testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, defID).AsInt64())
+	}
+
+	var lemmas []string
+	var cursor GetUserPicksCursor
+	for {
+		response, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+			UserID:   userID,
+			SortBy:   model.SortByLemma,
+			SortDesc: true,
+			Cursor:   cursor,
+			PageSize: 2,
+		})
+		require.NoError(t, err)
+
+		for _, pick := range response.Picks {
+			lemmas = append(lemmas, pick.Word.Lemma)
+		}
+		if response.NextCursor == nil {
+			break
+		}
+		cursor = *response.NextCursor
+	}
+	assert.Equal(t, []string{"date", "cherry", "banana", "apricot", "apple"}, lemmas)
+
+	// picks without a scheduled review come last
+	testdb.Query(t, db, "UPDATE user_picks SET next_review_at = now() + interval '1 day' WHERE id = $1 RETURNING id", pickIDs[3])
+	testdb.Query(t, db, "UPDATE user_picks SET next_review_at = now() WHERE id = $1 RETURNING id", pickIDs[2])
+	response, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		SortBy:   model.SortByNextReview,
+		PageSize: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 3)
+	assert.Equal(t, pickIDs[2], response.Picks[0].ID)
+	assert.Equal(t, pickIDs[3], response.Picks[1].ID)
+	assert.Nil(t, response.Picks[2].NextReviewAt)
+}
+
+func TestGetUserPicks_ClassesAndCreated(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		userID   = "user-123"
+		nounID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "run", "en", "noun").AsInt64()
+		verbID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "run", "en", "verb").AsInt64()
+		nounDef  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", nounID, "An act of running.").AsInt64()
+		verbDef1 = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", verbID, "To move fast.").AsInt64()
+		verbDef2 = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", verbID, "To manage.").AsInt64()
+		_        = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, nounDef).AsInt64()
+		oldPick  = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id, created_at) VALUES ($1, $2, now() - interval '10 days') RETURNING id", userID, verbDef1).AsInt64()
+		newPick  = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, verbDef2).AsInt64()
+	)
+
+	response, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		Classes:  []model.WordClass{model.Verb},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 2)
+
+	response, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:        userID,
+		Classes:       []model.WordClass{model.Verb},
+		CreatedBefore: time.Now().Add(-24 * time.Hour),
+		PageSize:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 1)
+	assert.Equal(t, oldPick, response.Picks[0].ID)
+
+	response, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:       userID,
+		Classes:      []model.WordClass{model.Verb},
+		CreatedAfter: time.Now().Add(-24 * time.Hour),
+		PageSize:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 1)
+	assert.Equal(t, newPick, response.Picks[0].ID)
+}
+
+func TestCountUserPicks(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	tags, err := pgstore.CreateTags(t.Context(), CreateTagsRequest{UserID: userID, Tags: []string{"food", "verbs"}})
+	require.NoError(t, err)
+
+	var (
+		enID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "eat", "en", "verb").AsInt64()
+		deID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "Banane", "de", "noun").AsInt64()
+		enDef  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", enID, "To consume food.").AsInt64()
+		deDef  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", deID, "Eine gelbe Frucht.").AsInt64()
+		enPick = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, enDef).AsInt64()
+		dePick = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", userID, deDef).AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", enPick, tags["food"]).AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", enPick, tags["verbs"]).AsInt64()
+		_      = testdb.Query(t, db, "INSERT INTO tags_map (pick_id, tag_id) VALUES ($1, $2) RETURNING id", dePick, tags["food"]).AsInt64()
+	)
+
+	counts, err := pgstore.CountUserPicks(t.Context(), GetUserPicksRequest{UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, CountUserPicksResponse{
+		Total:   2,
+		Tags:    map[string]int{"food": 2, "verbs": 1},
+		Langs:   map[model.Lang]int{"en": 1, "de": 1},
+		Classes: map[model.WordClass]int{model.Verb: 1, model.Noun: 1},
+	}, counts)
+
+	counts, err = pgstore.CountUserPicks(t.Context(), GetUserPicksRequest{UserID: userID, Langs: []model.Lang{"de"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts.Total)
+	assert.Equal(t, map[string]int{"food": 1}, counts.Tags)
+}
+
+// seedPicksBenchmark creates picks of a user with two levels of tags, next to the picks of another user
+func seedPicksBenchmark(b *testing.B, userID string, picks int) {
+	b.Helper()
+
+	for _, step := range []struct {
+		query string
+		args  []any
+	}{
+		{"INSERT INTO words (lemma, lang, class) SELECT 'word-' || i, CASE WHEN i % 4 = 0 THEN 'de' ELSE 'en' END, 'noun' FROM generate_series(1, $1::int) AS i", []any{picks}},
+		{"INSERT INTO definitions (word_id, def, rarity) SELECT id, 'Definition of ' || lemma, id % 10 FROM words", nil},
+		{"INSERT INTO user_picks (user_id, def_id, created_at) SELECT $1, id, now() - id * interval '1 minute' FROM definitions", []any{userID}},
+		{"INSERT INTO user_picks (user_id, def_id) SELECT 'other-' || $1, id FROM definitions WHERE id % 2 = 0", []any{userID}},
+		{"INSERT INTO tags (user_id, tag) SELECT $1, 'topic-' || i FROM generate_series(0, 19) AS i", []any{userID}},
+		{"INSERT INTO tags (user_id, tag, parent_id) SELECT t.user_id, t.tag || '/sub-' || j, t.id FROM tags AS t, generate_series(0, 4) AS j WHERE t.user_id = $1", []any{userID}},
+		{"INSERT INTO tags_map (pick_id, tag_id) SELECT p.id, t.id FROM user_picks AS p JOIN tags AS t ON t.user_id = p.user_id AND t.tag = 'topic-' || (p.id % 20) || '/sub-' || (p.id % 5) WHERE p.user_id = $1", []any{userID}},
+		{"INSERT INTO tags_map (pick_id, tag_id) SELECT p.id, t.id FROM user_picks AS p JOIN tags AS t ON t.user_id = p.user_id AND t.tag = 'topic-' || (p.id * 7 % 20) WHERE p.user_id = $1 AND p.id % 3 = 0 ON CONFLICT DO NOTHING", []any{userID}},
+		{"ANALYZE", nil},
+	} {
+		_, err := db.ExecContext(b.Context(), step.query, step.args...)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkGetUserPicks(b *testing.B) {
+	testdb.RunMigrations(b, db, migrationsFolder)
+
+	userID := "user-bench"
+	seedPicksBenchmark(b, userID, 50000)
+
+	tagID := func(tag string) int64 {
+		return testdb.Query(b, db, "SELECT id FROM tags WHERE user_id = $1 AND tag = $2", userID, tag).AsInt64()
+	}
+	var (
+		topic   = tagID("topic-3")
+		sub     = tagID("topic-4/sub-2")
+		midPick = testdb.Query(b, db, "SELECT id FROM user_picks WHERE user_id = $1 ORDER BY id OFFSET 25000 LIMIT 1", userID).AsInt64()
+	)
+
+	for _, bc := range []struct {
+		name string
+		req  GetUserPicksRequest
+	}{
+		{name: "all", req: GetUserPicksRequest{}},
+		{name: "deep page", req: GetUserPicksRequest{Cursor: GetUserPicksCursor{LastPickID: midPick}}},
+		{name: "with tags", req: GetUserPicksRequest{WithTags: []int64{topic}}},
+		{name: "without tags", req: GetUserPicksRequest{WithoutTags: []int64{topic, sub}}},
+		{name: "tag expr", req: GetUserPicksRequest{TagExpr: &tagexpr.Or{
+			X: &tagexpr.Tag{ID: topic},
+			Y: &tagexpr.Not{X: &tagexpr.Tag{ID: sub}},
+		}}},
+		{name: "sort by lemma", req: GetUserPicksRequest{SortBy: model.SortByLemma, Langs: []model.Lang{"en"}}},
+		{name: "sort by created", req: GetUserPicksRequest{SortBy: model.SortByCreated, SortDesc: true}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			r := bc.req
+			r.UserID = userID
+			r.PageSize = 50
+
+			for b.Loop() {
+				resp, err := pgstore.GetUserPicks(b.Context(), r)
+				require.NoError(b, err)
+				require.NotEmpty(b, resp.Picks)
+			}
+		})
+	}
+}
+
+func TestPickStatus(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	userID := "user-123"
+	var pickIDs []int64
+	for _, lemma := range []string{"apple", "banana", "cherry"} {
+		wordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", lemma, "en", "noun").AsInt64()
+		defID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A fruit.").AsInt64()
+		pickIDs = append(pickIDs, testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id, next_review_at) VALUES ($1, $2, now()) RETURNING id", userID, defID).AsInt64())
+	}
+
+	pick, err := pgstore.GetPick(t.Context(), GetPickRequest{UserID: userID, PickID: pickIDs[0]})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusNew, pick.Status)
+
+	_, err = pgstore.GetPick(t.Context(), GetPickRequest{UserID: "user-456", PickID: pickIDs[0]})
+	assert.ErrorIs(t, err, ErrNotFound)
+	err = pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: "user-456", PickID: pickIDs[0], Status: model.StatusKnown})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: userID, PickID: pickIDs[0], Status: model.StatusSuspended}))
+	require.NoError(t, pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: userID, PickID: pickIDs[1], Status: model.StatusLearning}))
+
+	response, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		Statuses: []model.PickStatus{model.StatusNew, model.StatusLearning},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 2)
+	assert.Equal(t, model.StatusLearning, response.Picks[0].Status)
+	assert.Equal(t, model.StatusNew, response.Picks[1].Status)
+
+	// suspended, archived and known picks are left out of the review queue
+	require.NoError(t, pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: userID, PickID: pickIDs[2], Status: model.StatusKnown}))
+	response, err = pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{
+		UserID:   userID,
+		SortBy:   model.SortByNextReview,
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Picks, 1)
+	assert.Equal(t, pickIDs[1], response.Picks[0].ID)
+}
+
+func TestPickOverrides(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID   = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+		defID    = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+		imageID1 = testdb.Query(t, db, "INSERT INTO images (def_id, url) VALUES ($1, $2) RETURNING id", defID, "https://example.com/1.png").AsInt64()
+		imageID2 = testdb.Query(t, db, "INSERT INTO images (def_id, url) VALUES ($1, $2) RETURNING id", defID, "https://example.com/2.png").AsInt64()
+		pickID   = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64()
+	)
+
+	getPick := func() model.UserPick {
+		resp, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{UserID: "user-1", PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, resp.Picks, 1)
+		return resp.Picks[0]
+	}
+
+	pick := getPick()
+	assert.Equal(t, "A round fruit.", pick.Definition.Text)
+	assert.Empty(t, pick.DefOverride)
+	assert.Equal(t, "https://example.com/1.png", pick.ImageURL)
+
+	err := pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: "user-1", PickID: pickID, Status: model.StatusNew, ImageID: 9999})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, pgstore.UpdatePick(t.Context(), UpdatePickRequest{
+		UserID:      "user-1",
+		PickID:      pickID,
+		Status:      model.StatusNew,
+		DefOverride: "A red fruit.",
+		ImageID:     imageID2,
+	}))
+
+	pick = getPick()
+	assert.Equal(t, "A round fruit.", pick.Definition.Text)
+	assert.Equal(t, "A red fruit.", pick.DefOverride)
+	assert.Equal(t, imageID2, pick.ImageID)
+	assert.Equal(t, "https://example.com/2.png", pick.ImageURL)
+
+	stored, err := pgstore.GetPick(t.Context(), GetPickRequest{UserID: "user-1", PickID: pickID})
+	require.NoError(t, err)
+	assert.Equal(t, "A red fruit.", stored.DefOverride)
+	assert.Equal(t, imageID2, stored.ImageID)
+
+	// the shared definition is left untouched
+	assert.Equal(t, "A round fruit.", testdb.Query(t, db, "SELECT def FROM definitions WHERE id = $1", defID).AsString())
+
+	// deleting the chosen image falls back to the default one
+	_, err = db.ExecContext(t.Context(), "DELETE FROM images WHERE id = $1", imageID2)
+	require.NoError(t, err)
+	pick = getPick()
+	assert.Zero(t, pick.ImageID)
+	assert.Equal(t, "https://example.com/1.png", pick.ImageURL)
+
+	require.NoError(t, pgstore.UpdatePick(t.Context(), UpdatePickRequest{UserID: "user-1", PickID: pickID, Status: model.StatusNew}))
+	pick = getPick()
+	assert.Empty(t, pick.DefOverride)
+
+	img, err := pgstore.GetImage(t.Context(), GetImageRequest{ImageID: imageID1})
+	require.NoError(t, err)
+	assert.Equal(t, defID, img.DefID)
+	_, err = pgstore.GetImage(t.Context(), GetImageRequest{ImageID: imageID2})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPickContexts(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64()
+	)
+
+	_, err := pgstore.AddPickContext(t.Context(), AddPickContextRequest{UserID: "user-2", PickID: pickID, Sentence: "stolen"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	id1, err := pgstore.AddPickContext(t.Context(), AddPickContextRequest{
+		UserID:      "user-1",
+		PickID:      pickID,
+		Sentence:    "An apple a day.",
+		SourceTitle: "Proverbs",
+		SourceURL:   "https://example.com/proverbs",
+	})
+	require.NoError(t, err)
+	id2, err := pgstore.AddPickContext(t.Context(), AddPickContextRequest{UserID: "user-1", PickID: pickID, Sentence: "Apples are red."})
+	require.NoError(t, err)
+
+	resp, err := pgstore.GetUserPicks(t.Context(), GetUserPicksRequest{UserID: "user-1", PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, resp.Picks, 1)
+	require.Len(t, resp.Picks[0].Contexts, 2)
+	assert.Equal(t, id1, resp.Picks[0].Contexts[0].ID)
+	assert.Equal(t, "An apple a day.", resp.Picks[0].Contexts[0].Sentence)
+	assert.Equal(t, "Proverbs", resp.Picks[0].Contexts[0].SourceTitle)
+	assert.Equal(t, "https://example.com/proverbs", resp.Picks[0].Contexts[0].SourceURL)
+	assert.Equal(t, id2, resp.Picks[0].Contexts[1].ID)
+	assert.Empty(t, resp.Picks[0].Contexts[1].SourceURL)
+
+	require.NoError(t, pgstore.DeleteUserPick(t.Context(), DeleteUserPickRequest{UserID: "user-1", PickID: pickID}))
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(*) FROM pick_contexts").AsInt64())
+}
+
+func TestListUserPickIDs(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var pickIDs []int64
+	for _, lemma := range []string{"apple", "banana", "cherry"} {
+		wordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", lemma, "en", "noun").AsInt64()
+		defID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A fruit.").AsInt64()
+		pickIDs = append(pickIDs, testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64())
+	}
+	otherWordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "date", "en", "noun").AsInt64()
+	otherDefID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", otherWordID, "A fruit.").AsInt64()
+	otherPickID := testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-2", otherDefID).AsInt64()
+
+	ids, err := pgstore.ListUserPickIDs(t.Context(), GetUserPicksRequest{UserID: "user-1", PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, pickIDs[:2], ids)
+
+	ids, err = pgstore.ListUserPickIDs(t.Context(), GetUserPicksRequest{
+		UserID:   "user-1",
+		PickIDs:  []int64{pickIDs[2], otherPickID, pickIDs[0]},
+		PageSize: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{pickIDs[0], pickIDs[2]}, ids)
+
+	err = pgstore.WithTx(t.Context(), func(tx DataStore) error {
+		ids, err = tx.ListUserPickIDs(t.Context(), GetUserPicksRequest{UserID: "user-1", PageSize: 10, ForUpdate: true})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pickIDs, ids)
+}
+
+func TestWithTx_Nested(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+		defID  = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+		pickID = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64()
+		tagID  = testdb.Query(t, db, "INSERT INTO tags (user_id, tag) VALUES ($1, $2) RETURNING id", "user-1", "fruit").AsInt64()
+	)
+
+	err := pgstore.WithTx(t.Context(), func(tx DataStore) error {
+		// a failing statement in a nested transaction leaves the outer one usable
+		err := tx.WithTx(t.Context(), func(tx DataStore) error {
+			_, err := tx.CreateUserPick(t.Context(), CreateUserPickRequest{UserID: "user-1", DefID: defID})
+			return err
+		})
+		require.ErrorIs(t, err, ErrExists)
+
+		require.NoError(t, tx.WithTx(t.Context(), func(tx DataStore) error {
+			return tx.AddTags(t.Context(), AddTagsRequest{UserID: "user-1", PickID: pickID, TagIDs: []int64{tagID}})
+		}))
+
+		// tags the pick already has can be skipped
+		return tx.AddTags(t.Context(), AddTagsRequest{UserID: "user-1", PickID: pickID, TagIDs: []int64{tagID}, IgnoreExisting: true})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), testdb.Query(t, db, "SELECT COUNT(*) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+
+	// a failing nested transaction undoes only its own changes
+	errRollback := errors.New("rollback")
+	err = pgstore.WithTx(t.Context(), func(tx DataStore) error {
+		require.NoError(t, tx.RemoveTags(t.Context(), RemoveTagsRequest{UserID: "user-1", PickID: pickID, TagIDs: []int64{tagID}}))
+		require.ErrorIs(t, tx.WithTx(t.Context(), func(tx DataStore) error {
+			require.NoError(t, tx.DeleteUserPick(t.Context(), DeleteUserPickRequest{UserID: "user-1", PickID: pickID}))
+			return errRollback
+		}), errRollback)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(*) FROM tags_map WHERE pick_id = $1", pickID).AsInt64())
+	assert.Equal(t, int64(1), testdb.Query(t, db, "SELECT COUNT(*) FROM user_picks WHERE id = $1", pickID).AsInt64())
+}
+
+func TestPickNotes(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	var (
+		wordID  = testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+		defID   = testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+		pickID1 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64()
+		pickID2 = testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-2", defID).AsInt64()
+	)
+
+	_, err := pgstore.SavePickNote(t.Context(), SavePickNoteRequest{UserID: "user-2", PickID: pickID1, Body: "stolen"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	note1, err := pgstore.SavePickNote(t.Context(), SavePickNoteRequest{UserID: "user-1", PickID: pickID1, Body: "first"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", note1.UserID)
+	assert.Equal(t, defID, note1.DefID)
+	_, err = pgstore.AddNoteRevision(t.Context(), AddNoteRevisionRequest{NoteID: note1.ID, Body: "first"})
+	require.NoError(t, err)
+
+	updated, err := pgstore.SavePickNote(t.Context(), SavePickNoteRequest{UserID: "user-1", PickID: pickID1, Body: "second", Public: true})
+	require.NoError(t, err)
+	assert.Equal(t, note1.ID, updated.ID)
+	_, err = pgstore.AddNoteRevision(t.Context(), AddNoteRevisionRequest{NoteID: note1.ID, Body: "second"})
+	require.NoError(t, err)
+
+	note, err := pgstore.GetPickNote(t.Context(), GetPickNoteRequest{UserID: "user-1", PickID: pickID1})
+	require.NoError(t, err)
+	assert.Equal(t, "second", note.Body)
+	assert.True(t, note.Public)
+
+	revisions, err := pgstore.ListNoteRevisions(t.Context(), ListNoteRevisionsRequest{UserID: "user-1", PickID: pickID1})
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "second", revisions[0].Body)
+	assert.Equal(t, "first", revisions[1].Body)
+
+	revisions, err = pgstore.ListNoteRevisions(t.Context(), ListNoteRevisionsRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Len(t, revisions, 2)
+
+	note2, err := pgstore.SavePickNote(t.Context(), SavePickNoteRequest{UserID: "user-2", PickID: pickID2, Body: "private"})
+	require.NoError(t, err)
+	_, err = pgstore.GetPublicNote(t.Context(), GetPublicNoteRequest{NoteID: note2.ID})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, pgstore.VoteNote(t.Context(), VoteNoteRequest{UserID: "user-2", NoteID: note1.ID}))
+	require.NoError(t, pgstore.VoteNote(t.Context(), VoteNoteRequest{UserID: "user-2", NoteID: note1.ID}))
+	require.NoError(t, pgstore.VoteNote(t.Context(), VoteNoteRequest{UserID: "user-3", NoteID: note1.ID}))
+	assert.ErrorIs(t, pgstore.VoteNote(t.Context(), VoteNoteRequest{UserID: "user-3", NoteID: 999999}), ErrNotFound)
+
+	mnemonics, err := pgstore.ListMnemonics(t.Context(), ListMnemonicsRequest{UserID: "user-2", DefID: defID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mnemonics, 1)
+	assert.Equal(t, note1.ID, mnemonics[0].ID)
+	assert.Equal(t, 2, mnemonics[0].Votes)
+	assert.True(t, mnemonics[0].Voted)
+
+	votes, err := pgstore.ListUserVotes(t.Context(), ListUserVotesRequest{UserID: "user-2"})
+	require.NoError(t, err)
+	require.Len(t, votes, 1)
+	assert.Equal(t, note1.ID, votes[0].NoteID)
+	assert.Equal(t, defID, votes[0].DefID)
+
+	require.NoError(t, pgstore.UnvoteNote(t.Context(), VoteNoteRequest{UserID: "user-2", NoteID: note1.ID}))
+	mnemonics, err = pgstore.ListMnemonics(t.Context(), ListMnemonicsRequest{UserID: "user-2", DefID: defID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mnemonics, 1)
+	assert.Equal(t, 1, mnemonics[0].Votes)
+	assert.False(t, mnemonics[0].Voted)
+
+	notes, err := pgstore.ListUserNotes(t.Context(), ListUserNotesRequest{UserID: "user-2"})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "private", notes[0].Body)
+
+	assert.ErrorIs(t, pgstore.DeletePickNote(t.Context(), DeletePickNoteRequest{UserID: "user-2", PickID: pickID1}), ErrNotFound)
+	require.NoError(t, pgstore.DeletePickNote(t.Context(), DeletePickNoteRequest{UserID: "user-1", PickID: pickID1}))
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM note_revisions WHERE note_id = $1", note1.ID).AsInt64())
+	assert.Equal(t, int64(0), testdb.Query(t, db, "SELECT COUNT(1) FROM note_votes WHERE note_id = $1", note1.ID).AsInt64())
+}
+
+func TestUpsertDictionary(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	wordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+	defID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+
+	entries := []DictionaryEntry{
+		{Lemma: "apple", Lang: "en", Class: model.Noun, Definitions: []DictionaryDefinition{{Text: "A round fruit.", Source: model.SrcUnknown}}},
+		{Lemma: "run", Lang: "en", Class: model.Verb, Definitions: []DictionaryDefinition{
+			{Text: "To move fast.", Rarity: 2, Source: model.SrcAI, ImageURLs: []string{"https://example.com/run.jpg"}},
+			{Text: "To manage.", Source: model.SrcWiktionary},
+		}},
+		{Lemma: "apple", Lang: "en", Class: model.Noun, Definitions: []DictionaryDefinition{{
+			Text:      "A round fruit.",
+			Source:    model.SrcUnknown,
+			ImageURLs: []string{"https://example.com/apple.jpg"},
+		}}},
+	}
+
+	resp, err := pgstore.UpsertDictionary(t.Context(), UpsertDictionaryRequest{Entries: entries})
+	require.NoError(t, err)
+
+	runID := testdb.Query(t, db, "SELECT id FROM words WHERE lemma = $1", "run").AsInt64()
+	wordIDs := []int64{wordID, runID, wordID}
+	defIDs := [][]int64{
+		{defID},
+		{
+			testdb.Query(t, db, "SELECT id FROM definitions WHERE def = $1", "To move fast.").AsInt64(),
+			testdb.Query(t, db, "SELECT id FROM definitions WHERE def = $1", "To manage.").AsInt64(),
+		},
+		{defID},
+	}
+	assert.Equal(t, UpsertDictionaryResponse{
+		Created:     []bool{false, true, true},
+		WordIDs:     wordIDs,
+		DefIDs:      defIDs,
+		Words:       1,
+		Definitions: 2,
+		Images:      2,
+	}, resp)
+	assert.Equal(t, int64(2), testdb.Query(t, db, "SELECT COUNT(*) FROM words").AsInt64())
+	assert.Equal(t, int64(2), testdb.Query(t, db, "SELECT rarity FROM definitions WHERE def = $1", "To move fast.").AsInt64())
+
+	// importing the same entries again changes nothing
+	resp, err = pgstore.UpsertDictionary(t.Context(), UpsertDictionaryRequest{Entries: entries})
+	require.NoError(t, err)
+	assert.Equal(t, UpsertDictionaryResponse{Created: []bool{false, false, false}, WordIDs: wordIDs, DefIDs: defIDs}, resp)
+	assert.Equal(t, int64(3), testdb.Query(t, db, "SELECT COUNT(*) FROM definitions").AsInt64())
+	assert.Equal(t, int64(2), testdb.Query(t, db, "SELECT COUNT(*) FROM images").AsInt64())
+}
+
+func TestDefinitionRelations(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	bigID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "big", "en", "adjective").AsInt64()
+	bigDefID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", bigID, "above average in size").AsInt64()
+	largeID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "large", "en", "adjective").AsInt64()
+	largeDefID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def, source) VALUES ($1, $2, $3) RETURNING id", largeID, "above average in size", "wordnet").AsInt64()
+
+	big := DefinitionKey{Lemma: "big", Lang: "en", Class: model.Adjective, Text: "above average in size"}
+	large := DefinitionKey{Lemma: "large", Lang: "en", Class: model.Adjective, Text: "above average in size"}
+	small := DefinitionKey{Lemma: "small", Lang: "en", Class: model.Adjective, Text: "below average in size"}
+	relations := []DefinitionRelation{
+		{From: big, To: large, Type: model.RelSynonym},
+		{From: large, To: big, Type: model.RelSynonym},
+		{From: big, To: small, Type: model.RelAntonym},
+		{From: big, To: big, Type: model.RelSynonym},
+	}
+
+	n, err := pgstore.UpsertDefinitionRelations(t.Context(), UpsertDefinitionRelationsRequest{Relations: relations})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// adding the same relations again changes nothing
+	n, err = pgstore.UpsertDefinitionRelations(t.Context(), UpsertDefinitionRelationsRequest{Relations: relations})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	related, err := pgstore.ListRelatedDefinitions(t.Context(), ListRelatedDefinitionsRequest{DefID: bigDefID})
+	require.NoError(t, err)
+	require.Len(t, related, 1)
+	assert.Equal(t, model.RelSynonym, related[0].Type)
+	assert.Equal(t, largeDefID, related[0].Definition.ID)
+	assert.Equal(t, model.SrcWordNet, related[0].Definition.Source)
+	assert.Equal(t, "large", related[0].Word.Lemma)
+
+	related, err = pgstore.ListRelatedDefinitions(t.Context(), ListRelatedDefinitionsRequest{DefID: largeDefID + 100})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, related)
+}
+
+func TestSchedulePick(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	wordID := testdb.Query(t, db, "INSERT INTO words (lemma, lang, class) VALUES ($1, $2, $3) RETURNING id", "apple", "en", "noun").AsInt64()
+	defID := testdb.Query(t, db, "INSERT INTO definitions (word_id, def) VALUES ($1, $2) RETURNING id", wordID, "A round fruit.").AsInt64()
+	pickID := testdb.Query(t, db, "INSERT INTO user_picks (user_id, def_id) VALUES ($1, $2) RETURNING id", "user-1", defID).AsInt64()
+
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, pgstore.SchedulePick(t.Context(), SchedulePickRequest{UserID: "user-1", PickID: pickID, Status: model.StatusLearning, NextReviewAt: &due}))
+
+	pick, err := pgstore.GetPick(t.Context(), GetPickRequest{UserID: "user-1", PickID: pickID})
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusLearning, pick.Status)
+	assert.Equal(t, due.Unix(), testdb.Query(t, db, "SELECT EXTRACT(EPOCH FROM next_review_at)::bigint FROM user_picks WHERE id = $1", pickID).AsInt64())
+
+	err = pgstore.SchedulePick(t.Context(), SchedulePickRequest{UserID: "user-2", PickID: pickID, Status: model.StatusKnown})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestImportJobs(t *testing.T) {
+	testdb.RunMigrations(t, db, migrationsFolder)
+
+	id, err := pgstore.CreateImportJob(t.Context(), CreateImportJobRequest{UserID: "user-1", Total: 3})
+	require.NoError(t, err)
+
+	job, err := pgstore.GetImportJob(t.Context(), GetImportJobRequest{ID: id, UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, model.ImportRunning, job.Status)
+	assert.Equal(t, 3, job.Total)
+	assert.Empty(t, job.Errors)
+	assert.Nil(t, job.FinishedAt)
+
+	err = pgstore.UpdateImportJob(t.Context(), UpdateImportJobRequest{
+		ID:      id,
+		Status:  model.ImportDone,
+		Cards:   3,
+		Created: 1,
+		Skipped: 1,
+		Failed:  1,
+		Errors:  []model.ImportJobError{{Index: 2, Msg: "lemma is required"}},
+	})
+	require.NoError(t, err)
+
+	job, err = pgstore.GetImportJob(t.Context(), GetImportJobRequest{ID: id, UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, model.ImportDone, job.Status)
+	assert.Equal(t, []int{3, 1, 1, 1}, []int{job.Cards, job.Created, job.Skipped, job.Failed})
+	assert.Equal(t, []model.ImportJobError{{Index: 2, Msg: "lemma is required"}}, job.Errors)
+	assert.NotNil(t, job.FinishedAt)
+
+	_, err = pgstore.GetImportJob(t.Context(), GetImportJobRequest{ID: id, UserID: "user-2"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	otherID, err := pgstore.CreateImportJob(t.Context(), CreateImportJobRequest{UserID: "user-1", Total: 1})
+	require.NoError(t, err)
+	_, err = pgstore.CreateImportJob(t.Context(), CreateImportJobRequest{UserID: "user-2", Total: 1})
+	require.NoError(t, err)
+
+	jobs, err := pgstore.ListImportJobs(t.Context(), ListImportJobsRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, []int64{id, otherID}, []int64{jobs[0].ID, jobs[1].ID})
+	assert.Equal(t, []model.ImportJobError{{Index: 2, Msg: "lemma is required"}}, jobs[0].Errors)
+
+	assert.ErrorIs(t, pgstore.UpdateImportJob(t.Context(), UpdateImportJobRequest{ID: id + 100, Status: model.ImportFailed}), ErrNotFound)
+
+	_, err = pgstore.DeleteUserPicks(t.Context(), DeleteUserPicksRequest{UserID: "user-1"})
+	require.NoError(t, err)
+	_, err = pgstore.GetImportJob(t.Context(), GetImportJobRequest{ID: id, UserID: "user-1"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
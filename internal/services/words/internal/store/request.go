@@ -1,7 +1,10 @@
 package store
 
 import (
+	"time"
+
 	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/tagexpr"
 )
 
 type InsertWordRequst struct {
@@ -14,6 +17,10 @@ type InsertWordResponse struct {
 	ID int64
 }
 
+type GetWordRequest struct {
+	ID int64
+}
+
 type DeleteWordRequest struct {
 	ID int64
 }
@@ -25,14 +32,32 @@ type CreateUserPickRequest struct {
 
 type GetUserPicksCursor struct {
 	LastPickID int64
+	// LastSortKey is the value of the sort field of the last pick as text, it is empty when sorting by ID
+	LastSortKey string `json:",omitempty"`
 }
 
 type GetUserPicksRequest struct {
-	UserID      string
+	UserID string
+	// PickIDs restricts the picks to these IDs, it is ignored when empty
+	PickIDs     []int64
 	WithTags    []int64
 	WithoutTags []int64
-	PageSize    int
-	Cursor      GetUserPicksCursor
+	// TagExpr additionally filters the picks by a tag expression with resolved tag IDs, it is ignored when nil
+	TagExpr tagexpr.Expr
+	Langs   []model.Lang
+	Classes []model.WordClass
+	// Statuses restricts the picks to these learning states, picks in any state are returned when empty
+	Statuses []model.PickStatus
+	// CreatedAfter and CreatedBefore bound the creation time of the picks, zero values leave the range open
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// SortBy orders the picks by the field and then by ID, picks are ordered by ID only when it is empty
+	SortBy   model.PickSort
+	SortDesc bool
+	PageSize int
+	Cursor   GetUserPicksCursor
+	// ForUpdate locks the picks until the end of the transaction, only ListUserPickIDs supports it
+	ForUpdate bool
 }
 
 type GetUserPicksResponse struct {
@@ -40,24 +65,186 @@ type GetUserPicksResponse struct {
 	NextCursor *GetUserPicksCursor
 }
 
+// CountUserPicksResponse holds the number of picks matching the filters of a GetUserPicksRequest,
+// in total and broken down by the tags assigned to them, their language and their word class
+type CountUserPicksResponse struct {
+	Total   int
+	Tags    map[string]int
+	Langs   map[model.Lang]int
+	Classes map[model.WordClass]int
+}
+
 type DeleteUserPickRequest struct {
+	UserID string
+	PickID int64
+}
+
+type GetPickRequest struct {
+	UserID string
 	PickID int64
+	// ForUpdate locks the pick until the end of the transaction
+	ForUpdate bool
+}
+
+// UpdatePickRequest holds the new values of the fields of a pick the user can change
+// SchedulePickRequest sets the learning state of a pick and the time of its next review,
+// a nil NextReviewAt leaves the pick unscheduled
+type SchedulePickRequest struct {
+	UserID       string
+	PickID       int64
+	Status       model.PickStatus
+	NextReviewAt *time.Time
+}
+
+type UpdatePickRequest struct {
+	UserID string
+	PickID int64
+	Status model.PickStatus
+	// DefOverride is empty to use the shared definition text
+	DefOverride string
+	// ImageID is zero to use the default image of the definition
+	ImageID int64
+}
+
+type AddPickContextRequest struct {
+	UserID      string
+	PickID      int64
+	Sentence    string
+	SourceTitle string
+	SourceURL   string
+}
+
+type DeleteUserPicksRequest struct {
+	UserID string
+}
+
+type GetPickNoteRequest struct {
+	UserID string
+	PickID int64
+	// ForUpdate locks the note until the end of the transaction
+	ForUpdate bool
+}
+
+type SavePickNoteRequest struct {
+	UserID string
+	PickID int64
+	Body   string
+	Public bool
+}
+
+type DeletePickNoteRequest struct {
+	UserID string
+	PickID int64
+}
+
+type ListUserNotesRequest struct {
+	UserID string
+}
+
+type AddNoteRevisionRequest struct {
+	NoteID int64
+	Body   string
+}
+
+type ListNoteRevisionsRequest struct {
+	UserID string
+	// PickID restricts the revisions to the note on this pick, the revisions of all notes
+	// of the user are listed when it is zero
+	PickID int64
+}
+
+type GetPublicNoteRequest struct {
+	NoteID int64
+}
+
+type VoteNoteRequest struct {
+	UserID string
+	NoteID int64
+}
+
+type ListUserVotesRequest struct {
+	UserID string
+}
+
+type ListMnemonicsRequest struct {
+	// UserID is the user the mnemonics are listed for, see model.Mnemonic.Voted
+	UserID string
+	DefID  int64
+	Limit  int
 }
 
 type CreateTagsRequest struct {
-	Tags []string
+	UserID string
+	Tags   []string
 }
 
 type GetTagsRequest struct {
-	Tags []string
+	UserID string
+	Tags   []string
+}
+
+type ListTagsRequest struct {
+	UserID string
+}
+
+type GetTagRequest struct {
+	UserID string
+	TagID  int64
+	// ForUpdate locks the tag until the end of the transaction
+	ForUpdate bool
+}
+
+type MoveTagRequest struct {
+	UserID string
+	TagID  int64
+	Path   string
+}
+
+type MergeTagsRequest struct {
+	UserID string
+	FromID int64
+	IntoID int64
+}
+
+type DeleteTagRequest struct {
+	UserID string
+	TagID  int64
+}
+
+type SaveTagFilterRequest struct {
+	UserID string
+	Name   string
+	Expr   string
+}
+
+type GetTagFilterRequest struct {
+	UserID string
+	Name   string
+}
+
+type ListTagFiltersRequest struct {
+	UserID string
+}
+
+type DeleteTagFilterRequest struct {
+	UserID string
+	Name   string
+}
+
+type GetImageRequest struct {
+	ImageID int64
 }
 
 type AddTagsRequest struct {
+	UserID string
 	PickID int64
 	TagIDs []int64
+	// IgnoreExisting skips the tags the pick already has instead of failing with ErrExists
+	IgnoreExisting bool
 }
 
 type RemoveTagsRequest struct {
+	UserID string
 	PickID int64
 	TagIDs []int64
 }
@@ -65,7 +252,7 @@ type RemoveTagsRequest struct {
 type CreateDefinitionRequest struct {
 	WordID int64
 	Text   string
-	Rarity float32
+	Rarity int
 	Source model.DataSource
 }
 
@@ -74,3 +261,83 @@ type AttachImageRequest struct {
 	ImageURL string
 	Source   model.DataSource
 }
+
+// DictionaryEntry is a word of the global dictionary together with its definitions and their images
+type DictionaryEntry struct {
+	Lemma       string
+	Lang        model.Lang
+	Class       model.WordClass
+	Definitions []DictionaryDefinition
+}
+
+type DictionaryDefinition struct {
+	Text   string
+	Rarity int
+	// Source is the source of the definition and of its images
+	Source    model.DataSource
+	ImageURLs []string
+}
+
+type UpsertDictionaryRequest struct {
+	Entries []DictionaryEntry
+}
+
+// UpsertDictionaryResponse tells what the upsert created, Created holds for every entry of the request
+// whether its word, any of its definitions or any of their images did not exist before
+type UpsertDictionaryResponse struct {
+	Created []bool
+	// WordIDs and DefIDs hold the IDs of the word of every entry and of its definitions in their order
+	WordIDs     []int64
+	DefIDs      [][]int64
+	Words       int
+	Definitions int
+	Images      int
+}
+
+// DefinitionKey identifies a definition by its word and its text, which are unique together
+type DefinitionKey struct {
+	Lemma string
+	Lang  model.Lang
+	Class model.WordClass
+	Text  string
+}
+
+type DefinitionRelation struct {
+	From DefinitionKey
+	To   DefinitionKey
+	Type model.RelationType
+}
+
+type UpsertDefinitionRelationsRequest struct {
+	Relations []DefinitionRelation
+}
+
+type ListRelatedDefinitionsRequest struct {
+	DefID int64
+}
+
+type CreateImportJobRequest struct {
+	UserID string
+	Total  int
+}
+
+// UpdateImportJobRequest saves the progress of an import job, the job is finished unless its status is running
+type UpdateImportJobRequest struct {
+	ID      int64
+	Status  model.ImportStatus
+	Cards   int
+	Created int
+	Skipped int
+	Failed  int
+	Errors  []model.ImportJobError
+	Error   string
+}
+
+type GetImportJobRequest struct {
+	ID     int64
+	UserID string
+}
+
+type ListImportJobsRequest struct {
+	UserID string
+}
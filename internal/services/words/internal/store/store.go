@@ -8,15 +8,51 @@ import (
 
 type DataStore interface {
 	InsertWord(ctx context.Context, r InsertWordRequst) (int64, error)
+	GetWord(ctx context.Context, r GetWordRequest) (model.Word, error)
 	DeleteWord(ctx context.Context, r DeleteWordRequest) error
 	CreateUserPick(ctx context.Context, r CreateUserPickRequest) (int64, error)
 	GetUserPicks(ctx context.Context, r GetUserPicksRequest) (GetUserPicksResponse, error)
+	CountUserPicks(ctx context.Context, r GetUserPicksRequest) (CountUserPicksResponse, error)
+	ListUserPickIDs(ctx context.Context, r GetUserPicksRequest) ([]int64, error)
+	GetPick(ctx context.Context, r GetPickRequest) (model.UserPick, error)
+	UpdatePick(ctx context.Context, r UpdatePickRequest) error
+	SchedulePick(ctx context.Context, r SchedulePickRequest) error
+	AddPickContext(ctx context.Context, r AddPickContextRequest) (int64, error)
 	DeleteUserPick(ctx context.Context, r DeleteUserPickRequest) error
+	DeleteUserPicks(ctx context.Context, r DeleteUserPicksRequest) (int64, error)
+	GetPickNote(ctx context.Context, r GetPickNoteRequest) (model.Note, error)
+	SavePickNote(ctx context.Context, r SavePickNoteRequest) (model.Note, error)
+	DeletePickNote(ctx context.Context, r DeletePickNoteRequest) error
+	ListUserNotes(ctx context.Context, r ListUserNotesRequest) ([]model.Note, error)
+	AddNoteRevision(ctx context.Context, r AddNoteRevisionRequest) (int64, error)
+	ListNoteRevisions(ctx context.Context, r ListNoteRevisionsRequest) ([]model.NoteRevision, error)
+	GetPublicNote(ctx context.Context, r GetPublicNoteRequest) (model.Note, error)
+	VoteNote(ctx context.Context, r VoteNoteRequest) error
+	UnvoteNote(ctx context.Context, r VoteNoteRequest) error
+	ListUserVotes(ctx context.Context, r ListUserVotesRequest) ([]model.NoteVote, error)
+	ListMnemonics(ctx context.Context, r ListMnemonicsRequest) ([]model.Mnemonic, error)
 	CreateTags(ctx context.Context, r CreateTagsRequest) (model.TagIDMap, error)
 	GetTags(ctx context.Context, r GetTagsRequest) (model.TagIDMap, error)
+	GetTag(ctx context.Context, r GetTagRequest) (model.Tag, error)
+	ListTags(ctx context.Context, r ListTagsRequest) ([]model.TagUsage, error)
+	MoveTag(ctx context.Context, r MoveTagRequest) ([]string, error)
+	MergeTags(ctx context.Context, r MergeTagsRequest) ([]string, error)
+	DeleteTag(ctx context.Context, r DeleteTagRequest) ([]string, error)
+	SaveTagFilter(ctx context.Context, r SaveTagFilterRequest) (int64, error)
+	GetTagFilter(ctx context.Context, r GetTagFilterRequest) (model.TagFilter, error)
+	ListTagFilters(ctx context.Context, r ListTagFiltersRequest) ([]model.TagFilter, error)
+	DeleteTagFilter(ctx context.Context, r DeleteTagFilterRequest) error
 	AddTags(ctx context.Context, r AddTagsRequest) error
 	RemoveTags(ctx context.Context, r RemoveTagsRequest) error
 	CreateDefinition(ctx context.Context, r CreateDefinitionRequest) (int64, error)
 	AttachImage(ctx context.Context, r AttachImageRequest) (int64, error)
+	GetImage(ctx context.Context, r GetImageRequest) (model.Image, error)
+	UpsertDictionary(ctx context.Context, r UpsertDictionaryRequest) (UpsertDictionaryResponse, error)
+	UpsertDefinitionRelations(ctx context.Context, r UpsertDefinitionRelationsRequest) (int64, error)
+	ListRelatedDefinitions(ctx context.Context, r ListRelatedDefinitionsRequest) ([]model.RelatedDefinition, error)
+	CreateImportJob(ctx context.Context, r CreateImportJobRequest) (int64, error)
+	UpdateImportJob(ctx context.Context, r UpdateImportJobRequest) error
+	GetImportJob(ctx context.Context, r GetImportJobRequest) (model.ImportJob, error)
+	ListImportJobs(ctx context.Context, r ListImportJobsRequest) ([]model.ImportJob, error)
 	WithTx(ctx context.Context, fn func(tx DataStore) error) error
 }
@@ -0,0 +1,348 @@
+// Package tagexpr parses boolean tag expressions such as `(verbs OR nouns) AND NOT mastered`
+// that are used to filter the picks of a user.
+package tagexpr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gamma-omg/lexi-go/internal/services/words/internal/model"
+)
+
+const (
+	// MaxLength is the maximum length of an expression in bytes
+	MaxLength = 1024
+	// MaxDepth is the maximum nesting of parentheses and NOT operators
+	MaxDepth = 32
+	// MaxTags is the maximum number of tags an expression may reference
+	MaxTags = 64
+)
+
+// Expr is a node of a parsed tag expression
+type Expr interface {
+	// String formats the expression so that parsing the result yields the same expression
+	String() string
+	precedence() int
+}
+
+// Tag matches picks tagged with the tag or with any tag below it
+type Tag struct {
+	Path string
+	// ID is the ID of the tag of the user, zero when the user has no such tag and the tag matches nothing
+	ID int64
+}
+
+// Not matches picks that are not matched by X
+type Not struct {
+	X Expr
+}
+
+// And matches picks that are matched by both X and Y
+type And struct {
+	X, Y Expr
+}
+
+// Or matches picks that are matched by X, Y or both
+type Or struct {
+	X, Y Expr
+}
+
+const (
+	precOr = iota
+	precAnd
+	precNot
+)
+
+func (t *Tag) precedence() int { return precNot }
+func (n *Not) precedence() int { return precNot }
+func (a *And) precedence() int { return precAnd }
+func (o *Or) precedence() int  { return precOr }
+
+func (t *Tag) String() string {
+	if needsQuotes(t.Path) {
+		return `"` + t.Path + `"`
+	}
+
+	return t.Path
+}
+
+func (n *Not) String() string {
+	return "NOT " + wrap(n.X, precNot)
+}
+
+func (a *And) String() string {
+	return wrap(a.X, precAnd) + " AND " + wrap(a.Y, precAnd+1)
+}
+
+func (o *Or) String() string {
+	return wrap(o.X, precOr) + " OR " + wrap(o.Y, precOr+1)
+}
+
+func wrap(e Expr, prec int) string {
+	if e.precedence() < prec {
+		return "(" + e.String() + ")"
+	}
+
+	return e.String()
+}
+
+func needsQuotes(path string) bool {
+	if isKeyword(path) {
+		return true
+	}
+
+	return strings.ContainsFunc(path, func(r rune) bool {
+		return isSpace(r) || r == '(' || r == ')'
+	})
+}
+
+// Tags returns the tag nodes of the expression in the order they appear
+func Tags(e Expr) []*Tag {
+	var tags []*Tag
+	var walk func(e Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case *Tag:
+			tags = append(tags, e)
+		case *Not:
+			walk(e.X)
+		case *And:
+			walk(e.X)
+			walk(e.Y)
+		case *Or:
+			walk(e.X)
+			walk(e.Y)
+		}
+	}
+	walk(e)
+
+	return tags
+}
+
+// SyntaxError describes why an expression could not be parsed
+type SyntaxError struct {
+	// Pos is the byte offset in the expression where the error was found
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+// Parse parses a tag expression. Operators are AND, OR and NOT in any case, with NOT binding
+// tighter than AND and AND tighter than OR. Tags are slash separated paths, tags containing
+// spaces, parentheses or named like an operator are written in double quotes.
+// It returns a *SyntaxError if the expression is invalid.
+func Parse(s string) (Expr, error) {
+	if len(s) > MaxLength {
+		return nil, &SyntaxError{Pos: MaxLength, Msg: fmt.Sprintf("expression is longer than %d bytes", MaxLength)}
+	}
+
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+
+	if n := len(Tags(e)); n > MaxTags {
+		return nil, &SyntaxError{Pos: 0, Msg: fmt.Sprintf("expression references %d tags, at most %d are allowed", n, MaxTags)}
+	}
+
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTag
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokTag:
+		return fmt.Sprintf("tag %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case isSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{Pos: i, Msg: "unterminated quoted tag"}
+			}
+
+			tag, err := tagToken(s[i+1:i+1+end], i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tag)
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && !isSpace(rune(s[i])) && s[i] != '(' && s[i] != ')' && s[i] != '"' {
+				i++
+			}
+
+			word := s[start:i]
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: start})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: start})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word, pos: start})
+			default:
+				tag, err := tagToken(word, start)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, tag)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(s)}), nil
+}
+
+func tagToken(text string, pos int) (token, error) {
+	path := model.NormalizeTagPath(text)
+	if path == "" {
+		return token{}, &SyntaxError{Pos: pos, Msg: "empty tag"}
+	}
+
+	return token{kind: tokTag, text: path, pos: pos}, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+
+	return false
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+
+	return t
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	x, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.advance()
+		y, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = &Or{X: x, Y: y}
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	x, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokAnd {
+		p.advance()
+		y, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = &And{X: x, Y: y}
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseUnary(depth int) (Expr, error) {
+	t := p.advance()
+	if depth >= MaxDepth && (t.kind == tokNot || t.kind == tokLParen) {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expression is nested deeper than %d levels", MaxDepth)}
+	}
+
+	switch t.kind {
+	case tokTag:
+		return &Tag{Path: t.text}, nil
+	case tokNot:
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	case tokLParen:
+		x, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != tokRParen {
+			return nil, &SyntaxError{Pos: closing.pos, Msg: fmt.Sprintf("expected \")\", found %s", closing)}
+		}
+		return x, nil
+	default:
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected a tag, found %s", t)}
+	}
+}
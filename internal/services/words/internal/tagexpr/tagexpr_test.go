@@ -0,0 +1,97 @@
+package tagexpr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"verbs", "verbs"},
+		{"(verbs OR nouns) AND NOT mastered", "(verbs OR nouns) AND NOT mastered"},
+		{"verbs or nouns and not mastered", "verbs OR nouns AND NOT mastered"},
+		{"a AND (b AND c)", "a AND (b AND c)"},
+		{"NOT (a OR b)", "NOT (a OR b)"},
+		{"spanish//verbs/", "spanish/verbs"},
+		{`" spanish / verbs "`, "spanish/verbs"},
+		{`"travel plans" OR "and"`, `"travel plans" OR "and"`},
+		{"((a))", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.String())
+
+			again, err := Parse(e.String())
+			require.NoError(t, err)
+			assert.Equal(t, e, again)
+		})
+	}
+}
+
+func TestParse_Precedence(t *testing.T) {
+	e, err := Parse("a OR b AND NOT c")
+	require.NoError(t, err)
+	assert.Equal(t, &Or{
+		X: &Tag{Path: "a"},
+		Y: &And{X: &Tag{Path: "b"}, Y: &Not{X: &Tag{Path: "c"}}},
+	}, e)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{"", 0},
+		{"verbs AND", 9},
+		{"verbs nouns", 6},
+		{"(verbs OR nouns", 15},
+		{"verbs)", 5},
+		{`"verbs`, 0},
+		{`verbs OR ""`, 9},
+		{"NOT / ", 4},
+		{strings.Repeat("(", MaxDepth+1) + "a" + strings.Repeat(")", MaxDepth+1), MaxDepth},
+		{strings.Repeat("x", MaxLength+1), MaxLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+
+			var se *SyntaxError
+			require.ErrorAs(t, err, &se)
+			assert.Equal(t, tt.pos, se.Pos)
+		})
+	}
+}
+
+func TestParse_TooManyTags(t *testing.T) {
+	tags := make([]string, MaxTags+1)
+	for i := range tags {
+		tags[i] = "t"
+	}
+
+	_, err := Parse(strings.Join(tags, " OR "))
+	var se *SyntaxError
+	require.ErrorAs(t, err, &se)
+}
+
+func TestTags(t *testing.T) {
+	e, err := Parse("(verbs OR nouns) AND NOT verbs")
+	require.NoError(t, err)
+
+	paths := []string{}
+	for _, tag := range Tags(e) {
+		paths = append(paths, tag.Path)
+	}
+	assert.Equal(t, []string{"verbs", "nouns", "verbs"}, paths)
+}
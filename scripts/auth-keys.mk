@@ -1,6 +1,8 @@
 KEY_REFRESH := deploy/lexigo/auth/keys/jwt-refresh.key
 KEY_ACCESS_PRV := deploy/lexigo/auth/keys/jwt-access-private.pem
 KEY_ACCESS_PUB := deploy/lexigo/common/keys/jwt-access-public.pem
+KEY_SERVICE := deploy/lexigo/common/keys/service.key
+KEY_CURSOR := deploy/lexigo/words/keys/cursor.key
 
 $(KEY_ACCESS_PRV):
 	@mkdir -p "$(dir $(KEY_ACCESS_PRV))"
@@ -14,6 +16,14 @@ $(KEY_REFRESH):
 	@mkdir -p "$(dir $(KEY_REFRESH))"
 	@openssl rand -out $(KEY_REFRESH) 32
 
+$(KEY_SERVICE):
+	@mkdir -p "$(dir $(KEY_SERVICE))"
+	@openssl rand -hex -out $(KEY_SERVICE) 32
+
+$(KEY_CURSOR):
+	@mkdir -p "$(dir $(KEY_CURSOR))"
+	@openssl rand -hex -out $(KEY_CURSOR) 32
+
 .PHONY: auth-keys
-auth-keys: $(KEY_ACCESS_PRV) $(KEY_ACCESS_PUB) $(KEY_REFRESH)
+auth-keys: $(KEY_ACCESS_PRV) $(KEY_ACCESS_PUB) $(KEY_REFRESH) $(KEY_SERVICE) $(KEY_CURSOR)
 	@echo "JWT keys generated."
\ No newline at end of file